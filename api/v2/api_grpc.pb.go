@@ -19,23 +19,32 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Dex_GetClient_FullMethodName       = "/api.Dex/GetClient"
-	Dex_CreateClient_FullMethodName    = "/api.Dex/CreateClient"
-	Dex_UpdateClient_FullMethodName    = "/api.Dex/UpdateClient"
-	Dex_DeleteClient_FullMethodName    = "/api.Dex/DeleteClient"
-	Dex_CreatePassword_FullMethodName  = "/api.Dex/CreatePassword"
-	Dex_UpdatePassword_FullMethodName  = "/api.Dex/UpdatePassword"
-	Dex_DeletePassword_FullMethodName  = "/api.Dex/DeletePassword"
-	Dex_ListPasswords_FullMethodName   = "/api.Dex/ListPasswords"
-	Dex_CreateConnector_FullMethodName = "/api.Dex/CreateConnector"
-	Dex_UpdateConnector_FullMethodName = "/api.Dex/UpdateConnector"
-	Dex_DeleteConnector_FullMethodName = "/api.Dex/DeleteConnector"
-	Dex_ListConnectors_FullMethodName  = "/api.Dex/ListConnectors"
-	Dex_GetVersion_FullMethodName      = "/api.Dex/GetVersion"
-	Dex_GetDiscovery_FullMethodName    = "/api.Dex/GetDiscovery"
-	Dex_ListRefresh_FullMethodName     = "/api.Dex/ListRefresh"
-	Dex_RevokeRefresh_FullMethodName   = "/api.Dex/RevokeRefresh"
-	Dex_VerifyPassword_FullMethodName  = "/api.Dex/VerifyPassword"
+	Dex_GetClient_FullMethodName                  = "/api.Dex/GetClient"
+	Dex_FindClientsByName_FullMethodName          = "/api.Dex/FindClientsByName"
+	Dex_GetClientByName_FullMethodName            = "/api.Dex/GetClientByName"
+	Dex_ListClients_FullMethodName                = "/api.Dex/ListClients"
+	Dex_WatchClients_FullMethodName               = "/api.Dex/WatchClients"
+	Dex_CreateClient_FullMethodName               = "/api.Dex/CreateClient"
+	Dex_BatchCreateClients_FullMethodName         = "/api.Dex/BatchCreateClients"
+	Dex_UpdateClient_FullMethodName               = "/api.Dex/UpdateClient"
+	Dex_RotateClientSecret_FullMethodName         = "/api.Dex/RotateClientSecret"
+	Dex_DeleteClient_FullMethodName               = "/api.Dex/DeleteClient"
+	Dex_CreatePassword_FullMethodName             = "/api.Dex/CreatePassword"
+	Dex_UpdatePassword_FullMethodName             = "/api.Dex/UpdatePassword"
+	Dex_DeletePassword_FullMethodName             = "/api.Dex/DeletePassword"
+	Dex_BatchDeletePasswords_FullMethodName       = "/api.Dex/BatchDeletePasswords"
+	Dex_ListPasswords_FullMethodName              = "/api.Dex/ListPasswords"
+	Dex_CreateConnector_FullMethodName            = "/api.Dex/CreateConnector"
+	Dex_UpdateConnector_FullMethodName            = "/api.Dex/UpdateConnector"
+	Dex_DeleteConnector_FullMethodName            = "/api.Dex/DeleteConnector"
+	Dex_ListConnectors_FullMethodName             = "/api.Dex/ListConnectors"
+	Dex_GetVersion_FullMethodName                 = "/api.Dex/GetVersion"
+	Dex_GetDiscovery_FullMethodName               = "/api.Dex/GetDiscovery"
+	Dex_ListRefresh_FullMethodName                = "/api.Dex/ListRefresh"
+	Dex_RevokeRefresh_FullMethodName              = "/api.Dex/RevokeRefresh"
+	Dex_DeleteRefreshTokensForUser_FullMethodName = "/api.Dex/DeleteRefreshTokensForUser"
+	Dex_GetClientGrantedScopes_FullMethodName     = "/api.Dex/GetClientGrantedScopes"
+	Dex_VerifyPassword_FullMethodName             = "/api.Dex/VerifyPassword"
 )
 
 // DexClient is the client API for Dex service.
@@ -46,10 +55,30 @@ const (
 type DexClient interface {
 	// GetClient gets a client.
 	GetClient(ctx context.Context, in *GetClientReq, opts ...grpc.CallOption) (*GetClientResp, error)
+	// FindClientsByName gets all clients with the given name.
+	FindClientsByName(ctx context.Context, in *FindClientsByNameReq, opts ...grpc.CallOption) (*FindClientsByNameResp, error)
+	// GetClientByName gets the client with the given name, if exactly one has it.
+	GetClientByName(ctx context.Context, in *GetClientByNameReq, opts ...grpc.CallOption) (*GetClientByNameResp, error)
+	// ListClients lists all clients, one page at a time.
+	ListClients(ctx context.Context, in *ListClientsReq, opts ...grpc.CallOption) (*ListClientsResp, error)
+	// WatchClients streams changes to clients made after the call, such as by
+	// other API calls or other dex instances. Changes are detected by polling
+	// storage, so they arrive with a delay and a client changed several times
+	// in between polls only yields a single event. The response headers are
+	// sent once the watch is established.
+	WatchClients(ctx context.Context, in *WatchClientsReq, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ClientEvent], error)
 	// CreateClient creates a client.
 	CreateClient(ctx context.Context, in *CreateClientReq, opts ...grpc.CallOption) (*CreateClientResp, error)
+	// BatchCreateClients creates several clients on a best-effort basis. The
+	// whole batch is rejected if any client is invalid, otherwise clients that
+	// already exist are reported and the others created. The batch isn't
+	// transactional: if storage fails to create a client, its result holds the
+	// error and the remaining clients are still created.
+	BatchCreateClients(ctx context.Context, in *BatchCreateClientsReq, opts ...grpc.CallOption) (*BatchCreateClientsResp, error)
 	// UpdateClient updates an existing client
 	UpdateClient(ctx context.Context, in *UpdateClientReq, opts ...grpc.CallOption) (*UpdateClientResp, error)
+	// RotateClientSecret replaces the secret of a client.
+	RotateClientSecret(ctx context.Context, in *RotateClientSecretReq, opts ...grpc.CallOption) (*RotateClientSecretResp, error)
 	// DeleteClient deletes the provided client.
 	DeleteClient(ctx context.Context, in *DeleteClientReq, opts ...grpc.CallOption) (*DeleteClientResp, error)
 	// CreatePassword creates a password.
@@ -58,6 +87,12 @@ type DexClient interface {
 	UpdatePassword(ctx context.Context, in *UpdatePasswordReq, opts ...grpc.CallOption) (*UpdatePasswordResp, error)
 	// DeletePassword deletes the password.
 	DeletePassword(ctx context.Context, in *DeletePasswordReq, opts ...grpc.CallOption) (*DeletePasswordResp, error)
+	// BatchDeletePasswords deletes several passwords on a best-effort basis. The
+	// whole batch is rejected if any email is empty, otherwise passwords that
+	// don't exist are reported and the others deleted. The batch isn't
+	// transactional: if storage fails to delete a password, its result holds the
+	// error and the remaining passwords are still deleted.
+	BatchDeletePasswords(ctx context.Context, in *BatchDeletePasswordsReq, opts ...grpc.CallOption) (*BatchDeletePasswordsResp, error)
 	// ListPassword lists all password entries.
 	ListPasswords(ctx context.Context, in *ListPasswordReq, opts ...grpc.CallOption) (*ListPasswordResp, error)
 	// CreateConnector creates a connector.
@@ -78,6 +113,10 @@ type DexClient interface {
 	//
 	// Note that each user-client pair can have only one refresh token at a time.
 	RevokeRefresh(ctx context.Context, in *RevokeRefreshReq, opts ...grpc.CallOption) (*RevokeRefreshResp, error)
+	// DeleteRefreshTokensForUser revokes all refresh tokens of a user, across all clients.
+	DeleteRefreshTokensForUser(ctx context.Context, in *DeleteRefreshTokensForUserReq, opts ...grpc.CallOption) (*DeleteRefreshTokensForUserResp, error)
+	// GetClientGrantedScopes returns the scopes granted to a client across all of its refresh tokens.
+	GetClientGrantedScopes(ctx context.Context, in *ClientGrantedScopesReq, opts ...grpc.CallOption) (*ClientGrantedScopesResp, error)
 	// VerifyPassword returns whether a password matches a hash for a specific email or not.
 	VerifyPassword(ctx context.Context, in *VerifyPasswordReq, opts ...grpc.CallOption) (*VerifyPasswordResp, error)
 }
@@ -100,6 +139,55 @@ func (c *dexClient) GetClient(ctx context.Context, in *GetClientReq, opts ...grp
 	return out, nil
 }
 
+func (c *dexClient) FindClientsByName(ctx context.Context, in *FindClientsByNameReq, opts ...grpc.CallOption) (*FindClientsByNameResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FindClientsByNameResp)
+	err := c.cc.Invoke(ctx, Dex_FindClientsByName_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dexClient) GetClientByName(ctx context.Context, in *GetClientByNameReq, opts ...grpc.CallOption) (*GetClientByNameResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetClientByNameResp)
+	err := c.cc.Invoke(ctx, Dex_GetClientByName_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dexClient) ListClients(ctx context.Context, in *ListClientsReq, opts ...grpc.CallOption) (*ListClientsResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListClientsResp)
+	err := c.cc.Invoke(ctx, Dex_ListClients_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dexClient) WatchClients(ctx context.Context, in *WatchClientsReq, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ClientEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Dex_ServiceDesc.Streams[0], Dex_WatchClients_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchClientsReq, ClientEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Dex_WatchClientsClient = grpc.ServerStreamingClient[ClientEvent]
+
 func (c *dexClient) CreateClient(ctx context.Context, in *CreateClientReq, opts ...grpc.CallOption) (*CreateClientResp, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(CreateClientResp)
@@ -110,6 +198,16 @@ func (c *dexClient) CreateClient(ctx context.Context, in *CreateClientReq, opts
 	return out, nil
 }
 
+func (c *dexClient) BatchCreateClients(ctx context.Context, in *BatchCreateClientsReq, opts ...grpc.CallOption) (*BatchCreateClientsResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchCreateClientsResp)
+	err := c.cc.Invoke(ctx, Dex_BatchCreateClients_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *dexClient) UpdateClient(ctx context.Context, in *UpdateClientReq, opts ...grpc.CallOption) (*UpdateClientResp, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(UpdateClientResp)
@@ -120,6 +218,16 @@ func (c *dexClient) UpdateClient(ctx context.Context, in *UpdateClientReq, opts
 	return out, nil
 }
 
+func (c *dexClient) RotateClientSecret(ctx context.Context, in *RotateClientSecretReq, opts ...grpc.CallOption) (*RotateClientSecretResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RotateClientSecretResp)
+	err := c.cc.Invoke(ctx, Dex_RotateClientSecret_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *dexClient) DeleteClient(ctx context.Context, in *DeleteClientReq, opts ...grpc.CallOption) (*DeleteClientResp, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(DeleteClientResp)
@@ -160,6 +268,16 @@ func (c *dexClient) DeletePassword(ctx context.Context, in *DeletePasswordReq, o
 	return out, nil
 }
 
+func (c *dexClient) BatchDeletePasswords(ctx context.Context, in *BatchDeletePasswordsReq, opts ...grpc.CallOption) (*BatchDeletePasswordsResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchDeletePasswordsResp)
+	err := c.cc.Invoke(ctx, Dex_BatchDeletePasswords_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *dexClient) ListPasswords(ctx context.Context, in *ListPasswordReq, opts ...grpc.CallOption) (*ListPasswordResp, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ListPasswordResp)
@@ -250,6 +368,26 @@ func (c *dexClient) RevokeRefresh(ctx context.Context, in *RevokeRefreshReq, opt
 	return out, nil
 }
 
+func (c *dexClient) DeleteRefreshTokensForUser(ctx context.Context, in *DeleteRefreshTokensForUserReq, opts ...grpc.CallOption) (*DeleteRefreshTokensForUserResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteRefreshTokensForUserResp)
+	err := c.cc.Invoke(ctx, Dex_DeleteRefreshTokensForUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dexClient) GetClientGrantedScopes(ctx context.Context, in *ClientGrantedScopesReq, opts ...grpc.CallOption) (*ClientGrantedScopesResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ClientGrantedScopesResp)
+	err := c.cc.Invoke(ctx, Dex_GetClientGrantedScopes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *dexClient) VerifyPassword(ctx context.Context, in *VerifyPasswordReq, opts ...grpc.CallOption) (*VerifyPasswordResp, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(VerifyPasswordResp)
@@ -268,10 +406,30 @@ func (c *dexClient) VerifyPassword(ctx context.Context, in *VerifyPasswordReq, o
 type DexServer interface {
 	// GetClient gets a client.
 	GetClient(context.Context, *GetClientReq) (*GetClientResp, error)
+	// FindClientsByName gets all clients with the given name.
+	FindClientsByName(context.Context, *FindClientsByNameReq) (*FindClientsByNameResp, error)
+	// GetClientByName gets the client with the given name, if exactly one has it.
+	GetClientByName(context.Context, *GetClientByNameReq) (*GetClientByNameResp, error)
+	// ListClients lists all clients, one page at a time.
+	ListClients(context.Context, *ListClientsReq) (*ListClientsResp, error)
+	// WatchClients streams changes to clients made after the call, such as by
+	// other API calls or other dex instances. Changes are detected by polling
+	// storage, so they arrive with a delay and a client changed several times
+	// in between polls only yields a single event. The response headers are
+	// sent once the watch is established.
+	WatchClients(*WatchClientsReq, grpc.ServerStreamingServer[ClientEvent]) error
 	// CreateClient creates a client.
 	CreateClient(context.Context, *CreateClientReq) (*CreateClientResp, error)
+	// BatchCreateClients creates several clients on a best-effort basis. The
+	// whole batch is rejected if any client is invalid, otherwise clients that
+	// already exist are reported and the others created. The batch isn't
+	// transactional: if storage fails to create a client, its result holds the
+	// error and the remaining clients are still created.
+	BatchCreateClients(context.Context, *BatchCreateClientsReq) (*BatchCreateClientsResp, error)
 	// UpdateClient updates an existing client
 	UpdateClient(context.Context, *UpdateClientReq) (*UpdateClientResp, error)
+	// RotateClientSecret replaces the secret of a client.
+	RotateClientSecret(context.Context, *RotateClientSecretReq) (*RotateClientSecretResp, error)
 	// DeleteClient deletes the provided client.
 	DeleteClient(context.Context, *DeleteClientReq) (*DeleteClientResp, error)
 	// CreatePassword creates a password.
@@ -280,6 +438,12 @@ type DexServer interface {
 	UpdatePassword(context.Context, *UpdatePasswordReq) (*UpdatePasswordResp, error)
 	// DeletePassword deletes the password.
 	DeletePassword(context.Context, *DeletePasswordReq) (*DeletePasswordResp, error)
+	// BatchDeletePasswords deletes several passwords on a best-effort basis. The
+	// whole batch is rejected if any email is empty, otherwise passwords that
+	// don't exist are reported and the others deleted. The batch isn't
+	// transactional: if storage fails to delete a password, its result holds the
+	// error and the remaining passwords are still deleted.
+	BatchDeletePasswords(context.Context, *BatchDeletePasswordsReq) (*BatchDeletePasswordsResp, error)
 	// ListPassword lists all password entries.
 	ListPasswords(context.Context, *ListPasswordReq) (*ListPasswordResp, error)
 	// CreateConnector creates a connector.
@@ -300,6 +464,10 @@ type DexServer interface {
 	//
 	// Note that each user-client pair can have only one refresh token at a time.
 	RevokeRefresh(context.Context, *RevokeRefreshReq) (*RevokeRefreshResp, error)
+	// DeleteRefreshTokensForUser revokes all refresh tokens of a user, across all clients.
+	DeleteRefreshTokensForUser(context.Context, *DeleteRefreshTokensForUserReq) (*DeleteRefreshTokensForUserResp, error)
+	// GetClientGrantedScopes returns the scopes granted to a client across all of its refresh tokens.
+	GetClientGrantedScopes(context.Context, *ClientGrantedScopesReq) (*ClientGrantedScopesResp, error)
 	// VerifyPassword returns whether a password matches a hash for a specific email or not.
 	VerifyPassword(context.Context, *VerifyPasswordReq) (*VerifyPasswordResp, error)
 	mustEmbedUnimplementedDexServer()
@@ -315,12 +483,30 @@ type UnimplementedDexServer struct{}
 func (UnimplementedDexServer) GetClient(context.Context, *GetClientReq) (*GetClientResp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetClient not implemented")
 }
+func (UnimplementedDexServer) FindClientsByName(context.Context, *FindClientsByNameReq) (*FindClientsByNameResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindClientsByName not implemented")
+}
+func (UnimplementedDexServer) GetClientByName(context.Context, *GetClientByNameReq) (*GetClientByNameResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetClientByName not implemented")
+}
+func (UnimplementedDexServer) ListClients(context.Context, *ListClientsReq) (*ListClientsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListClients not implemented")
+}
+func (UnimplementedDexServer) WatchClients(*WatchClientsReq, grpc.ServerStreamingServer[ClientEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchClients not implemented")
+}
 func (UnimplementedDexServer) CreateClient(context.Context, *CreateClientReq) (*CreateClientResp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateClient not implemented")
 }
+func (UnimplementedDexServer) BatchCreateClients(context.Context, *BatchCreateClientsReq) (*BatchCreateClientsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchCreateClients not implemented")
+}
 func (UnimplementedDexServer) UpdateClient(context.Context, *UpdateClientReq) (*UpdateClientResp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateClient not implemented")
 }
+func (UnimplementedDexServer) RotateClientSecret(context.Context, *RotateClientSecretReq) (*RotateClientSecretResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RotateClientSecret not implemented")
+}
 func (UnimplementedDexServer) DeleteClient(context.Context, *DeleteClientReq) (*DeleteClientResp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteClient not implemented")
 }
@@ -333,6 +519,9 @@ func (UnimplementedDexServer) UpdatePassword(context.Context, *UpdatePasswordReq
 func (UnimplementedDexServer) DeletePassword(context.Context, *DeletePasswordReq) (*DeletePasswordResp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeletePassword not implemented")
 }
+func (UnimplementedDexServer) BatchDeletePasswords(context.Context, *BatchDeletePasswordsReq) (*BatchDeletePasswordsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchDeletePasswords not implemented")
+}
 func (UnimplementedDexServer) ListPasswords(context.Context, *ListPasswordReq) (*ListPasswordResp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListPasswords not implemented")
 }
@@ -360,6 +549,12 @@ func (UnimplementedDexServer) ListRefresh(context.Context, *ListRefreshReq) (*Li
 func (UnimplementedDexServer) RevokeRefresh(context.Context, *RevokeRefreshReq) (*RevokeRefreshResp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RevokeRefresh not implemented")
 }
+func (UnimplementedDexServer) DeleteRefreshTokensForUser(context.Context, *DeleteRefreshTokensForUserReq) (*DeleteRefreshTokensForUserResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteRefreshTokensForUser not implemented")
+}
+func (UnimplementedDexServer) GetClientGrantedScopes(context.Context, *ClientGrantedScopesReq) (*ClientGrantedScopesResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetClientGrantedScopes not implemented")
+}
 func (UnimplementedDexServer) VerifyPassword(context.Context, *VerifyPasswordReq) (*VerifyPasswordResp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method VerifyPassword not implemented")
 }
@@ -402,6 +597,71 @@ func _Dex_GetClient_Handler(srv interface{}, ctx context.Context, dec func(inter
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Dex_FindClientsByName_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindClientsByNameReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DexServer).FindClientsByName(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dex_FindClientsByName_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DexServer).FindClientsByName(ctx, req.(*FindClientsByNameReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dex_GetClientByName_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetClientByNameReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DexServer).GetClientByName(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dex_GetClientByName_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DexServer).GetClientByName(ctx, req.(*GetClientByNameReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dex_ListClients_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListClientsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DexServer).ListClients(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dex_ListClients_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DexServer).ListClients(ctx, req.(*ListClientsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dex_WatchClients_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchClientsReq)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DexServer).WatchClients(m, &grpc.GenericServerStream[WatchClientsReq, ClientEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Dex_WatchClientsServer = grpc.ServerStreamingServer[ClientEvent]
+
 func _Dex_CreateClient_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CreateClientReq)
 	if err := dec(in); err != nil {
@@ -420,6 +680,24 @@ func _Dex_CreateClient_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Dex_BatchCreateClients_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchCreateClientsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DexServer).BatchCreateClients(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dex_BatchCreateClients_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DexServer).BatchCreateClients(ctx, req.(*BatchCreateClientsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Dex_UpdateClient_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(UpdateClientReq)
 	if err := dec(in); err != nil {
@@ -438,6 +716,24 @@ func _Dex_UpdateClient_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Dex_RotateClientSecret_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RotateClientSecretReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DexServer).RotateClientSecret(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dex_RotateClientSecret_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DexServer).RotateClientSecret(ctx, req.(*RotateClientSecretReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Dex_DeleteClient_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DeleteClientReq)
 	if err := dec(in); err != nil {
@@ -510,6 +806,24 @@ func _Dex_DeletePassword_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Dex_BatchDeletePasswords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchDeletePasswordsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DexServer).BatchDeletePasswords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dex_BatchDeletePasswords_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DexServer).BatchDeletePasswords(ctx, req.(*BatchDeletePasswordsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Dex_ListPasswords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ListPasswordReq)
 	if err := dec(in); err != nil {
@@ -672,6 +986,42 @@ func _Dex_RevokeRefresh_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Dex_DeleteRefreshTokensForUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRefreshTokensForUserReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DexServer).DeleteRefreshTokensForUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dex_DeleteRefreshTokensForUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DexServer).DeleteRefreshTokensForUser(ctx, req.(*DeleteRefreshTokensForUserReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dex_GetClientGrantedScopes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClientGrantedScopesReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DexServer).GetClientGrantedScopes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dex_GetClientGrantedScopes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DexServer).GetClientGrantedScopes(ctx, req.(*ClientGrantedScopesReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Dex_VerifyPassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(VerifyPasswordReq)
 	if err := dec(in); err != nil {
@@ -701,14 +1051,34 @@ var Dex_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetClient",
 			Handler:    _Dex_GetClient_Handler,
 		},
+		{
+			MethodName: "FindClientsByName",
+			Handler:    _Dex_FindClientsByName_Handler,
+		},
+		{
+			MethodName: "GetClientByName",
+			Handler:    _Dex_GetClientByName_Handler,
+		},
+		{
+			MethodName: "ListClients",
+			Handler:    _Dex_ListClients_Handler,
+		},
 		{
 			MethodName: "CreateClient",
 			Handler:    _Dex_CreateClient_Handler,
 		},
+		{
+			MethodName: "BatchCreateClients",
+			Handler:    _Dex_BatchCreateClients_Handler,
+		},
 		{
 			MethodName: "UpdateClient",
 			Handler:    _Dex_UpdateClient_Handler,
 		},
+		{
+			MethodName: "RotateClientSecret",
+			Handler:    _Dex_RotateClientSecret_Handler,
+		},
 		{
 			MethodName: "DeleteClient",
 			Handler:    _Dex_DeleteClient_Handler,
@@ -725,6 +1095,10 @@ var Dex_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeletePassword",
 			Handler:    _Dex_DeletePassword_Handler,
 		},
+		{
+			MethodName: "BatchDeletePasswords",
+			Handler:    _Dex_BatchDeletePasswords_Handler,
+		},
 		{
 			MethodName: "ListPasswords",
 			Handler:    _Dex_ListPasswords_Handler,
@@ -761,11 +1135,25 @@ var Dex_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RevokeRefresh",
 			Handler:    _Dex_RevokeRefresh_Handler,
 		},
+		{
+			MethodName: "DeleteRefreshTokensForUser",
+			Handler:    _Dex_DeleteRefreshTokensForUser_Handler,
+		},
+		{
+			MethodName: "GetClientGrantedScopes",
+			Handler:    _Dex_GetClientGrantedScopes_Handler,
+		},
 		{
 			MethodName: "VerifyPassword",
 			Handler:    _Dex_VerifyPassword_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchClients",
+			Handler:       _Dex_WatchClients_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "api/v2/api.proto",
 }
@@ -9,6 +9,7 @@ import (
 	"net/netip"
 	"os"
 	"strings"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 
@@ -76,6 +77,9 @@ func (c Config) Validate() error {
 		{c.GRPC.TLSMinVersion != "" && c.GRPC.TLSMinVersion != "1.2" && c.GRPC.TLSMinVersion != "1.3", "supported TLS versions are: 1.2, 1.3"},
 		{c.GRPC.TLSMaxVersion != "" && c.GRPC.TLSMaxVersion != "1.2" && c.GRPC.TLSMaxVersion != "1.3", "supported TLS versions are: 1.2, 1.3"},
 		{c.GRPC.TLSMaxVersion != "" && c.GRPC.TLSMinVersion != "" && c.GRPC.TLSMinVersion > c.GRPC.TLSMaxVersion, "TLSMinVersion greater than TLSMaxVersion"},
+		{c.GRPC.MinClientSecretLength < 0, "gRPC minimum client secret length must not be negative"},
+		{c.GRPC.MinClientSecretEntropy < 0, "gRPC minimum client secret entropy must not be negative"},
+		{c.GRPC.PasswordHashCost != 0 && (c.GRPC.PasswordHashCost < bcrypt.DefaultCost || c.GRPC.PasswordHashCost > 16), "gRPC password hash cost must be between 10 and 16"},
 	}
 
 	var checkErrors []string
@@ -149,6 +153,21 @@ type OAuth2 struct {
 	AlwaysShowLoginScreen bool `json:"alwaysShowLoginScreen"`
 	// This is the connector that can be used for password grant
 	PasswordConnector string `json:"passwordConnector"`
+	// Format of the codes issued by the device flow, defaults are used if unset.
+	DeviceCodes DeviceCodes `json:"deviceCodes"`
+}
+
+// DeviceCodes is the config format for device flow codes.
+type DeviceCodes struct {
+	// Number of characters in a user code, excluding separators. Defaults to 8.
+	UserCodeLength int `json:"userCodeLength"`
+	// User codes are split into dash-separated groups of this size. Defaults to 4.
+	UserCodeGroupSize int `json:"userCodeGroupSize"`
+	// Upper case characters user codes are drawn from. Ambiguous characters
+	// such as 0, O, 1 and I are rejected. Defaults to "BCDFGHJKLMNPQRSTVWXZ".
+	UserCodeCharacters string `json:"userCodeCharacters"`
+	// Number of random bytes device codes are generated from. Defaults to 32.
+	DeviceCodeLength int `json:"deviceCodeLength"`
 }
 
 // Web is the config format for the HTTP server.
@@ -238,6 +257,8 @@ type Telemetry struct {
 	HTTP string `json:"http"`
 	// EnableProfiling makes profiling endpoints available via web interface host:port/debug/pprof/
 	EnableProfiling bool `json:"enableProfiling"`
+	// ConnectorHealthChecks adds a health check per connector that can ping its upstream provider.
+	ConnectorHealthChecks bool `json:"connectorHealthChecks"`
 }
 
 // GRPC is the config for the gRPC API.
@@ -250,6 +271,19 @@ type GRPC struct {
 	TLSMinVersion string `json:"tlsMinVersion"`
 	TLSMaxVersion string `json:"tlsMaxVersion"`
 	Reflection    bool   `json:"reflection"`
+
+	// MinClientSecretLength and MinClientSecretEntropy (in bits) reject weak
+	// secrets supplied through CreateClient. Zero disables the check.
+	MinClientSecretLength  int     `json:"minClientSecretLength"`
+	MinClientSecretEntropy float64 `json:"minClientSecretEntropy"`
+
+	// PasswordHashCost is the bcrypt cost of passwords supplied in plain text
+	// through CreatePassword and UpdatePassword. Defaults to 12.
+	PasswordHashCost int `json:"passwordHashCost"`
+
+	// AuditLog logs changes made through the API along with the caller
+	// identifying itself with the x-dex-caller metadata.
+	AuditLog bool `json:"auditLog"`
 }
 
 // Storage holds app's storage configuration.
@@ -275,6 +309,26 @@ var (
 	_ StorageConfig = (*ent.MySQL)(nil)
 )
 
+// setDeviceRequestTTL passes the device request lifetime to the SQL storages,
+// which use it to date device requests stored before their creation time was
+// recorded.
+func setDeviceRequestTTL(s StorageConfig, ttl time.Duration) {
+	switch s := s.(type) {
+	case *sql.SQLite3:
+		s.DeviceRequestTTL = ttl
+	case *sql.Postgres:
+		s.DeviceRequestTTL = ttl
+	case *sql.MySQL:
+		s.DeviceRequestTTL = ttl
+	case *ent.SQLite3:
+		s.DeviceRequestTTL = ttl
+	case *ent.Postgres:
+		s.DeviceRequestTTL = ttl
+	case *ent.MySQL:
+		s.DeviceRequestTTL = ttl
+	}
+}
+
 func getORMBasedSQLStorage(normal, entBased StorageConfig) func() StorageConfig {
 	return func() StorageConfig {
 		if featureflags.EntEnabled.Enabled() {
@@ -455,6 +509,12 @@ type Expiry struct {
 	// DeviceRequests defines the duration of time for which the DeviceRequests will be valid.
 	DeviceRequests string `json:"deviceRequests"`
 
+	// MaxAccessTokenLifetime caps the per-client access token lifetime accepted by the API.
+	MaxAccessTokenLifetime string `json:"maxAccessTokenLifetime"`
+
+	// GCFrequency defines how often expired objects are removed from the storage.
+	GCFrequency string `json:"gcFrequency"`
+
 	// RefreshTokens defines refresh tokens expiry policy
 	RefreshTokens RefreshToken `json:"refreshTokens"`
 }
@@ -473,4 +533,5 @@ type RefreshToken struct {
 	ReuseInterval     string `json:"reuseInterval"`
 	AbsoluteLifetime  string `json:"absoluteLifetime"`
 	ValidIfNotUsedFor string `json:"validIfNotUsedFor"`
+	RevokeOnReplay    bool   `json:"revokeOnReplay"`
 }
@@ -196,6 +196,16 @@ func runServe(options serveOptions) error {
 		grpcOptions = append(grpcOptions, grpc.Creds(credentials.NewTLS(tlsConfig)))
 	}
 
+	var deviceRequestsValidFor time.Duration
+	if c.Expiry.DeviceRequests != "" {
+		deviceRequests, err := time.ParseDuration(c.Expiry.DeviceRequests)
+		if err != nil {
+			return fmt.Errorf("invalid config value %q for device request expiry: %v", c.Expiry.DeviceRequests, err)
+		}
+		deviceRequestsValidFor = deviceRequests
+		setDeviceRequestTTL(c.Storage.Config, deviceRequests)
+	}
+
 	s, err := c.Storage.Config.Open(logger)
 	if err != nil {
 		return fmt.Errorf("failed to initialize storage: %v", err)
@@ -268,6 +278,12 @@ func runServe(options serveOptions) error {
 
 	s = storage.WithStaticConnectors(s, storageConnectors)
 
+	deviceCodeFormat := storage.DeviceCodeFormat(c.OAuth2.DeviceCodes)
+	if err := deviceCodeFormat.Validate(); err != nil {
+		return fmt.Errorf("invalid config: device codes: %v", err)
+	}
+	s = storage.WithDeviceCodeValidation(s, deviceCodeFormat)
+
 	if len(c.OAuth2.ResponseTypes) > 0 {
 		logger.Info("config response types accepted", "response_types", c.OAuth2.ResponseTypes)
 	}
@@ -292,6 +308,7 @@ func runServe(options serveOptions) error {
 		SkipApprovalScreen:     c.OAuth2.SkipApprovalScreen,
 		AlwaysShowLoginScreen:  c.OAuth2.AlwaysShowLoginScreen,
 		PasswordConnector:      c.OAuth2.PasswordConnector,
+		DeviceCodeFormat:       deviceCodeFormat,
 		Headers:                c.Web.Headers.ToHTTPHeader(),
 		AllowedOrigins:         c.Web.AllowedOrigins,
 		AllowedHeaders:         c.Web.AllowedHeaders,
@@ -302,6 +319,13 @@ func runServe(options serveOptions) error {
 		Now:                    now,
 		PrometheusRegistry:     prometheusRegistry,
 		HealthChecker:          healthChecker,
+		ConnectorHealthChecks:  c.Telemetry.ConnectorHealthChecks,
+		MinClientSecretLength:  c.GRPC.MinClientSecretLength,
+		MinClientSecretEntropy: c.GRPC.MinClientSecretEntropy,
+		PasswordHashCost:       c.GRPC.PasswordHashCost,
+	}
+	if c.GRPC.AuditLog {
+		serverConfig.APIAuditLogger = logger.With("component", "api-audit")
 	}
 	if c.Expiry.SigningKeys != "" {
 		signingKeys, err := time.ParseDuration(c.Expiry.SigningKeys)
@@ -327,13 +351,28 @@ func runServe(options serveOptions) error {
 		logger.Info("config auth requests", "valid_for", authRequests)
 		serverConfig.AuthRequestsValidFor = authRequests
 	}
-	if c.Expiry.DeviceRequests != "" {
-		deviceRequests, err := time.ParseDuration(c.Expiry.DeviceRequests)
+	if deviceRequestsValidFor != 0 {
+		logger.Info("config device requests", "valid_for", deviceRequestsValidFor)
+		serverConfig.DeviceRequestsValidFor = deviceRequestsValidFor
+	}
+	if c.Expiry.MaxAccessTokenLifetime != "" {
+		maxAccessTokenLifetime, err := time.ParseDuration(c.Expiry.MaxAccessTokenLifetime)
 		if err != nil {
-			return fmt.Errorf("invalid config value %q for device request expiry: %v", c.Expiry.AuthRequests, err)
+			return fmt.Errorf("invalid config value %q for max access token lifetime: %v", c.Expiry.MaxAccessTokenLifetime, err)
+		}
+		logger.Info("config max access token lifetime", "max", maxAccessTokenLifetime)
+		serverConfig.MaxAccessTokenLifetime = maxAccessTokenLifetime
+	}
+	if c.Expiry.GCFrequency != "" {
+		gcFrequency, err := time.ParseDuration(c.Expiry.GCFrequency)
+		if err != nil {
+			return fmt.Errorf("invalid config value %q for garbage collection frequency: %v", c.Expiry.GCFrequency, err)
+		}
+		if gcFrequency <= 0 {
+			return fmt.Errorf("invalid config value %q for garbage collection frequency: must be positive", c.Expiry.GCFrequency)
 		}
-		logger.Info("config device requests", "valid_for", deviceRequests)
-		serverConfig.DeviceRequestsValidFor = deviceRequests
+		logger.Info("config garbage collection", "frequency", gcFrequency)
+		serverConfig.GCFrequency = gcFrequency
 	}
 	refreshTokenPolicy, err := server.NewRefreshTokenPolicy(
 		logger,
@@ -341,6 +380,7 @@ func runServe(options serveOptions) error {
 		c.Expiry.RefreshTokens.ValidIfNotUsedFor,
 		c.Expiry.RefreshTokens.AbsoluteLifetime,
 		c.Expiry.RefreshTokens.ReuseInterval,
+		c.Expiry.RefreshTokens.RevokeOnReplay,
 	)
 	if err != nil {
 		return fmt.Errorf("invalid refresh token expiration policy config: %v", err)
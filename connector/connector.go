@@ -3,7 +3,11 @@ package connector
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // Connector is a mechanism for federating login to a remote identity service.
@@ -31,6 +35,10 @@ type Identity struct {
 
 	Groups []string
 
+	// ExtraClaims holds additional, connector-specific claims to include in the
+	// ID token. Claims which collide with the standard claims are ignored.
+	ExtraClaims map[string]interface{}
+
 	// ConnectorData holds data used by the connector for subsequent requests after initial
 	// authentication, such as access tokens for upstream provides.
 	//
@@ -100,6 +108,56 @@ type RefreshConnector interface {
 	Refresh(ctx context.Context, s Scopes, identity Identity) (Identity, error)
 }
 
+// AuthorizationError is returned, possibly wrapped, when the upstream
+// authenticated a user but the connector's configuration denies the login.
+type AuthorizationError struct {
+	// User identifies the denied user upstream.
+	User string
+	// Denied lists the groups, orgs or similar the configuration requires
+	// which didn't authorize the user. It is meant for logs and isn't part of
+	// the error message.
+	Denied []string
+}
+
+func (e *AuthorizationError) Error() string {
+	return fmt.Sprintf("user %q is not authorized", e.User)
+}
+
+// Errors a RefreshConnector may return, possibly wrapped, to tell the server why
+// a refresh failed.
+var (
+	// ErrUserNotFound means the upstream account no longer exists. Retrying
+	// won't help, so the session should be terminated.
+	ErrUserNotFound = errors.New("user no longer exists")
+
+	// ErrTokenRevoked means the upstream rejected the credentials stored for
+	// the user, e.g. because the user revoked dex's authorization. The user has
+	// to log in again.
+	ErrTokenRevoked = errors.New("access token revoked")
+
+	// ErrUnavailable means the upstream is temporarily unavailable, e.g. down
+	// for maintenance. Clients should back off and retry later.
+	ErrUnavailable = errors.New("service unavailable")
+)
+
 type TokenIdentityConnector interface {
 	TokenIdentity(ctx context.Context, subjectTokenType, subjectToken string) (Identity, error)
 }
+
+// MetricsConnector is an interface implemented by connectors which export
+// Prometheus metrics.
+type MetricsConnector interface {
+	// RegisterMetrics is called once the connector is opened, if the server
+	// exports metrics. Connectors opened again must reuse collectors already
+	// registered with reg.
+	RegisterMetrics(reg prometheus.Registerer) error
+}
+
+// PingConnector is an interface implemented by connectors which can check
+// that their upstream identity provider is reachable.
+type PingConnector interface {
+	// Ping returns an error if the upstream identity provider can't be
+	// reached. It's called periodically by health checks, so it should be
+	// cheap.
+	Ping(ctx context.Context) error
+}
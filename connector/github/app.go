@@ -0,0 +1,199 @@
+package github
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"golang.org/x/oauth2"
+)
+
+// appJWTLifetime is how long the JWTs dex signs as a GitHub App are valid for.
+// GitHub accepts at most 10 minutes.
+const appJWTLifetime = 9 * time.Minute
+
+// appJWTClockSkew backdates the JWTs' issue time, as recommended by GitHub, to
+// allow for clock drift.
+const appJWTClockSkew = time.Minute
+
+// appTokenSource mints installation access tokens of a GitHub App.
+//
+// See https://docs.github.com/en/apps/creating-github-apps/authenticating-with-a-github-app/authenticating-as-a-github-app-installation
+type appTokenSource struct {
+	apiURL         string
+	appID          int64
+	installationID int64
+	key            *rsa.PrivateKey
+	// used to request tokens, http.DefaultClient if nil
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// newAppTokenSource returns a token source for the installation that caches
+// tokens until shortly before they expire.
+func newAppTokenSource(apiURL string, appID, installationID int64, key *rsa.PrivateKey, httpClient *http.Client) oauth2.TokenSource {
+	return oauth2.ReuseTokenSource(nil, &appTokenSource{
+		apiURL:         apiURL,
+		appID:          appID,
+		installationID: installationID,
+		key:            key,
+		httpClient:     httpClient,
+		now:            time.Now,
+	})
+}
+
+// Token exchanges a JWT signed with the app's private key for an installation
+// access token.
+func (s *appTokenSource) Token() (*oauth2.Token, error) {
+	jwt, err := s.jwt()
+	if err != nil {
+		return nil, fmt.Errorf("github: sign app JWT: %v", err)
+	}
+
+	apiURL := fmt.Sprintf("%s/app/installations/%d/access_tokens", s.apiURL, s.installationID)
+	req, err := http.NewRequest(http.MethodPost, apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("github: new req: %v", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+jwt)
+
+	client := s.httpClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("github: get installation token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := readBody(resp.Body, defaultMaxResponseBytes)
+	if err != nil {
+		return nil, fmt.Errorf("github: read body: %w", err)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("github: get installation token: %w", &apiError{statusCode: resp.StatusCode, status: resp.Status, header: resp.Header, body: body})
+	}
+
+	var token struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("github: unmarshal installation token: %v", err)
+	}
+	return &oauth2.Token{AccessToken: token.Token, TokenType: "Bearer", Expiry: token.ExpiresAt}, nil
+}
+
+// jwt returns a JWT authenticating as the app.
+func (s *appTokenSource) jwt() (string, error) {
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: s.key}, (&jose.SignerOptions{}).WithType("JWT"))
+	if err != nil {
+		return "", err
+	}
+
+	now := s.now()
+	payload, err := json.Marshal(struct {
+		IssuedAt int64  `json:"iat"`
+		Expiry   int64  `json:"exp"`
+		Issuer   string `json:"iss"`
+	}{
+		IssuedAt: now.Add(-appJWTClockSkew).Unix(),
+		Expiry:   now.Add(appJWTLifetime).Unix(),
+		Issuer:   strconv.FormatInt(s.appID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	jws, err := signer.Sign(payload)
+	if err != nil {
+		return "", err
+	}
+	return jws.CompactSerialize()
+}
+
+// loadAppPrivateKey parses an RSA private key given either inline as PEM or as
+// the path of a PEM file.
+func loadAppPrivateKey(privateKey string) (*rsa.PrivateKey, error) {
+	data := []byte(privateKey)
+	if !strings.HasPrefix(strings.TrimSpace(privateKey), "-----BEGIN") {
+		var err error
+		if data, err = os.ReadFile(privateKey); err != nil {
+			return nil, err
+		}
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+	// GitHub issues PKCS #1 keys, but accept PKCS #8 ones as well.
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+// groupsClient returns the client used to look up the user's org and team
+// membership: one authenticated as the GitHub App installation if configured,
+// otherwise the user's own client.
+func (c *githubConnector) groupsClient(ctx context.Context, client *http.Client) *http.Client {
+	if c.appTokens == nil {
+		return client
+	}
+	return oauth2.NewClient(ctx, c.appTokens)
+}
+
+// userTeamsInOrg returns the teams of orgName userName is a member of. With a
+// GitHub App, whose installation tokens can't list the user's teams, every
+// team of the org is checked for the user's membership instead.
+func (c *githubConnector) userTeamsInOrg(ctx context.Context, client *http.Client, orgName, userName string) ([]string, error) {
+	if c.appTokens == nil {
+		return c.teamsForOrg(ctx, client, orgName, userName)
+	}
+
+	apiURL, memberTeams := c.pagedURL(fmt.Sprintf("/orgs/%s/teams", orgName)), []team{}
+	for apiURL != "" {
+		// https://docs.github.com/en/rest/teams/teams#list-teams
+		var (
+			teams []team
+			err   error
+		)
+		if apiURL, err = c.get(ctx, client, apiURL, &teams); err != nil {
+			return nil, fmt.Errorf("github: get org teams: %w", err)
+		}
+
+		for _, t := range teams {
+			membership, err := c.teamMembership(ctx, client, orgName, t.Slug, userName)
+			if err != nil {
+				return nil, err
+			}
+			// Pending members haven't accepted their invitation yet.
+			if membership != nil && membership.State == "active" {
+				t.role = membership.Role
+				memberTeams = append(memberTeams, t)
+			}
+		}
+	}
+	return c.teamsGroupClaims(ctx, client, orgName, userName, memberTeams)
+}
@@ -0,0 +1,156 @@
+package github
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"golang.org/x/oauth2"
+)
+
+func newTestAppServer(t *testing.T, key *rsa.PrivateKey, tokensIssued *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		switch r.RequestURI {
+		case "/app/installations/42/access_tokens":
+			jws, err := jose.ParseSigned(auth, []jose.SignatureAlgorithm{jose.RS256})
+			if err != nil {
+				t.Errorf("parse app JWT: %v", err)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			payload, err := jws.Verify(&key.PublicKey)
+			if err != nil {
+				t.Errorf("verify app JWT: %v", err)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			var claims struct {
+				Issuer string `json:"iss"`
+			}
+			json.Unmarshal(payload, &claims)
+			expectEquals(t, claims.Issuer, "7")
+
+			atomic.AddInt32(tokensIssued, 1)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"token":      "installation-token",
+				"expires_at": time.Now().Add(time.Hour),
+			})
+			return
+		}
+
+		if auth != "installation-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.RequestURI {
+		case "/orgs/org-1/members/some-login":
+			w.WriteHeader(http.StatusNoContent)
+		case "/orgs/org-1/teams":
+			json.NewEncoder(w).Encode([]team{
+				{Name: "Team 1", Slug: "team-1"},
+				{Name: "Team 2", Slug: "team-2"},
+				{Name: "Team 3", Slug: "team-3"},
+			})
+		case "/orgs/org-1/teams/team-1/memberships/some-login":
+			json.NewEncoder(w).Encode(teamMembership{State: "active"})
+		case "/orgs/org-1/teams/team-2/memberships/some-login":
+			json.NewEncoder(w).Encode(teamMembership{State: "pending"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestAppGroups(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	expectNil(t, err)
+
+	var tokensIssued int32
+	s := newTestAppServer(t, key, &tokensIssued)
+	defer s.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	c := githubConnector{
+		apiURL:    s.URL,
+		logger:    logger,
+		orgs:      []Org{{Name: "org-1"}},
+		appTokens: newAppTokenSource(s.URL, 7, 42, key, nil),
+	}
+
+	// The user's own client is never used for group lookups.
+	userClient := &http.Client{Transport: roundTripperFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("unexpected request with the user's token")
+	})}
+
+	for i := 0; i < 2; i++ {
+		groups, _, err := c.getGroups(context.Background(), userClient, true, "some-login", "")
+		expectNil(t, err)
+		expectEquals(t, groups, []string{"org-1:Team 1"})
+	}
+	expectEquals(t, atomic.LoadInt32(&tokensIssued), int32(1))
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestAppTokenRenewal(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	expectNil(t, err)
+
+	var tokensIssued int32
+	s := newTestAppServer(t, key, &tokensIssued)
+	defer s.Close()
+
+	now := time.Now()
+	src := &appTokenSource{apiURL: s.URL, appID: 7, installationID: 42, key: key, now: func() time.Time { return now }}
+	tokens := oauth2.ReuseTokenSource(&oauth2.Token{AccessToken: "expired", Expiry: now.Add(-time.Minute)}, src)
+
+	token, err := tokens.Token()
+	expectNil(t, err)
+	expectEquals(t, token.AccessToken, "installation-token")
+	expectEquals(t, atomic.LoadInt32(&tokensIssued), int32(1))
+}
+
+func Test_Open_AppConfig(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	expectNil(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	keyFile := filepath.Join(t.TempDir(), "app.pem")
+	expectNil(t, os.WriteFile(keyFile, keyPEM, 0o600))
+
+	c := Config{AppID: 7, InstallationID: 42, PrivateKey: string(keyPEM)}
+	conn, err := c.Open("id", log)
+	expectNil(t, err)
+	expectNotNil(t, conn.(*githubConnector).appTokens, "appTokens")
+
+	c.PrivateKey = keyFile
+	conn, err = c.Open("id", log)
+	expectNil(t, err)
+	expectNotNil(t, conn.(*githubConnector).appTokens, "appTokens")
+
+	c.InstallationID = 0
+	_, err = c.Open("id", log)
+	expectEquals(t, err, errors.New("invalid connector config: appID, installationID and privateKey must be set together"))
+}
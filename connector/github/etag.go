@@ -0,0 +1,81 @@
+package github
+
+import (
+	"sync"
+	"time"
+)
+
+// maxETagCacheEntries bounds the number of responses kept for conditional
+// requests. When full, the least recently used response makes room for a new
+// one.
+const maxETagCacheEntries = 1000
+
+// etagEntry is a response cached along with its ETag.
+type etagEntry struct {
+	etag string
+	body []byte
+	// the response's "Link" header, for pagination
+	link string
+
+	lastUsed time.Time
+}
+
+// etagCache holds the last response with an ETag for each URL. A nil
+// *etagCache caches nothing.
+//
+// Responses are keyed by URL alone even though they depend on whose token
+// requested them: GitHub only answers 304 Not Modified if the ETag matches
+// the response it would have sent, so a response cached for another user is
+// never reused unless it's identical.
+type etagCache struct {
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex // guards entries
+	entries map[string]etagEntry
+}
+
+func newETagCache(maxEntries int) *etagCache {
+	return &etagCache{maxEntries: maxEntries, now: time.Now, entries: make(map[string]etagEntry)}
+}
+
+// get returns the response cached for apiURL, if any.
+func (e *etagCache) get(apiURL string) (etagEntry, bool) {
+	if e == nil {
+		return etagEntry{}, false
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	entry, ok := e.entries[apiURL]
+	if ok {
+		entry.lastUsed = e.now()
+		e.entries[apiURL] = entry
+	}
+	return entry, ok
+}
+
+// add caches the response for apiURL, evicting the least recently used one if
+// the cache is full.
+func (e *etagCache) add(apiURL string, entry etagEntry) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := e.entries[apiURL]; !ok && len(e.entries) >= e.maxEntries {
+		var (
+			oldest     string
+			oldestUsed time.Time
+		)
+		for k, cached := range e.entries {
+			if oldestUsed.IsZero() || cached.lastUsed.Before(oldestUsed) {
+				oldest, oldestUsed = k, cached.lastUsed
+			}
+		}
+		delete(e.entries, oldest)
+	}
+	entry.lastUsed = e.now()
+	e.entries[apiURL] = entry
+}
@@ -0,0 +1,119 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestETagCache(t *testing.T) {
+	var requests, notModified int
+	var s *httptest.Server
+	s = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		etag := `"` + r.URL.Query().Get("page") + `"`
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Add("Content-Type", "application/json")
+		switch r.RequestURI {
+		case "/user/teams?page=1":
+			w.Header().Set("Link", fmt.Sprintf(`<%[1]s/user/teams?page=2>; rel="next", <%[1]s/user/teams?page=2>; rel="last"`, s.URL))
+			json.NewEncoder(w).Encode([]team{{Name: "team-1", Org: org{Login: "org-1"}}})
+		case "/user/teams?page=2":
+			json.NewEncoder(w).Encode([]team{{Name: "team-2", Org: org{Login: "org-1"}}})
+		}
+	}))
+	defer s.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	c := githubConnector{apiURL: s.URL, logger: logger, etags: newETagCache(maxETagCacheEntries)}
+
+	get := func() []string {
+		var all []string
+		apiURL := s.URL + "/user/teams?page=1"
+		for apiURL != "" {
+			var teams []team
+			var err error
+			apiURL, err = c.get(context.Background(), newClient(), apiURL, &teams)
+			expectNil(t, err)
+			for _, t := range teams {
+				all = append(all, t.Name)
+			}
+		}
+		return all
+	}
+
+	expectEquals(t, get(), []string{"team-1", "team-2"})
+	expectEquals(t, notModified, 0)
+
+	// Both pages are unchanged, so the cached responses are decoded and the
+	// cached "Link" header still leads to the second page.
+	expectEquals(t, get(), []string{"team-1", "team-2"})
+	expectEquals(t, requests, 4)
+	expectEquals(t, notModified, 2)
+}
+
+func TestETagCacheDisabled(t *testing.T) {
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expectEquals(t, r.Header.Get("If-None-Match"), "")
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Add("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(user{Login: "some-login", Email: "some@email.com"})
+	}))
+	defer s.Close()
+
+	c := githubConnector{apiURL: s.URL}
+	for i := 0; i < 2; i++ {
+		_, err := c.user(context.Background(), newClient())
+		expectNil(t, err)
+	}
+}
+
+func TestETagCacheEviction(t *testing.T) {
+	now := time.Now()
+	e := newETagCache(2)
+	e.now = func() time.Time { return now }
+
+	e.add("a", etagEntry{etag: "1"})
+	now = now.Add(time.Second)
+	e.add("b", etagEntry{etag: "2"})
+	now = now.Add(time.Second)
+	// Using "a" makes "b" the least recently used.
+	_, ok := e.get("a")
+	expectEquals(t, ok, true)
+	now = now.Add(time.Second)
+	e.add("c", etagEntry{etag: "3"})
+
+	_, ok = e.get("b")
+	expectEquals(t, ok, false)
+	entry, ok := e.get("a")
+	expectEquals(t, ok, true)
+	expectEquals(t, entry.etag, "1")
+	_, ok = e.get("c")
+	expectEquals(t, ok, true)
+}
+
+func Test_Open_ETagCacheConfig(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	c := Config{}
+	conn, err := c.Open("id", log)
+	expectNil(t, err)
+	expectEquals(t, conn.(*githubConnector).etags == nil, true)
+
+	c.EnableETagCache = true
+	conn, err = c.Open("id", log)
+	expectNil(t, err)
+	expectNotNil(t, conn.(*githubConnector).etags, "etags")
+}
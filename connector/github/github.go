@@ -3,18 +3,28 @@ package github
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"net"
 	"net/http"
+	"net/url"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"golang.org/x/net/http/httpproxy"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/github"
+	"golang.org/x/sync/singleflight"
 
 	"github.com/dexidp/dex/connector"
 	groups_pkg "github.com/dexidp/dex/pkg/groups"
@@ -30,6 +40,45 @@ const (
 	scopeOrgs = "read:org"
 )
 
+// defaultMaintenanceRetryInterval is how long to wait before retrying a request
+// that failed because GitHub is in maintenance mode. It is deliberately long so
+// dex doesn't hammer GitHub while it recovers.
+const defaultMaintenanceRetryInterval = 30 * time.Second
+
+// Bounds of the exponential backoff between retries of requests that failed to
+// reach GitHub at all.
+const (
+	defaultConnectionRetryBackoff = 500 * time.Millisecond
+	maxConnectionRetryBackoff     = 8 * time.Second
+)
+
+// defaultRetryMaxWait is the longest a request waits for GitHub's rate limit
+// to reset before retrying, unless configured otherwise.
+const defaultRetryMaxWait = time.Minute
+
+// maxNegativeCacheTTL bounds NegativeCacheTTL, so that a user who joins an org
+// isn't denied for long.
+const maxNegativeCacheTTL = 5 * time.Minute
+
+// maxGroupsCacheEntries bounds the number of users whose groups are cached.
+// When full, the entry closest to expiry makes room for a new one.
+const maxGroupsCacheEntries = 10000
+
+// defaultMaxResponseBytes is the default limit on the size of a GitHub API
+// response body.
+const defaultMaxResponseBytes = 10 << 20
+
+// maxPageSize is the largest page size GitHub supports, and the default.
+const maxPageSize = 100
+
+// defaultConcurrency is the default number of configured orgs whose membership
+// is checked at once.
+const defaultConcurrency = 4
+
+// pageLogInterval is the number of pages fetched between debug logs reporting
+// the progress of a paginated GitHub API lookup.
+const pageLogInterval = 10
+
 // Pagination URL patterns
 // https://developer.github.com/v3/#pagination
 var (
@@ -37,6 +86,75 @@ var (
 	reLast = regexp.MustCompile("<([^>]+)>; rel=\"last\"")
 )
 
+// ErrUserDeleted is returned by Refresh when GitHub reports that the
+// authenticated user no longer exists. Unlike transient API failures it is not
+// worth retrying, and the session should be terminated. It wraps
+// connector.ErrUserNotFound.
+var ErrUserDeleted = fmt.Errorf("github: %w", connector.ErrUserNotFound)
+
+// ErrTokenRevoked is returned by Refresh when GitHub rejects the stored access
+// token, e.g. because the user revoked dex's authorization. The user has to
+// log in again. It wraps connector.ErrTokenRevoked.
+var ErrTokenRevoked = fmt.Errorf("github: %w", connector.ErrTokenRevoked)
+
+// ErrServiceUnavailable is returned when GitHub responds that it is down for
+// maintenance. Callers should back off rather than retry immediately. It wraps
+// connector.ErrUnavailable.
+var ErrServiceUnavailable = fmt.Errorf("github: %w for maintenance", connector.ErrUnavailable)
+
+// ErrRequestTimeout is returned when a request to GitHub doesn't complete
+// within the configured HTTPTimeout.
+var ErrRequestTimeout = errors.New("github: request timed out")
+
+// ErrRateLimited is returned when GitHub keeps rejecting requests because of a
+// rate limit, and waiting for it to lift would take too long.
+var ErrRateLimited = errors.New("github: rate limited")
+
+// ErrUserNotAuthorized is matched by errors returned when the user
+// authenticated with GitHub but the connector's configuration denies the
+// login, e.g. because the user isn't in any of the required orgs.
+var ErrUserNotAuthorized = errors.New("github: user not authorized")
+
+// ErrUpstreamUnavailable is matched by errors returned when GitHub can't be
+// reached, doesn't respond in time or responds with a server error. Unlike
+// other failures, these are worth retrying later.
+var ErrUpstreamUnavailable = errors.New("github: upstream unavailable")
+
+// ErrSSORequired is matched by errors returned when GitHub withholds an org's
+// data because the user's access token hasn't been authorized for the org's
+// SAML single sign-on. The user has to authorize the token and log in again.
+var ErrSSORequired = errors.New("github: access token not authorized for SAML SSO")
+
+// authorizationError denies a login for the reason given by its message. It
+// matches ErrUserNotAuthorized.
+type authorizationError string
+
+func (e authorizationError) Error() string {
+	return string(e)
+}
+
+func (e authorizationError) Is(target error) bool {
+	return target == ErrUserNotAuthorized
+}
+
+// unavailableError wraps an error of a request that didn't get a response from
+// GitHub, keeping its message. It matches ErrUpstreamUnavailable.
+type unavailableError struct {
+	err error
+}
+
+func (e *unavailableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *unavailableError) Unwrap() error {
+	return e.err
+}
+
+func (e *unavailableError) Is(target error) bool {
+	return target == ErrUpstreamUnavailable
+}
+
 // Config holds configuration options for github logins.
 type Config struct {
 	ClientID             string `json:"clientID"`
@@ -50,6 +168,109 @@ type Config struct {
 	LoadAllGroups        bool   `json:"loadAllGroups"`
 	UseLoginAsID         bool   `json:"useLoginAsID"`
 	PreferredEmailDomain string `json:"preferredEmailDomain"`
+	// UserIDPrefix is prepended to the user's ID, e.g. "github:" gives
+	// "github:12345678", or "github:some-login" with UseLoginAsID.
+	UserIDPrefix string `json:"userIDPrefix"`
+	// ExcludeOrgs lists orgs, matched case-insensitively against their login,
+	// that are left out together with their teams when LoadAllGroups loads
+	// all of the user's orgs and teams.
+	ExcludeOrgs []string `json:"excludeOrgs"`
+	// LoginOrgs only lets members of at least one of these orgs log in. Unlike
+	// Orgs, it doesn't restrict the groups, so combined with LoadAllGroups all
+	// of the user's orgs and teams are still returned. It can't be combined
+	// with Org or Orgs.
+	LoginOrgs []string `json:"loginOrgs"`
+	// UseGraphQL makes LoadAllGroups resolve the user's orgs and teams with
+	// GitHub's GraphQL API, taking one request per 100 orgs rather than
+	// paging through the REST API. The groups are the same. Logins fall back
+	// to the REST API if the GraphQL request fails, or if the user is in more
+	// than 100 teams of a single org.
+	UseGraphQL bool `json:"useGraphQL"`
+	// PreferredEmailDomains lists further preferred email domains, with the
+	// same syntax as PreferredEmailDomain. The user's verified email in the
+	// first listed domain that matches is selected, PreferredEmailDomain
+	// taking priority over all of them.
+	//
+	// A "*" label in a domain matches any single label, e.g. "mail.*.corp.com",
+	// except that a leading "*." followed by a domain without wildcards, e.g.
+	// "*.corp.com", matches subdomains of any depth.
+	PreferredEmailDomains []string `json:"preferredEmailDomains"`
+	// PreferredEmailDomainSuffixMatch makes PreferredEmailDomain match the
+	// configured domain and any of its subdomains, e.g. "corp.com" matches
+	// "mail.eng.corp.com". It cannot be combined with "*" glob patterns.
+	PreferredEmailDomainSuffixMatch bool `json:"preferredEmailDomainSuffixMatch"`
+	// EmailStrategy selects the user's email when none is in a preferred
+	// domain. One of "primary" (default) for the verified primary email,
+	// "primary-then-any-verified" to fall back to the first verified email if
+	// there's no verified primary one, or "first-verified" for the first
+	// verified email whether it's the primary one or not.
+	EmailStrategy string `json:"emailStrategy"`
+	// RequiredEmailDomains rejects logins whose selected email is not in one of
+	// the listed domains. Unlike PreferredEmailDomain, which only ranks the
+	// user's emails, this is a hard gate.
+	RequiredEmailDomains []string `json:"requiredEmailDomains"`
+	// AllowedEmailDomains rejects logins and refreshes whose selected email
+	// doesn't match one of the listed domain patterns, using the same "*"
+	// globs as PreferredEmailDomains. It cannot be combined with
+	// RequiredEmailDomains.
+	AllowedEmailDomains []string `json:"allowedEmailDomains"`
+	// DefaultGroups are added to the groups of every user authenticating
+	// through this connector, in addition to any groups derived from GitHub.
+	DefaultGroups []string `json:"defaultGroups"`
+	// GroupTransforms rewrite the groups derived from GitHub, in order. They
+	// are applied after the groups are fetched and before DefaultGroups are
+	// added, so default groups are never transformed.
+	GroupTransforms []GroupTransform `json:"groupTransforms"`
+	// TeamNameMapping replaces the groups of teams, keyed by "org:team", with
+	// custom group names, whichever way the groups are resolved. Role groups
+	// added by IncludeTeamRole keep their role suffix. Unmapped teams are left
+	// unchanged. It is applied before GroupTransforms.
+	TeamNameMapping map[string]string `json:"teamNameMapping"`
+	// CaseInsensitiveGroups matches the names of configured orgs and teams
+	// against GitHub's case-insensitively. Groups still use GitHub's casing,
+	// which costs an extra API request per org the user is a member of.
+	CaseInsensitiveGroups bool `json:"caseInsensitiveGroups"`
+	// MaxGroups limits the number of groups derived from GitHub, so that
+	// users in hundreds of teams don't get oversized tokens. Groups beyond the
+	// limit are dropped, keeping the order they were resolved in. Unlimited
+	// if 0.
+	MaxGroups int `json:"maxGroups"`
+	// PriorityGroups lists orgs and "org:team" groups kept ahead of others
+	// when MaxGroups truncates the groups. An org also covers its teams.
+	PriorityGroups []string `json:"priorityGroups"`
+	// IncludeOrgCountClaim adds the number of orgs the user belongs to as the
+	// "github_org_count" claim. It only takes effect when the user's full org
+	// list is already fetched for groups (see LoadAllGroups), so it never
+	// causes additional API calls.
+	IncludeOrgCountClaim bool `json:"includeOrgCountClaim"`
+	// IncludeAccountAgeClaim adds the creation time of the user's GitHub
+	// account, in seconds since the Unix epoch, as the "github_created_at"
+	// claim. The claim is omitted if GitHub doesn't report the creation time.
+	IncludeAccountAgeClaim bool `json:"includeAccountAgeClaim"`
+	// IncludeParentTeams adds the ancestors of the user's teams to the user's
+	// teams, as if the user were a direct member of them. Ancestors that aren't
+	// among the user's teams cost an extra API request each.
+	IncludeParentTeams bool `json:"includeParentTeams"`
+	// IncludeTeamRole adds, next to each of the user's teams, a group with the
+	// user's role in the team appended, e.g. "org:team:maintainer". The role is
+	// either "member" or "maintainer", as reported by GitHub. Looking up the
+	// role costs an extra API request per team.
+	IncludeTeamRole bool `json:"includeTeamRole"`
+	// ExcludeArchivedTeams leaves teams GitHub reports as archived out of the
+	// user's groups. Teams listed without their archive status are looked up
+	// individually, costing an extra API request per team.
+	ExcludeArchivedTeams bool `json:"excludeArchivedTeams"`
+	// MinAccountAge denies login to GitHub accounts created less than this
+	// long ago, e.g. "720h". Accounts without a reported creation time are
+	// denied as well when this is set.
+	MinAccountAge string `json:"minAccountAge"`
+	// IncludeSubjectHashClaim adds the "subject_hash" claim, an HMAC-SHA256 of
+	// the user's immutable GitHub ID keyed with SubjectHashSalt. It identifies
+	// the user across email and username changes without exposing the ID.
+	IncludeSubjectHashClaim bool `json:"includeSubjectHashClaim"`
+	// SubjectHashSalt is the secret key of the "subject_hash" claim. Required
+	// if IncludeSubjectHashClaim is set.
+	SubjectHashSalt string `json:"subjectHashSalt"`
 	// NoreplyPrivateEmail configures the connector to use
 	// {id}+{login}@users.noreply.github.com as the user email if user has
 	// marked their email as private on GitHub.
@@ -57,6 +278,104 @@ type Config struct {
 	// Note, this is only valid for public and Enterprise Cloud GitHub (i.e. this only works on github.com domains).
 	// There is no equivalent for Enterprise Server GitHub / custom hosts.
 	NoreplyPrivateEmail bool `json:"noreplyPrivateEmail"`
+	// DeniedScopes lists scopes, "groups" or "offline_access", that are
+	// ignored when a client requests them, as if they were never requested.
+	DeniedScopes []string `json:"deniedScopes"`
+	// MaintenanceRetries is the number of times an API request is retried when
+	// GitHub responds that it is down for maintenance. Defaults to 0, failing
+	// immediately with ErrServiceUnavailable.
+	MaintenanceRetries int `json:"maintenanceRetries"`
+	// MaintenanceRetryInterval is how long to wait between maintenance retries,
+	// e.g. "1m". Defaults to 30s.
+	MaintenanceRetryInterval string `json:"maintenanceRetryInterval"`
+	// ConnectionRetries is the number of times an API request or token
+	// exchange is retried when it fails to reach GitHub at all, e.g. on DNS
+	// resolution failures, refused connections or TLS handshake timeouts.
+	// Retries back off exponentially, starting at 500ms. Defaults to 0.
+	ConnectionRetries int `json:"connectionRetries"`
+	// MaxRetries is the number of times an API request is retried after
+	// hitting GitHub's rate limit, waiting for the limit to reset in between.
+	// Defaults to 0, failing immediately.
+	MaxRetries int `json:"maxRetries"`
+	// RetryMaxWait is the longest to wait for the rate limit to reset, e.g.
+	// "30s". Requests whose limit resets later fail without waiting.
+	// Defaults to 1m.
+	RetryMaxWait string `json:"retryMaxWait"`
+	// NegativeCacheTTL is how long a user found not to be a member of one of
+	// the configured orgs is remembered as such, e.g. "30s". Repeated logins
+	// within that time skip the membership check. Memberships that were found
+	// are never cached. Disabled by default, and may be at most 5m.
+	NegativeCacheTTL string `json:"negativeCacheTTL"`
+	// GroupsCacheTTL is how long the groups resolved for a user are reused
+	// by later logins and refreshes, e.g. "5m". Group changes on GitHub take
+	// up to that long to show up. Disabled by default or if "0".
+	GroupsCacheTTL string `json:"groupsCacheTTL"`
+	// MaxResponseBytes limits the size of GitHub API response bodies read by
+	// the connector. Larger responses fail the request. Defaults to 10MiB.
+	MaxResponseBytes int64 `json:"maxResponseBytes"`
+	// UnsatisfiedGroupsScope controls what happens when a client requests the
+	// groups scope but neither orgs nor loadAllGroups are configured, so no
+	// groups can be returned. One of "ignore" (default), "warn" to log a
+	// warning, or "error" to fail the login.
+	UnsatisfiedGroupsScope string `json:"unsatisfiedGroupsScope"`
+	// PageSize is the number of items requested per page from paginated
+	// GitHub API endpoints. Defaults to, and may be at most, 100.
+	PageSize int `json:"pageSize"`
+	// HTTPTimeout bounds each request to GitHub, including the token
+	// exchange, e.g. "10s". Disabled by default.
+	HTTPTimeout string `json:"httpTimeout"`
+	// ProxyURL is the URL of an HTTP or HTTPS proxy all requests to GitHub go
+	// through, e.g. "http://proxy.example.com:3128". Hosts listed in the
+	// NO_PROXY environment variable are reached directly. If unset, the
+	// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables apply.
+	ProxyURL string `json:"proxyURL"`
+	// APIPath is the path GitHub Enterprise serves its REST API under on
+	// HostName, for installations behind a reverse proxy. Defaults to
+	// "/api/v3".
+	APIPath string `json:"apiPath"`
+	// EnableETagCache makes the connector remember the ETags of GitHub API
+	// responses and send conditional requests, reusing a response GitHub
+	// reports as unchanged. Such requests don't count against the rate limit.
+	// Up to 1000 responses are kept in memory.
+	EnableETagCache bool `json:"enableETagCache"`
+	// Concurrency is the number of configured orgs whose membership is
+	// checked at once during a login. Defaults to 4.
+	Concurrency int `json:"concurrency"`
+	// UsernameTemplate derives the preferred username from the user's
+	// profile, e.g. "{login}@github". It may use the placeholders {login},
+	// {name} and {id}. Defaults to the user's login.
+	UsernameTemplate string `json:"usernameTemplate"`
+	// PublicEmailOnly uses the email on the user's public profile and never
+	// reads their private emails, so the "user:email" scope isn't requested.
+	// Users without a public email get no email, which fails
+	// RequiredEmailDomains and AllowedEmailDomains if set. It cannot be
+	// combined with PreferredEmailDomain or PreferredEmailDomains, which
+	// select among all of the user's emails.
+	PublicEmailOnly bool `json:"publicEmailOnly"`
+	// TrustEnterpriseEmailVerification treats all emails of users of a GitHub
+	// Enterprise host as verified, since older GitHub Enterprise versions
+	// don't verify emails. Defaults to true. Set it to false to honor the
+	// verified flag reported by installations that do verify emails.
+	TrustEnterpriseEmailVerification *bool `json:"trustEnterpriseEmailVerification"`
+	// IncludeOrgInGroups adds the login of each org the user is a member of,
+	// from Org or Orgs, to the groups ahead of its teams, so that org
+	// membership can be told apart from team membership. With Org, this
+	// costs a request per login for users in none of the org's teams.
+	IncludeOrgInGroups bool `json:"includeOrgInGroups"`
+	// OrgNameField is the field orgs are named by in groups, "login" (default)
+	// or "name" for their display name. Display names cost a request per org
+	// and login. Orgs without a display name keep their login.
+	// TeamNameMapping is still keyed by org login.
+	OrgNameField string `json:"orgNameField"`
+	// AppID, InstallationID and PrivateKey configure a GitHub App whose
+	// installation tokens are used instead of the user's token to look up org
+	// and team membership of the configured orgs. Users are still identified
+	// through the OAuth flow. PrivateKey is either the app's PEM encoded
+	// private key or the path of a file holding it. The app needs read access
+	// to the orgs' members.
+	AppID          int64  `json:"appID"`
+	InstallationID int64  `json:"installationID"`
+	PrivateKey     string `json:"privateKey"`
 }
 
 // Org holds org-team filters, in which teams are optional.
@@ -69,46 +388,242 @@ type Org struct {
 	// authenticate if they are members of at least one of these teams. Users
 	// in the organization can authenticate if this field is omitted from the
 	// config file.
+	//
+	// Besides exact names, entries may be glob patterns, e.g. "platform-*",
+	// or regular expressions prefixed with "regex:", e.g.
+	// "regex:team-[0-9]+", which must match the whole team name.
 	Teams []string `json:"teams,omitempty"`
+
+	// If set, only members whose selected email is in this domain are
+	// considered members of the organization. Other users don't get its teams
+	// and aren't authorized through it, though another org may still match.
+	RequiredEmailDomain string `json:"requiredEmailDomain,omitempty"`
+}
+
+// GroupTransform is a single declarative step rewriting group names.
+type GroupTransform struct {
+	// Type is one of "regexReplace", "trimPrefix", "upper" or "lower".
+	Type string `json:"type"`
+	// Pattern is the regular expression matched by "regexReplace", or the
+	// prefix removed by "trimPrefix".
+	Pattern string `json:"pattern,omitempty"`
+	// Replacement replaces matches of Pattern for "regexReplace". It may
+	// reference capture groups, e.g. "${1}".
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// usernamePlaceholder matches a placeholder of UsernameTemplate.
+var usernamePlaceholder = regexp.MustCompile(`\{[^{}]*\}`)
+
+// validateUsernameTemplate rejects templates with unknown placeholders or
+// unmatched braces.
+func validateUsernameTemplate(tmpl string) error {
+	for _, placeholder := range usernamePlaceholder.FindAllString(tmpl, -1) {
+		switch placeholder {
+		case "{login}", "{name}", "{id}":
+		default:
+			return fmt.Errorf("unknown placeholder %s", placeholder)
+		}
+	}
+	if strings.ContainsAny(usernamePlaceholder.ReplaceAllString(tmpl, ""), "{}") {
+		return errors.New("unmatched brace")
+	}
+	return nil
+}
+
+// userID returns the ID of u, its login if useLoginAsID is set, prefixed with
+// userIDPrefix.
+func (c *githubConnector) userID(u user) string {
+	if c.useLoginAsID {
+		return c.userIDPrefix + u.Login
+	}
+	return c.userIDPrefix + strconv.Itoa(u.ID)
+}
+
+// preferredUsername returns the preferred username of u, from
+// usernameTemplate if set.
+func (c *githubConnector) preferredUsername(u user) string {
+	if c.usernameTemplate == "" {
+		return u.Login
+	}
+	return strings.NewReplacer(
+		"{login}", u.Login,
+		"{name}", u.Name,
+		"{id}", strconv.Itoa(u.ID),
+	).Replace(c.usernameTemplate)
+}
+
+// compile validates the transform and returns the function applying it to a
+// single group name.
+func (t GroupTransform) compile() (func(string) string, error) {
+	switch t.Type {
+	case "regexReplace":
+		if t.Pattern == "" {
+			return nil, errors.New("regexReplace requires a pattern")
+		}
+		re, err := regexp.Compile(t.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern: %v", err)
+		}
+		return func(group string) string {
+			return re.ReplaceAllString(group, t.Replacement)
+		}, nil
+	case "trimPrefix":
+		if t.Pattern == "" {
+			return nil, errors.New("trimPrefix requires a pattern")
+		}
+		return func(group string) string {
+			return strings.TrimPrefix(group, t.Pattern)
+		}, nil
+	case "upper":
+		return strings.ToUpper, nil
+	case "lower":
+		return strings.ToLower, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %q", t.Type)
+	}
+}
+
+// validateHostPort checks the port of a hostName that includes one, e.g.
+// "ghe.internal:8443".
+func validateHostPort(hostName string) error {
+	if !strings.Contains(hostName, ":") || (strings.HasPrefix(hostName, "[") && strings.HasSuffix(hostName, "]")) {
+		return nil
+	}
+	host, port, err := net.SplitHostPort(hostName)
+	if err != nil {
+		return err
+	}
+	if host == "" {
+		return errors.New("missing host")
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("port %q must be a number between 1 and 65535", port)
+	}
+	return nil
+}
+
+// Validate checks the config without opening the connector, e.g. to vet it
+// before a rollout. It returns all problems found joined with errors.Join, or
+// the problem itself if there's only one. Open fails with the same error.
+func (c *Config) Validate() error {
+	var errs []error
+	if c.Org != "" && len(c.Orgs) > 0 {
+		errs = append(errs, errors.New("github: cannot use both 'org' and 'orgs' fields simultaneously"))
+	}
+
+	if c.HostName != "" {
+		// ensure this is a hostname and not a URL or path.
+		if strings.Contains(c.HostName, "/") {
+			errs = append(errs, errors.New("invalid hostname: hostname cannot contain `/`"))
+		} else if err := validateHostPort(c.HostName); err != nil {
+			errs = append(errs, fmt.Errorf("invalid hostname: %v", err))
+		}
+		if c.APIPath != "" && (!strings.HasPrefix(c.APIPath, "/") || strings.Contains(c.APIPath, "://")) {
+			errs = append(errs, errors.New("invalid connector config: apiPath must be a path starting with `/`"))
+		}
+	} else {
+		if c.APIPath != "" {
+			errs = append(errs, errors.New("invalid connector config: hostName is required with apiPath"))
+		}
+		if c.RootCA != "" {
+			errs = append(errs, errors.New("invalid connector config: Host name field required for a root certificate file"))
+		}
+	}
+
+	for _, org := range c.Orgs {
+		if _, err := groups_pkg.NewMatcher(org.Teams, false); err != nil {
+			errs = append(errs, fmt.Errorf("invalid connector config: teams of org %q: %v", org.Name, err))
+		}
+	}
+
+	switch c.TeamNameField {
+	case "name", "slug", "both", "":
+	default:
+		errs = append(errs, fmt.Errorf("invalid connector config: unsupported team name field value `%s`", c.TeamNameField))
+	}
+
+	preferredDomains := c.PreferredEmailDomains
+	if c.PreferredEmailDomain != "" {
+		preferredDomains = append([]string{c.PreferredEmailDomain}, preferredDomains...)
+	}
+	for _, domain := range preferredDomains {
+		if err := validatePreferredEmailDomain(domain, c.PreferredEmailDomainSuffixMatch); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(preferredDomains) == 0 && c.PreferredEmailDomainSuffixMatch {
+		errs = append(errs, errors.New("invalid connector config: preferredEmailDomainSuffixMatch requires preferredEmailDomain"))
+	}
+	if len(c.RequiredEmailDomains) > 0 && len(c.AllowedEmailDomains) > 0 {
+		errs = append(errs, errors.New("invalid connector config: requiredEmailDomains cannot be combined with allowedEmailDomains"))
+	}
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return errors.Join(errs...)
 }
 
 // Open returns a strategy for logging in through GitHub.
 func (c *Config) Open(id string, logger *slog.Logger) (connector.Connector, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
 	if c.Org != "" {
-		// Return error if both 'org' and 'orgs' fields are used.
-		if len(c.Orgs) > 0 {
-			return nil, errors.New("github: cannot use both 'org' and 'orgs' fields simultaneously")
-		}
 		logger.Warn("github: legacy field 'org' being used. Switch to the newer 'orgs' field structure")
 	}
 
 	g := githubConnector{
-		redirectURI:          c.RedirectURI,
-		org:                  c.Org,
-		orgs:                 c.Orgs,
-		clientID:             c.ClientID,
-		clientSecret:         c.ClientSecret,
-		apiURL:               apiURL,
-		logger:               logger.With(slog.Group("connector", "type", "github", "id", id)),
-		useLoginAsID:         c.UseLoginAsID,
-		preferredEmailDomain: c.PreferredEmailDomain,
-		noreplyPrivateEmail:  c.NoreplyPrivateEmail,
+		id:                              id,
+		redirectURI:                     c.RedirectURI,
+		org:                             c.Org,
+		orgs:                            c.Orgs,
+		clientID:                        c.ClientID,
+		clientSecret:                    c.ClientSecret,
+		apiURL:                          apiURL,
+		logger:                          logger.With(slog.Group("connector", "type", "github", "id", id)),
+		useLoginAsID:                    c.UseLoginAsID,
+		userIDPrefix:                    c.UserIDPrefix,
+		groupsFlight:                    new(singleflight.Group),
+		preferredEmailDomain:            c.PreferredEmailDomain,
+		preferredEmailDomains:           c.PreferredEmailDomains,
+		preferredEmailDomainSuffixMatch: c.PreferredEmailDomainSuffixMatch,
+		noreplyPrivateEmail:             c.NoreplyPrivateEmail,
+		includeOrgInGroups:              c.IncludeOrgInGroups,
+		requiredEmailDomains:            c.RequiredEmailDomains,
+		allowedEmailDomains:             c.AllowedEmailDomains,
+		defaultGroups:                   c.DefaultGroups,
+		includeOrgCountClaim:            c.IncludeOrgCountClaim,
+		includeAccountAgeClaim:          c.IncludeAccountAgeClaim,
+		includeParentTeams:              c.IncludeParentTeams,
+		includeTeamRole:                 c.IncludeTeamRole,
+		excludeArchivedTeams:            c.ExcludeArchivedTeams,
+		maintenanceRetries:              c.MaintenanceRetries,
+		maintenanceRetryInterval:        defaultMaintenanceRetryInterval,
+		connectionRetries:               c.ConnectionRetries,
+		connectionRetryBackoff:          defaultConnectionRetryBackoff,
+		maxRetries:                      c.MaxRetries,
+		retryMaxWait:                    defaultRetryMaxWait,
+		maxResponseBytes:                defaultMaxResponseBytes,
+		pageSize:                        maxPageSize,
+		concurrency:                     defaultConcurrency,
 	}
 
 	if c.HostName != "" {
-		// ensure this is a hostname and not a URL or path.
-		if strings.Contains(c.HostName, "/") {
-			return nil, errors.New("invalid hostname: hostname cannot contain `/`")
+		apiPath := "/api/v3"
+		if c.APIPath != "" {
+			apiPath = strings.TrimSuffix(c.APIPath, "/")
 		}
 
 		g.hostName = c.HostName
-		g.apiURL = "https://" + c.HostName + "/api/v3"
+		g.apiURL = "https://" + c.HostName + apiPath
+	}
+	if c.UseGraphQL {
+		g.useGraphQL = true
+		g.graphQLURL = graphQLURL(g.apiURL, g.hostName)
 	}
 
 	if c.RootCA != "" {
-		if c.HostName == "" {
-			return nil, errors.New("invalid connector config: Host name field required for a root certificate file")
-		}
 		g.rootCA = c.RootCA
 
 		var err error
@@ -116,27 +631,318 @@ func (c *Config) Open(id string, logger *slog.Logger) (connector.Connector, erro
 			return nil, fmt.Errorf("failed to create HTTP client: %v", err)
 		}
 	}
+	if c.ProxyURL != "" {
+		proxy, err := proxyFunc(c.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid connector config: proxyURL: %v", err)
+		}
+		if g.httpClient == nil {
+			g.httpClient = &http.Client{Transport: http.DefaultTransport.(*http.Transport).Clone()}
+		}
+		// The client built for rootCA always has an *http.Transport.
+		g.httpClient.Transport.(*http.Transport).Proxy = proxy
+	}
 	g.loadAllGroups = c.LoadAllGroups
+	g.excludeOrgs = c.ExcludeOrgs
+	if len(c.LoginOrgs) > 0 && (c.Org != "" || len(c.Orgs) > 0) {
+		return nil, errors.New("invalid connector config: loginOrgs cannot be combined with org or orgs")
+	}
+	g.loginOrgs = c.LoginOrgs
 
-	switch c.TeamNameField {
-	case "name", "slug", "both", "":
-		g.teamNameField = c.TeamNameField
+	if c.HTTPTimeout != "" {
+		timeout, err := time.ParseDuration(c.HTTPTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid connector config: httpTimeout: %v", err)
+		}
+		if timeout <= 0 {
+			return nil, errors.New("invalid connector config: httpTimeout must be positive")
+		}
+		g.httpTimeout = timeout
+		if g.httpClient == nil {
+			g.httpClient = &http.Client{}
+		}
+		// Bounds the token exchange. API requests get their deadline in do.
+		g.httpClient.Timeout = timeout
+	}
+
+	if c.AppID != 0 || c.InstallationID != 0 || c.PrivateKey != "" {
+		if c.AppID == 0 || c.InstallationID == 0 || c.PrivateKey == "" {
+			return nil, errors.New("invalid connector config: appID, installationID and privateKey must be set together")
+		}
+		key, err := loadAppPrivateKey(c.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid connector config: privateKey: %v", err)
+		}
+		g.appTokens = newAppTokenSource(g.apiURL, c.AppID, c.InstallationID, key, g.httpClient)
+	}
+
+	if c.IncludeSubjectHashClaim {
+		if c.SubjectHashSalt == "" {
+			return nil, errors.New("invalid connector config: subjectHashSalt is required when includeSubjectHashClaim is set")
+		}
+		g.subjectHashSalt = []byte(c.SubjectHashSalt)
+	}
+
+	if c.PageSize < 0 || c.PageSize > maxPageSize {
+		return nil, fmt.Errorf("invalid connector config: pageSize must be between 1 and %d", maxPageSize)
+	}
+	if c.PageSize > 0 {
+		g.pageSize = c.PageSize
+	}
+
+	if c.EnableETagCache {
+		g.etags = newETagCache(maxETagCacheEntries)
+	}
+
+	if err := validateUsernameTemplate(c.UsernameTemplate); err != nil {
+		return nil, fmt.Errorf("invalid connector config: usernameTemplate: %v", err)
+	}
+	g.usernameTemplate = c.UsernameTemplate
+
+	if c.Concurrency < 0 {
+		return nil, errors.New("invalid connector config: concurrency cannot be negative")
+	}
+	if c.Concurrency > 0 {
+		g.concurrency = c.Concurrency
+	}
+
+	switch c.UnsatisfiedGroupsScope {
+	case "", unsatisfiedGroupsScopeIgnore, unsatisfiedGroupsScopeWarn, unsatisfiedGroupsScopeError:
+		g.unsatisfiedGroupsScope = c.UnsatisfiedGroupsScope
 	default:
-		return nil, fmt.Errorf("invalid connector config: unsupported team name field value `%s`", c.TeamNameField)
+		return nil, fmt.Errorf("invalid connector config: unsupported unsatisfiedGroupsScope %q", c.UnsatisfiedGroupsScope)
 	}
 
-	if c.PreferredEmailDomain != "" {
-		if strings.HasSuffix(c.PreferredEmailDomain, "*") {
-			return nil, errors.New("invalid PreferredEmailDomain: glob pattern cannot end with \"*\"")
+	switch c.EmailStrategy {
+	case "", emailStrategyPrimary, emailStrategyPrimaryThenAnyVerified, emailStrategyFirstVerified:
+		g.emailStrategy = c.EmailStrategy
+	default:
+		return nil, fmt.Errorf("invalid connector config: unsupported emailStrategy %q", c.EmailStrategy)
+	}
+
+	g.teamNameField = c.TeamNameField
+	switch c.OrgNameField {
+	case "login", "name", "":
+		g.orgNameField = c.OrgNameField
+	default:
+		return nil, fmt.Errorf("invalid connector config: unsupported org name field value `%s`", c.OrgNameField)
+	}
+	if c.IncludeParentTeams {
+		g.logger.Info("parent teams included in groups, each ancestor team a user isn't directly in costs an extra API request per login")
+	}
+	if c.ExcludeArchivedTeams {
+		g.logger.Info("archived teams excluded from groups, each team listed without its archive status costs an extra API request per login")
+	}
+
+	preferredDomains := g.preferredEmailDomainList()
+	if c.PublicEmailOnly {
+		if len(preferredDomains) > 0 {
+			return nil, errors.New("invalid connector config: publicEmailOnly cannot be combined with preferredEmailDomain or preferredEmailDomains")
+		}
+		g.publicEmailOnly = true
+	}
+	if c.TrustEnterpriseEmailVerification != nil && !*c.TrustEnterpriseEmailVerification {
+		g.honorEnterpriseEmailVerification = true
+	}
+	for _, domain := range c.AllowedEmailDomains {
+		if err := validateEmailDomainPattern(domain); err != nil {
+			return nil, fmt.Errorf("invalid connector config: allowedEmailDomains: %v", err)
+		}
+	}
+	for _, scope := range c.DeniedScopes {
+		switch scope {
+		case "groups":
+			g.denyGroupsScope = true
+		case "offline_access":
+			g.denyOfflineAccessScope = true
+		default:
+			return nil, fmt.Errorf("invalid connector config: unsupported denied scope %q", scope)
+		}
+	}
+
+	for team := range c.TeamNameMapping {
+		if !strings.Contains(team, ":") {
+			return nil, fmt.Errorf("invalid connector config: teamNameMapping key %q must be of the form org:team", team)
+		}
+	}
+	g.teamNameMapping = c.TeamNameMapping
+	g.caseInsensitiveGroups = c.CaseInsensitiveGroups
+
+	if c.MaxGroups < 0 {
+		return nil, errors.New("invalid connector config: maxGroups cannot be negative")
+	}
+	g.maxGroups = c.MaxGroups
+	g.priorityGroups = c.PriorityGroups
+
+	for i, t := range c.GroupTransforms {
+		transform, err := t.compile()
+		if err != nil {
+			return nil, fmt.Errorf("invalid connector config: groupTransforms[%d]: %v", i, err)
+		}
+		g.groupTransforms = append(g.groupTransforms, transform)
+	}
+
+	if c.MinAccountAge != "" {
+		age, err := time.ParseDuration(c.MinAccountAge)
+		if err != nil {
+			return nil, fmt.Errorf("invalid connector config: minAccountAge: %v", err)
+		}
+		if age < 0 {
+			return nil, errors.New("invalid connector config: minAccountAge cannot be negative")
+		}
+		g.minAccountAge = age
+	}
+
+	if c.MaintenanceRetries < 0 {
+		return nil, errors.New("invalid connector config: maintenanceRetries cannot be negative")
+	}
+	if c.MaintenanceRetryInterval != "" {
+		interval, err := time.ParseDuration(c.MaintenanceRetryInterval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid connector config: maintenanceRetryInterval: %v", err)
+		}
+		if interval <= 0 {
+			return nil, errors.New("invalid connector config: maintenanceRetryInterval must be positive")
+		}
+		g.maintenanceRetryInterval = interval
+	}
+
+	if c.ConnectionRetries < 0 {
+		return nil, errors.New("invalid connector config: connectionRetries cannot be negative")
+	}
+
+	if c.MaxRetries < 0 {
+		return nil, errors.New("invalid connector config: maxRetries cannot be negative")
+	}
+	if c.RetryMaxWait != "" {
+		wait, err := time.ParseDuration(c.RetryMaxWait)
+		if err != nil {
+			return nil, fmt.Errorf("invalid connector config: retryMaxWait: %v", err)
+		}
+		if wait <= 0 {
+			return nil, errors.New("invalid connector config: retryMaxWait must be positive")
+		}
+		g.retryMaxWait = wait
+	}
+
+	if c.NegativeCacheTTL != "" {
+		ttl, err := time.ParseDuration(c.NegativeCacheTTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid connector config: negativeCacheTTL: %v", err)
 		}
+		if ttl <= 0 || ttl > maxNegativeCacheTTL {
+			return nil, fmt.Errorf("invalid connector config: negativeCacheTTL must be positive and at most %s", maxNegativeCacheTTL)
+		}
+		g.orgMisses = newMembershipCache(ttl, time.Now)
+	}
+
+	if c.GroupsCacheTTL != "" {
+		ttl, err := time.ParseDuration(c.GroupsCacheTTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid connector config: groupsCacheTTL: %v", err)
+		}
+		if ttl < 0 {
+			return nil, errors.New("invalid connector config: groupsCacheTTL cannot be negative")
+		}
+		if ttl > 0 {
+			g.groupsCache = newGroupsCache(ttl, maxGroupsCacheEntries, time.Now)
+		}
+	}
+
+	if c.MaxResponseBytes < 0 {
+		return nil, errors.New("invalid connector config: maxResponseBytes cannot be negative")
+	}
+	if c.MaxResponseBytes > 0 {
+		g.maxResponseBytes = c.MaxResponseBytes
 	}
 
 	return &g, nil
 }
 
+// proxyFunc returns a proxy function for http.Transport that sends requests
+// through proxyURL, except to hosts excluded by the NO_PROXY environment
+// variable.
+func proxyFunc(proxyURL string) (func(*http.Request) (*url.URL, error), error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme %q, must be http or https", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, errors.New("missing host")
+	}
+
+	proxy := (&httpproxy.Config{
+		HTTPProxy:  proxyURL,
+		HTTPSProxy: proxyURL,
+		NoProxy:    httpproxy.FromEnvironment().NoProxy,
+	}).ProxyFunc()
+	return func(r *http.Request) (*url.URL, error) {
+		return proxy(r.URL)
+	}, nil
+}
+
 type connectorData struct {
 	// GitHub's OAuth2 tokens never expire. We don't need a refresh token.
 	AccessToken string `json:"accessToken"`
+
+	// The rate limit budget left when the user logged in, and when it resets
+	// as seconds since the Unix epoch. Zero if GitHub didn't report it.
+	RateLimitRemaining int   `json:"rateLimitRemaining,omitempty"`
+	RateLimitReset     int64 `json:"rateLimitReset,omitempty"`
+}
+
+// rateLimitKey is the context key of the *rateLimitStatus that get records
+// the rate limit headers of responses in.
+type rateLimitKey struct{}
+
+// rateLimitStatus is the lowest rate limit budget seen for the latest reset.
+// Requests may be sent concurrently, so the most recent response isn't
+// necessarily the one with the lowest budget.
+type rateLimitStatus struct {
+	mu        sync.Mutex
+	seen      bool
+	remaining int
+	reset     int64
+}
+
+// withRateLimitStatus returns a context that makes get record the rate limit
+// headers of responses in the returned status.
+func withRateLimitStatus(ctx context.Context) (context.Context, *rateLimitStatus) {
+	status := &rateLimitStatus{}
+	return context.WithValue(ctx, rateLimitKey{}, status), status
+}
+
+// record updates the status from the rate limit headers of a response.
+//
+// https://docs.github.com/en/rest/using-the-rest-api/rate-limits-for-the-rest-api#checking-the-status-of-your-rate-limit
+func (s *rateLimitStatus) record(header http.Header) {
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.seen && (reset < s.reset || reset == s.reset && remaining >= s.remaining) {
+		return
+	}
+	s.seen, s.remaining, s.reset = true, remaining, reset
+}
+
+// apply sets the rate limit fields of data to the status, if any was seen.
+func (s *rateLimitStatus) apply(data *connectorData) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.seen {
+		data.RateLimitRemaining, data.RateLimitReset = s.remaining, s.reset
+	}
 }
 
 var (
@@ -151,9 +957,11 @@ type githubConnector struct {
 	clientID     string
 	clientSecret string
 	logger       *slog.Logger
+	// ID of the connector, used to label its metrics
+	id string
 	// apiURL defaults to "https://api.github.com"
 	apiURL string
-	// hostName of the GitHub enterprise account.
+	// hostName of the GitHub enterprise account, optionally with a port.
 	hostName string
 	// Used to support untrusted/self-signed CA certs.
 	rootCA string
@@ -163,16 +971,107 @@ type githubConnector struct {
 	teamNameField string
 	// if set to true and no orgs are configured then connector loads all user claims (all orgs and team)
 	loadAllGroups bool
+	// orgs left out of the groups loaded by loadAllGroups
+	excludeOrgs []string
+	// if set, only members of at least one of these orgs may log in
+	loginOrgs []string
+	// if set to true, loadAllGroups uses the GraphQL API at graphQLURL
+	useGraphQL bool
+	graphQLURL string
 	// if set to true will use the user's handle rather than their numeric id as the ID
 	useLoginAsID bool
+	// prepended to the user's ID
+	userIDPrefix string
 	// the domain to be preferred among the user's emails. e.g. "github.com"
 	preferredEmailDomain string
+	// further preferred email domains, in priority order after preferredEmailDomain
+	preferredEmailDomains []string
+	// if set to true, preferredEmailDomain also matches any of its subdomains
+	preferredEmailDomainSuffixMatch bool
 	// use {id}+{login}@users.noreply.github.com as the user email if user has
 	// marked their email as private on GitHub.
 	// See https://docs.github.com/en/enterprise-cloud@latest/account-and-profile/setting-up-and-managing-your-personal-account-on-github/managing-email-preferences/setting-your-commit-email-address#setting-your-commit-email-address-on-github.
 	// Note, this is only valid for public and Enterprise Cloud GitHub (i.e. this only works on github.com domains).
 	// There is no equivalent for Enterprise Server GitHub / custom hosts.
 	noreplyPrivateEmail bool
+	// if set, only the public profile email is used and "user:email" isn't requested
+	publicEmailOnly bool
+	// if set, the verified flag of emails is honored on GitHub Enterprise hosts
+	honorEnterpriseEmailVerification bool
+	// how the email is selected when none is in a preferred domain, see EmailStrategy
+	emailStrategy string
+	// if set, the orgs of org or orgs the user is in are included in the groups
+	includeOrgInGroups bool
+	// optional choice between 'login' (default) or 'name'
+	orgNameField string
+	// if not empty, the user's selected email must be in one of these domains
+	requiredEmailDomains []string
+	// if not empty, the user's selected email must match one of these domain patterns
+	allowedEmailDomains []string
+	// groups added to every user's groups
+	defaultGroups []string
+	// custom group names of teams, keyed by "org:team"
+	teamNameMapping map[string]string
+	// if set to true, configured org and team names match GitHub's regardless of case
+	caseInsensitiveGroups bool
+	// maximum number of groups derived from GitHub, 0 if unlimited
+	maxGroups int
+	// orgs and teams whose groups survive truncation to maxGroups first
+	priorityGroups []string
+	// compiled GroupTransforms, applied in order to the groups derived from GitHub
+	groupTransforms []func(string) string
+	// if set to true, the number of orgs is added as a claim when the org list is fetched
+	includeOrgCountClaim bool
+	// if set to true, the account creation time is added as a claim
+	includeAccountAgeClaim bool
+	// if set to true, the ancestors of the user's teams are added to the groups
+	includeParentTeams bool
+	// if set to true, the user's role in each team is added to the groups
+	includeTeamRole bool
+	// if set to true, archived teams are left out of the groups
+	excludeArchivedTeams bool
+	// if non-zero, accounts younger than this are denied
+	minAccountAge time.Duration
+	// if set, the salted hash of the user's ID is added as a claim
+	subjectHashSalt []byte
+	// scopes treated as not requested even if a client asks for them
+	denyGroupsScope        bool
+	denyOfflineAccessScope bool
+	// number of retries and the wait between them when GitHub is in maintenance mode
+	maintenanceRetries       int
+	maintenanceRetryInterval time.Duration
+	// number of retries and the initial backoff when GitHub can't be reached
+	connectionRetries      int
+	connectionRetryBackoff time.Duration
+	// number of retries and the longest wait for a reset when rate limited
+	maxRetries   int
+	retryMaxWait time.Duration
+	// users recently found not to be members of an org, nil if disabled
+	orgMisses *membershipCache
+	// groups recently resolved for each user, nil if disabled
+	groupsCache *groupsCache
+	// coalesces concurrent group lookups for the same user, nil if disabled
+	groupsFlight *singleflight.Group
+	// responses conditional requests are made for, nil if disabled
+	etags *etagCache
+	// limit on the size of API response bodies, 0 means no limit
+	maxResponseBytes int64
+	// what to do when the groups scope can't be satisfied, see UnsatisfiedGroupsScope
+	unsatisfiedGroupsScope string
+	// items per page requested from paginated endpoints, 0 uses GitHub's default
+	pageSize int
+	// if non-zero, bounds each request to GitHub
+	httpTimeout time.Duration
+	// number of orgs whose membership is checked at once, 0 checks them one
+	// at a time
+	concurrency int
+	// template of the preferred username, the login if empty
+	usernameTemplate string
+	// instruments requests to the GitHub API, nil until RegisterMetrics is called
+	metrics *metrics
+	// installation tokens of the GitHub App used for group lookups, nil if
+	// the user's token is used
+	appTokens oauth2.TokenSource
 }
 
 // groupsRequired returns whether dex requires GitHub's 'read:org' scope. Dex
@@ -185,8 +1084,11 @@ func (c *githubConnector) groupsRequired(groupScope bool) bool {
 func (c *githubConnector) oauth2Config(scopes connector.Scopes) *oauth2.Config {
 	// 'read:org' scope is required by the GitHub API, and thus for dex to ensure
 	// a user is a member of orgs and teams provided in configs.
-	githubScopes := []string{scopeEmail}
-	if c.groupsRequired(scopes.Groups) {
+	var githubScopes []string
+	if !c.publicEmailOnly {
+		githubScopes = append(githubScopes, scopeEmail)
+	}
+	if c.groupsRequired(scopes.Groups) || len(c.loginOrgs) > 0 {
 		githubScopes = append(githubScopes, scopeOrgs)
 	}
 
@@ -208,7 +1110,19 @@ func (c *githubConnector) oauth2Config(scopes connector.Scopes) *oauth2.Config {
 	}
 }
 
+// allowedScopes returns s without the scopes denied by the config.
+func (c *githubConnector) allowedScopes(s connector.Scopes) connector.Scopes {
+	if c.denyGroupsScope {
+		s.Groups = false
+	}
+	if c.denyOfflineAccessScope {
+		s.OfflineAccess = false
+	}
+	return s
+}
+
 func (c *githubConnector) LoginURL(scopes connector.Scopes, callbackURL, state string) (string, error) {
+	scopes = c.allowedScopes(scopes)
 	if c.redirectURI != callbackURL {
 		return "", fmt.Errorf("expected callback URL %q did not match the URL in the config %q", callbackURL, c.redirectURI)
 	}
@@ -229,6 +1143,7 @@ func (e *oauth2Error) Error() string {
 }
 
 func (c *githubConnector) HandleCallback(s connector.Scopes, r *http.Request) (identity connector.Identity, err error) {
+	s = c.allowedScopes(s)
 	q := r.URL.Query()
 	if errType := q.Get("error"); errType != "" {
 		return identity, &oauth2Error{errType, q.Get("error_description")}
@@ -241,17 +1156,22 @@ func (c *githubConnector) HandleCallback(s connector.Scopes, r *http.Request) (i
 	if c.httpClient != nil {
 		ctx = context.WithValue(r.Context(), oauth2.HTTPClient, c.httpClient)
 	}
+	ctx, rateLimit := withRateLimitStatus(ctx)
 
-	token, err := oauth2Config.Exchange(ctx, q.Get("code"))
+	var token *oauth2.Token
+	err = c.retryConnection(ctx, func() (err error) {
+		token, err = oauth2Config.Exchange(ctx, q.Get("code"))
+		return c.wrapTimeout(ctx, err)
+	})
 	if err != nil {
-		return identity, fmt.Errorf("github: failed to get token: %v", err)
+		return identity, fmt.Errorf("github: failed to get token: %w", err)
 	}
 
 	client := oauth2Config.Client(ctx, token)
 
 	user, err := c.user(ctx, client)
 	if err != nil {
-		return identity, fmt.Errorf("github: get user: %v", err)
+		return identity, fmt.Errorf("github: get user: %w", err)
 	}
 
 	username := user.Name
@@ -260,27 +1180,43 @@ func (c *githubConnector) HandleCallback(s connector.Scopes, r *http.Request) (i
 	}
 
 	identity = connector.Identity{
-		UserID:            strconv.Itoa(user.ID),
+		UserID:            c.userID(user),
 		Username:          username,
-		PreferredUsername: user.Login,
+		PreferredUsername: c.preferredUsername(user),
 		Email:             user.Email,
-		EmailVerified:     true,
+		EmailVerified:     user.Email != "",
 	}
-	if c.useLoginAsID {
-		identity.UserID = user.Login
+
+	if err := c.checkRequiredEmailDomain(user.Email); err != nil {
+		return identity, err
+	}
+	if err := c.checkAllowedEmailDomain(user.Email); err != nil {
+		return identity, err
+	}
+	if err := c.checkAccountAge(user.CreatedAt); err != nil {
+		return identity, err
 	}
+	if err := c.checkLoginOrgs(ctx, client, user.Login); err != nil {
+		return identity, err
+	}
+	c.setAccountAgeClaim(&identity, user.CreatedAt)
+	c.setSubjectHashClaim(&identity, user.ID)
+	setAvatarClaim(&identity, user.AvatarURL)
 
 	// Only set identity.Groups if 'orgs', 'org', or 'groups' scope are specified.
 	if c.groupsRequired(s.Groups) {
-		groups, err := c.getGroups(ctx, client, s.Groups, user.Login)
+		groups, orgs, err := c.getGroups(ctx, client, s.Groups, user.Login, user.Email)
 		if err != nil {
 			return identity, err
 		}
-		identity.Groups = groups
+		identity.Groups = c.transformGroups(groups)
+		c.setOrgCountClaim(&identity, orgs)
 	}
+	identity.Groups = c.withDefaultGroups(identity.Groups)
 
 	if s.OfflineAccess {
 		data := connectorData{AccessToken: token.AccessToken}
+		rateLimit.apply(&data)
 		connData, err := json.Marshal(data)
 		if err != nil {
 			return identity, fmt.Errorf("marshal connector data: %v", err)
@@ -292,6 +1228,7 @@ func (c *githubConnector) HandleCallback(s connector.Scopes, r *http.Request) (i
 }
 
 func (c *githubConnector) Refresh(ctx context.Context, s connector.Scopes, identity connector.Identity) (connector.Identity, error) {
+	s = c.allowedScopes(s)
 	if len(identity.ConnectorData) == 0 {
 		return identity, errors.New("no upstream access token found")
 	}
@@ -301,135 +1238,785 @@ func (c *githubConnector) Refresh(ctx context.Context, s connector.Scopes, ident
 		return identity, fmt.Errorf("github: unmarshal access token: %v", err)
 	}
 
+	if c.httpClient != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, c.httpClient)
+	}
+
 	client := c.oauth2Config(s).Client(ctx, &oauth2.Token{AccessToken: data.AccessToken})
 	user, err := c.user(ctx, client)
 	if err != nil {
-		return identity, fmt.Errorf("github: get user: %v", err)
+		// A 404 on the authenticated user's own profile means the account has
+		// been deleted, and a 401 that the token is no longer valid. Anything
+		// else, e.g. a 5xx, may be transient. A 404 on the user's emails only
+		// means the token lacks the user:email scope.
+		var (
+			apiErr     *apiError
+			profileErr *profileError
+		)
+		if errors.As(err, &apiErr) {
+			switch {
+			case apiErr.statusCode == http.StatusNotFound && errors.As(err, &profileErr):
+				return identity, ErrUserDeleted
+			case apiErr.statusCode == http.StatusUnauthorized:
+				return identity, ErrTokenRevoked
+			}
+		}
+		return identity, fmt.Errorf("github: get user: %w", err)
 	}
 
 	username := user.Name
 	if username == "" {
 		username = user.Login
 	}
+	identity.UserID = c.userID(user)
 	identity.Username = username
-	identity.PreferredUsername = user.Login
+	identity.PreferredUsername = c.preferredUsername(user)
 	identity.Email = user.Email
+	identity.EmailVerified = user.Email != ""
+
+	if err := c.checkRequiredEmailDomain(user.Email); err != nil {
+		return identity, err
+	}
+	if err := c.checkAllowedEmailDomain(user.Email); err != nil {
+		return identity, err
+	}
+	if err := c.checkAccountAge(user.CreatedAt); err != nil {
+		return identity, err
+	}
+	if err := c.checkLoginOrgs(ctx, client, user.Login); err != nil {
+		return identity, err
+	}
+	c.setAccountAgeClaim(&identity, user.CreatedAt)
+	c.setSubjectHashClaim(&identity, user.ID)
+	setAvatarClaim(&identity, user.AvatarURL)
 
 	// Only set identity.Groups if 'orgs', 'org', or 'groups' scope are specified.
 	if c.groupsRequired(s.Groups) {
-		groups, err := c.getGroups(ctx, client, s.Groups, user.Login)
+		groups, orgs, err := c.getGroups(ctx, client, s.Groups, user.Login, user.Email)
 		if err != nil {
 			return identity, err
 		}
-		identity.Groups = groups
+		identity.Groups = c.transformGroups(groups)
+		c.setOrgCountClaim(&identity, orgs)
 	}
+	identity.Groups = c.withDefaultGroups(identity.Groups)
 
 	return identity, nil
 }
 
-// getGroups retrieves GitHub orgs and teams a user is in, if any.
-func (c *githubConnector) getGroups(ctx context.Context, client *http.Client, groupScope bool, userLogin string) ([]string, error) {
+// getGroups retrieves GitHub orgs and teams a user is in, if any. The user's
+// full org list is also returned when it had to be fetched to build the groups,
+// and is nil otherwise. Groups that end up with the same name, e.g. teams of
+// different orgs mapped by teamNameMapping to one group, are only returned
+// once.
+func (c *githubConnector) getGroups(ctx context.Context, client *http.Client, groupScope bool, userLogin, userEmail string) (groups []string, orgs []string, err error) {
+	key := groupsKey{login: userLogin, email: userEmail, groupScope: groupScope}
+	if groups, orgs, ok := c.groupsCache.get(key); ok {
+		c.debug(ctx, "github: resolved groups", "user", userLogin, "groups", groups, "cached", true)
+		return groups, orgs, nil
+	}
+	resolve := func(ctx context.Context) ([]string, []string, error) {
+		groups, orgs, err := c.resolveGroups(ctx, client, groupScope, userLogin, userEmail)
+		if err == nil {
+			c.groupsCache.add(key, groups, orgs)
+			c.debug(ctx, "github: resolved groups", "user", userLogin, "groups", groups, "cached", false)
+		}
+		return groups, orgs, err
+	}
+	if c.groupsFlight == nil {
+		return resolve(ctx)
+	}
+
+	// Concurrent refreshes for the same user share a single lookup. It isn't
+	// canceled with the context of the caller that started it, as others may
+	// still be waiting for it, but each caller stops waiting when its own
+	// context is done.
+	flightKey := fmt.Sprintf("%s\x00%s\x00%t", userLogin, userEmail, groupScope)
+	ch := c.groupsFlight.DoChan(flightKey, func() (any, error) {
+		groups, orgs, err := resolve(context.WithoutCancel(ctx))
+		if err != nil {
+			return nil, err
+		}
+		return groupsEntry{groups: groups, orgs: orgs}, nil
+	})
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, nil, res.Err
+		}
+		// The result is shared between callers, who may modify their slices.
+		entry := res.Val.(groupsEntry)
+		return slices.Clone(entry.groups), slices.Clone(entry.orgs), nil
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+}
+
+// resolveGroups looks up the groups and orgs of a user on GitHub.
+func (c *githubConnector) resolveGroups(ctx context.Context, client *http.Client, groupScope bool, userLogin, userEmail string) (groups []string, orgs []string, err error) {
 	switch {
 	case len(c.orgs) > 0:
-		return c.groupsForOrgs(ctx, client, userLogin)
+		groups, err = c.groupsForOrgs(ctx, c.groupsClient(ctx, client), userLogin, userEmail)
 	case c.org != "":
-		return c.teamsForOrg(ctx, client, c.org)
+		groupsClient := c.groupsClient(ctx, client)
+		groups, err = c.userTeamsInOrg(ctx, groupsClient, c.org, userLogin)
+		for i, group := range groups {
+			if mapped, ok := c.mapTeamGroup(formatTeamName(c.org, group)); ok {
+				groups[i] = mapped
+			}
+		}
+		if err == nil && c.includeOrgInGroups {
+			// Members of any of the org's teams are known to be in the org.
+			inOrg := len(groups) > 0
+			if !inOrg {
+				inOrg, err = c.userInOrg(ctx, groupsClient, userLogin, c.org)
+			}
+			if inOrg {
+				var name string
+				if name, err = c.orgNamer(ctx, groupsClient)(c.org); err == nil {
+					groups = append([]string{name}, groups...)
+				}
+			}
+		}
 	case groupScope && c.loadAllGroups:
-		return c.userGroups(ctx, client)
+		groups, orgs, err = c.userGroups(ctx, client, userLogin)
+	case groupScope:
+		err = c.handleUnsatisfiedGroupsScope(ctx)
 	}
-	return nil, nil
+	return c.limitGroups(ctx, userLogin, uniqueGroups(groups)), orgs, err
 }
 
-// formatTeamName returns unique team name.
-// Orgs might have the same team names. To make team name unique it should be prefixed with the org name.
-func formatTeamName(org string, team string) string {
-	return fmt.Sprintf("%s:%s", org, team)
+// limitGroups truncates groups to maxGroups. Groups of priorityGroups are
+// kept first, then the others in order, and the kept groups stay in their
+// original order.
+func (c *githubConnector) limitGroups(ctx context.Context, userLogin string, groups []string) []string {
+	if c.maxGroups == 0 || len(groups) <= c.maxGroups {
+		return groups
+	}
+	keep := make([]bool, len(groups))
+	kept := 0
+	for i, group := range groups {
+		if kept < c.maxGroups && c.isPriorityGroup(group) {
+			keep[i] = true
+			kept++
+		}
+	}
+	for i := range groups {
+		if kept < c.maxGroups && !keep[i] {
+			keep[i] = true
+			kept++
+		}
+	}
+	limited := make([]string, 0, c.maxGroups)
+	for i, group := range groups {
+		if keep[i] {
+			limited = append(limited, group)
+		}
+	}
+	c.debug(ctx, "github: truncated groups", "user", userLogin, "groups", len(groups), "max_groups", c.maxGroups)
+	return limited
 }
 
-// groupsForOrgs enforces org and team constraints on user authorization
-// Cases in which user is authorized:
-//
+// isPriorityGroup returns whether group is one of the priorityGroups, or a
+// team of one of the orgs listed there.
+func (c *githubConnector) isPriorityGroup(group string) bool {
+	for _, priority := range c.priorityGroups {
+		if c.groupNameEqual(group, priority) {
+			return true
+		}
+		if org, _, ok := strings.Cut(group, ":"); ok && !strings.Contains(priority, ":") && c.groupNameEqual(org, priority) {
+			return true
+		}
+	}
+	return false
+}
+
+// groupNameEqual compares group names, case-insensitively with
+// caseInsensitiveGroups.
+func (c *githubConnector) groupNameEqual(a, b string) bool {
+	if c.caseInsensitiveGroups {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// uniqueGroups returns groups without duplicates, keeping the first occurrence
+// of each group.
+func uniqueGroups(groups []string) []string {
+	if len(groups) < 2 {
+		return groups
+	}
+	seen := make(map[string]bool, len(groups))
+	unique := make([]string, 0, len(groups))
+	for _, group := range groups {
+		if !seen[group] {
+			seen[group] = true
+			unique = append(unique, group)
+		}
+	}
+	return unique
+}
+
+// Values of UnsatisfiedGroupsScope.
+const (
+	unsatisfiedGroupsScopeIgnore = "ignore"
+	unsatisfiedGroupsScopeWarn   = "warn"
+	unsatisfiedGroupsScopeError  = "error"
+)
+
+// errGroupsScopeUnsatisfied is returned for logins requesting the groups scope
+// when UnsatisfiedGroupsScope is "error".
+var errGroupsScopeUnsatisfied = errors.New("github: groups scope requested but connector has no orgs configured and loadAllGroups disabled")
+
+// handleUnsatisfiedGroupsScope reports a groups scope that no groups can be
+// returned for, as configured by UnsatisfiedGroupsScope.
+func (c *githubConnector) handleUnsatisfiedGroupsScope(ctx context.Context) error {
+	switch c.unsatisfiedGroupsScope {
+	case unsatisfiedGroupsScopeWarn:
+		c.logger.WarnContext(ctx, "groups scope requested but connector has no orgs configured and loadAllGroups disabled, returning no groups")
+	case unsatisfiedGroupsScopeError:
+		return errGroupsScopeUnsatisfied
+	}
+	return nil
+}
+
+// orgCountClaim is the extra claim holding the number of orgs a user is in.
+const orgCountClaim = "github_org_count"
+
+// setOrgCountClaim adds the org count claim to identity if it's enabled and the
+// user's org list was fetched. It never fetches the org list itself.
+func (c *githubConnector) setOrgCountClaim(identity *connector.Identity, orgs []string) {
+	if !c.includeOrgCountClaim || orgs == nil {
+		return
+	}
+	if identity.ExtraClaims == nil {
+		identity.ExtraClaims = make(map[string]interface{})
+	}
+	identity.ExtraClaims[orgCountClaim] = len(orgs)
+}
+
+// accountCreatedAtClaim is the extra claim holding the account creation time.
+const accountCreatedAtClaim = "github_created_at"
+
+// setAccountAgeClaim adds the account creation time claim to identity if it's
+// enabled and GitHub reported the creation time.
+func (c *githubConnector) setAccountAgeClaim(identity *connector.Identity, createdAt time.Time) {
+	if !c.includeAccountAgeClaim || createdAt.IsZero() {
+		return
+	}
+	if identity.ExtraClaims == nil {
+		identity.ExtraClaims = make(map[string]interface{})
+	}
+	identity.ExtraClaims[accountCreatedAtClaim] = createdAt.Unix()
+}
+
+// subjectHashClaim is the extra claim holding the salted hash of the user's ID.
+const subjectHashClaim = "subject_hash"
+
+// setSubjectHashClaim adds the subject hash claim to identity if it's enabled.
+func (c *githubConnector) setSubjectHashClaim(identity *connector.Identity, id int) {
+	if len(c.subjectHashSalt) == 0 {
+		return
+	}
+	mac := hmac.New(sha256.New, c.subjectHashSalt)
+	mac.Write([]byte(strconv.Itoa(id)))
+	if identity.ExtraClaims == nil {
+		identity.ExtraClaims = make(map[string]interface{})
+	}
+	identity.ExtraClaims[subjectHashClaim] = hex.EncodeToString(mac.Sum(nil))
+}
+
+// avatarClaim is the extra claim holding the URL of the user's avatar. It's
+// the standard OpenID Connect profile picture claim.
+const avatarClaim = "picture"
+
+// setAvatarClaim adds the avatar claim to identity if GitHub reported an
+// avatar, replacing any stale one on refresh.
+func setAvatarClaim(identity *connector.Identity, avatarURL string) {
+	if avatarURL == "" {
+		delete(identity.ExtraClaims, avatarClaim)
+		return
+	}
+	if identity.ExtraClaims == nil {
+		identity.ExtraClaims = make(map[string]interface{})
+	}
+	identity.ExtraClaims[avatarClaim] = avatarURL
+}
+
+// checkAccountAge denies accounts younger than the configured minimum age.
+func (c *githubConnector) checkAccountAge(createdAt time.Time) error {
+	if c.minAccountAge == 0 {
+		return nil
+	}
+	if createdAt.IsZero() {
+		return authorizationError("github: account creation time unknown, cannot check minimum account age")
+	}
+	if age := time.Since(createdAt); age < c.minAccountAge {
+		return authorizationError(fmt.Sprintf("github: account created %s ago, minimum age is %s", age.Truncate(time.Second), c.minAccountAge))
+	}
+	return nil
+}
+
+// Ping checks that the GitHub API can be reached by requesting the rate limit
+// status, which doesn't count against the rate limit. The request is made as
+// the GitHub App installation if configured. Failures match
+// ErrUpstreamUnavailable.
+func (c *githubConnector) Ping(ctx context.Context) error {
+	client := c.httpClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	client = c.groupsClient(ctx, client)
+
+	// https://docs.github.com/en/rest/rate-limit/rate-limit#get-rate-limit-status-for-the-authenticated-user
+	var status struct{}
+	if _, err := c.get(ctx, client, c.apiURL+"/rate_limit", &status); err != nil {
+		return &unavailableError{fmt.Errorf("github: ping: %w", err)}
+	}
+	return nil
+}
+
+// checkLoginOrgs denies users who aren't a member of any of the loginOrgs.
+func (c *githubConnector) checkLoginOrgs(ctx context.Context, client *http.Client, userLogin string) error {
+	if len(c.loginOrgs) == 0 {
+		return nil
+	}
+	groupsClient := c.groupsClient(ctx, client)
+	for _, org := range c.loginOrgs {
+		inOrg, err := c.userInOrg(ctx, groupsClient, userLogin, org)
+		if err != nil {
+			return err
+		}
+		if inOrg {
+			return nil
+		}
+	}
+	return &OrgAuthorizationError{User: userLogin, DeniedOrgs: c.loginOrgs}
+}
+
+// transformGroups applies the configured group transforms to groups. Groups
+// that end up empty are dropped, and groups that end up identical are merged.
+func (c *githubConnector) transformGroups(groups []string) []string {
+	if len(c.groupTransforms) == 0 {
+		return groups
+	}
+	transformed := make([]string, 0, len(groups))
+	for _, group := range groups {
+		for _, transform := range c.groupTransforms {
+			group = transform(group)
+		}
+		if group != "" && !slices.Contains(transformed, group) {
+			transformed = append(transformed, group)
+		}
+	}
+	return transformed
+}
+
+// withDefaultGroups appends the configured default groups to groups, skipping
+// any that are already present.
+func (c *githubConnector) withDefaultGroups(groups []string) []string {
+	for _, group := range c.defaultGroups {
+		if !slices.Contains(groups, group) {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// mapTeamGroup returns the custom name TeamNameMapping gives group, the
+// "org:team" group of a team, if any. A role suffix added by includeTeamRole
+// is kept.
+func (c *githubConnector) mapTeamGroup(group string) (string, bool) {
+	if mapped, ok := c.teamNameMapping[group]; ok {
+		return mapped, true
+	}
+	if c.includeTeamRole {
+		if i := strings.LastIndex(group, ":"); i >= 0 {
+			if mapped, ok := c.teamNameMapping[group[:i]]; ok {
+				return mapped + group[i:], true
+			}
+		}
+	}
+	return "", false
+}
+
+// formatTeamName returns unique team name.
+// Orgs might have the same team names. To make team name unique it should be prefixed with the org name.
+func formatTeamName(org string, team string) string {
+	return fmt.Sprintf("%s:%s", org, team)
+}
+
+// groupsForOrgs fetches the user's membership of the configured orgs and
+// enforces org and team constraints on it, see EvaluateOrgAuthorization.
+func (c *githubConnector) groupsForOrgs(ctx context.Context, client *http.Client, userName, userEmail string) ([]string, error) {
+	results, err := c.orgMemberships(ctx, client, userName, userEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	memberships := make(map[string][]string)
+	// The configured orgs, with names in GitHub's casing if matched
+	// case-insensitively.
+	orgs := make([]Org, len(c.orgs))
+	for i, result := range results {
+		orgs[i] = result.org
+		if result.inOrg {
+			memberships[result.org.Name] = result.teams
+		}
+		c.debug(ctx, "github: checked org membership",
+			"user", userName, "org", result.org.Name, "member", result.inOrg, "teams", result.teams)
+	}
+
+	groups, authorized := EvaluateOrgAuthorization(orgs, memberships)
+	if c.includeOrgInGroups {
+		// Each org the user is in precedes its teams.
+		groups = make([]string, 0, len(groups)+len(memberships))
+		for _, org := range orgs {
+			if _, inOrg := memberships[org.Name]; !inOrg {
+				continue
+			}
+			orgGroups, _ := EvaluateOrgAuthorization([]Org{org}, memberships)
+			groups = append(groups, org.Name)
+			groups = append(groups, orgGroups...)
+		}
+	}
+	orgName := c.orgNamer(ctx, client)
+	for i, group := range groups {
+		if mapped, ok := c.mapTeamGroup(group); ok {
+			groups[i] = mapped
+			continue
+		}
+		// Groups are either an org login or "login:team".
+		login, team, isTeam := strings.Cut(group, ":")
+		name, err := orgName(login)
+		if err != nil {
+			return nil, err
+		}
+		groups[i] = name
+		if isTeam {
+			groups[i] = formatTeamName(name, team)
+		}
+	}
+	c.debug(ctx, "github: evaluated org authorization", "user", userName, "authorized", authorized, "groups", groups)
+	if !authorized {
+		deniedOrgs := make([]string, 0, len(c.orgs))
+		for _, org := range c.orgs {
+			deniedOrgs = append(deniedOrgs, org.Name)
+		}
+		return groups, &OrgAuthorizationError{User: userName, DeniedOrgs: deniedOrgs}
+	}
+	return groups, nil
+}
+
+// orgMembership is the user's membership of one of the configured orgs.
+type orgMembership struct {
+	// the configured org, in GitHub's casing with caseInsensitiveGroups
+	org   Org
+	inOrg bool
+	teams []string
+}
+
+// orgMemberships checks the user's membership of each configured org, up to
+// c.concurrency orgs at once. The results are in the order of c.orgs. The
+// first error cancels the checks still running.
+func (c *githubConnector) orgMemberships(ctx context.Context, client *http.Client, userName, userEmail string) ([]orgMembership, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		results  = make([]orgMembership, len(c.orgs))
+		sem      = make(chan struct{}, max(c.concurrency, 1))
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for i, org := range c.orgs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+			if ctx.Err() != nil {
+				return
+			}
+
+			result, err := c.orgMembership(ctx, client, org, userName, userEmail)
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			results[i] = result
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	// ctx may have been canceled by the caller before any check failed.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// orgMembership checks the user's membership of org and their teams in it.
+func (c *githubConnector) orgMembership(ctx context.Context, client *http.Client, org Org, userName, userEmail string) (orgMembership, error) {
+	result := orgMembership{org: org}
+	// Membership of an org is only checked if the user's email satisfies
+	// its domain requirement.
+	if org.RequiredEmailDomain != "" && !emailInDomain(userEmail, org.RequiredEmailDomain) {
+		c.logger.Info("user email not in domain required by org", "user", userName, "org", org.Name)
+		return result, nil
+	}
+
+	if c.orgMisses.isMiss(userName, org.Name) {
+		c.logger.Debug("user recently found not in org, skipping membership check", "user", userName, "org", org.Name)
+		return result, nil
+	}
+	inOrg, err := c.userInOrg(ctx, client, userName, org.Name)
+	if err != nil {
+		return result, err
+	}
+	if !inOrg {
+		c.orgMisses.addMiss(userName, org.Name)
+		return result, nil
+	}
+
+	teams, err := c.userTeamsInOrg(ctx, client, org.Name, userName)
+	if err != nil {
+		return result, err
+	}
+	if c.caseInsensitiveGroups {
+		if result.org, err = c.canonicalOrg(ctx, client, org, teams); err != nil {
+			return result, err
+		}
+	}
+	if len(org.Teams) > 0 && len(filterTeams(teams, result.org.Teams)) == 0 {
+		c.logger.Info("user in org but no teams", "user", userName, "org", org.Name)
+	}
+	result.inOrg = true
+	result.teams = teams
+	return result, nil
+}
+
+// canonicalOrg returns a copy of the configured org with its name and team
+// names in GitHub's casing. Teams are matched against the user's teams, so
+// teams the user isn't in keep their configured names.
+func (c *githubConnector) canonicalOrg(ctx context.Context, client *http.Client, configured Org, userTeams []string) (Org, error) {
+	// https://docs.github.com/en/rest/orgs/orgs#get-an-organization
+	var o org
+	if _, err := c.get(ctx, client, fmt.Sprintf("%s/orgs/%s", c.apiURL, configured.Name), &o); err != nil {
+		return Org{}, fmt.Errorf("github: get org: %w", err)
+	}
+	if o.Login != "" {
+		configured.Name = o.Login
+	}
+
+	teams := make([]string, len(configured.Teams))
+	for i, name := range configured.Teams {
+		teams[i] = name
+		for _, userTeam := range userTeams {
+			if strings.EqualFold(userTeam, name) {
+				teams[i] = userTeam
+				break
+			}
+		}
+	}
+	// The user's teams matching a pattern regardless of case are added by
+	// name, so that they're matched exactly later.
+	if matcher, err := groups_pkg.NewMatcher(configured.Teams, true); err == nil {
+		for _, userTeam := range userTeams {
+			if matcher.Match(userTeam) && !slices.Contains(teams, userTeam) {
+				teams = append(teams, userTeam)
+			}
+		}
+	}
+	configured.Teams = teams
+	return configured, nil
+}
+
+// filterTeams returns the teams matching the configured teams of an org,
+// which may hold patterns, see Org.Teams.
+func filterTeams(teams, configured []string) []string {
+	matcher, err := groups_pkg.NewMatcher(configured, false)
+	if err != nil {
+		// Open rejects malformed patterns, so only match exact names.
+		return groups_pkg.Filter(teams, configured)
+	}
+	return matcher.Filter(teams)
+}
+
+// OrgAuthorizationError is returned when none of the configured orgs
+// authorizes a user.
+type OrgAuthorizationError struct {
+	// User is the login of the denied user.
+	User string
+	// DeniedOrgs lists the configured orgs that didn't authorize the user,
+	// because the user isn't a member, isn't in any of the org's configured
+	// teams or lacks the org's required email domain. It is meant for logs
+	// and isn't part of the error message.
+	DeniedOrgs []string
+}
+
+func (e *OrgAuthorizationError) Error() string {
+	return fmt.Sprintf("github: user %q not in required orgs or teams", e.User)
+}
+
+// Is reports whether target is ErrUserNotAuthorized.
+func (e *OrgAuthorizationError) Is(target error) bool {
+	return target == ErrUserNotAuthorized
+}
+
+// As lets errors.As match the error as a *connector.AuthorizationError.
+func (e *OrgAuthorizationError) As(target any) bool {
+	t, ok := target.(**connector.AuthorizationError)
+	if ok {
+		*t = &connector.AuthorizationError{User: e.User, Denied: e.DeniedOrgs}
+	}
+	return ok
+}
+
+// EvaluateOrgAuthorization enforces org and team constraints on user
+// authorization. memberships maps the name of each org the user is a member
+// of to the names of the user's teams in that org; orgs missing from it are
+// treated as orgs the user isn't a member of.
+//
+// Cases in which user is authorized:
+//
 //	N orgs, no teams: user is member of at least 1 org
 //	N orgs, M teams per org: user is member of any team from at least 1 org
 //	N-1 orgs, M teams per org, 1 org with no teams: user is member of any team
 //
 // from at least 1 org, or member of org with no teams
-func (c *githubConnector) groupsForOrgs(ctx context.Context, client *http.Client, userName string) ([]string, error) {
-	groups := make([]string, 0)
-	var inOrgNoTeams bool
-	for _, org := range c.orgs {
-		inOrg, err := c.userInOrg(ctx, client, userName, org.Name)
-		if err != nil {
-			return nil, err
-		}
+//
+// The returned groups hold the "org:team" names of the user's teams, limited
+// to the configured teams of orgs that have them.
+func EvaluateOrgAuthorization(orgs []Org, memberships map[string][]string) (groups []string, authorized bool) {
+	groups = make([]string, 0)
+	for _, org := range orgs {
+		teams, inOrg := memberships[org.Name]
 		if !inOrg {
 			continue
 		}
 
-		teams, err := c.teamsForOrg(ctx, client, org.Name)
-		if err != nil {
-			return nil, err
-		}
 		// User is in at least one org. User is authorized if no teams are specified
 		// in config; include all teams in claim. Otherwise filter out teams not in
 		// 'teams' list in config.
 		if len(org.Teams) == 0 {
-			inOrgNoTeams = true
-		} else if teams = groups_pkg.Filter(teams, org.Teams); len(teams) == 0 {
-			c.logger.Info("user in org but no teams", "user", userName, "org", org.Name)
+			authorized = true
+		} else {
+			teams = filterTeams(teams, org.Teams)
 		}
 
 		for _, teamName := range teams {
 			groups = append(groups, formatTeamName(org.Name, teamName))
 		}
 	}
-	if inOrgNoTeams || len(groups) > 0 {
-		return groups, nil
-	}
-	return groups, fmt.Errorf("github: user %q not in required orgs or teams", userName)
+	return groups, authorized || len(groups) > 0
 }
 
-func (c *githubConnector) userGroups(ctx context.Context, client *http.Client) ([]string, error) {
+func (c *githubConnector) userGroups(ctx context.Context, client *http.Client, userName string) ([]string, []string, error) {
+	if c.useGraphQL {
+		orgs, orgTeams, err := c.userOrgTeamsGraphQL(ctx, client, userName)
+		if err == nil {
+			groups, err := c.orgTeamGroups(ctx, client, orgs, orgTeams)
+			return groups, orgs, err
+		}
+		c.logger.WarnContext(ctx, "failed to get groups through GraphQL, falling back to REST", "user", userName, "err", err)
+	}
+
 	orgs, err := c.userOrgs(ctx, client)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	orgTeams, err := c.userOrgTeams(ctx, client)
+	orgTeams, err := c.userOrgTeams(ctx, client, userName)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
+	groups, err := c.orgTeamGroups(ctx, client, orgs, orgTeams)
+	return groups, orgs, err
+}
+
+// orgTeamGroups returns the groups of orgs and their teams, as returned by
+// userGroups.
+func (c *githubConnector) orgTeamGroups(ctx context.Context, client *http.Client, orgs []string, orgTeams map[string][]string) ([]string, error) {
+	orgName := c.orgNamer(ctx, client)
 	groups := make([]string, 0)
 	for _, o := range orgs {
-		groups = append(groups, o)
+		name, err := orgName(o)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, name)
 		if teams, ok := orgTeams[o]; ok {
 			for _, t := range teams {
-				groups = append(groups, formatTeamName(o, t))
+				group := formatTeamName(name, t)
+				if mapped, ok := c.mapTeamGroup(formatTeamName(o, t)); ok {
+					group = mapped
+				}
+				groups = append(groups, group)
 			}
 		}
 	}
-
 	return groups, nil
 }
 
+// orgNamer returns a function naming orgs in groups by their login or, if
+// orgNameField is "name", their display name. Each org's display name is only
+// looked up once.
+func (c *githubConnector) orgNamer(ctx context.Context, client *http.Client) func(login string) (string, error) {
+	if c.orgNameField != "name" {
+		return func(login string) (string, error) { return login, nil }
+	}
+	names := make(map[string]string)
+	return func(login string) (string, error) {
+		if name, ok := names[login]; ok {
+			return name, nil
+		}
+		// https://docs.github.com/en/rest/orgs/orgs#get-an-organization
+		var o org
+		if _, err := c.get(ctx, client, fmt.Sprintf("%s/orgs/%s", c.apiURL, login), &o); err != nil {
+			return "", fmt.Errorf("github: get org: %w", err)
+		}
+		name := o.Name
+		if name == "" {
+			name = login
+		}
+		names[login] = name
+		return name, nil
+	}
+}
+
 // userOrgs retrieves list of current user orgs
 func (c *githubConnector) userOrgs(ctx context.Context, client *http.Client) ([]string, error) {
 	groups := make([]string, 0)
-	apiURL := c.apiURL + "/user/orgs"
-	for {
+	apiURL := c.pagedURL("/user/orgs")
+	for page := 1; ; page++ {
 		// https://developer.github.com/v3/orgs/#list-your-organizations
 		var (
 			orgs []org
 			err  error
 		)
-		if apiURL, err = get(ctx, client, apiURL, &orgs); err != nil {
-			return nil, fmt.Errorf("github: get orgs: %v", err)
+		if apiURL, err = c.get(ctx, client, apiURL, &orgs); err != nil {
+			return nil, fmt.Errorf("github: get orgs: %w", err)
 		}
 
 		for _, o := range orgs {
-			groups = append(groups, o.Login)
+			if !c.isExcludedOrg(o.Login) {
+				groups = append(groups, o.Login)
+			}
 		}
+		c.logPageProgress(ctx, "orgs", page, len(groups))
 
 		if apiURL == "" {
 			break
@@ -441,60 +2028,460 @@ func (c *githubConnector) userOrgs(ctx context.Context, client *http.Client) ([]
 
 // userOrgTeams retrieves teams which current user belongs to.
 // Method returns a map where key is an org name and value list of teams under the org.
-func (c *githubConnector) userOrgTeams(ctx context.Context, client *http.Client) (map[string][]string, error) {
-	groups := make(map[string][]string)
-	apiURL := c.apiURL + "/user/teams"
-	for {
+func (c *githubConnector) userOrgTeams(ctx context.Context, client *http.Client, userName string) (map[string][]string, error) {
+	orgTeams := make(map[string][]team)
+	apiURL := c.pagedURL("/user/teams")
+	count := 0
+	for page := 1; ; page++ {
 		// https://developer.github.com/v3/orgs/teams/#list-user-teams
 		var (
 			teams []team
 			err   error
 		)
-		if apiURL, err = get(ctx, client, apiURL, &teams); err != nil {
-			return nil, fmt.Errorf("github: get teams: %v", err)
+		if apiURL, err = c.get(ctx, client, apiURL, &teams); err != nil {
+			return nil, fmt.Errorf("github: get teams: %w", err)
 		}
 
 		for _, t := range teams {
-			groups[t.Org.Login] = append(groups[t.Org.Login], c.teamGroupClaims(t)...)
+			if !c.isExcludedOrg(t.Org.Login) {
+				orgTeams[t.Org.Login] = append(orgTeams[t.Org.Login], t)
+			}
 		}
+		count += len(teams)
+		c.logPageProgress(ctx, "teams", page, count)
 
 		if apiURL == "" {
 			break
 		}
 	}
 
+	return c.orgTeamsGroupClaims(ctx, client, userName, orgTeams)
+}
+
+// orgTeamsGroupClaims returns the group claims of userName's teams of each org,
+// see teamsGroupClaims.
+func (c *githubConnector) orgTeamsGroupClaims(ctx context.Context, client *http.Client, userName string, orgTeams map[string][]team) (map[string][]string, error) {
+	groups := make(map[string][]string, len(orgTeams))
+	for orgName, teams := range orgTeams {
+		claims, err := c.teamsGroupClaims(ctx, client, orgName, userName, teams)
+		if err != nil {
+			return nil, err
+		}
+		groups[orgName] = claims
+	}
 	return groups, nil
 }
 
-// get creates a "GET `apiURL`" request with context, sends the request using
-// the client, and decodes the resulting response body into v. A pagination URL
-// is returned if one exists. Any errors encountered when building requests,
-// sending requests, and reading and decoding response data are returned.
-func get(ctx context.Context, client *http.Client, apiURL string, v interface{}) (string, error) {
+// isExcludedOrg returns whether orgName is one of the excludeOrgs.
+func (c *githubConnector) isExcludedOrg(orgName string) bool {
+	return slices.ContainsFunc(c.excludeOrgs, func(excluded string) bool {
+		return strings.EqualFold(excluded, orgName)
+	})
+}
+
+// ssoError is returned when GitHub responds with an X-GitHub-SSO header
+// requiring the access token to be authorized for an org's SAML single
+// sign-on. It matches ErrSSORequired.
+type ssoError struct {
+	org string // empty if unknown
+	url string // where the token can be authorized, empty if unknown
+}
+
+func (e *ssoError) Error() string {
+	msg := "github: access token must be authorized for SAML single sign-on"
+	if e.org != "" {
+		msg += fmt.Sprintf(" of org %q", e.org)
+	}
+	if e.url != "" {
+		msg += ", authorize it at " + e.url + " and log in again"
+	} else {
+		msg += ", authorize it in your GitHub settings and log in again"
+	}
+	return msg
+}
+
+func (e *ssoError) Is(target error) bool {
+	return target == ErrSSORequired
+}
+
+// ssoRequired returns an *ssoError if header holds an X-GitHub-SSO header
+// requiring SSO authorization, e.g. "required; url=https://github.com/...".
+// Other values, such as "partial-results" for lists some orgs were left out
+// of, return nil.
+//
+// https://docs.github.com/en/rest/authentication/authenticating-to-the-rest-api#accessing-resources-that-use-saml-sso
+func ssoRequired(header http.Header, org string) error {
+	parts := strings.Split(header.Get("X-GitHub-SSO"), ";")
+	if strings.TrimSpace(parts[0]) != "required" {
+		return nil
+	}
+	err := &ssoError{org: org}
+	for _, part := range parts[1:] {
+		if u, ok := strings.CutPrefix(strings.TrimSpace(part), "url="); ok {
+			err.url = u
+		}
+	}
+	return err
+}
+
+// apiError is returned by get when the GitHub API responds with a non-200
+// status code.
+type apiError struct {
+	statusCode int
+	status     string
+	header     http.Header
+	body       []byte
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s: %s", e.status, e.body)
+}
+
+// Is reports whether the error is a GitHub maintenance, server error or rate
+// limit response, so that errors.Is(err, ErrServiceUnavailable), errors.Is(err,
+// ErrUpstreamUnavailable) and errors.Is(err, ErrRateLimited) hold while the
+// response body is still available for logging. Maintenance responses also
+// match connector.ErrUnavailable.
+func (e *apiError) Is(target error) bool {
+	switch target {
+	case ErrServiceUnavailable, connector.ErrUnavailable:
+		return e.maintenance()
+	case ErrUpstreamUnavailable:
+		return e.statusCode >= http.StatusInternalServerError
+	case ErrRateLimited:
+		_, primary := e.rateLimitReset()
+		_, secondary := retryAfter(e.statusCode, e.header)
+		return primary || secondary
+	}
+	return false
+}
+
+// maintenance returns whether the response indicates that GitHub is down for
+// maintenance, as opposed to any other server error.
+func (e *apiError) maintenance() bool {
+	return e.statusCode == http.StatusServiceUnavailable &&
+		strings.Contains(strings.ToLower(string(e.body)), "maintenance")
+}
+
+// rateLimitReset returns when the rate limit that caused the error resets, if
+// the error is due to GitHub's primary rate limit.
+//
+// https://docs.github.com/en/rest/using-the-rest-api/rate-limits-for-the-rest-api
+func (e *apiError) rateLimitReset() (time.Time, bool) {
+	if e.statusCode != http.StatusForbidden && e.statusCode != http.StatusTooManyRequests {
+		return time.Time{}, false
+	}
+	if e.header.Get("X-RateLimit-Remaining") != "0" {
+		return time.Time{}, false
+	}
+	reset, err := strconv.ParseInt(e.header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(reset, 0), true
+}
+
+// retryAfter returns how long GitHub's secondary rate limit asks to wait
+// before retrying a response, if it does.
+//
+// https://docs.github.com/en/rest/using-the-rest-api/rate-limits-for-the-rest-api#exceeding-the-rate-limit
+func retryAfter(statusCode int, header http.Header) (time.Duration, bool) {
+	if statusCode != http.StatusForbidden && statusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+// get sends a "GET `apiURL`" request using the client, and decodes the
+// resulting response body into v. A pagination URL is returned if one exists.
+// Requests are retried as described on do.
+func (c *githubConnector) get(ctx context.Context, client *http.Client, apiURL string, v interface{}) (string, error) {
+	var header http.Header
+	expected := []int{http.StatusOK}
+	cached, ok := c.etags.get(apiURL)
+	if ok {
+		header = http.Header{"If-None-Match": {cached.etag}}
+		expected = append(expected, http.StatusNotModified)
+	}
+	resp, err := c.do(ctx, client, apiURL, header, expected...)
+	if err != nil {
+		var apiErr *apiError
+		if errors.As(err, &apiErr) {
+			if ssoErr := ssoRequired(apiErr.header, ""); ssoErr != nil {
+				return "", ssoErr
+			}
+		}
+		return "", err
+	}
+	if status, ok := ctx.Value(rateLimitKey{}).(*rateLimitStatus); ok {
+		status.record(resp.Header)
+	}
+	if strings.HasPrefix(resp.Header.Get("X-GitHub-SSO"), "partial-results") {
+		c.logger.WarnContext(ctx, "github: orgs the access token isn't authorized for SAML SSO of were left out of the results",
+			"url", apiURL, "sso", resp.Header.Get("X-GitHub-SSO"))
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		resp.body = cached.body
+		resp.Header.Set("Link", cached.link)
+	} else if etag := resp.Header.Get("ETag"); etag != "" {
+		c.etags.add(apiURL, etagEntry{etag: etag, body: resp.body, link: resp.Header.Get("Link")})
+	}
+
+	if err := json.Unmarshal(resp.body, v); err != nil {
+		return "", fmt.Errorf("failed to decode response: %v", err)
+	}
+
+	return getPagination(apiURL, resp.Response), nil
+}
+
+// do calls send with header, returning responses with a status code other
+// than the expected ones as an *apiError. It retries requests that fail because GitHub
+// is down for maintenance after waiting maintenanceRetryInterval, requests
+// that hit the rate limit after it resets as configured by maxRetries, and
+// requests that fail to reach GitHub as configured by connectionRetries.
+// Requests GitHub asks to retry after a delay are retried once.
+func (c *githubConnector) do(ctx context.Context, client *http.Client, apiURL string, header http.Header, expected ...int) (*apiResponse, error) {
+	var (
+		maintenanceAttempts, rateLimitAttempts int
+		retriedAfter                           bool
+	)
+	for {
+		var resp *apiResponse
+		err := c.retryConnection(ctx, func() (err error) {
+			reqCtx, cancel := ctx, context.CancelFunc(func() {})
+			if c.httpTimeout > 0 {
+				reqCtx, cancel = context.WithTimeout(ctx, c.httpTimeout)
+			}
+			var start time.Time
+			if c.metrics != nil {
+				start = time.Now()
+			}
+			resp, err = send(reqCtx, client, apiURL, header, c.maxResponseBytes)
+			cancel()
+			if c.metrics != nil {
+				statusCode := 0
+				if err == nil {
+					statusCode = resp.StatusCode
+				}
+				c.metrics.observeRequest(apiURL, statusCode, time.Since(start))
+			}
+			err = c.wrapTimeout(ctx, err)
+			if err == nil && !slices.Contains(expected, resp.StatusCode) {
+				err = &apiError{statusCode: resp.StatusCode, status: resp.Status, header: resp.Header, body: resp.body}
+			}
+			return err
+		})
+		if errors.Is(err, ErrRateLimited) {
+			c.metrics.observeRateLimited(apiURL)
+		}
+
+		var (
+			wait   time.Duration
+			apiErr *apiError
+		)
+		switch {
+		case err == nil:
+			return resp, nil
+		case errors.Is(err, ErrServiceUnavailable) && maintenanceAttempts < c.maintenanceRetries:
+			maintenanceAttempts++
+			wait = c.maintenanceRetryInterval
+			c.logger.WarnContext(ctx, "github: API is down for maintenance, retrying",
+				"url", apiURL, "attempt", maintenanceAttempts, "retry_in", wait)
+		case errors.As(err, &apiErr) && apiErr.header.Get("Retry-After") != "":
+			var ok bool
+			wait, ok = retryAfter(apiErr.statusCode, apiErr.header)
+			if !ok || retriedAfter || !c.canWait(ctx, wait) {
+				return nil, err
+			}
+			retriedAfter = true
+			c.logger.WarnContext(ctx, "github: API secondary rate limit exceeded, retrying",
+				"url", apiURL, "retry_in", wait)
+		case errors.As(err, &apiErr) && rateLimitAttempts < c.maxRetries:
+			reset, ok := apiErr.rateLimitReset()
+			if !ok {
+				return nil, err
+			}
+			wait = max(time.Until(reset), 0)
+			// Give up straight away rather than fail later if the limit
+			// resets too late, so the caller gets the rate limit response.
+			if !c.canWait(ctx, wait) {
+				return nil, err
+			}
+			rateLimitAttempts++
+			c.logger.WarnContext(ctx, "github: API rate limit exceeded, retrying after reset",
+				"url", apiURL, "attempt", rateLimitAttempts, "retry_in", wait)
+		default:
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// wrapTimeout marks errors of requests that exceeded httpTimeout with
+// ErrRequestTimeout. Errors caused by ctx itself are returned unchanged.
+func (c *githubConnector) wrapTimeout(ctx context.Context, err error) error {
+	if err == nil || c.httpTimeout == 0 || ctx.Err() != nil {
+		return err
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return fmt.Errorf("%w: %w", ErrRequestTimeout, err)
+	}
+	return err
+}
+
+// canWait returns whether waiting for d before retrying a request stays within
+// retryMaxWait and the deadline of ctx.
+func (c *githubConnector) canWait(ctx context.Context, d time.Duration) bool {
+	if d > c.retryMaxWait {
+		return false
+	}
+	deadline, ok := ctx.Deadline()
+	return !ok || time.Now().Add(d).Before(deadline)
+}
+
+// retryConnection calls fn, retrying it up to connectionRetries times with
+// exponential backoff while it fails with a connection error. Since such a
+// request never reached GitHub, retrying is safe even for requests that aren't
+// idempotent, like exchanging an authorization code. Connection errors and
+// timeouts left once the retries are used up match ErrUpstreamUnavailable.
+func (c *githubConnector) retryConnection(ctx context.Context, fn func() error) error {
+	backoff := c.connectionRetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+		if attempt >= c.connectionRetries || !isConnectionError(err) {
+			if isConnectionError(err) || errors.Is(err, ErrRequestTimeout) {
+				return &unavailableError{err}
+			}
+			return err
+		}
+		c.logger.WarnContext(ctx, "github: failed to connect, retrying",
+			"err", err, "attempt", attempt+1, "retry_in", backoff)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxConnectionRetryBackoff)
+	}
+}
+
+// isConnectionError returns whether err indicates that a request failed before
+// reaching GitHub, as opposed to GitHub responding with an error.
+func isConnectionError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	// Dial errors cover refused connections and connection timeouts.
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	// net/http doesn't export its TLS handshake timeout error.
+	return strings.HasSuffix(err.Error(), "TLS handshake timeout")
+}
+
+// apiResponse is a GitHub API response whose body has already been read.
+type apiResponse struct {
+	*http.Response
+	body []byte
+}
+
+// send creates a "GET `apiURL`" request with context and any extra header,
+// sends the request using the client, and reads the response body. Any errors
+// encountered when building requests, sending requests, and reading response
+// data are returned. Response bodies larger than maxBytes are rejected unless
+// maxBytes is 0.
+func send(ctx context.Context, client *http.Client, apiURL string, header http.Header, maxBytes int64) (*apiResponse, error) {
 	req, err := http.NewRequest("GET", apiURL, nil)
 	if err != nil {
-		return "", fmt.Errorf("github: new req: %v", err)
+		return nil, fmt.Errorf("github: new req: %v", err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
 	}
 	req = req.WithContext(ctx)
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("github: get URL %v", err)
+		return nil, fmt.Errorf("github: get URL %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return "", fmt.Errorf("github: read body: %v", err)
-		}
-		return "", fmt.Errorf("%s: %s", resp.Status, body)
+	body, err := readBody(resp.Body, maxBytes)
+	if err != nil {
+		return nil, err
 	}
+	return &apiResponse{Response: resp, body: body}, nil
+}
 
-	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
-		return "", fmt.Errorf("failed to decode response: %v", err)
+// readBody reads r, failing if it holds more than maxBytes unless maxBytes is 0.
+func readBody(r io.Reader, maxBytes int64) ([]byte, error) {
+	if maxBytes > 0 {
+		// Read one byte past the limit to tell a body of exactly maxBytes
+		// apart from a larger one.
+		r = io.LimitReader(r, maxBytes+1)
 	}
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("github: read body: %v", err)
+	}
+	if maxBytes > 0 && int64(len(body)) > maxBytes {
+		return nil, fmt.Errorf("github: response body exceeds %d bytes", maxBytes)
+	}
+	return body, nil
+}
 
-	return getPagination(apiURL, resp), nil
+// pagedURL returns the URL of the first page of a paginated API endpoint. The
+// URLs of later pages, taken from the "Link" header, keep the page size.
+func (c *githubConnector) pagedURL(path string) string {
+	if c.pageSize == 0 {
+		return c.apiURL + path
+	}
+	return fmt.Sprintf("%s%s?per_page=%d", c.apiURL, path, c.pageSize)
+}
+
+// logPageProgress emits a debug log every pageLogInterval pages with the number
+// of items fetched so far, so operators can tell that a login against a very
+// large org is progressing rather than stuck.
+func (c *githubConnector) logPageProgress(ctx context.Context, resource string, page, count int) {
+	if page%pageLogInterval != 0 {
+		return
+	}
+	c.logger.DebugContext(ctx, "github: paginating API results", "resource", resource, "pages", page, "count", count)
+}
+
+// debugEnabled returns whether debug messages are logged, so that details only
+// needed for them aren't gathered otherwise.
+func (c *githubConnector) debugEnabled(ctx context.Context) bool {
+	return c.logger != nil && c.logger.Enabled(ctx, slog.LevelDebug)
+}
+
+// debug logs a debug message about resolving a user's groups. Callers must not
+// pass tokens or other secrets.
+func (c *githubConnector) debug(ctx context.Context, msg string, args ...any) {
+	if c.debugEnabled(ctx) {
+		c.logger.DebugContext(ctx, msg, args...)
+	}
 }
 
 // getPagination checks the "Link" header field for "next" or "last" pagination URLs,
@@ -528,10 +2515,12 @@ func getPagination(apiURL string, resp *http.Response) string {
 // user holds GitHub user information (relevant to dex) as defined by
 // https://developer.github.com/v3/users/#response-with-public-profile-information
 type user struct {
-	Name  string `json:"name"`
-	Login string `json:"login"`
-	ID    int    `json:"id"`
-	Email string `json:"email"`
+	Name      string    `json:"name"`
+	Login     string    `json:"login"`
+	ID        int       `json:"id"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+	AvatarURL string    `json:"avatar_url"`
 }
 
 // user queries the GitHub API for profile information using the provided client.
@@ -542,8 +2531,8 @@ func (c *githubConnector) user(ctx context.Context, client *http.Client) (user,
 	var u user
 
 	// https://developer.github.com/v3/users/#get-the-authenticated-user
-	if _, err := get(ctx, client, c.apiURL+"/user", &u); err != nil {
-		return u, err
+	if _, err := c.get(ctx, client, c.apiURL+"/user", &u); err != nil {
+		return u, &profileError{err: err}
 	}
 
 	// メールアドレスの公開状態によらず、noreply のメールアドレスを利用する
@@ -557,8 +2546,11 @@ func (c *githubConnector) user(ctx context.Context, client *http.Client) (user,
 
 	// Only public user emails are returned by 'GET /user'.
 	// If a user has no public email, we must retrieve private emails explicitly.
-	// If preferredEmailDomain is set, we always need to retrieve all emails.
-	if u.Email == "" || c.preferredEmailDomain != "" {
+	// If preferred email domains are set, we always need to retrieve all emails.
+	if c.publicEmailOnly {
+		return u, nil
+	}
+	if u.Email == "" || len(c.preferredEmailDomainList()) > 0 {
 		var err error
 		if u.Email, err = c.userEmail(ctx, client); err != nil {
 			return u, err
@@ -567,6 +2559,16 @@ func (c *githubConnector) user(ctx context.Context, client *http.Client) (user,
 	return u, nil
 }
 
+// profileError wraps an error getting the authenticated user's own profile, as
+// opposed to the other requests made to look the user up.
+type profileError struct {
+	err error
+}
+
+func (e *profileError) Error() string { return e.err.Error() }
+
+func (e *profileError) Unwrap() error { return e.err }
+
 // userEmail holds GitHub user email information as defined by
 // https://developer.github.com/v3/users/emails/#response
 type userEmail struct {
@@ -583,20 +2585,25 @@ type userEmail struct {
 // The HTTP client is expected to be constructed by the golang.org/x/oauth2 package,
 // which inserts a bearer token as part of the request.
 func (c *githubConnector) userEmail(ctx context.Context, client *http.Client) (string, error) {
+	preferredDomains := c.preferredEmailDomainList()
 	var (
-		primaryEmail    userEmail
-		preferredEmails []userEmail
+		primaryEmail userEmail
+		// the first verified email in any domain
+		firstVerifiedEmail string
+		// the first verified email in each preferred domain
+		preferredEmails = make([]string, len(preferredDomains))
 	)
 
-	apiURL := c.apiURL + "/user/emails"
+	apiURL := c.pagedURL("/user/emails")
+	count := 0
 
-	for {
+	for page := 1; ; page++ {
 		// https://developer.github.com/v3/users/emails/#list-email-addresses-for-a-user
 		var (
 			emails []userEmail
 			err    error
 		)
-		if apiURL, err = get(ctx, client, apiURL, &emails); err != nil {
+		if apiURL, err = c.get(ctx, client, apiURL, &emails); err != nil {
 			return "", err
 		}
 
@@ -610,56 +2617,136 @@ func (c *githubConnector) userEmail(ctx context.Context, client *http.Client) (s
 				In addition, GitHub Enterprise support replied to a support
 				ticket with "There is no way to verify an email address in
 				GitHub Enterprise."
+				Newer installations that do verify emails can opt out with
+				trustEnterpriseEmailVerification.
 			*/
-			if c.hostName != "" {
+			if c.hostName != "" && !c.honorEnterpriseEmailVerification {
 				email.Verified = true
 			}
 
 			if email.Verified && email.Primary {
 				primaryEmail = email
 			}
+			if email.Verified && firstVerifiedEmail == "" {
+				firstVerifiedEmail = email.Email
+			}
 
-			if c.preferredEmailDomain != "" {
+			if len(preferredDomains) > 0 {
 				_, domainPart, ok := strings.Cut(email.Email, "@")
 				if !ok {
 					return "", errors.New("github: invalid format email is detected")
 				}
-				if email.Verified && c.isPreferredEmailDomain(domainPart) {
-					preferredEmails = append(preferredEmails, email)
+				for i, preferredDomain := range preferredDomains {
+					if email.Verified && preferredEmails[i] == "" && c.isPreferredEmailDomain(preferredDomain, domainPart) {
+						preferredEmails[i] = email.Email
+					}
 				}
 			}
 		}
+		count += len(emails)
+		c.logPageProgress(ctx, "emails", page, count)
 
 		if apiURL == "" {
 			break
 		}
 	}
 
-	if len(preferredEmails) > 0 {
-		return preferredEmails[0].Email, nil
+	for _, email := range preferredEmails {
+		if email != "" {
+			return email, nil
+		}
 	}
 
-	if primaryEmail.Email != "" {
+	if primaryEmail.Email != "" && c.emailStrategy != emailStrategyFirstVerified {
 		return primaryEmail.Email, nil
 	}
+	if c.emailStrategy == "" || c.emailStrategy == emailStrategyPrimary {
+		return "", errors.New("github: user has no verified, primary email or preferred-domain email")
+	}
+	if firstVerifiedEmail != "" {
+		return firstVerifiedEmail, nil
+	}
+	return "", errors.New("github: user has no verified email")
+}
 
-	return "", errors.New("github: user has no verified, primary email or preferred-domain email")
+// Values of EmailStrategy.
+const (
+	emailStrategyPrimary                = "primary"
+	emailStrategyPrimaryThenAnyVerified = "primary-then-any-verified"
+	emailStrategyFirstVerified          = "first-verified"
+)
+
+// validatePreferredEmailDomain checks that domain is a well-formed preferred
+// email domain pattern.
+func validatePreferredEmailDomain(domain string, suffixMatch bool) error {
+	if domain == "" {
+		return errors.New("invalid PreferredEmailDomains: domain cannot be empty")
+	}
+	if err := validateEmailDomainPattern(domain); err != nil {
+		return fmt.Errorf("invalid PreferredEmailDomain: %v", err)
+	}
+	if suffixMatch && strings.Contains(domain, "*") {
+		return errors.New("invalid PreferredEmailDomain: glob pattern cannot be used with preferredEmailDomainSuffixMatch")
+	}
+	return nil
 }
 
-// isPreferredEmailDomain checks the domain is matching with preferredEmailDomain.
-func (c *githubConnector) isPreferredEmailDomain(domain string) bool {
-	if domain == c.preferredEmailDomain {
+// validateEmailDomainPattern checks that domain is a well-formed email domain
+// pattern, see matchEmailDomain.
+func validateEmailDomainPattern(domain string) error {
+	if strings.HasSuffix(domain, "*") {
+		return errors.New("glob pattern cannot end with \"*\"")
+	}
+	for _, label := range strings.Split(domain, ".") {
+		if label == "" {
+			return fmt.Errorf("%q has an empty label", domain)
+		}
+		if label != "*" && strings.Contains(label, "*") {
+			return fmt.Errorf("%q must use \"*\" as a whole label", domain)
+		}
+	}
+	return nil
+}
+
+// preferredEmailDomainList returns the preferred email domains in priority
+// order.
+func (c *githubConnector) preferredEmailDomainList() []string {
+	if c.preferredEmailDomain == "" {
+		return c.preferredEmailDomains
+	}
+	return append([]string{c.preferredEmailDomain}, c.preferredEmailDomains...)
+}
+
+// isPreferredEmailDomain checks the domain is matching with preferredDomain,
+// one of the preferred email domains.
+func (c *githubConnector) isPreferredEmailDomain(preferredDomain, domain string) bool {
+	if c.preferredEmailDomainSuffixMatch {
+		return domain == preferredDomain || strings.HasSuffix(domain, "."+preferredDomain)
+	}
+	return matchEmailDomain(preferredDomain, domain)
+}
+
+// matchEmailDomain reports whether domain matches pattern, in which a "*"
+// label matches any single label. A leading "*." followed by a literal domain
+// matches subdomains of any depth instead, e.g. "*.corp.com" matches
+// "mail.eng.corp.com".
+func matchEmailDomain(pattern, domain string) bool {
+	if domain == pattern {
 		return true
 	}
 
-	preferredDomainParts := strings.Split(c.preferredEmailDomain, ".")
+	if suffix, ok := strings.CutPrefix(pattern, "*"); ok && strings.HasPrefix(suffix, ".") && !strings.Contains(suffix, "*") {
+		return len(domain) > len(suffix) && strings.HasSuffix(domain, suffix)
+	}
+
+	patternParts := strings.Split(pattern, ".")
 	domainParts := strings.Split(domain, ".")
 
-	if len(preferredDomainParts) != len(domainParts) {
+	if len(patternParts) != len(domainParts) {
 		return false
 	}
 
-	for i, v := range preferredDomainParts {
+	for i, v := range patternParts {
 		if domainParts[i] != v && v != "*" {
 			return false
 		}
@@ -667,6 +2754,44 @@ func (c *githubConnector) isPreferredEmailDomain(domain string) bool {
 	return true
 }
 
+// checkAllowedEmailDomain returns an error if allowedEmailDomains is set and
+// the email's domain matches none of them, ignoring case.
+func (c *githubConnector) checkAllowedEmailDomain(email string) error {
+	if len(c.allowedEmailDomains) == 0 {
+		return nil
+	}
+
+	_, domain, _ := strings.Cut(email, "@")
+	domain = strings.ToLower(domain)
+	for _, pattern := range c.allowedEmailDomains {
+		if matchEmailDomain(strings.ToLower(pattern), domain) {
+			return nil
+		}
+	}
+	return authorizationError(fmt.Sprintf("github: user email %q not in allowed domains", email))
+}
+
+// checkRequiredEmailDomain returns an error if requiredEmailDomains is set and
+// the email's domain is not one of them.
+func (c *githubConnector) checkRequiredEmailDomain(email string) error {
+	if len(c.requiredEmailDomains) == 0 {
+		return nil
+	}
+
+	for _, domain := range c.requiredEmailDomains {
+		if emailInDomain(email, domain) {
+			return nil
+		}
+	}
+	return authorizationError(fmt.Sprintf("github: user email %q not in required domains", email))
+}
+
+// emailInDomain reports whether email belongs to domain, ignoring case.
+func emailInDomain(email, domain string) bool {
+	_, domainPart, ok := strings.Cut(email, "@")
+	return ok && strings.EqualFold(domainPart, domain)
+}
+
 // userInOrg queries the GitHub API for a users' org membership.
 //
 // The HTTP passed client is expected to be constructed by the golang.org/x/oauth2 package,
@@ -678,71 +2803,351 @@ func (c *githubConnector) userInOrg(ctx context.Context, client *http.Client, us
 	// https://developer.github.com/v3/orgs/members/#check-membership
 	apiURL := fmt.Sprintf("%s/orgs/%s/members/%s", c.apiURL, orgName, userName)
 
-	req, err := http.NewRequest("GET", apiURL, nil)
+	resp, err := c.do(ctx, client, apiURL, nil, http.StatusNoContent, http.StatusFound, http.StatusNotFound)
 	if err != nil {
-		return false, fmt.Errorf("github: new req: %v", err)
-	}
-	req = req.WithContext(ctx)
-	resp, err := client.Do(req)
-	if err != nil {
-		return false, fmt.Errorf("github: get teams: %v", err)
+		var apiErr *apiError
+		if errors.As(err, &apiErr) {
+			if ssoErr := ssoRequired(apiErr.header, orgName); ssoErr != nil {
+				return false, ssoErr
+			}
+		}
+		return false, fmt.Errorf("github: check org membership: %w", err)
 	}
-	defer resp.Body.Close()
 
-	switch resp.StatusCode {
-	case http.StatusNoContent:
-	case http.StatusFound, http.StatusNotFound:
+	// 204 if user is a member. Orgs enforcing SSO respond as if the user
+	// weren't a member if the token isn't authorized for it.
+	if ssoErr := ssoRequired(resp.Header, orgName); ssoErr != nil {
+		return false, ssoErr
+	}
+	if resp.StatusCode != http.StatusNoContent {
 		c.logger.Info("user not in org or application not authorized to read org data", "user", userName, "org", orgName)
-	default:
-		err = fmt.Errorf("github: unexpected return status: %q", resp.Status)
+		return false, nil
+	}
+	return true, nil
+}
+
+// membershipKey identifies a user's membership of an org.
+type membershipKey struct {
+	user, org string
+}
+
+// membershipCache remembers for a limited time which users aren't members of
+// which orgs. A nil cache remembers nothing.
+type membershipCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu     sync.Mutex                  // guards misses
+	misses map[membershipKey]time.Time // expiry of each entry
+}
+
+func newMembershipCache(ttl time.Duration, now func() time.Time) *membershipCache {
+	return &membershipCache{ttl: ttl, now: now, misses: make(map[membershipKey]time.Time)}
+}
+
+// isMiss returns whether user was recently found not to be a member of org.
+func (m *membershipCache) isMiss(user, org string) bool {
+	if m == nil {
+		return false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := membershipKey{user, org}
+	expiry, ok := m.misses[key]
+	if ok && !m.now().Before(expiry) {
+		delete(m.misses, key)
+		return false
+	}
+	return ok
+}
+
+// addMiss records that user isn't a member of org. Expired entries are
+// dropped at the same time, so the cache doesn't outgrow a burst of logins.
+func (m *membershipCache) addMiss(user, org string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	for key, expiry := range m.misses {
+		if !now.Before(expiry) {
+			delete(m.misses, key)
+		}
+	}
+	m.misses[membershipKey{user, org}] = now.Add(m.ttl)
+}
+
+// groupsKey identifies the groups resolved for a login. The email and the
+// groups scope are part of it as they change the groups getGroups returns.
+type groupsKey struct {
+	login      string
+	email      string
+	groupScope bool
+}
+
+type groupsEntry struct {
+	groups []string
+	orgs   []string
+	expiry time.Time
+}
+
+// groupsCache remembers the groups resolved for users for a limited time. A
+// nil cache remembers nothing.
+type groupsCache struct {
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex // guards entries
+	entries map[groupsKey]groupsEntry
+}
+
+func newGroupsCache(ttl time.Duration, maxEntries int, now func() time.Time) *groupsCache {
+	return &groupsCache{ttl: ttl, maxEntries: maxEntries, now: now, entries: make(map[groupsKey]groupsEntry)}
+}
+
+// get returns the groups and orgs cached for key, if they haven't expired.
+func (g *groupsCache) get(key groupsKey) (groups, orgs []string, ok bool) {
+	if g == nil {
+		return nil, nil, false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	entry, ok := g.entries[key]
+	if !ok {
+		return nil, nil, false
+	}
+	if !g.now().Before(entry.expiry) {
+		delete(g.entries, key)
+		return nil, nil, false
 	}
+	// Callers may modify the returned slices.
+	return slices.Clone(entry.groups), slices.Clone(entry.orgs), true
+}
+
+// add caches groups and orgs for key. Expired entries are dropped at the same
+// time, and if the cache is still full the entry closest to expiry is evicted.
+func (g *groupsCache) add(key groupsKey, groups, orgs []string) {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
 
-	// 204 if user is a member
-	return resp.StatusCode == http.StatusNoContent, err
+	now := g.now()
+	for k, entry := range g.entries {
+		if !now.Before(entry.expiry) {
+			delete(g.entries, k)
+		}
+	}
+	if _, ok := g.entries[key]; !ok && len(g.entries) >= g.maxEntries {
+		var (
+			oldest    groupsKey
+			oldestExp time.Time
+		)
+		for k, entry := range g.entries {
+			if oldestExp.IsZero() || entry.expiry.Before(oldestExp) {
+				oldest, oldestExp = k, entry.expiry
+			}
+		}
+		delete(g.entries, oldest)
+	}
+	g.entries[key] = groupsEntry{groups: slices.Clone(groups), orgs: slices.Clone(orgs), expiry: now.Add(g.ttl)}
 }
 
 // teams holds GitHub a users' team information as defined by
 // https://developer.github.com/v3/orgs/teams/#response-12
 type team struct {
-	Name string `json:"name"`
-	Org  org    `json:"organization"`
-	Slug string `json:"slug"`
+	Name   string `json:"name"`
+	Org    org    `json:"organization"`
+	Slug   string `json:"slug"`
+	Parent *team  `json:"parent"`
+	// nil if GitHub didn't report whether the team is archived
+	Archived *bool `json:"archived"`
+
+	// user's role in the team, if already known
+	role string
 }
 
 type org struct {
 	Login string `json:"login"`
+	// display name, only returned when getting a single org
+	Name string `json:"name"`
 }
 
 // teamsForOrg queries the GitHub API for team membership within a specific organization.
 //
 // The HTTP passed client is expected to be constructed by the golang.org/x/oauth2 package,
 // which inserts a bearer token as part of the request.
-func (c *githubConnector) teamsForOrg(ctx context.Context, client *http.Client, orgName string) ([]string, error) {
-	apiURL, groups := c.apiURL+"/user/teams", []string{}
-	for {
+func (c *githubConnector) teamsForOrg(ctx context.Context, client *http.Client, orgName, userName string) ([]string, error) {
+	apiURL, orgTeams := c.pagedURL("/user/teams"), []team{}
+	count := 0
+	for page := 1; ; page++ {
 		// https://developer.github.com/v3/orgs/teams/#list-user-teams
 		var (
 			teams []team
 			err   error
 		)
-		if apiURL, err = get(ctx, client, apiURL, &teams); err != nil {
-			return nil, fmt.Errorf("github: get teams: %v", err)
+		if apiURL, err = c.get(ctx, client, apiURL, &teams); err != nil {
+			return nil, fmt.Errorf("github: get teams: %w", err)
 		}
 
 		for _, t := range teams {
-			if t.Org.Login == orgName {
-				groups = append(groups, c.teamGroupClaims(t)...)
+			if t.Org.Login == orgName || (c.caseInsensitiveGroups && strings.EqualFold(t.Org.Login, orgName)) {
+				orgTeams = append(orgTeams, t)
 			}
 		}
+		count += len(teams)
+		c.logPageProgress(ctx, "teams", page, count)
 
 		if apiURL == "" {
 			break
 		}
 	}
+	if c.debugEnabled(ctx) {
+		names := make([]string, len(orgTeams))
+		for i, t := range orgTeams {
+			names[i] = t.Name
+		}
+		c.debug(ctx, "github: found user's teams in org", "user", userName, "org", orgName, "teams", names)
+	}
 
+	return c.teamsGroupClaims(ctx, client, orgName, userName, orgTeams)
+}
+
+// teamsGroupClaims returns the group claims of userName's teams of orgName,
+// including those of their ancestors if includeParentTeams is set, and
+// role-qualified ones if includeTeamRole is set. Archived teams are left out
+// if excludeArchivedTeams is set.
+func (c *githubConnector) teamsGroupClaims(ctx context.Context, client *http.Client, orgName, userName string, teams []team) ([]string, error) {
+	var err error
+	if c.includeParentTeams {
+		if teams, err = c.withParentTeams(ctx, client, orgName, teams); err != nil {
+			return nil, err
+		}
+	}
+	if c.excludeArchivedTeams {
+		if teams, err = c.withoutArchivedTeams(ctx, client, orgName, teams); err != nil {
+			return nil, err
+		}
+	}
+
+	groups := []string{}
+	for _, t := range teams {
+		claims := c.teamGroupClaims(t)
+		groups = append(groups, claims...)
+		if !c.includeTeamRole {
+			continue
+		}
+
+		role := t.role
+		if role == "" {
+			membership, err := c.teamMembership(ctx, client, orgName, t.Slug, userName)
+			if err != nil {
+				return nil, err
+			}
+			if membership == nil {
+				continue
+			}
+			role = membership.Role
+		}
+		for _, claim := range claims {
+			groups = append(groups, claim+":"+role)
+		}
+	}
 	return groups, nil
 }
 
+// teamMembership holds a user's membership of a team as defined by
+// https://docs.github.com/en/rest/teams/members#get-team-membership-for-a-user
+type teamMembership struct {
+	// "active", or "pending" if the user hasn't accepted the invitation yet
+	State string `json:"state"`
+	// "member" or "maintainer"
+	Role string `json:"role"`
+}
+
+// teamMembership returns userName's membership of the team, or nil if the user
+// isn't a member. Members of a child team count as members of its ancestors.
+func (c *githubConnector) teamMembership(ctx context.Context, client *http.Client, orgName, teamSlug, userName string) (*teamMembership, error) {
+	apiURL := fmt.Sprintf("%s/orgs/%s/teams/%s/memberships/%s", c.apiURL, orgName, teamSlug, userName)
+	resp, err := c.do(ctx, client, apiURL, nil, http.StatusOK, http.StatusNotFound)
+	if err != nil {
+		return nil, fmt.Errorf("github: check team membership: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	var membership teamMembership
+	if err := json.Unmarshal(resp.body, &membership); err != nil {
+		return nil, fmt.Errorf("github: unmarshal team membership: %v", err)
+	}
+	return &membership, nil
+}
+
+// withParentTeams returns teams followed by their ancestors, each team only
+// once. GitHub only includes a team's direct parent, so ancestors that aren't
+// in teams are fetched to find their own parents.
+func (c *githubConnector) withParentTeams(ctx context.Context, client *http.Client, orgName string, teams []team) ([]team, error) {
+	known := make(map[string]team, len(teams))
+	for _, t := range teams {
+		known[t.Slug] = t
+	}
+
+	seen := make(map[string]bool)
+	var result []team
+	for _, t := range teams {
+		// Ancestors of a team already seen have been added with it.
+		for !seen[t.Slug] {
+			seen[t.Slug] = true
+			result = append(result, t)
+			if t.Parent == nil {
+				break
+			}
+
+			parent, ok := known[t.Parent.Slug]
+			if !ok {
+				// https://docs.github.com/en/rest/teams/teams#get-a-team-by-name
+				apiURL := fmt.Sprintf("%s/orgs/%s/teams/%s", c.apiURL, orgName, t.Parent.Slug)
+				if _, err := c.get(ctx, client, apiURL, &parent); err != nil {
+					return nil, fmt.Errorf("github: get parent team: %w", err)
+				}
+				known[parent.Slug] = parent
+			}
+			t = parent
+		}
+	}
+	return result, nil
+}
+
+// withoutArchivedTeams returns the teams that aren't archived. Teams listed
+// without their archive status are fetched to find it.
+func (c *githubConnector) withoutArchivedTeams(ctx context.Context, client *http.Client, orgName string, teams []team) ([]team, error) {
+	result := make([]team, 0, len(teams))
+	for _, t := range teams {
+		archived := t.Archived
+		if archived == nil {
+			// https://docs.github.com/en/rest/teams/teams#get-a-team-by-name
+			var full team
+			apiURL := fmt.Sprintf("%s/orgs/%s/teams/%s", c.apiURL, orgName, t.Slug)
+			if _, err := c.get(ctx, client, apiURL, &full); err != nil {
+				return nil, fmt.Errorf("github: get team: %w", err)
+			}
+			archived = full.Archived
+		}
+		if archived != nil && *archived {
+			continue
+		}
+		result = append(result, t)
+	}
+	return result, nil
+}
+
 // teamGroupClaims returns team slug if 'teamNameField' option is set to
 // 'slug', returns the slug *and* name if set to 'both', otherwise returns team
 // name.
@@ -1,27 +1,42 @@
 package github
 
 import (
+	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
 	"crypto/tls"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"reflect"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/sync/singleflight"
 
 	"github.com/dexidp/dex/connector"
 )
 
 type testResponse struct {
-	data     interface{}
-	nextLink string
-	lastLink string
+	data       interface{}
+	nextLink   string
+	lastLink   string
+	statusCode int
+	header     http.Header
 }
 
 func TestUserGroups(t *testing.T) {
@@ -52,7 +67,7 @@ func TestUserGroups(t *testing.T) {
 	defer s.Close()
 
 	c := githubConnector{apiURL: s.URL}
-	groups, err := c.userGroups(context.Background(), newClient())
+	groups, _, err := c.userGroups(context.Background(), newClient(), "some-login")
 
 	expectNil(t, err)
 	expectEquals(t, groups, []string{
@@ -66,335 +81,3078 @@ func TestUserGroups(t *testing.T) {
 	})
 }
 
-func TestUserGroupsWithoutOrgs(t *testing.T) {
+func TestIncludeParentTeams(t *testing.T) {
 	s := newTestServer(map[string]testResponse{
-		"/user/orgs":  {data: []org{}},
-		"/user/teams": {data: []team{}},
+		"/user/orgs": {data: []org{{Login: "org-1"}, {Login: "org-2"}}},
+		"/user/teams": {data: []team{
+			{Name: "Child", Slug: "child", Org: org{Login: "org-1"}, Parent: &team{Name: "Middle", Slug: "middle"}},
+			{Name: "Top", Slug: "top", Org: org{Login: "org-1"}},
+			{Name: "Other", Slug: "other", Org: org{Login: "org-2"}, Parent: &team{Name: "Top", Slug: "top"}},
+		}},
+		"/orgs/org-1/teams/middle": {data: team{Name: "Middle", Slug: "middle", Parent: &team{Name: "Top", Slug: "top"}}},
+		"/orgs/org-2/teams/top":    {data: team{Name: "Top", Slug: "top"}},
 	})
 	defer s.Close()
 
-	c := githubConnector{apiURL: s.URL}
-	groups, err := c.userGroups(context.Background(), newClient())
+	c := githubConnector{apiURL: s.URL, includeParentTeams: true}
+	teams, err := c.teamsForOrg(context.Background(), newClient(), "org-1", "some-login")
 
+	// The user is directly in Top, so it's neither fetched nor duplicated.
 	expectNil(t, err)
-	expectEquals(t, len(groups), 0)
+	expectEquals(t, teams, []string{"Child", "Middle", "Top"})
+
+	groups, _, err := c.userGroups(context.Background(), newClient(), "some-login")
+
+	expectNil(t, err)
+	expectEquals(t, groups, []string{"org-1", "org-1:Child", "org-1:Middle", "org-1:Top", "org-2", "org-2:Other", "org-2:Top"})
 }
 
-func TestUserGroupsWithTeamNameFieldConfig(t *testing.T) {
+func TestExcludeArchivedTeams(t *testing.T) {
+	archived, active := true, false
 	s := newTestServer(map[string]testResponse{
-		"/user/orgs": {
-			data: []org{{Login: "org-1"}},
-		},
-		"/user/teams": {
-			data: []team{
-				{Name: "Team 1", Slug: "team-1", Org: org{Login: "org-1"}},
-			},
-		},
+		"/user/orgs": {data: []org{{Login: "org-1"}}},
+		"/user/teams": {data: []team{
+			{Name: "Active", Slug: "active", Org: org{Login: "org-1"}, Archived: &active},
+			{Name: "Archived", Slug: "archived", Org: org{Login: "org-1"}, Archived: &archived},
+			{Name: "Disbanded", Slug: "disbanded", Org: org{Login: "org-1"}},
+			{Name: "Unknown", Slug: "unknown", Org: org{Login: "org-1"}},
+		}},
+		"/orgs/org-1/teams/disbanded": {data: team{Name: "Disbanded", Slug: "disbanded", Archived: &archived}},
+		"/orgs/org-1/teams/unknown":   {data: team{Name: "Unknown", Slug: "unknown"}},
 	})
 	defer s.Close()
 
-	c := githubConnector{apiURL: s.URL, teamNameField: "slug"}
-	groups, err := c.userGroups(context.Background(), newClient())
+	c := githubConnector{apiURL: s.URL}
+	groups, _, err := c.userGroups(context.Background(), newClient(), "some-login")
 
 	expectNil(t, err)
-	expectEquals(t, groups, []string{
-		"org-1",
-		"org-1:team-1",
-	})
+	expectEquals(t, groups, []string{"org-1", "org-1:Active", "org-1:Archived", "org-1:Disbanded", "org-1:Unknown"})
+
+	// Teams listed without their status are looked up, and teams GitHub
+	// doesn't report as archived are kept.
+	c.excludeArchivedTeams = true
+	groups, _, err = c.userGroups(context.Background(), newClient(), "some-login")
+
+	expectNil(t, err)
+	expectEquals(t, groups, []string{"org-1", "org-1:Active", "org-1:Unknown"})
+
+	teams, err := c.teamsForOrg(context.Background(), newClient(), "org-1", "some-login")
+
+	expectNil(t, err)
+	expectEquals(t, teams, []string{"Active", "Unknown"})
 }
 
-func TestUserGroupsWithTeamNameAndSlugFieldConfig(t *testing.T) {
+func TestIncludeTeamRole(t *testing.T) {
 	s := newTestServer(map[string]testResponse{
-		"/user/orgs": {
-			data: []org{{Login: "org-1"}},
-		},
-		"/user/teams": {
-			data: []team{
-				{Name: "Team 1", Slug: "team-1", Org: org{Login: "org-1"}},
-			},
-		},
+		"/user/orgs": {data: []org{{Login: "org-1"}}},
+		"/user/teams": {data: []team{
+			{Name: "Team 1", Slug: "team-1", Org: org{Login: "org-1"}},
+			{Name: "Team 2", Slug: "team-2", Org: org{Login: "org-1"}},
+		}},
+		"/orgs/org-1/teams/team-1/memberships/some-login": {data: teamMembership{State: "active", Role: "maintainer"}},
+		"/orgs/org-1/teams/team-2/memberships/some-login": {data: teamMembership{State: "active", Role: "member"}},
 	})
 	defer s.Close()
 
-	c := githubConnector{apiURL: s.URL, teamNameField: "both"}
-	groups, err := c.userGroups(context.Background(), newClient())
+	c := githubConnector{apiURL: s.URL, includeTeamRole: true, teamNameField: "slug"}
+	groups, _, err := c.userGroups(context.Background(), newClient(), "some-login")
 
 	expectNil(t, err)
-	expectEquals(t, groups, []string{
-		"org-1",
-		"org-1:Team 1",
-		"org-1:team-1",
-	})
+	expectEquals(t, groups, []string{"org-1", "org-1:team-1", "org-1:team-1:maintainer", "org-1:team-2", "org-1:team-2:member"})
 }
 
-// tests that the users login is used as their username when they have no username set
-func TestUsernameIncludedInFederatedIdentity(t *testing.T) {
+func TestLimitGroups(t *testing.T) {
+	groups := []string{"org-1", "org-1:team-1", "org-2", "org-2:team-1", "org-2:team-2", "org-3:team-1"}
+
+	for _, tc := range []struct {
+		name           string
+		maxGroups      int
+		priorityGroups []string
+		want           []string
+	}{
+		{name: "unlimited", want: groups},
+		{name: "under limit", maxGroups: 10, want: groups},
+		{name: "at limit", maxGroups: 6, want: groups},
+		{name: "truncated", maxGroups: 3, want: []string{"org-1", "org-1:team-1", "org-2"}},
+		{
+			name:           "priority org",
+			maxGroups:      3,
+			priorityGroups: []string{"org-2"},
+			want:           []string{"org-2", "org-2:team-1", "org-2:team-2"},
+		},
+		{
+			name:           "priority team",
+			maxGroups:      3,
+			priorityGroups: []string{"org-3:team-1"},
+			want:           []string{"org-1", "org-1:team-1", "org-3:team-1"},
+		},
+		{
+			name:           "more priority groups than limit",
+			maxGroups:      2,
+			priorityGroups: []string{"org-3:team-1", "org-2"},
+			want:           []string{"org-2", "org-2:team-1"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := githubConnector{maxGroups: tc.maxGroups, priorityGroups: tc.priorityGroups}
+			got := c.limitGroups(context.Background(), "some-login", slices.Clone(groups))
+			expectEquals(t, got, tc.want)
+		})
+	}
+}
+
+func TestLimitGroupsCaseInsensitive(t *testing.T) {
+	c := githubConnector{maxGroups: 1, priorityGroups: []string{"ORG-2"}, caseInsensitiveGroups: true}
+	got := c.limitGroups(context.Background(), "some-login", []string{"org-1", "Org-2:Team-1"})
+	expectEquals(t, got, []string{"Org-2:Team-1"})
+}
+
+func Test_Open_MaxGroupsConfig(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	c := Config{MaxGroups: 50, PriorityGroups: []string{"org-1"}}
+	conn, err := c.Open("id", log)
+	expectNil(t, err)
+	expectEquals(t, conn.(*githubConnector).maxGroups, 50)
+	expectEquals(t, conn.(*githubConnector).priorityGroups, []string{"org-1"})
+
+	c = Config{MaxGroups: -1}
+	_, err = c.Open("id", log)
+	expectNotNil(t, err, "negative maxGroups error")
+}
+
+func TestTeamNameMapping(t *testing.T) {
 	s := newTestServer(map[string]testResponse{
-		"/user": {data: user{Login: "some-login", ID: 12345678}},
-		"/user/emails": {data: []userEmail{{
-			Email:    "some@email.com",
-			Verified: true,
-			Primary:  true,
-		}}},
-		"/login/oauth/access_token": {data: map[string]interface{}{
-			"access_token": "eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9",
-			"expires_in":   "30",
+		"/user/orgs": {data: []org{{Login: "org-1"}}},
+		"/user/teams": {data: []team{
+			{Name: "team-1", Slug: "team-1", Org: org{Login: "org-1"}},
+			{Name: "team-2", Slug: "team-2", Org: org{Login: "org-1"}},
 		}},
-		"/user/orgs": {
-			data: []org{{Login: "org-1"}},
-		},
+		"/orgs/org-1/members/some-login":                  {statusCode: http.StatusNoContent},
+		"/orgs/org-1/teams/team-1/memberships/some-login": {data: teamMembership{State: "active", Role: "maintainer"}},
+		"/orgs/org-1/teams/team-2/memberships/some-login": {data: teamMembership{State: "active", Role: "member"}},
 	})
 	defer s.Close()
 
-	hostURL, err := url.Parse(s.URL)
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	mapping := map[string]string{"org-1:team-1": "admins"}
+	want := []string{"admins", "admins:maintainer", "org-1:team-2", "org-1:team-2:member"}
+
+	c := githubConnector{apiURL: s.URL, logger: logger, teamNameMapping: mapping, includeTeamRole: true, loadAllGroups: true}
+	groups, _, err := c.getGroups(context.Background(), newClient(), true, "some-login", "")
 	expectNil(t, err)
+	expectEquals(t, groups, append([]string{"org-1"}, want...))
 
-	req, err := http.NewRequest("GET", hostURL.String(), nil)
+	c = githubConnector{apiURL: s.URL, logger: logger, teamNameMapping: mapping, includeTeamRole: true, orgs: []Org{{Name: "org-1"}}}
+	groups, _, err = c.getGroups(context.Background(), newClient(), true, "some-login", "")
 	expectNil(t, err)
+	expectEquals(t, groups, want)
 
-	c := githubConnector{apiURL: s.URL, hostName: hostURL.Host, httpClient: newClient()}
-	identity, err := c.HandleCallback(connector.Scopes{Groups: true}, req)
+	// The legacy org field yields team names without the org prefix, unless mapped.
+	c = githubConnector{apiURL: s.URL, logger: logger, teamNameMapping: mapping, includeTeamRole: true, org: "org-1"}
+	groups, _, err = c.getGroups(context.Background(), newClient(), true, "some-login", "")
+	expectNil(t, err)
+	expectEquals(t, groups, []string{"admins", "admins:maintainer", "team-2", "team-2:member"})
+}
+
+func TestGroupsDeduplicated(t *testing.T) {
+	s := newTestServer(map[string]testResponse{
+		"/user/orgs": {data: []org{{Login: "org-1"}, {Login: "org-2"}}},
+		"/user/teams": {data: []team{
+			{Name: "admins", Slug: "admins", Org: org{Login: "org-1"}},
+			{Name: "devs", Slug: "devs", Org: org{Login: "org-1"}},
+			{Name: "admins", Slug: "admins", Org: org{Login: "org-2"}},
+		}},
+		"/orgs/org-1/members/some-login": {statusCode: http.StatusNoContent},
+		"/orgs/org-2/members/some-login": {statusCode: http.StatusNoContent},
+	})
+	defer s.Close()
 
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	mapping := map[string]string{"org-1:admins": "admins", "org-2:admins": "admins"}
+
+	// The same-named teams keep their org prefix unless mapped to one group.
+	c := githubConnector{apiURL: s.URL, logger: logger, loadAllGroups: true}
+	groups, _, err := c.getGroups(context.Background(), newClient(), true, "some-login", "")
 	expectNil(t, err)
-	expectEquals(t, identity.Username, "some-login")
-	expectEquals(t, identity.UserID, "12345678")
-	expectEquals(t, 0, len(identity.Groups))
+	expectEquals(t, groups, []string{"org-1", "org-1:admins", "org-1:devs", "org-2", "org-2:admins"})
 
-	c = githubConnector{apiURL: s.URL, hostName: hostURL.Host, httpClient: newClient(), loadAllGroups: true}
-	identity, err = c.HandleCallback(connector.Scopes{Groups: true}, req)
+	c = githubConnector{apiURL: s.URL, logger: logger, teamNameMapping: mapping, loadAllGroups: true}
+	groups, _, err = c.getGroups(context.Background(), newClient(), true, "some-login", "")
+	expectNil(t, err)
+	expectEquals(t, groups, []string{"org-1", "admins", "org-1:devs", "org-2"})
 
+	c = githubConnector{apiURL: s.URL, logger: logger, teamNameMapping: mapping, orgs: []Org{{Name: "org-1"}, {Name: "org-2"}}}
+	groups, _, err = c.getGroups(context.Background(), newClient(), true, "some-login", "")
 	expectNil(t, err)
-	expectEquals(t, identity.Username, "some-login")
-	expectEquals(t, identity.UserID, "12345678")
-	expectEquals(t, identity.Groups, []string{"org-1"})
+	expectEquals(t, groups, []string{"admins", "org-1:devs"})
 }
 
-func TestLoginUsedAsIDWhenConfigured(t *testing.T) {
+func Test_Open_TeamNameMappingConfig(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	c := Config{TeamNameMapping: map[string]string{"team-1": "admins"}}
+	_, err := c.Open("id", log)
+	expectEquals(t, err, errors.New(`invalid connector config: teamNameMapping key "team-1" must be of the form org:team`))
+}
+
+func TestUserGroupsExcludeOrgs(t *testing.T) {
 	s := newTestServer(map[string]testResponse{
-		"/user": {data: user{Login: "some-login", ID: 12345678, Name: "Joe Bloggs"}},
-		"/user/emails": {data: []userEmail{{
-			Email:    "some@email.com",
-			Verified: true,
-			Primary:  true,
-		}}},
-		"/login/oauth/access_token": {data: map[string]interface{}{
-			"access_token": "eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9",
-			"expires_in":   "30",
+		"/user/orgs": {data: []org{{Login: "org-1"}, {Login: "Vendor-Org"}, {Login: "org-2"}}},
+		"/user/teams": {data: []team{
+			{Name: "team-1", Org: org{Login: "org-1"}},
+			{Name: "team-2", Org: org{Login: "Vendor-Org"}},
+			{Name: "team-3", Org: org{Login: "org-2"}},
 		}},
-		"/user/orgs": {
-			data: []org{{Login: "org-1"}},
-		},
 	})
 	defer s.Close()
 
-	hostURL, err := url.Parse(s.URL)
-	expectNil(t, err)
+	c := githubConnector{apiURL: s.URL, excludeOrgs: []string{"vendor-org"}}
+	groups, orgs, err := c.userGroups(context.Background(), newClient(), "some-login")
 
-	req, err := http.NewRequest("GET", hostURL.String(), nil)
 	expectNil(t, err)
+	expectEquals(t, groups, []string{"org-1", "org-1:team-1", "org-2", "org-2:team-3"})
+	expectEquals(t, orgs, []string{"org-1", "org-2"})
+}
 
-	c := githubConnector{apiURL: s.URL, hostName: hostURL.Host, httpClient: newClient(), useLoginAsID: true}
-	identity, err := c.HandleCallback(connector.Scopes{Groups: true}, req)
+func TestCaseInsensitiveGroups(t *testing.T) {
+	s := newTestServer(map[string]testResponse{
+		"/orgs/ORG-1":                    {data: org{Login: "Org-1"}},
+		"/orgs/ORG-1/members/some-login": {statusCode: http.StatusNoContent},
+		"/user/teams": {data: []team{
+			{Name: "Team-1", Org: org{Login: "Org-1"}},
+			{Name: "Team-2", Org: org{Login: "Org-1"}},
+		}},
+	})
+	defer s.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	orgs := []Org{{Name: "ORG-1", Teams: []string{"team-1"}}}
 
+	// Exact matching is the default.
+	c := githubConnector{apiURL: s.URL, logger: logger, orgs: orgs}
+	_, err := c.groupsForOrgs(context.Background(), newClient(), "some-login", "")
+	var orgErr *OrgAuthorizationError
+	expectEquals(t, errors.As(err, &orgErr), true)
+
+	c.caseInsensitiveGroups = true
+	groups, err := c.groupsForOrgs(context.Background(), newClient(), "some-login", "")
 	expectNil(t, err)
-	expectEquals(t, identity.UserID, "some-login")
-	expectEquals(t, identity.Username, "Joe Bloggs")
+	expectEquals(t, groups, []string{"Org-1:Team-1"})
+
+	teams, err := c.teamsForOrg(context.Background(), newClient(), "org-1", "some-login")
+	expectNil(t, err)
+	expectEquals(t, teams, []string{"Team-1", "Team-2"})
 }
 
-func TestPreferredEmailDomainConfigured(t *testing.T) {
-	ctx := context.Background()
+func TestGroupsForOrgsTeamPatterns(t *testing.T) {
 	s := newTestServer(map[string]testResponse{
-		"/user": {data: user{Login: "some-login", ID: 12345678, Name: "Joe Bloggs"}},
-		"/user/emails": {
-			data: []userEmail{
-				{
-					Email:    "some@email.com",
-					Verified: true,
-					Primary:  true,
-				},
-				{
-					Email:    "another@email.com",
-					Verified: true,
-					Primary:  false,
-				},
-				{
-					Email:    "some@preferred-domain.com",
-					Verified: true,
-					Primary:  false,
-				},
-				{
-					Email:    "another@preferred-domain.com",
-					Verified: true,
-					Primary:  false,
-				},
-			},
-		},
+		"/orgs/org-1":                    {data: org{Login: "org-1"}},
+		"/orgs/org-1/members/some-login": {statusCode: http.StatusNoContent},
+		"/user/teams": {data: []team{
+			{Name: "Ops", Org: org{Login: "org-1"}},
+			{Name: "Platform-API", Org: org{Login: "org-1"}},
+			{Name: "web", Org: org{Login: "org-1"}},
+		}},
 	})
 	defer s.Close()
 
-	hostURL, err := url.Parse(s.URL)
-	expectNil(t, err)
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	orgs := []Org{{Name: "org-1", Teams: []string{"Ops", "platform-*"}}}
 
-	client := newClient()
-	c := githubConnector{apiURL: s.URL, hostName: hostURL.Host, httpClient: client, preferredEmailDomain: "preferred-domain.com"}
+	c := githubConnector{apiURL: s.URL, logger: logger, orgs: orgs}
+	groups, err := c.groupsForOrgs(context.Background(), newClient(), "some-login", "")
+	expectNil(t, err)
+	expectEquals(t, groups, []string{"org-1:Ops"})
 
-	u, err := c.user(ctx, client)
+	c.caseInsensitiveGroups = true
+	groups, err = c.groupsForOrgs(context.Background(), newClient(), "some-login", "")
 	expectNil(t, err)
-	expectEquals(t, u.Email, "some@preferred-domain.com")
+	expectEquals(t, groups, []string{"org-1:Ops", "org-1:Platform-API"})
+
+	c = githubConnector{apiURL: s.URL, logger: logger, orgs: []Org{{Name: "org-1", Teams: []string{"regex:team-.*"}}}}
+	_, err = c.groupsForOrgs(context.Background(), newClient(), "some-login", "")
+	var orgErr *OrgAuthorizationError
+	expectEquals(t, errors.As(err, &orgErr), true)
 }
 
-func TestPreferredEmailDomainConfiguredWithGlob(t *testing.T) {
-	ctx := context.Background()
-	s := newTestServer(map[string]testResponse{
-		"/user": {data: user{Login: "some-login", ID: 12345678, Name: "Joe Bloggs"}},
-		"/user/emails": {
-			data: []userEmail{
-				{
-					Email:    "some@email.com",
-					Verified: true,
-					Primary:  true,
-				},
-				{
-					Email:    "another@email.com",
-					Verified: true,
-					Primary:  false,
-				},
-				{
-					Email:    "some@another.preferred-domain.com",
-					Verified: true,
-					Primary:  false,
-				},
-				{
-					Email:    "some@sub-domain.preferred-domain.co",
-					Verified: true,
-					Primary:  false,
-				},
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		errs   []string
+	}{
+		{
+			name:   "valid",
+			config: Config{HostName: "github.example.com", APIPath: "/api/v3", RootCA: "ca.pem", Orgs: []Org{{Name: "org-1"}}},
+		},
+		{
+			name:   "org and orgs",
+			config: Config{Org: "org-1", Orgs: []Org{{Name: "org-2"}}},
+			errs:   []string{"github: cannot use both 'org' and 'orgs' fields simultaneously"},
+		},
+		{
+			name:   "hostname with path",
+			config: Config{HostName: "github.example.com/api"},
+			errs:   []string{"invalid hostname: hostname cannot contain `/`"},
+		},
+		{
+			name:   "hostname with bad port",
+			config: Config{HostName: "github.example.com:0"},
+			errs:   []string{`invalid hostname: port "0" must be a number between 1 and 65535`},
+		},
+		{
+			name:   "relative apiPath",
+			config: Config{HostName: "github.example.com", APIPath: "api/v3"},
+			errs:   []string{"invalid connector config: apiPath must be a path starting with `/`"},
+		},
+		{
+			name:   "apiPath without hostname",
+			config: Config{APIPath: "/api/v3"},
+			errs:   []string{"invalid connector config: hostName is required with apiPath"},
+		},
+		{
+			name:   "rootCA without hostname",
+			config: Config{RootCA: "ca.pem"},
+			errs:   []string{"invalid connector config: Host name field required for a root certificate file"},
+		},
+		{
+			name:   "invalid team pattern",
+			config: Config{Orgs: []Org{{Name: "org-1", Teams: []string{"regex:team-("}}}},
+			errs:   []string{`invalid connector config: teams of org "org-1": invalid pattern "regex:team-("`},
+		},
+		{
+			name:   "unsupported team name field",
+			config: Config{TeamNameField: "id"},
+			errs:   []string{"invalid connector config: unsupported team name field value `id`"},
+		},
+		{
+			name:   "invalid preferred domain",
+			config: Config{PreferredEmailDomain: "example.*"},
+			errs:   []string{`invalid PreferredEmailDomain: glob pattern cannot end with "*"`},
+		},
+		{
+			name:   "suffix match without preferred domain",
+			config: Config{PreferredEmailDomainSuffixMatch: true},
+			errs:   []string{"invalid connector config: preferredEmailDomainSuffixMatch requires preferredEmailDomain"},
+		},
+		{
+			name:   "required and allowed email domains",
+			config: Config{RequiredEmailDomains: []string{"corp.com"}, AllowedEmailDomains: []string{"*.corp.com"}},
+			errs:   []string{"invalid connector config: requiredEmailDomains cannot be combined with allowedEmailDomains"},
+		},
+		{
+			name: "all problems",
+			config: Config{
+				Org:                   "org-1",
+				Orgs:                  []Org{{Name: "org-2", Teams: []string{"regex:("}}},
+				RootCA:                "ca.pem",
+				TeamNameField:         "id",
+				PreferredEmailDomains: []string{"", "*..example.com"},
+			},
+			errs: []string{
+				"github: cannot use both 'org' and 'orgs' fields simultaneously",
+				"invalid connector config: Host name field required for a root certificate file",
+				`invalid connector config: teams of org "org-2"`,
+				"invalid connector config: unsupported team name field value `id`",
+				"invalid PreferredEmailDomains: domain cannot be empty",
+				`invalid PreferredEmailDomain: "*..example.com" has an empty label`,
 			},
 		},
-	})
-	defer s.Close()
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.config.Validate()
+			if len(test.errs) == 0 {
+				expectNil(t, err)
+				return
+			}
+			expectNotNil(t, err, "validation error")
+			lines := strings.Split(err.Error(), "\n")
+			expectEquals(t, len(lines), len(test.errs))
+			for i, want := range test.errs {
+				if !strings.HasPrefix(lines[i], want) {
+					t.Errorf("expected error %d to start with %q, got %q", i, want, lines[i])
+				}
+			}
+		})
+	}
+}
 
-	hostURL, err := url.Parse(s.URL)
+func Test_Open_TeamPatternsConfig(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	c := Config{Orgs: []Org{{Name: "org-1", Teams: []string{"ops", "platform-*", "regex:team-[0-9]+"}}}}
+	_, err := c.Open("id", log)
 	expectNil(t, err)
 
-	client := newClient()
-	c := githubConnector{apiURL: s.URL, hostName: hostURL.Host, httpClient: client, preferredEmailDomain: "*.preferred-domain.co"}
+	c = Config{Orgs: []Org{{Name: "org-1", Teams: []string{"regex:team-("}}}}
+	_, err = c.Open("id", log)
+	expectNotNil(t, err, "invalid team pattern error")
+}
+
+func TestTeamsForOrgLogsPaginationProgress(t *testing.T) {
+	responses := map[string]testResponse{}
+	last := fmt.Sprintf("/user/teams?page=%d", pageLogInterval)
+	for i := 1; i <= pageLogInterval; i++ {
+		path := fmt.Sprintf("/user/teams?page=%d", i)
+		if i == 1 {
+			path = "/user/teams"
+		}
+		resp := testResponse{
+			data:     []team{{Name: fmt.Sprintf("team-%d", i), Org: org{Login: "org-1"}}},
+			lastLink: last,
+		}
+		if i < pageLogInterval {
+			resp.nextLink = fmt.Sprintf("/user/teams?page=%d", i+1)
+		}
+		responses[path] = resp
+	}
+	s := newTestServer(responses)
+	defer s.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	c := githubConnector{apiURL: s.URL, logger: logger}
+	teams, err := c.teamsForOrg(context.Background(), newClient(), "org-1", "some-login")
 
-	u, err := c.user(ctx, client)
 	expectNil(t, err)
-	expectEquals(t, u.Email, "some@sub-domain.preferred-domain.co")
+	expectEquals(t, len(teams), pageLogInterval)
+	if !strings.Contains(buf.String(), fmt.Sprintf("pages=%d count=%d", pageLogInterval, pageLogInterval)) {
+		t.Errorf("expected pagination progress log, got %q", buf.String())
+	}
 }
 
-func TestPreferredEmailDomainConfigured_UserHasNoPreferredDomainEmail(t *testing.T) {
-	ctx := context.Background()
+// recordHandler captures log records for tests.
+type recordHandler struct {
+	level   slog.Level
+	records []slog.Record
+	mu      sync.Mutex
+}
+
+func (h *recordHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *recordHandler) Handle(_ context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, r)
+	return nil
+}
+
+func (h *recordHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h *recordHandler) WithGroup(string) slog.Handler      { return h }
+
+// attrs returns the attributes of the records with message msg, formatted as
+// "key=value".
+func (h *recordHandler) attrs(msg string) [][]string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var result [][]string
+	for _, r := range h.records {
+		if r.Message != msg {
+			continue
+		}
+		var attrs []string
+		r.Attrs(func(a slog.Attr) bool {
+			attrs = append(attrs, a.String())
+			return true
+		})
+		result = append(result, attrs)
+	}
+	return result
+}
+
+func TestGroupsDebugLogging(t *testing.T) {
 	s := newTestServer(map[string]testResponse{
-		"/user": {data: user{Login: "some-login", ID: 12345678, Name: "Joe Bloggs"}},
-		"/user/emails": {
-			data: []userEmail{
-				{
-					Email:    "some@email.com",
-					Verified: true,
-					Primary:  true,
-				},
-				{
-					Email:    "another@email.com",
-					Verified: true,
-					Primary:  false,
-				},
+		"/orgs/org-1/members/some-login": {statusCode: http.StatusNoContent},
+		"/orgs/org-2/members/some-login": {statusCode: http.StatusNotFound},
+		"/user/teams": {data: []team{
+			{Name: "team-1", Org: org{Login: "org-1"}},
+			{Name: "team-2", Org: org{Login: "org-3"}},
+		}},
+	})
+	defer s.Close()
+
+	h := &recordHandler{level: slog.LevelDebug}
+	c := githubConnector{apiURL: s.URL, logger: slog.New(h), orgs: []Org{{Name: "org-1"}, {Name: "org-2"}}}
+	groups, _, err := c.getGroups(context.Background(), newClient(), true, "some-login", "")
+
+	expectNil(t, err)
+	expectEquals(t, groups, []string{"org-1:team-1"})
+	expectEquals(t, h.attrs("github: found user's teams in org"), [][]string{
+		{"user=some-login", "org=org-1", "teams=[team-1]"},
+	})
+	expectEquals(t, h.attrs("github: checked org membership"), [][]string{
+		{"user=some-login", "org=org-1", "member=true", "teams=[team-1]"},
+		{"user=some-login", "org=org-2", "member=false", "teams=[]"},
+	})
+	expectEquals(t, h.attrs("github: evaluated org authorization"), [][]string{
+		{"user=some-login", "authorized=true", "groups=[org-1:team-1]"},
+	})
+	expectEquals(t, h.attrs("github: resolved groups"), [][]string{
+		{"user=some-login", "groups=[org-1:team-1]", "cached=false"},
+	})
+
+	// None of it is logged at the info level.
+	h = &recordHandler{level: slog.LevelInfo}
+	c.logger = slog.New(h)
+	_, _, err = c.getGroups(context.Background(), newClient(), true, "some-login", "")
+
+	expectNil(t, err)
+	expectEquals(t, len(h.attrs("github: checked org membership")), 0)
+	expectEquals(t, len(h.attrs("github: resolved groups")), 0)
+}
+
+func TestPageSize(t *testing.T) {
+	s := newTestServer(map[string]testResponse{
+		"/user/orgs?per_page=50": {
+			data:     []org{{Login: "org-1"}},
+			nextLink: "/user/orgs?per_page=50&page=2",
+			lastLink: "/user/orgs?per_page=50&page=2",
+		},
+		"/user/orgs?per_page=50&page=2": {data: []org{{Login: "org-2"}}},
+		"/user/teams?per_page=50":       {data: []team{}},
+	})
+	defer s.Close()
+
+	c := githubConnector{apiURL: s.URL, pageSize: 50}
+	groups, _, err := c.userGroups(context.Background(), newClient(), "some-login")
+
+	expectNil(t, err)
+	expectEquals(t, groups, []string{"org-1", "org-2"})
+}
+
+func Test_Open_PageSizeConfig(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	c := Config{}
+	conn, err := c.Open("id", log)
+	expectNil(t, err)
+	expectEquals(t, conn.(*githubConnector).pageSize, 100)
+
+	c.PageSize = 101
+	_, err = c.Open("id", log)
+	expectEquals(t, err, errors.New("invalid connector config: pageSize must be between 1 and 100"))
+}
+
+func TestHTTPTimeout(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		json.NewEncoder(w).Encode(user{Login: "some-login"})
+	}))
+	defer s.Close()
+
+	c := githubConnector{apiURL: s.URL, httpTimeout: 10 * time.Millisecond}
+	_, err := c.user(context.Background(), newClient())
+	expectNotNil(t, err, "Expected an error")
+	expectEquals(t, errors.Is(err, ErrRequestTimeout), true)
+}
+
+func Test_Open_HTTPTimeoutConfig(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	c := Config{HTTPTimeout: "5s"}
+	conn, err := c.Open("id", log)
+	expectNil(t, err)
+	expectEquals(t, conn.(*githubConnector).httpTimeout, 5*time.Second)
+	expectEquals(t, conn.(*githubConnector).httpClient.Timeout, 5*time.Second)
+
+	c.HTTPTimeout = "0s"
+	_, err = c.Open("id", log)
+	expectEquals(t, err, errors.New("invalid connector config: httpTimeout must be positive"))
+}
+
+func Test_Open_APIPathConfig(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	c := Config{HostName: "github.example.com"}
+	conn, err := c.Open("id", log)
+	expectNil(t, err)
+	expectEquals(t, conn.(*githubConnector).apiURL, "https://github.example.com/api/v3")
+
+	c.APIPath = "/github/api/"
+	conn, err = c.Open("id", log)
+	expectNil(t, err)
+	expectEquals(t, conn.(*githubConnector).apiURL, "https://github.example.com/github/api")
+
+	c.APIPath = "https://github.example.com/api"
+	_, err = c.Open("id", log)
+	expectEquals(t, err, errors.New("invalid connector config: apiPath must be a path starting with `/`"))
+
+	c = Config{APIPath: "/github/api"}
+	_, err = c.Open("id", log)
+	expectEquals(t, err, errors.New("invalid connector config: hostName is required with apiPath"))
+}
+
+func Test_Open_HostNamePortConfig(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	for _, tc := range []struct {
+		hostName string
+		base     string
+	}{
+		{hostName: "ghe.internal", base: "https://ghe.internal"},
+		{hostName: "ghe.internal:8443", base: "https://ghe.internal:8443"},
+		{hostName: "[2001:db8::1]:8443", base: "https://[2001:db8::1]:8443"},
+	} {
+		c := Config{HostName: tc.hostName, UseGraphQL: true}
+		conn, err := c.Open("id", log)
+		expectNil(t, err)
+
+		g := conn.(*githubConnector)
+		endpoint := g.oauth2Config(connector.Scopes{}).Endpoint
+		expectEquals(t, g.apiURL, tc.base+"/api/v3")
+		expectEquals(t, g.graphQLURL, tc.base+"/api/graphql")
+		expectEquals(t, endpoint.AuthURL, tc.base+"/login/oauth/authorize")
+		expectEquals(t, endpoint.TokenURL, tc.base+"/login/oauth/access_token")
+	}
+
+	for _, hostName := range []string{"ghe.internal:https", "ghe.internal:0", "ghe.internal:65536", "ghe.internal:", ":8443"} {
+		c := Config{HostName: hostName}
+		_, err := c.Open("id", log)
+		expectNotNil(t, err, hostName)
+	}
+}
+
+func Test_Open_ProxyURLConfig(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	t.Setenv("NO_PROXY", "github.example.com")
+
+	c := Config{ProxyURL: "http://proxy.example.com:3128", HTTPTimeout: "5s"}
+	conn, err := c.Open("id", log)
+	expectNil(t, err)
+	httpClient := conn.(*githubConnector).httpClient
+	expectEquals(t, httpClient.Timeout, 5*time.Second)
+
+	proxy := httpClient.Transport.(*http.Transport).Proxy
+	req, _ := http.NewRequest(http.MethodGet, "https://api.github.com/user", nil)
+	proxyURL, err := proxy(req)
+	expectNil(t, err)
+	expectEquals(t, proxyURL.String(), "http://proxy.example.com:3128")
+
+	req, _ = http.NewRequest(http.MethodGet, "https://github.example.com/api/v3/user", nil)
+	proxyURL, err = proxy(req)
+	expectNil(t, err)
+	expectEquals(t, proxyURL == nil, true)
+
+	c = Config{ProxyURL: "proxy.example.com:3128"}
+	_, err = c.Open("id", log)
+	expectEquals(t, err, errors.New(`invalid connector config: proxyURL: unsupported scheme "proxy.example.com", must be http or https`))
+
+	c = Config{ProxyURL: "http://"}
+	_, err = c.Open("id", log)
+	expectEquals(t, err, errors.New("invalid connector config: proxyURL: missing host"))
+}
+
+func TestProxyURL(t *testing.T) {
+	proxied := 0
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied++
+		expectEquals(t, r.URL.String(), "http://github.invalid/user")
+		w.Header().Add("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(user{Login: "some-login", Email: "some@email.com"})
+	}))
+	defer proxy.Close()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	c := Config{ProxyURL: proxy.URL}
+	conn, err := c.Open("id", log)
+	expectNil(t, err)
+
+	g := conn.(*githubConnector)
+	g.apiURL = "http://github.invalid"
+	u, err := g.user(context.Background(), g.httpClient)
+	expectNil(t, err)
+	expectEquals(t, u.Login, "some-login")
+	expectEquals(t, proxied, 1)
+}
+
+func TestUserGroupsWithoutOrgs(t *testing.T) {
+	s := newTestServer(map[string]testResponse{
+		"/user/orgs":  {data: []org{}},
+		"/user/teams": {data: []team{}},
+	})
+	defer s.Close()
+
+	c := githubConnector{apiURL: s.URL}
+	groups, _, err := c.userGroups(context.Background(), newClient(), "some-login")
+
+	expectNil(t, err)
+	expectEquals(t, len(groups), 0)
+}
+
+func TestGroupsForOrgsRequiredEmailDomain(t *testing.T) {
+	s := newTestServer(map[string]testResponse{
+		"/orgs/org-1/members/some-login": {statusCode: http.StatusNoContent},
+		"/orgs/org-2/members/some-login": {statusCode: http.StatusNoContent},
+		"/user/teams": {data: []team{
+			{Name: "team-1", Org: org{Login: "org-1"}},
+			{Name: "team-2", Org: org{Login: "org-2"}},
+		}},
+	})
+	defer s.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	c := githubConnector{apiURL: s.URL, logger: logger, orgs: []Org{
+		{Name: "org-1", RequiredEmailDomain: "example.com"},
+		{Name: "org-2", RequiredEmailDomain: "other.com"},
+	}}
+
+	// Only the org whose domain requirement the email satisfies contributes groups.
+	groups, err := c.groupsForOrgs(context.Background(), newClient(), "some-login", "some@Example.com")
+
+	expectNil(t, err)
+	expectEquals(t, groups, []string{"org-1:team-1"})
+
+	// No org accepts the email, so the user isn't authorized.
+	_, err = c.groupsForOrgs(context.Background(), newClient(), "some-login", "some@unknown.com")
+
+	var orgErr *OrgAuthorizationError
+	expectEquals(t, errors.As(err, &orgErr), true)
+	expectEquals(t, orgErr.DeniedOrgs, []string{"org-1", "org-2"})
+	expectEquals(t, err.Error(), `github: user "some-login" not in required orgs or teams`)
+
+	var authzErr *connector.AuthorizationError
+	expectEquals(t, errors.As(err, &authzErr), true)
+	expectEquals(t, authzErr.User, "some-login")
+	expectEquals(t, authzErr.Denied, []string{"org-1", "org-2"})
+
+	// Orgs without a requirement match any email.
+	c.orgs[1].RequiredEmailDomain = ""
+	groups, err = c.groupsForOrgs(context.Background(), newClient(), "some-login", "some@unknown.com")
+
+	expectNil(t, err)
+	expectEquals(t, groups, []string{"org-2:team-2"})
+}
+
+func TestIncludeOrgInGroups(t *testing.T) {
+	s := newTestServer(map[string]testResponse{
+		"/orgs/org-1/members/some-login": {statusCode: http.StatusNoContent},
+		"/orgs/org-2/members/some-login": {statusCode: http.StatusNoContent},
+		"/orgs/org-3/members/some-login": {statusCode: http.StatusNotFound},
+		"/user/teams": {data: []team{
+			{Name: "team-1", Org: org{Login: "org-1"}},
+		}},
+	})
+	defer s.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	c := githubConnector{apiURL: s.URL, logger: logger, includeOrgInGroups: true, orgs: []Org{
+		{Name: "org-1"}, {Name: "org-2", Teams: []string{"team-2"}}, {Name: "org-3"},
+	}}
+	groups, err := c.groupsForOrgs(context.Background(), newClient(), "some-login", "")
+
+	// The user is in org-2 but not its configured team, which still adds
+	// the org.
+	expectNil(t, err)
+	expectEquals(t, groups, []string{"org-1", "org-1:team-1", "org-2"})
+
+	// With the legacy org, members of the org's teams are known to be in it.
+	c = githubConnector{apiURL: s.URL, logger: logger, includeOrgInGroups: true, org: "org-1"}
+	groups, _, err = c.getGroups(context.Background(), newClient(), true, "some-login", "")
+
+	expectNil(t, err)
+	expectEquals(t, groups, []string{"org-1", "team-1"})
+
+	// Members of none of the org's teams have their membership checked.
+	c.org = "org-2"
+	groups, _, err = c.getGroups(context.Background(), newClient(), true, "some-login", "")
+
+	expectNil(t, err)
+	expectEquals(t, groups, []string{"org-2"})
+
+	c.org = "org-3"
+	groups, _, err = c.getGroups(context.Background(), newClient(), true, "some-login", "")
+
+	expectNil(t, err)
+	expectEquals(t, groups, []string{})
+}
+
+func TestOrgNameField(t *testing.T) {
+	orgRequests := 0
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		switch r.RequestURI {
+		case "/user/orgs":
+			json.NewEncoder(w).Encode([]org{{Login: "org-1"}, {Login: "org-2"}})
+		case "/user/teams":
+			json.NewEncoder(w).Encode([]team{
+				{Name: "team-1", Org: org{Login: "org-1"}},
+				{Name: "team-2", Org: org{Login: "org-1"}},
+			})
+		case "/orgs/org-1":
+			orgRequests++
+			json.NewEncoder(w).Encode(org{Login: "org-1", Name: "Org One"})
+		case "/orgs/org-2":
+			orgRequests++
+			json.NewEncoder(w).Encode(org{Login: "org-2"})
+		case "/orgs/org-1/members/some-login":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer s.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	c := githubConnector{apiURL: s.URL, logger: logger, orgNameField: "name", teamNameMapping: map[string]string{"org-1:team-2": "mapped"}}
+	groups, orgs, err := c.userGroups(context.Background(), newClient(), "some-login")
+
+	// Orgs without a display name keep their login, and mapped teams are
+	// keyed by login.
+	expectNil(t, err)
+	expectEquals(t, groups, []string{"Org One", "Org One:team-1", "mapped", "org-2"})
+	expectEquals(t, orgs, []string{"org-1", "org-2"})
+	expectEquals(t, orgRequests, 2)
+
+	orgRequests = 0
+	c.orgs = []Org{{Name: "org-1"}}
+	c.includeOrgInGroups = true
+	groups, err = c.groupsForOrgs(context.Background(), newClient(), "some-login", "")
+
+	expectNil(t, err)
+	expectEquals(t, groups, []string{"Org One", "Org One:team-1", "mapped"})
+	expectEquals(t, orgRequests, 1)
+}
+
+func Test_Open_OrgNameFieldConfig(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	c := Config{OrgNameField: "name"}
+	conn, err := c.Open("id", log)
+	expectNil(t, err)
+	expectEquals(t, conn.(*githubConnector).orgNameField, "name")
+
+	c.OrgNameField = "slug"
+	_, err = c.Open("id", log)
+	expectEquals(t, err, errors.New("invalid connector config: unsupported org name field value `slug`"))
+}
+
+func TestGroupsForOrgsConcurrency(t *testing.T) {
+	var inFlight, maxInFlight int32
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case "/user/teams":
+			w.Header().Add("Content-Type", "application/json")
+			json.NewEncoder(w).Encode([]team{
+				{Name: "team-1", Org: org{Login: "org-1"}},
+				{Name: "team-4", Org: org{Login: "org-4"}},
+				{Name: "team-5", Org: org{Login: "org-5"}},
+			})
+		case "/orgs/org-3/members/some-login":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			n := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				m := atomic.LoadInt32(&maxInFlight)
+				if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer s.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	c := githubConnector{apiURL: s.URL, logger: logger, concurrency: 3, orgs: []Org{
+		{Name: "org-5"}, {Name: "org-1"}, {Name: "org-3"}, {Name: "org-2"}, {Name: "org-4"},
+	}}
+	groups, err := c.groupsForOrgs(context.Background(), newClient(), "some-login", "")
+
+	expectNil(t, err)
+	// Groups follow the configured order of the orgs whatever the order the
+	// checks complete in.
+	expectEquals(t, groups, []string{"org-5:team-5", "org-1:team-1", "org-4:team-4"})
+	expectEquals(t, atomic.LoadInt32(&maxInFlight) > 1, true)
+	expectEquals(t, atomic.LoadInt32(&maxInFlight) <= 3, true)
+}
+
+func TestGroupsForOrgsCancelOnError(t *testing.T) {
+	var requests int32
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer s.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	c := githubConnector{apiURL: s.URL, logger: logger, concurrency: 1, orgs: []Org{
+		{Name: "org-1"}, {Name: "org-2"}, {Name: "org-3"},
+	}}
+	_, err := c.groupsForOrgs(context.Background(), newClient(), "some-login", "")
+
+	expectNotNil(t, err, "groupsForOrgs error")
+	expectEquals(t, atomic.LoadInt32(&requests), int32(1))
+}
+
+func TestUserGroupsWithTeamNameFieldConfig(t *testing.T) {
+	s := newTestServer(map[string]testResponse{
+		"/user/orgs": {
+			data: []org{{Login: "org-1"}},
+		},
+		"/user/teams": {
+			data: []team{
+				{Name: "Team 1", Slug: "team-1", Org: org{Login: "org-1"}},
+			},
+		},
+	})
+	defer s.Close()
+
+	c := githubConnector{apiURL: s.URL, teamNameField: "slug"}
+	groups, _, err := c.userGroups(context.Background(), newClient(), "some-login")
+
+	expectNil(t, err)
+	expectEquals(t, groups, []string{
+		"org-1",
+		"org-1:team-1",
+	})
+}
+
+func TestUserGroupsWithTeamNameAndSlugFieldConfig(t *testing.T) {
+	s := newTestServer(map[string]testResponse{
+		"/user/orgs": {
+			data: []org{{Login: "org-1"}},
+		},
+		"/user/teams": {
+			data: []team{
+				{Name: "Team 1", Slug: "team-1", Org: org{Login: "org-1"}},
 			},
 		},
-	})
-	defer s.Close()
-
-	hostURL, err := url.Parse(s.URL)
-	expectNil(t, err)
-
-	client := newClient()
-	c := githubConnector{apiURL: s.URL, hostName: hostURL.Host, httpClient: client, preferredEmailDomain: "preferred-domain.com"}
+	})
+	defer s.Close()
+
+	c := githubConnector{apiURL: s.URL, teamNameField: "both"}
+	groups, _, err := c.userGroups(context.Background(), newClient(), "some-login")
+
+	expectNil(t, err)
+	expectEquals(t, groups, []string{
+		"org-1",
+		"org-1:Team 1",
+		"org-1:team-1",
+	})
+}
+
+// tests that the users login is used as their username when they have no username set
+func TestUsernameIncludedInFederatedIdentity(t *testing.T) {
+	s := newTestServer(map[string]testResponse{
+		"/user": {data: user{Login: "some-login", ID: 12345678}},
+		"/user/emails": {data: []userEmail{{
+			Email:    "some@email.com",
+			Verified: true,
+			Primary:  true,
+		}}},
+		"/login/oauth/access_token": {data: map[string]interface{}{
+			"access_token": "eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9",
+			"expires_in":   "30",
+		}},
+		"/user/orgs": {
+			data: []org{{Login: "org-1"}},
+		},
+	})
+	defer s.Close()
+
+	hostURL, err := url.Parse(s.URL)
+	expectNil(t, err)
+
+	req, err := http.NewRequest("GET", hostURL.String(), nil)
+	expectNil(t, err)
+
+	c := githubConnector{apiURL: s.URL, hostName: hostURL.Host, httpClient: newClient()}
+	identity, err := c.HandleCallback(connector.Scopes{Groups: true}, req)
+
+	expectNil(t, err)
+	expectEquals(t, identity.Username, "some-login")
+	expectEquals(t, identity.UserID, "12345678")
+	expectEquals(t, 0, len(identity.Groups))
+
+	c = githubConnector{apiURL: s.URL, hostName: hostURL.Host, httpClient: newClient(), loadAllGroups: true}
+	identity, err = c.HandleCallback(connector.Scopes{Groups: true}, req)
+
+	expectNil(t, err)
+	expectEquals(t, identity.Username, "some-login")
+	expectEquals(t, identity.UserID, "12345678")
+	expectEquals(t, identity.Groups, []string{"org-1"})
+}
+
+func TestDefaultGroups(t *testing.T) {
+	s := newTestServer(map[string]testResponse{
+		"/user": {data: user{Login: "some-login", ID: 12345678}},
+		"/user/emails": {data: []userEmail{{
+			Email:    "some@email.com",
+			Verified: true,
+			Primary:  true,
+		}}},
+		"/login/oauth/access_token": {data: map[string]interface{}{
+			"access_token": "eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9",
+			"expires_in":   "30",
+		}},
+		"/user/orgs": {
+			data: []org{{Login: "org-1"}},
+		},
+	})
+	defer s.Close()
+
+	hostURL, err := url.Parse(s.URL)
+	expectNil(t, err)
+
+	req, err := http.NewRequest("GET", hostURL.String(), nil)
+	expectNil(t, err)
+
+	c := githubConnector{apiURL: s.URL, hostName: hostURL.Host, httpClient: newClient(), defaultGroups: []string{"all-staff"}}
+	identity, err := c.HandleCallback(connector.Scopes{Groups: true}, req)
+
+	expectNil(t, err)
+	expectEquals(t, identity.Groups, []string{"all-staff"})
+
+	c = githubConnector{apiURL: s.URL, hostName: hostURL.Host, httpClient: newClient(), loadAllGroups: true, defaultGroups: []string{"org-1", "all-staff"}}
+	identity, err = c.HandleCallback(connector.Scopes{Groups: true}, req)
+
+	expectNil(t, err)
+	expectEquals(t, identity.Groups, []string{"org-1", "all-staff"})
+}
+
+func TestOrgCountClaim(t *testing.T) {
+	s := newTestServer(map[string]testResponse{
+		"/user": {data: user{Login: "some-login", ID: 12345678}},
+		"/user/emails": {data: []userEmail{{
+			Email:    "some@email.com",
+			Verified: true,
+			Primary:  true,
+		}}},
+		"/login/oauth/access_token": {data: map[string]interface{}{
+			"access_token": "eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9",
+			"expires_in":   "30",
+		}},
+		"/user/orgs": {
+			data: []org{{Login: "org-1"}, {Login: "org-2"}},
+		},
+		"/user/teams": {
+			data: []team{{Name: "team-1", Org: org{Login: "org-1"}}},
+		},
+	})
+	defer s.Close()
+
+	hostURL, err := url.Parse(s.URL)
+	expectNil(t, err)
+
+	req, err := http.NewRequest("GET", hostURL.String(), nil)
+	expectNil(t, err)
+
+	c := githubConnector{apiURL: s.URL, hostName: hostURL.Host, httpClient: newClient(), loadAllGroups: true, includeOrgCountClaim: true}
+	identity, err := c.HandleCallback(connector.Scopes{Groups: true}, req)
+
+	expectNil(t, err)
+	expectEquals(t, identity.ExtraClaims, map[string]interface{}{"github_org_count": 2})
+
+	// Without the groups scope the org list isn't fetched, so no claim is added.
+	identity, err = c.HandleCallback(connector.Scopes{}, req)
+
+	expectNil(t, err)
+	expectEquals(t, len(identity.ExtraClaims), 0)
+
+	c.includeOrgCountClaim = false
+	identity, err = c.HandleCallback(connector.Scopes{Groups: true}, req)
+
+	expectNil(t, err)
+	expectEquals(t, len(identity.ExtraClaims), 0)
+}
+
+func TestLoginOrgs(t *testing.T) {
+	s := newTestServer(map[string]testResponse{
+		"/user": {data: user{Login: "some-login", ID: 12345678, Email: "some@email.com"}},
+		"/login/oauth/access_token": {data: map[string]interface{}{
+			"access_token": "eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9",
+			"expires_in":   "30",
+		}},
+		"/orgs/login-org/members/some-login": {statusCode: http.StatusNoContent},
+		"/orgs/other-org/members/some-login": {statusCode: http.StatusNotFound},
+		"/user/orgs": {
+			data: []org{{Login: "login-org"}, {Login: "org-1"}, {Login: "org-2"}},
+		},
+		"/user/teams": {
+			data: []team{{Name: "team-1", Org: org{Login: "org-1"}}, {Name: "team-2", Org: org{Login: "org-2"}}},
+		},
+	})
+	defer s.Close()
+
+	hostURL, err := url.Parse(s.URL)
+	expectNil(t, err)
+
+	req, err := http.NewRequest("GET", hostURL.String(), nil)
+	expectNil(t, err)
+
+	c := githubConnector{
+		apiURL:        s.URL,
+		hostName:      hostURL.Host,
+		httpClient:    newClient(),
+		logger:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+		loadAllGroups: true,
+		loginOrgs:     []string{"other-org", "login-org"},
+	}
+	identity, err := c.HandleCallback(connector.Scopes{Groups: true}, req)
+
+	// Groups of orgs other than the login org are still returned.
+	expectNil(t, err)
+	expectEquals(t, identity.Groups, []string{"login-org", "org-1", "org-1:team-1", "org-2", "org-2:team-2"})
+	expectEquals(t, c.oauth2Config(connector.Scopes{}).Scopes, []string{scopeEmail, scopeOrgs})
+
+	// The login orgs are checked even without the groups scope.
+	identity, err = c.HandleCallback(connector.Scopes{}, req)
+
+	expectNil(t, err)
+	expectEquals(t, len(identity.Groups), 0)
+
+	c.loginOrgs = []string{"other-org"}
+	_, err = c.HandleCallback(connector.Scopes{Groups: true}, req)
+
+	expectEquals(t, errors.Is(err, ErrUserNotAuthorized), true)
+	expectEquals(t, err.Error(), `github: user "some-login" not in required orgs or teams`)
+}
+
+func Test_Open_LoginOrgsConfig(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	c := Config{LoginOrgs: []string{"org-1"}, LoadAllGroups: true}
+	_, err := c.Open("id", log)
+	expectNil(t, err)
+
+	c.Orgs = []Org{{Name: "org-1"}}
+	_, err = c.Open("id", log)
+	expectEquals(t, err, errors.New("invalid connector config: loginOrgs cannot be combined with org or orgs"))
+}
+
+func TestGroupTransforms(t *testing.T) {
+	s := newTestServer(map[string]testResponse{
+		"/user": {data: user{Login: "some-login", ID: 12345678}},
+		"/user/emails?per_page=100": {data: []userEmail{{
+			Email:    "some@email.com",
+			Verified: true,
+			Primary:  true,
+		}}},
+		"/login/oauth/access_token": {data: map[string]interface{}{
+			"access_token": "eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9",
+			"expires_in":   "30",
+		}},
+		"/user/orgs?per_page=100": {
+			data: []org{{Login: "acme-eng"}, {Login: "acme-ops"}},
+		},
+		"/user/teams?per_page=100": {
+			data: []team{{Name: "admins", Org: org{Login: "acme-eng"}}},
+		},
+	})
+	defer s.Close()
+
+	hostURL, err := url.Parse(s.URL)
+	expectNil(t, err)
+
+	req, err := http.NewRequest("GET", hostURL.String(), nil)
+	expectNil(t, err)
+
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	cfg := Config{
+		LoadAllGroups: true,
+		DefaultGroups: []string{"all-staff"},
+		GroupTransforms: []GroupTransform{
+			{Type: "trimPrefix", Pattern: "acme-"},
+			{Type: "regexReplace", Pattern: `^(\w+):(\w+)$`, Replacement: "${1}/${2}"},
+			{Type: "upper"},
+		},
+	}
+	conn, err := cfg.Open("id", log)
+	expectNil(t, err)
+
+	c := conn.(*githubConnector)
+	c.apiURL = s.URL
+	c.hostName = hostURL.Host
+	c.httpClient = newClient()
+
+	identity, err := c.HandleCallback(connector.Scopes{Groups: true}, req)
+
+	expectNil(t, err)
+	expectEquals(t, identity.Groups, []string{"ENG", "ENG/ADMINS", "OPS", "all-staff"})
+
+	// Groups that collapse into the same name or into nothing are dropped.
+	c.groupTransforms = nil
+	c.defaultGroups = nil
+	for _, gt := range []GroupTransform{
+		{Type: "regexReplace", Pattern: `^acme-ops$`},
+		{Type: "regexReplace", Pattern: `:.*$`},
+	} {
+		transform, err := gt.compile()
+		expectNil(t, err)
+		c.groupTransforms = append(c.groupTransforms, transform)
+	}
+	identity, err = c.HandleCallback(connector.Scopes{Groups: true}, req)
+
+	expectNil(t, err)
+	expectEquals(t, identity.Groups, []string{"acme-eng"})
+}
+
+func Test_Open_GroupTransformsConfig(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	tests := []struct {
+		name      string
+		transform GroupTransform
+		expected  error
+	}{
+		{
+			name:      "valid regex",
+			transform: GroupTransform{Type: "regexReplace", Pattern: "^team-", Replacement: ""},
+		},
+		{
+			name:      "invalid regex",
+			transform: GroupTransform{Type: "regexReplace", Pattern: "("},
+			expected:  errors.New("invalid connector config: groupTransforms[0]: invalid pattern: error parsing regexp: missing closing ): `(`"),
+		},
+		{
+			name:      "missing prefix",
+			transform: GroupTransform{Type: "trimPrefix"},
+			expected:  errors.New("invalid connector config: groupTransforms[0]: trimPrefix requires a pattern"),
+		},
+		{
+			name:      "unknown type",
+			transform: GroupTransform{Type: "reverse"},
+			expected:  errors.New(`invalid connector config: groupTransforms[0]: unsupported type "reverse"`),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := Config{GroupTransforms: []GroupTransform{test.transform}}
+			_, err := c.Open("id", log)
+
+			expectEquals(t, err, test.expected)
+		})
+	}
+}
+
+func TestDeniedScopes(t *testing.T) {
+	s := newTestServer(map[string]testResponse{
+		"/user": {data: user{Login: "some-login", ID: 12345678}},
+		"/user/emails": {data: []userEmail{{
+			Email:    "some@email.com",
+			Verified: true,
+			Primary:  true,
+		}}},
+		"/login/oauth/access_token": {data: map[string]interface{}{
+			"access_token": "eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9",
+			"expires_in":   "30",
+		}},
+		// Fetching orgs fails, so any call made for the denied scope fails the login.
+		"/user/orgs": {statusCode: http.StatusInternalServerError},
+	})
+	defer s.Close()
+
+	hostURL, err := url.Parse(s.URL)
+	expectNil(t, err)
+
+	req, err := http.NewRequest("GET", hostURL.String(), nil)
+	expectNil(t, err)
+
+	c := githubConnector{
+		apiURL: s.URL, hostName: hostURL.Host, httpClient: newClient(), loadAllGroups: true, includeOrgCountClaim: true,
+		redirectURI: "https://dex.example.com/callback", denyGroupsScope: true, denyOfflineAccessScope: true,
+	}
+	identity, err := c.HandleCallback(connector.Scopes{Groups: true, OfflineAccess: true}, req)
+
+	expectNil(t, err)
+	expectEquals(t, len(identity.Groups), 0)
+	expectEquals(t, len(identity.ExtraClaims), 0)
+	expectEquals(t, len(identity.ConnectorData), 0)
+
+	loginURL, err := c.LoginURL(connector.Scopes{Groups: true}, c.redirectURI, "state")
+	expectNil(t, err)
+
+	u, err := url.Parse(loginURL)
+	expectNil(t, err)
+	expectEquals(t, u.Query().Get("scope"), "user:email")
+}
+
+func Test_Open_DeniedScopesConfig(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	tests := []struct {
+		name     string
+		scopes   []string
+		expected error
+	}{
+		{
+			name:   "supported scopes",
+			scopes: []string{"groups", "offline_access"},
+		},
+		{
+			name:     "unsupported scope",
+			scopes:   []string{"email"},
+			expected: errors.New(`invalid connector config: unsupported denied scope "email"`),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := Config{DeniedScopes: test.scopes}
+			_, err := c.Open("id", log)
+
+			expectEquals(t, err, test.expected)
+		})
+	}
+}
+
+func TestAccountAge(t *testing.T) {
+	createdAt := time.Date(2015, 3, 1, 12, 0, 0, 0, time.UTC)
+	s := newTestServer(map[string]testResponse{
+		"/user": {data: map[string]interface{}{"login": "some-login", "id": 12345678, "created_at": createdAt}},
+		"/user/emails": {data: []userEmail{{
+			Email:    "some@email.com",
+			Verified: true,
+			Primary:  true,
+		}}},
+		"/login/oauth/access_token": {data: map[string]interface{}{
+			"access_token": "eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9",
+			"expires_in":   "30",
+		}},
+	})
+	defer s.Close()
+
+	hostURL, err := url.Parse(s.URL)
+	expectNil(t, err)
+
+	req, err := http.NewRequest("GET", hostURL.String(), nil)
+	expectNil(t, err)
+
+	c := githubConnector{apiURL: s.URL, hostName: hostURL.Host, httpClient: newClient(), includeAccountAgeClaim: true, minAccountAge: 24 * time.Hour}
+	identity, err := c.HandleCallback(connector.Scopes{}, req)
+
+	expectNil(t, err)
+	expectEquals(t, identity.ExtraClaims, map[string]interface{}{"github_created_at": createdAt.Unix()})
+
+	c.includeAccountAgeClaim = false
+	identity, err = c.HandleCallback(connector.Scopes{}, req)
+
+	expectNil(t, err)
+	expectEquals(t, len(identity.ExtraClaims), 0)
+
+	c.minAccountAge = time.Since(createdAt) + time.Hour
+	_, err = c.HandleCallback(connector.Scopes{}, req)
+
+	expectNotNil(t, err, "HandleCallback should deny an account younger than minAccountAge")
+}
+
+func TestAccountAgeUnknown(t *testing.T) {
+	s := newTestServer(map[string]testResponse{
+		"/user": {data: user{Login: "some-login", ID: 12345678}},
+		"/user/emails": {data: []userEmail{{
+			Email:    "some@email.com",
+			Verified: true,
+			Primary:  true,
+		}}},
+		"/login/oauth/access_token": {data: map[string]interface{}{
+			"access_token": "eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9",
+			"expires_in":   "30",
+		}},
+	})
+	defer s.Close()
+
+	hostURL, err := url.Parse(s.URL)
+	expectNil(t, err)
+
+	req, err := http.NewRequest("GET", hostURL.String(), nil)
+	expectNil(t, err)
+
+	// Without a creation time the claim is omitted.
+	c := githubConnector{apiURL: s.URL, hostName: hostURL.Host, httpClient: newClient(), includeAccountAgeClaim: true}
+	identity, err := c.HandleCallback(connector.Scopes{}, req)
+
+	expectNil(t, err)
+	expectEquals(t, len(identity.ExtraClaims), 0)
+
+	// The minimum age can't be verified, so the login is denied.
+	c.minAccountAge = time.Hour
+	_, err = c.HandleCallback(connector.Scopes{}, req)
+
+	expectNotNil(t, err, "HandleCallback should deny an account without a creation time")
+}
+
+func Test_Open_MinAccountAgeConfig(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	tests := []struct {
+		name     string
+		age      string
+		expected error
+	}{
+		{
+			name: "valid age",
+			age:  "720h",
+		},
+		{
+			name:     "invalid age",
+			age:      "a month",
+			expected: errors.New(`invalid connector config: minAccountAge: time: invalid duration "a month"`),
+		},
+		{
+			name:     "negative age",
+			age:      "-1h",
+			expected: errors.New("invalid connector config: minAccountAge cannot be negative"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := Config{MinAccountAge: test.age}
+			_, err := c.Open("id", log)
+
+			expectEquals(t, err, test.expected)
+		})
+	}
+}
+
+func TestUnsatisfiedGroupsScope(t *testing.T) {
+	s := newTestServer(map[string]testResponse{
+		"/user": {data: user{Login: "some-login", ID: 12345678}},
+		"/user/emails": {data: []userEmail{{
+			Email:    "some@email.com",
+			Verified: true,
+			Primary:  true,
+		}}},
+		"/login/oauth/access_token": {data: map[string]interface{}{
+			"access_token": "eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9",
+			"expires_in":   "30",
+		}},
+	})
+	defer s.Close()
+
+	hostURL, err := url.Parse(s.URL)
+	expectNil(t, err)
+
+	req, err := http.NewRequest("GET", hostURL.String(), nil)
+	expectNil(t, err)
+
+	var buf bytes.Buffer
+	c := githubConnector{apiURL: s.URL, hostName: hostURL.Host, httpClient: newClient(), logger: slog.New(slog.NewTextHandler(&buf, nil))}
+
+	// By default no groups are returned without further notice.
+	identity, err := c.HandleCallback(connector.Scopes{Groups: true}, req)
+
+	expectNil(t, err)
+	expectEquals(t, len(identity.Groups), 0)
+	expectEquals(t, buf.Len(), 0)
+
+	c.unsatisfiedGroupsScope = "warn"
+	identity, err = c.HandleCallback(connector.Scopes{Groups: true}, req)
+
+	expectNil(t, err)
+	expectEquals(t, len(identity.Groups), 0)
+	expectEquals(t, strings.Contains(buf.String(), "groups scope requested"), true)
+
+	c.unsatisfiedGroupsScope = "error"
+	_, err = c.HandleCallback(connector.Scopes{Groups: true}, req)
+
+	expectEquals(t, err, errGroupsScopeUnsatisfied)
+
+	// Logins without the groups scope are unaffected.
+	_, err = c.HandleCallback(connector.Scopes{}, req)
+
+	expectNil(t, err)
+
+	_, err = (&Config{UnsatisfiedGroupsScope: "fail"}).Open("id", c.logger)
+
+	expectEquals(t, err, errors.New(`invalid connector config: unsupported unsatisfiedGroupsScope "fail"`))
+}
+
+func TestSubjectHashClaim(t *testing.T) {
+	s := newTestServer(map[string]testResponse{
+		"/user": {data: user{Login: "some-login", ID: 12345678, Email: "some@email.com"}},
+		"/login/oauth/access_token": {data: map[string]interface{}{
+			"access_token": "eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9",
+			"expires_in":   "30",
+		}},
+	})
+	defer s.Close()
+
+	hostURL, err := url.Parse(s.URL)
+	expectNil(t, err)
+
+	req, err := http.NewRequest("GET", hostURL.String(), nil)
+	expectNil(t, err)
+
+	c := githubConnector{apiURL: s.URL, hostName: hostURL.Host, httpClient: newClient(), subjectHashSalt: []byte("salt")}
+	identity, err := c.HandleCallback(connector.Scopes{}, req)
+
+	expectNil(t, err)
+	// HMAC-SHA256 of "12345678" keyed with "salt".
+	mac := hmac.New(sha256.New, []byte("salt"))
+	mac.Write([]byte("12345678"))
+	expectEquals(t, identity.ExtraClaims, map[string]interface{}{"subject_hash": hex.EncodeToString(mac.Sum(nil))})
+
+	// A different salt yields an unrelated hash.
+	c.subjectHashSalt = []byte("pepper")
+	other, err := c.HandleCallback(connector.Scopes{}, req)
+
+	expectNil(t, err)
+	expectEquals(t, other.ExtraClaims["subject_hash"] == identity.ExtraClaims["subject_hash"], false)
+
+	c.subjectHashSalt = nil
+	identity, err = c.HandleCallback(connector.Scopes{}, req)
+
+	expectNil(t, err)
+	expectEquals(t, len(identity.ExtraClaims), 0)
+}
+
+func Test_Open_SubjectHashConfig(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	c := Config{IncludeSubjectHashClaim: true}
+	_, err := c.Open("id", log)
+	expectEquals(t, err, errors.New("invalid connector config: subjectHashSalt is required when includeSubjectHashClaim is set"))
+
+	c.SubjectHashSalt = "salt"
+	_, err = c.Open("id", log)
+	expectNil(t, err)
+}
+
+func TestLoginUsedAsIDWhenConfigured(t *testing.T) {
+	s := newTestServer(map[string]testResponse{
+		"/user": {data: user{Login: "some-login", ID: 12345678, Name: "Joe Bloggs"}},
+		"/user/emails": {data: []userEmail{{
+			Email:    "some@email.com",
+			Verified: true,
+			Primary:  true,
+		}}},
+		"/login/oauth/access_token": {data: map[string]interface{}{
+			"access_token": "eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9",
+			"expires_in":   "30",
+		}},
+		"/user/orgs": {
+			data: []org{{Login: "org-1"}},
+		},
+	})
+	defer s.Close()
+
+	hostURL, err := url.Parse(s.URL)
+	expectNil(t, err)
+
+	req, err := http.NewRequest("GET", hostURL.String(), nil)
+	expectNil(t, err)
+
+	c := githubConnector{apiURL: s.URL, hostName: hostURL.Host, httpClient: newClient(), useLoginAsID: true}
+	identity, err := c.HandleCallback(connector.Scopes{Groups: true}, req)
+
+	expectNil(t, err)
+	expectEquals(t, identity.UserID, "some-login")
+	expectEquals(t, identity.Username, "Joe Bloggs")
+}
+
+func TestUserIDPrefix(t *testing.T) {
+	tests := []struct {
+		name         string
+		useLoginAsID bool
+		userIDPrefix string
+		want         string
+	}{
+		{name: "numeric", want: "12345678"},
+		{name: "login", useLoginAsID: true, want: "some-login"},
+		{name: "prefixed numeric", userIDPrefix: "github:", want: "github:12345678"},
+		{name: "prefixed login", useLoginAsID: true, userIDPrefix: "github:", want: "github:some-login"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newTestServer(map[string]testResponse{
+				"/user": {data: user{Login: "some-login", ID: 12345678, Email: "some@email.com"}},
+				"/login/oauth/access_token": {data: map[string]interface{}{
+					"access_token": "eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9",
+					"expires_in":   "30",
+				}},
+			})
+			defer s.Close()
+
+			hostURL, err := url.Parse(s.URL)
+			expectNil(t, err)
+			req, err := http.NewRequest("GET", hostURL.String(), nil)
+			expectNil(t, err)
+
+			c := githubConnector{
+				apiURL: s.URL, hostName: hostURL.Host, httpClient: newClient(),
+				useLoginAsID: tc.useLoginAsID, userIDPrefix: tc.userIDPrefix,
+			}
+			identity, err := c.HandleCallback(connector.Scopes{OfflineAccess: true}, req)
+			expectNil(t, err)
+			expectEquals(t, identity.UserID, tc.want)
+
+			// Refreshing must keep the ID stable rather than prefix it again.
+			identity, err = c.Refresh(context.Background(), connector.Scopes{OfflineAccess: true}, identity)
+			expectNil(t, err)
+			expectEquals(t, identity.UserID, tc.want)
+		})
+	}
+}
+
+func TestRequiredEmailDomains(t *testing.T) {
+	s := newTestServer(map[string]testResponse{
+		"/user": {data: user{Login: "some-login", ID: 12345678, Name: "Joe Bloggs"}},
+		"/user/emails": {data: []userEmail{{
+			Email:    "some@email.com",
+			Verified: true,
+			Primary:  true,
+		}}},
+		"/login/oauth/access_token": {data: map[string]interface{}{
+			"access_token": "eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9",
+			"expires_in":   "30",
+		}},
+	})
+	defer s.Close()
+
+	hostURL, err := url.Parse(s.URL)
+	expectNil(t, err)
+
+	req, err := http.NewRequest("GET", hostURL.String(), nil)
+	expectNil(t, err)
+
+	c := githubConnector{apiURL: s.URL, hostName: hostURL.Host, httpClient: newClient(), requiredEmailDomains: []string{"other.com", "Email.com"}}
+	identity, err := c.HandleCallback(connector.Scopes{}, req)
+	expectNil(t, err)
+	expectEquals(t, identity.Email, "some@email.com")
+
+	c = githubConnector{apiURL: s.URL, hostName: hostURL.Host, httpClient: newClient(), requiredEmailDomains: []string{"other.com"}}
+	_, err = c.HandleCallback(connector.Scopes{}, req)
+	expectNotNil(t, err, "required email domain error")
+	expectEquals(t, err.Error(), "github: user email \"some@email.com\" not in required domains")
+}
+
+func TestAllowedEmailDomains(t *testing.T) {
+	s := newTestServer(map[string]testResponse{
+		"/user": {data: user{Login: "some-login", ID: 12345678, Name: "Joe Bloggs"}},
+		"/user/emails": {data: []userEmail{{
+			Email:    "some@mail.eng.corp.com",
+			Verified: true,
+			Primary:  true,
+		}}},
+		"/login/oauth/access_token": {data: map[string]interface{}{
+			"access_token": "eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9",
+			"expires_in":   "30",
+		}},
+	})
+	defer s.Close()
+
+	hostURL, err := url.Parse(s.URL)
+	expectNil(t, err)
+
+	req, err := http.NewRequest("GET", hostURL.String(), nil)
+	expectNil(t, err)
+
+	c := githubConnector{apiURL: s.URL, hostName: hostURL.Host, httpClient: newClient(), allowedEmailDomains: []string{"other.com", "*.corp.com"}}
+	identity, err := c.HandleCallback(connector.Scopes{OfflineAccess: true}, req)
+	expectNil(t, err)
+	expectEquals(t, identity.Email, "some@mail.eng.corp.com")
+
+	// Refresh re-checks the email, so a disallowed domain revokes access.
+	c.allowedEmailDomains = []string{"mail.*.other.com"}
+	_, err = c.Refresh(context.Background(), connector.Scopes{OfflineAccess: true}, identity)
+	expectNotNil(t, err, "allowed email domain error")
+	expectEquals(t, err.Error(), "github: user email \"some@mail.eng.corp.com\" not in allowed domains")
+}
+
+func TestCheckAllowedEmailDomainIgnoresCase(t *testing.T) {
+	c := githubConnector{allowedEmailDomains: []string{"Corp.com", "*.Eng.corp.com"}}
+	expectNil(t, c.checkAllowedEmailDomain("User@CORP.COM"))
+	expectNil(t, c.checkAllowedEmailDomain("user@Mail.ENG.corp.com"))
+	expectNotNil(t, c.checkAllowedEmailDomain("user@Other.com"), "allowed email domain error")
+}
+
+func Test_Open_AllowedEmailDomainsConfig(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	c := Config{AllowedEmailDomains: []string{"*.corp.com", "corp.*"}}
+	_, err := c.Open("id", log)
+	expectEquals(t, err, errors.New(`invalid connector config: allowedEmailDomains: glob pattern cannot end with "*"`))
+}
+
+func TestAvatarURL(t *testing.T) {
+	s := newTestServer(map[string]testResponse{
+		"/user": {data: user{Login: "some-login", ID: 12345678, Email: "some@email.com", AvatarURL: "https://avatars.githubusercontent.com/u/12345678"}},
+		"/login/oauth/access_token": {data: map[string]interface{}{
+			"access_token": "eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9",
+			"expires_in":   "30",
+		}},
+	})
+	defer s.Close()
+
+	hostURL, err := url.Parse(s.URL)
+	expectNil(t, err)
+
+	req, err := http.NewRequest("GET", hostURL.String(), nil)
+	expectNil(t, err)
+
+	c := githubConnector{apiURL: s.URL, hostName: hostURL.Host, httpClient: newClient()}
+	identity, err := c.HandleCallback(connector.Scopes{OfflineAccess: true}, req)
+	expectNil(t, err)
+	expectEquals(t, identity.ExtraClaims["picture"], "https://avatars.githubusercontent.com/u/12345678")
+
+	identity.ExtraClaims = nil
+	identity, err = c.Refresh(context.Background(), connector.Scopes{OfflineAccess: true}, identity)
+	expectNil(t, err)
+	expectEquals(t, identity.ExtraClaims["picture"], "https://avatars.githubusercontent.com/u/12345678")
+}
+
+func TestUsernameTemplate(t *testing.T) {
+	s := newTestServer(map[string]testResponse{
+		"/user": {data: user{Login: "some-login", ID: 12345678, Name: "Joe Bloggs", Email: "some@email.com"}},
+		"/login/oauth/access_token": {data: map[string]interface{}{
+			"access_token": "eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9",
+			"expires_in":   "30",
+		}},
+	})
+	defer s.Close()
+
+	hostURL, err := url.Parse(s.URL)
+	expectNil(t, err)
+
+	req, err := http.NewRequest("GET", hostURL.String(), nil)
+	expectNil(t, err)
+
+	c := githubConnector{apiURL: s.URL, hostName: hostURL.Host, httpClient: newClient()}
+	identity, err := c.HandleCallback(connector.Scopes{OfflineAccess: true}, req)
+	expectNil(t, err)
+	expectEquals(t, identity.PreferredUsername, "some-login")
+
+	c.usernameTemplate = "{login}@github ({name}, {id})"
+	identity, err = c.HandleCallback(connector.Scopes{OfflineAccess: true}, req)
+	expectNil(t, err)
+	expectEquals(t, identity.PreferredUsername, "some-login@github (Joe Bloggs, 12345678)")
+
+	identity.PreferredUsername = ""
+	identity, err = c.Refresh(context.Background(), connector.Scopes{OfflineAccess: true}, identity)
+	expectNil(t, err)
+	expectEquals(t, identity.PreferredUsername, "some-login@github (Joe Bloggs, 12345678)")
+}
+
+func Test_Open_UsernameTemplateConfig(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	c := Config{UsernameTemplate: "{login}@github"}
+	conn, err := c.Open("id", log)
+	expectNil(t, err)
+	expectEquals(t, conn.(*githubConnector).usernameTemplate, "{login}@github")
+
+	c.UsernameTemplate = "{username}@github"
+	_, err = c.Open("id", log)
+	expectEquals(t, err, errors.New("invalid connector config: usernameTemplate: unknown placeholder {username}"))
+
+	c.UsernameTemplate = "{login@github"
+	_, err = c.Open("id", log)
+	expectEquals(t, err, errors.New("invalid connector config: usernameTemplate: unmatched brace"))
+}
+
+func TestPublicEmailOnly(t *testing.T) {
+	emailsRequested := false
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		switch r.RequestURI {
+		case "/user":
+			json.NewEncoder(w).Encode(user{Login: "some-login", ID: 12345678})
+		case "/login/oauth/access_token":
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9"})
+		case "/user/emails":
+			emailsRequested = true
+			json.NewEncoder(w).Encode([]userEmail{{Email: "some@email.com", Verified: true, Primary: true}})
+		}
+	}))
+	defer s.Close()
+
+	hostURL, err := url.Parse(s.URL)
+	expectNil(t, err)
+
+	req, err := http.NewRequest("GET", hostURL.String(), nil)
+	expectNil(t, err)
+
+	c := githubConnector{apiURL: s.URL, hostName: hostURL.Host, httpClient: newClient(), publicEmailOnly: true}
+	identity, err := c.HandleCallback(connector.Scopes{OfflineAccess: true}, req)
+	expectNil(t, err)
+	expectEquals(t, identity.Email, "")
+	expectEquals(t, identity.EmailVerified, false)
+
+	identity, err = c.Refresh(context.Background(), connector.Scopes{OfflineAccess: true}, identity)
+	expectNil(t, err)
+	expectEquals(t, identity.Email, "")
+	expectEquals(t, identity.EmailVerified, false)
+	expectEquals(t, emailsRequested, false)
+
+	expectEquals(t, c.oauth2Config(connector.Scopes{}).Scopes, []string(nil))
+	expectEquals(t, c.oauth2Config(connector.Scopes{Groups: true}).Scopes, []string{scopeOrgs})
+}
+
+func Test_Open_PublicEmailOnlyConfig(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	c := Config{PublicEmailOnly: true}
+	conn, err := c.Open("id", log)
+	expectNil(t, err)
+	expectEquals(t, conn.(*githubConnector).publicEmailOnly, true)
+
+	c.PreferredEmailDomain = "example.com"
+	_, err = c.Open("id", log)
+	expectEquals(t, err, errors.New("invalid connector config: publicEmailOnly cannot be combined with preferredEmailDomain or preferredEmailDomains"))
+}
+
+func TestPreferredEmailDomainConfigured(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServer(map[string]testResponse{
+		"/user": {data: user{Login: "some-login", ID: 12345678, Name: "Joe Bloggs"}},
+		"/user/emails": {
+			data: []userEmail{
+				{
+					Email:    "some@email.com",
+					Verified: true,
+					Primary:  true,
+				},
+				{
+					Email:    "another@email.com",
+					Verified: true,
+					Primary:  false,
+				},
+				{
+					Email:    "some@preferred-domain.com",
+					Verified: true,
+					Primary:  false,
+				},
+				{
+					Email:    "another@preferred-domain.com",
+					Verified: true,
+					Primary:  false,
+				},
+			},
+		},
+	})
+	defer s.Close()
+
+	hostURL, err := url.Parse(s.URL)
+	expectNil(t, err)
+
+	client := newClient()
+	c := githubConnector{apiURL: s.URL, hostName: hostURL.Host, httpClient: client, preferredEmailDomain: "preferred-domain.com"}
+
+	u, err := c.user(ctx, client)
+	expectNil(t, err)
+	expectEquals(t, u.Email, "some@preferred-domain.com")
+}
+
+func TestPreferredEmailDomainsPriority(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServer(map[string]testResponse{
+		"/user": {data: user{Login: "some-login", ID: 12345678, Name: "Joe Bloggs"}},
+		"/user/emails": {
+			data: []userEmail{
+				{Email: "some@email.com", Verified: true, Primary: true},
+				{Email: "some@unverified.acquired.com", Verified: false, Primary: false},
+				{Email: "some@acquired.com", Verified: true, Primary: false},
+				{Email: "some@corp.com", Verified: true, Primary: false},
+			},
+		},
+	})
+	defer s.Close()
+
+	client := newClient()
+	c := githubConnector{apiURL: s.URL, preferredEmailDomains: []string{"corp.com", "*.acquired.com", "acquired.com"}}
+
+	u, err := c.user(ctx, client)
+	expectNil(t, err)
+	expectEquals(t, u.Email, "some@corp.com")
+
+	c.preferredEmailDomains = []string{"other.com", "*.acquired.com", "acquired.com", "corp.com"}
+	u, err = c.user(ctx, client)
+	expectNil(t, err)
+	expectEquals(t, u.Email, "some@acquired.com")
+}
+
+func TestPreferredEmailDomainConfiguredWithGlob(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServer(map[string]testResponse{
+		"/user": {data: user{Login: "some-login", ID: 12345678, Name: "Joe Bloggs"}},
+		"/user/emails": {
+			data: []userEmail{
+				{
+					Email:    "some@email.com",
+					Verified: true,
+					Primary:  true,
+				},
+				{
+					Email:    "another@email.com",
+					Verified: true,
+					Primary:  false,
+				},
+				{
+					Email:    "some@another.preferred-domain.com",
+					Verified: true,
+					Primary:  false,
+				},
+				{
+					Email:    "some@sub-domain.preferred-domain.co",
+					Verified: true,
+					Primary:  false,
+				},
+			},
+		},
+	})
+	defer s.Close()
+
+	hostURL, err := url.Parse(s.URL)
+	expectNil(t, err)
+
+	client := newClient()
+	c := githubConnector{apiURL: s.URL, hostName: hostURL.Host, httpClient: client, preferredEmailDomain: "*.preferred-domain.co"}
+
+	u, err := c.user(ctx, client)
+	expectNil(t, err)
+	expectEquals(t, u.Email, "some@sub-domain.preferred-domain.co")
+}
+
+func TestPreferredEmailDomainConfigured_UserHasNoPreferredDomainEmail(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServer(map[string]testResponse{
+		"/user": {data: user{Login: "some-login", ID: 12345678, Name: "Joe Bloggs"}},
+		"/user/emails": {
+			data: []userEmail{
+				{
+					Email:    "some@email.com",
+					Verified: true,
+					Primary:  true,
+				},
+				{
+					Email:    "another@email.com",
+					Verified: true,
+					Primary:  false,
+				},
+			},
+		},
+	})
+	defer s.Close()
+
+	hostURL, err := url.Parse(s.URL)
+	expectNil(t, err)
+
+	client := newClient()
+	c := githubConnector{apiURL: s.URL, hostName: hostURL.Host, httpClient: client, preferredEmailDomain: "preferred-domain.com"}
+
+	u, err := c.user(ctx, client)
+	expectNil(t, err)
+	expectEquals(t, u.Email, "some@email.com")
+}
+
+func TestPreferredEmailDomainNotConfigured(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServer(map[string]testResponse{
+		"/user": {data: user{Login: "some-login", ID: 12345678, Name: "Joe Bloggs"}},
+		"/user/emails": {
+			data: []userEmail{
+				{
+					Email:    "some@email.com",
+					Verified: true,
+					Primary:  true,
+				},
+				{
+					Email:    "another@email.com",
+					Verified: true,
+					Primary:  false,
+				},
+				{
+					Email:    "some@preferred-domain.com",
+					Verified: true,
+					Primary:  false,
+				},
+			},
+		},
+	})
+	defer s.Close()
+
+	hostURL, err := url.Parse(s.URL)
+	expectNil(t, err)
+
+	client := newClient()
+	c := githubConnector{apiURL: s.URL, hostName: hostURL.Host, httpClient: client}
+
+	u, err := c.user(ctx, client)
+	expectNil(t, err)
+	expectEquals(t, u.Email, "some@email.com")
+}
+
+func TestPreferredEmailDomainConfigured_Error_BothPrimaryAndPreferredDomainEmailNotFound(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServer(map[string]testResponse{
+		"/user": {data: user{Login: "some-login", ID: 12345678, Name: "Joe Bloggs"}},
+		"/user/emails": {
+			data: []userEmail{
+				{
+					Email:    "some@email.com",
+					Verified: true,
+					Primary:  false,
+				},
+				{
+					Email:    "another@email.com",
+					Verified: true,
+					Primary:  false,
+				},
+				{
+					Email:    "some@preferred-domain.com",
+					Verified: true,
+					Primary:  false,
+				},
+			},
+		},
+	})
+	defer s.Close()
+
+	hostURL, err := url.Parse(s.URL)
+	expectNil(t, err)
+
+	client := newClient()
+	c := githubConnector{apiURL: s.URL, hostName: hostURL.Host, httpClient: client, preferredEmailDomain: "foo.bar"}
+
+	_, err = c.user(ctx, client)
+	expectNotNil(t, err, "Email not found error")
+	expectEquals(t, err.Error(), "github: user has no verified, primary email or preferred-domain email")
+}
+
+func TestRefreshDeletedUser(t *testing.T) {
+	connData, err := json.Marshal(connectorData{AccessToken: "some-token"})
+	expectNil(t, err)
+
+	for _, tc := range []struct {
+		name       string
+		statusCode int
+		deleted    bool
+		revoked    bool
+	}{
+		{name: "not found", statusCode: http.StatusNotFound, deleted: true},
+		{name: "unauthorized", statusCode: http.StatusUnauthorized, revoked: true},
+		{name: "server error", statusCode: http.StatusInternalServerError},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newTestServer(map[string]testResponse{
+				"/user": {data: map[string]string{"message": "Not Found"}, statusCode: tc.statusCode},
+			})
+			defer s.Close()
+
+			c := githubConnector{apiURL: s.URL, httpClient: newClient()}
+			ctx := context.WithValue(context.Background(), oauth2.HTTPClient, newClient())
+			_, err := c.Refresh(ctx, connector.Scopes{}, connector.Identity{ConnectorData: connData})
+
+			expectNotNil(t, err, "refresh error")
+			expectEquals(t, errors.Is(err, ErrUserDeleted), tc.deleted)
+			expectEquals(t, errors.Is(err, connector.ErrUserNotFound), tc.deleted)
+			expectEquals(t, errors.Is(err, ErrTokenRevoked), tc.revoked)
+			expectEquals(t, errors.Is(err, connector.ErrTokenRevoked), tc.revoked)
+		})
+	}
+}
+
+func TestRefreshUserEmailsNotFound(t *testing.T) {
+	connData, err := json.Marshal(connectorData{AccessToken: "some-token"})
+	expectNil(t, err)
+
+	// GitHub returns a 404 on the user's emails if the token lacks the
+	// user:email scope, which doesn't mean the user was deleted.
+	s := newTestServer(map[string]testResponse{
+		"/user":        {data: user{Login: "some-login", ID: 12345678}},
+		"/user/emails": {data: map[string]string{"message": "Not Found"}, statusCode: http.StatusNotFound},
+	})
+	defer s.Close()
+
+	c := githubConnector{apiURL: s.URL, httpClient: newClient()}
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, newClient())
+	_, err = c.Refresh(ctx, connector.Scopes{}, connector.Identity{ConnectorData: connData})
+
+	expectNotNil(t, err, "refresh error")
+	expectEquals(t, errors.Is(err, ErrUserDeleted), false)
+	expectEquals(t, errors.Is(err, connector.ErrUserNotFound), false)
+}
+
+func TestRefreshCoalescesGroups(t *testing.T) {
+	const refreshes = 5
+
+	connData, err := json.Marshal(connectorData{AccessToken: "some-token"})
+	expectNil(t, err)
+
+	for _, tc := range []struct {
+		name       string
+		statusCode int
+	}{
+		{name: "success", statusCode: http.StatusOK},
+		{name: "error", statusCode: http.StatusInternalServerError},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var users, orgFetches int32
+			release := make(chan struct{})
+			s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/user":
+					json.NewEncoder(w).Encode(user{Login: "some-login", Email: "some@example.com"})
+					atomic.AddInt32(&users, 1)
+				case "/user/orgs":
+					atomic.AddInt32(&orgFetches, 1)
+					<-release
+					w.WriteHeader(tc.statusCode)
+					json.NewEncoder(w).Encode([]org{{Login: "org-1"}})
+				case "/user/teams":
+					json.NewEncoder(w).Encode([]team{{Name: "team-1", Org: org{Login: "org-1"}}})
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer s.Close()
+
+			c := githubConnector{apiURL: s.URL, httpClient: newClient(), loadAllGroups: true, groupsFlight: new(singleflight.Group)}
+			ctx := context.WithValue(context.Background(), oauth2.HTTPClient, newClient())
+
+			identities := make([]connector.Identity, refreshes)
+			errs := make([]error, refreshes)
+			var wg sync.WaitGroup
+			for i := 0; i < refreshes; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					identities[i], errs[i] = c.Refresh(ctx, connector.Scopes{Groups: true}, connector.Identity{ConnectorData: connData})
+				}(i)
+			}
+
+			// Hold the group lookup until every refresh is waiting for it.
+			for atomic.LoadInt32(&users) < refreshes {
+				time.Sleep(time.Millisecond)
+			}
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+			wg.Wait()
+
+			expectEquals(t, atomic.LoadInt32(&orgFetches), int32(1))
+			for i := 0; i < refreshes; i++ {
+				if tc.statusCode != http.StatusOK {
+					expectNotNil(t, errs[i], "refresh error")
+					continue
+				}
+				expectNil(t, errs[i])
+				expectEquals(t, identities[i].Groups, []string{"org-1", "org-1:team-1"})
+			}
+			if tc.statusCode == http.StatusOK {
+				// Each refresh gets its own copy of the groups.
+				identities[0].Groups[0] = "modified"
+				expectEquals(t, identities[1].Groups[0], "org-1")
+			}
+		})
+	}
+}
+
+func TestMaintenanceResponse(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		message     string
+		maintenance bool
+	}{
+		{name: "maintenance", message: "GitHub is currently down for maintenance.", maintenance: true},
+		{name: "other outage", message: "Service Unavailable", maintenance: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newTestServer(map[string]testResponse{
+				"/user": {data: map[string]string{"message": tc.message}, statusCode: http.StatusServiceUnavailable},
+			})
+			defer s.Close()
+
+			c := githubConnector{apiURL: s.URL}
+			_, err := c.user(context.Background(), newClient())
+
+			expectNotNil(t, err, "user error")
+			expectEquals(t, errors.Is(err, ErrServiceUnavailable), tc.maintenance)
+			expectEquals(t, errors.Is(err, connector.ErrUnavailable), tc.maintenance)
+			expectEquals(t, strings.Contains(err.Error(), tc.message), true)
+		})
+	}
+}
+
+func TestMaintenanceRetry(t *testing.T) {
+	requests := 0
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Add("Content-Type", "application/json")
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(map[string]string{"message": "GitHub is down for maintenance"})
+			return
+		}
+		json.NewEncoder(w).Encode(user{Login: "some-login", ID: 12345678, Email: "some@email.com"})
+	}))
+	defer s.Close()
+
+	c := githubConnector{
+		apiURL:                   s.URL,
+		logger:                   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		maintenanceRetries:       1,
+		maintenanceRetryInterval: time.Millisecond,
+	}
+	u, err := c.user(context.Background(), newClient())
+
+	expectNil(t, err)
+	expectEquals(t, u.Login, "some-login")
+	expectEquals(t, requests, 2)
+
+	// Without retries the maintenance error is returned straight away.
+	requests = 0
+	c.maintenanceRetries = 0
+	_, err = c.user(context.Background(), newClient())
+
+	expectEquals(t, errors.Is(err, ErrServiceUnavailable), true)
+	expectEquals(t, requests, 1)
+}
+
+func TestRateLimitRetry(t *testing.T) {
+	requests := 0
+	reset := time.Now()
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Add("Content-Type", "application/json")
+		if requests == 1 {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]string{"message": "API rate limit exceeded"})
+			return
+		}
+		json.NewEncoder(w).Encode(user{Login: "some-login", ID: 12345678, Email: "some@email.com"})
+	}))
+	defer s.Close()
+
+	c := githubConnector{
+		apiURL:       s.URL,
+		logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+		maxRetries:   1,
+		retryMaxWait: time.Minute,
+	}
+	u, err := c.user(context.Background(), newClient())
+
+	expectNil(t, err)
+	expectEquals(t, u.Login, "some-login")
+	expectEquals(t, requests, 2)
+
+	// A reset beyond retryMaxWait isn't waited for, and the rate limit
+	// response is returned.
+	requests = 0
+	reset = time.Now().Add(time.Hour)
+	_, err = c.user(context.Background(), newClient())
+
+	var apiErr *apiError
+	expectEquals(t, errors.As(err, &apiErr), true)
+	expectEquals(t, apiErr.statusCode, http.StatusForbidden)
+	expectEquals(t, strings.Contains(string(apiErr.body), "API rate limit exceeded"), true)
+	expectEquals(t, requests, 1)
+
+	// Neither is a reset after the context deadline.
+	requests = 0
+	reset = time.Now().Add(30 * time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = c.user(ctx, newClient())
+
+	expectEquals(t, errors.As(err, &apiErr), true)
+	expectEquals(t, requests, 1)
+}
+
+func TestRetryAfter(t *testing.T) {
+	requests := 0
+	retryAfterValue := "0"
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", retryAfterValue)
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		switch r.URL.Path {
+		case "/user":
+			w.Header().Add("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(user{Login: "some-login", ID: 12345678, Email: "some@email.com"})
+		case "/orgs/org-1/members/some-login":
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer s.Close()
+
+	c := githubConnector{
+		apiURL:       s.URL,
+		logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+		retryMaxWait: time.Minute,
+	}
+	u, err := c.user(context.Background(), newClient())
+
+	expectNil(t, err)
+	expectEquals(t, u.Login, "some-login")
+	expectEquals(t, requests, 2)
+
+	requests = 0
+	inOrg, err := c.userInOrg(context.Background(), newClient(), "some-login", "org-1")
+
+	expectNil(t, err)
+	expectEquals(t, inOrg, true)
+	expectEquals(t, requests, 2)
+
+	// A wait beyond the context deadline fails straight away with a
+	// rate limit error.
+	requests = 0
+	retryAfterValue = "60"
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = c.user(ctx, newClient())
+
+	expectEquals(t, errors.Is(err, ErrRateLimited), true)
+	expectEquals(t, requests, 1)
+
+	requests = 0
+	_, err = c.userInOrg(ctx, newClient(), "some-login", "org-1")
+
+	expectEquals(t, errors.Is(err, ErrRateLimited), true)
+	expectEquals(t, requests, 1)
+}
+
+func TestErrorSentinels(t *testing.T) {
+	sentinels := []error{ErrUserNotAuthorized, ErrRateLimited, ErrUpstreamUnavailable}
+	expectOnly := func(t *testing.T, err, want error) {
+		t.Helper()
+		for _, sentinel := range sentinels {
+			expectEquals(t, errors.Is(err, sentinel), sentinel == want)
+		}
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("user not in org", func(t *testing.T) {
+		s := newTestServer(map[string]testResponse{
+			"/orgs/org-1/members/some-login": {statusCode: http.StatusNotFound},
+		})
+		defer s.Close()
+
+		c := githubConnector{apiURL: s.URL, logger: logger, orgs: []Org{{Name: "org-1"}}}
+		_, err := c.groupsForOrgs(context.Background(), newClient(), "some-login", "some@email.com")
+
+		expectOnly(t, err, ErrUserNotAuthorized)
+		expectEquals(t, err.Error(), `github: user "some-login" not in required orgs or teams`)
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		s := newTestServer(map[string]testResponse{
+			"/user": {data: map[string]string{"message": "Server Error"}, statusCode: http.StatusBadGateway},
+		})
+		defer s.Close()
+
+		c := githubConnector{apiURL: s.URL, logger: logger}
+		var u user
+		_, err := c.get(context.Background(), newClient(), s.URL+"/user", &u)
+
+		expectOnly(t, err, ErrUpstreamUnavailable)
+		expectEquals(t, strings.Contains(err.Error(), "Server Error"), true)
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer s.Close()
+
+		c := githubConnector{apiURL: s.URL, logger: logger}
+		var u user
+		_, err := c.get(context.Background(), newClient(), s.URL+"/user", &u)
+
+		expectOnly(t, err, ErrRateLimited)
+	})
+
+	t.Run("connection error", func(t *testing.T) {
+		c := githubConnector{apiURL: "https://api.github.invalid", logger: logger}
+		var u user
+		_, err := c.get(context.Background(), &http.Client{Transport: &flakyTransport{failures: 1}}, c.apiURL+"/user", &u)
+
+		expectOnly(t, err, ErrUpstreamUnavailable)
+		var dnsErr *net.DNSError
+		expectEquals(t, errors.As(err, &dnsErr), true)
+	})
+
+	callback := func(t *testing.T, responses map[string]testResponse, configure func(c *githubConnector)) error {
+		responses["/login/oauth/access_token"] = testResponse{data: map[string]interface{}{
+			"access_token": "eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9",
+			"expires_in":   "30",
+		}}
+		s := newTestServer(responses)
+		t.Cleanup(s.Close)
+
+		hostURL, err := url.Parse(s.URL)
+		expectNil(t, err)
+		req, err := http.NewRequest("GET", hostURL.String(), nil)
+		expectNil(t, err)
+
+		c := githubConnector{apiURL: s.URL, hostName: hostURL.Host, httpClient: newClient(), logger: logger}
+		if configure != nil {
+			configure(&c)
+		}
+		_, err = c.HandleCallback(connector.Scopes{}, req)
+		return err
+	}
+
+	t.Run("callback upstream unavailable", func(t *testing.T) {
+		err := callback(t, map[string]testResponse{
+			"/user": {data: map[string]string{"message": "Service Unavailable"}, statusCode: http.StatusServiceUnavailable},
+		}, nil)
+
+		expectOnly(t, err, ErrUpstreamUnavailable)
+	})
+
+	t.Run("callback email domain denied", func(t *testing.T) {
+		err := callback(t, map[string]testResponse{
+			"/user": {data: user{Login: "some-login", ID: 12345678, Email: "some@email.com"}},
+		}, func(c *githubConnector) {
+			c.requiredEmailDomains = []string{"example.com"}
+		})
+
+		expectOnly(t, err, ErrUserNotAuthorized)
+		expectEquals(t, err.Error(), `github: user email "some@email.com" not in required domains`)
+	})
+}
+
+func TestUserInOrg(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		inOrg      bool
+		wantErr    bool
+	}{
+		{name: "member", statusCode: http.StatusNoContent, inOrg: true},
+		{name: "not a member", statusCode: http.StatusNotFound},
+		{name: "requester not a member", statusCode: http.StatusFound},
+		{name: "server error", statusCode: http.StatusInternalServerError, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := newTestServer(map[string]testResponse{
+				"/orgs/org-1/members/some-login": {statusCode: test.statusCode},
+			})
+			defer s.Close()
+
+			c := githubConnector{apiURL: s.URL, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+			inOrg, err := c.userInOrg(context.Background(), newClient(), "some-login", "org-1")
+
+			expectEquals(t, inOrg, test.inOrg)
+			expectEquals(t, err != nil, test.wantErr)
+		})
+	}
+}
+
+func TestSSORequired(t *testing.T) {
+	const ssoURL = "https://github.com/orgs/org-1/sso?authorization_request=abc"
+	sso := http.Header{"X-GitHub-SSO": {"required; url=" + ssoURL}}
+
+	t.Run("membership", func(t *testing.T) {
+		s := newTestServer(map[string]testResponse{
+			"/orgs/org-1/members/some-login": {statusCode: http.StatusNotFound, header: sso},
+		})
+		defer s.Close()
+
+		c := githubConnector{apiURL: s.URL, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+		inOrg, err := c.userInOrg(context.Background(), newClient(), "some-login", "org-1")
+
+		expectEquals(t, inOrg, false)
+		expectNotNil(t, err, "SSO error")
+		expectEquals(t, errors.Is(err, ErrSSORequired), true)
+		expectEquals(t, strings.Contains(err.Error(), `"org-1"`), true)
+		expectEquals(t, strings.Contains(err.Error(), ssoURL), true)
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		s := newTestServer(map[string]testResponse{
+			"/user/teams": {data: map[string]string{"message": "Resource protected by organization SAML enforcement."}, statusCode: http.StatusForbidden, header: sso},
+		})
+		defer s.Close()
+
+		c := githubConnector{apiURL: s.URL}
+		var teams []team
+		_, err := c.get(context.Background(), newClient(), s.URL+"/user/teams", &teams)
+
+		expectNotNil(t, err, "SSO error")
+		expectEquals(t, errors.Is(err, ErrSSORequired), true)
+		expectEquals(t, strings.Contains(err.Error(), ssoURL), true)
+	})
+
+	t.Run("partial results", func(t *testing.T) {
+		s := newTestServer(map[string]testResponse{
+			"/user/orgs": {data: []org{{Login: "org-2"}}, header: http.Header{"X-GitHub-SSO": {"partial-results; organizations=21955855"}}},
+		})
+		defer s.Close()
+
+		c := githubConnector{apiURL: s.URL, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+		orgs, err := c.userOrgs(context.Background(), newClient())
+
+		expectNil(t, err)
+		expectEquals(t, orgs, []string{"org-2"})
+	})
+}
+
+func TestUserInOrgMaintenanceRetry(t *testing.T) {
+	requests := 0
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(map[string]string{"message": "GitHub is down for maintenance"})
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer s.Close()
+
+	c := githubConnector{
+		apiURL:                   s.URL,
+		logger:                   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		maintenanceRetries:       1,
+		maintenanceRetryInterval: time.Millisecond,
+	}
+	inOrg, err := c.userInOrg(context.Background(), newClient(), "some-login", "org-1")
+
+	expectNil(t, err)
+	expectEquals(t, inOrg, true)
+	expectEquals(t, requests, 2)
+}
+
+func Test_retryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		value      string
+		wait       time.Duration
+		ok         bool
+	}{
+		{name: "seconds", statusCode: http.StatusTooManyRequests, value: "5", wait: 5 * time.Second, ok: true},
+		{name: "date in the past", statusCode: http.StatusForbidden, value: "Wed, 21 Oct 2015 07:28:00 GMT", ok: true},
+		{name: "invalid value", statusCode: http.StatusTooManyRequests, value: "soon"},
+		{name: "missing header", statusCode: http.StatusTooManyRequests},
+		{name: "not rate limited", statusCode: http.StatusServiceUnavailable, value: "5"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			header := http.Header{}
+			if test.value != "" {
+				header.Set("Retry-After", test.value)
+			}
+			wait, ok := retryAfter(test.statusCode, header)
+
+			expectEquals(t, wait, test.wait)
+			expectEquals(t, ok, test.ok)
+		})
+	}
+}
+
+func TestRateLimitRetryIgnoresOtherErrors(t *testing.T) {
+	requests := 0
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		// A 403 without rate limit headers, e.g. missing permissions.
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer s.Close()
+
+	c := githubConnector{apiURL: s.URL, maxRetries: 3, retryMaxWait: time.Minute}
+	_, err := c.user(context.Background(), newClient())
+
+	expectNotNil(t, err, "user should fail on a 403")
+	expectEquals(t, requests, 1)
+}
+
+func Test_Open_RetryConfig(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	tests := []struct {
+		name     string
+		config   Config
+		expected error
+	}{
+		{
+			name:   "valid config",
+			config: Config{MaxRetries: 3, RetryMaxWait: "30s"},
+		},
+		{
+			name:     "negative retries",
+			config:   Config{MaxRetries: -1},
+			expected: errors.New("invalid connector config: maxRetries cannot be negative"),
+		},
+		{
+			name:     "invalid wait",
+			config:   Config{RetryMaxWait: "forever"},
+			expected: errors.New(`invalid connector config: retryMaxWait: time: invalid duration "forever"`),
+		},
+		{
+			name:     "zero wait",
+			config:   Config{RetryMaxWait: "0s"},
+			expected: errors.New("invalid connector config: retryMaxWait must be positive"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := test.config.Open("id", log)
+
+			expectEquals(t, err, test.expected)
+		})
+	}
+}
+
+// flakyTransport fails the first failures round trips with a DNS error before
+// passing requests on to next.
+type flakyTransport struct {
+	failures int
+	attempts int
+	next     http.RoundTripper
+}
+
+func (f *flakyTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	f.attempts++
+	if f.attempts <= f.failures {
+		return nil, &net.DNSError{Err: "no such host", Name: r.URL.Host, IsNotFound: true}
+	}
+	return f.next.RoundTrip(r)
+}
+
+func TestConnectionRetry(t *testing.T) {
+	s := newTestServer(map[string]testResponse{
+		"/user": {data: user{Login: "some-login", ID: 12345678, Email: "some@email.com"}},
+	})
+	defer s.Close()
+
+	transport := &flakyTransport{failures: 2, next: newClient().Transport}
+	client := &http.Client{Transport: transport}
+	c := githubConnector{
+		apiURL:                 s.URL,
+		logger:                 slog.New(slog.NewTextHandler(io.Discard, nil)),
+		connectionRetries:      2,
+		connectionRetryBackoff: time.Millisecond,
+	}
+	u, err := c.user(context.Background(), client)
+
+	expectNil(t, err)
+	expectEquals(t, u.Login, "some-login")
+	expectEquals(t, transport.attempts, 3)
+
+	// Once the retries are used up the connection error is returned.
+	transport.attempts = 0
+	c.connectionRetries = 1
+	_, err = c.user(context.Background(), client)
+
+	var dnsErr *net.DNSError
+	expectEquals(t, errors.As(err, &dnsErr), true)
+	expectEquals(t, transport.attempts, 2)
+}
+
+func TestConnectionRetryHonorsContext(t *testing.T) {
+	transport := &flakyTransport{failures: 10}
+	c := githubConnector{
+		apiURL:                 "https://api.github.invalid",
+		logger:                 slog.New(slog.NewTextHandler(io.Discard, nil)),
+		connectionRetries:      5,
+		connectionRetryBackoff: time.Hour,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := c.user(ctx, &http.Client{Transport: transport})
+
+	expectEquals(t, errors.Is(err, context.DeadlineExceeded), true)
+	expectEquals(t, transport.attempts, 1)
+}
+
+func TestConnectionRetryIgnoresHTTPErrors(t *testing.T) {
+	s := newTestServer(map[string]testResponse{
+		"/user": {statusCode: http.StatusInternalServerError},
+	})
+	defer s.Close()
+
+	transport := &flakyTransport{next: newClient().Transport}
+	c := githubConnector{
+		apiURL:                 s.URL,
+		logger:                 slog.New(slog.NewTextHandler(io.Discard, nil)),
+		connectionRetries:      2,
+		connectionRetryBackoff: time.Millisecond,
+	}
+	_, err := c.user(context.Background(), &http.Client{Transport: transport})
+
+	expectNotNil(t, err, "user should fail on an HTTP error")
+	expectEquals(t, transport.attempts, 1)
+}
+
+func TestConnectionRetryTokenExchange(t *testing.T) {
+	s := newTestServer(map[string]testResponse{
+		"/user": {data: user{Login: "some-login", ID: 12345678}},
+		"/user/emails": {data: []userEmail{{
+			Email:    "some@email.com",
+			Verified: true,
+			Primary:  true,
+		}}},
+		"/login/oauth/access_token": {data: map[string]interface{}{
+			"access_token": "eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9",
+			"expires_in":   "30",
+		}},
+	})
+	defer s.Close()
+
+	hostURL, err := url.Parse(s.URL)
+	expectNil(t, err)
+
+	req, err := http.NewRequest("GET", hostURL.String(), nil)
+	expectNil(t, err)
+
+	// The first attempt is the token exchange.
+	transport := &flakyTransport{failures: 1, next: newClient().Transport}
+	c := githubConnector{
+		apiURL:                 s.URL,
+		hostName:               hostURL.Host,
+		httpClient:             &http.Client{Transport: transport},
+		logger:                 slog.New(slog.NewTextHandler(io.Discard, nil)),
+		connectionRetries:      1,
+		connectionRetryBackoff: time.Millisecond,
+	}
+	identity, err := c.HandleCallback(connector.Scopes{}, req)
+
+	expectNil(t, err)
+	expectEquals(t, identity.Username, "some-login")
+}
+
+func Test_Open_ConnectionRetriesConfig(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	c := Config{ConnectionRetries: 3}
+	_, err := c.Open("id", log)
+	expectNil(t, err)
+
+	c.ConnectionRetries = -1
+	_, err = c.Open("id", log)
+	expectEquals(t, err, errors.New("invalid connector config: connectionRetries cannot be negative"))
+}
+
+func TestOrgMembershipNegativeCache(t *testing.T) {
+	checks := map[string]int{}
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/orgs/org-1/members/some-login", "/orgs/org-2/members/some-login":
+			checks[r.URL.Path]++
+			if strings.Contains(r.URL.Path, "org-1") {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		case "/user/teams":
+			w.Header().Add("Content-Type", "application/json")
+			json.NewEncoder(w).Encode([]team{{Name: "team-1", Org: org{Login: "org-2"}}})
+		}
+	}))
+	defer s.Close()
+
+	now := time.Now()
+	c := githubConnector{
+		apiURL:    s.URL,
+		logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+		orgs:      []Org{{Name: "org-1"}, {Name: "org-2"}},
+		orgMisses: newMembershipCache(time.Minute, func() time.Time { return now }),
+	}
+	for i := 0; i < 3; i++ {
+		groups, err := c.groupsForOrgs(context.Background(), newClient(), "some-login", "some@email.com")
+
+		expectNil(t, err)
+		expectEquals(t, groups, []string{"org-2:team-1"})
+	}
+
+	// Misses are only checked once within the TTL, memberships every time.
+	expectEquals(t, checks["/orgs/org-1/members/some-login"], 1)
+	expectEquals(t, checks["/orgs/org-2/members/some-login"], 3)
+
+	now = now.Add(time.Minute)
+	_, err := c.groupsForOrgs(context.Background(), newClient(), "some-login", "some@email.com")
+
+	expectNil(t, err)
+	expectEquals(t, checks["/orgs/org-1/members/some-login"], 2)
+}
+
+func TestMembershipCache(t *testing.T) {
+	now := time.Now()
+	m := newMembershipCache(time.Minute, func() time.Time { return now })
+
+	m.addMiss("user-1", "org-1")
+	expectEquals(t, m.isMiss("user-1", "org-1"), true)
+	expectEquals(t, m.isMiss("user-1", "org-2"), false)
+	expectEquals(t, m.isMiss("user-2", "org-1"), false)
+
+	// Adding a miss drops expired entries.
+	now = now.Add(time.Minute)
+	m.addMiss("user-2", "org-1")
+	expectEquals(t, len(m.misses), 1)
+	expectEquals(t, m.isMiss("user-1", "org-1"), false)
+	expectEquals(t, m.isMiss("user-2", "org-1"), true)
+
+	// A nil cache remembers nothing.
+	var disabled *membershipCache
+	disabled.addMiss("user-1", "org-1")
+	expectEquals(t, disabled.isMiss("user-1", "org-1"), false)
+}
+
+func TestGetGroupsCache(t *testing.T) {
+	requests := 0
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Add("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/user/orgs":
+			json.NewEncoder(w).Encode([]org{{Login: "org-1"}})
+		case "/user/teams":
+			json.NewEncoder(w).Encode([]team{{Name: "team-1", Org: org{Login: "org-1"}}})
+		}
+	}))
+	defer s.Close()
+
+	now := time.Now()
+	c := githubConnector{
+		apiURL:        s.URL,
+		loadAllGroups: true,
+		groupsCache:   newGroupsCache(time.Minute, 10, func() time.Time { return now }),
+	}
+	for i := 0; i < 3; i++ {
+		groups, orgs, err := c.getGroups(context.Background(), newClient(), true, "some-login", "some@email.com")
+
+		expectNil(t, err)
+		expectEquals(t, groups, []string{"org-1", "org-1:team-1"})
+		expectEquals(t, orgs, []string{"org-1"})
+	}
+	expectEquals(t, requests, 2)
+
+	now = now.Add(time.Minute)
+	_, _, err := c.getGroups(context.Background(), newClient(), true, "some-login", "some@email.com")
+
+	expectNil(t, err)
+	expectEquals(t, requests, 4)
+}
+
+func TestGroupsCache(t *testing.T) {
+	now := time.Now()
+	g := newGroupsCache(time.Minute, 2, func() time.Time { return now })
+	user1 := groupsKey{login: "user-1"}
+	user2 := groupsKey{login: "user-2"}
+	user3 := groupsKey{login: "user-3"}
+
+	g.add(user1, []string{"group-1"}, nil)
+	groups, orgs, ok := g.get(user1)
+	expectEquals(t, ok, true)
+	expectEquals(t, groups, []string{"group-1"})
+	expectEquals(t, orgs, []string(nil))
+	_, _, ok = g.get(user2)
+	expectEquals(t, ok, false)
+
+	// A full cache evicts the entry closest to expiry.
+	now = now.Add(time.Second)
+	g.add(user2, []string{"group-2"}, nil)
+	g.add(user3, []string{"group-3"}, nil)
+	expectEquals(t, len(g.entries), 2)
+	_, _, ok = g.get(user1)
+	expectEquals(t, ok, false)
+
+	// Adding an entry drops expired ones.
+	now = now.Add(time.Minute)
+	g.add(user1, []string{"group-1"}, nil)
+	expectEquals(t, len(g.entries), 1)
+
+	// A nil cache remembers nothing.
+	var disabled *groupsCache
+	disabled.add(user1, []string{"group-1"}, nil)
+	_, _, ok = disabled.get(user1)
+	expectEquals(t, ok, false)
+}
+
+func Test_Open_GroupsCacheTTLConfig(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	c := Config{GroupsCacheTTL: "5m"}
+	conn, err := c.Open("id", log)
+	expectNil(t, err)
+	expectEquals(t, conn.(*githubConnector).groupsCache.ttl, 5*time.Minute)
+
+	c.GroupsCacheTTL = "0"
+	conn, err = c.Open("id", log)
+	expectNil(t, err)
+	expectEquals(t, conn.(*githubConnector).groupsCache, (*groupsCache)(nil))
+
+	c.GroupsCacheTTL = "-1m"
+	_, err = c.Open("id", log)
+	expectEquals(t, err, errors.New("invalid connector config: groupsCacheTTL cannot be negative"))
+}
+
+func Test_Open_NegativeCacheTTLConfig(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	tests := []struct {
+		name     string
+		ttl      string
+		expected error
+	}{
+		{
+			name: "valid ttl",
+			ttl:  "30s",
+		},
+		{
+			name:     "invalid ttl",
+			ttl:      "soon",
+			expected: errors.New(`invalid connector config: negativeCacheTTL: time: invalid duration "soon"`),
+		},
+		{
+			name:     "ttl too long",
+			ttl:      "1h",
+			expected: errors.New("invalid connector config: negativeCacheTTL must be positive and at most 5m0s"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := Config{NegativeCacheTTL: test.ttl}
+			_, err := c.Open("id", log)
 
-	u, err := c.user(ctx, client)
-	expectNil(t, err)
-	expectEquals(t, u.Email, "some@email.com")
+			expectEquals(t, err, test.expected)
+		})
+	}
 }
 
-func TestPreferredEmailDomainNotConfigured(t *testing.T) {
-	ctx := context.Background()
+func TestMaxResponseBytes(t *testing.T) {
 	s := newTestServer(map[string]testResponse{
-		"/user": {data: user{Login: "some-login", ID: 12345678, Name: "Joe Bloggs"}},
-		"/user/emails": {
-			data: []userEmail{
-				{
-					Email:    "some@email.com",
-					Verified: true,
-					Primary:  true,
-				},
-				{
-					Email:    "another@email.com",
-					Verified: true,
-					Primary:  false,
-				},
-				{
-					Email:    "some@preferred-domain.com",
-					Verified: true,
-					Primary:  false,
-				},
-			},
-		},
+		"/user":        {data: user{Login: "some-login", ID: 12345678, Email: "some@email.com", Name: strings.Repeat("x", 1024)}},
+		"/user/emails": {data: strings.Repeat("x", 1024), statusCode: http.StatusInternalServerError},
 	})
 	defer s.Close()
 
-	hostURL, err := url.Parse(s.URL)
-	expectNil(t, err)
+	c := githubConnector{apiURL: s.URL, maxResponseBytes: 512}
+	_, err := c.user(context.Background(), newClient())
 
-	client := newClient()
-	c := githubConnector{apiURL: s.URL, hostName: hostURL.Host, httpClient: client}
+	expectEquals(t, err.Error(), "github: response body exceeds 512 bytes")
+
+	// Error bodies are limited as well.
+	var emails []userEmail
+	_, err = c.get(context.Background(), newClient(), s.URL+"/user/emails", &emails)
+
+	expectEquals(t, err, errors.New("github: response body exceeds 512 bytes"))
+
+	c.maxResponseBytes = 2048
+	u, err := c.user(context.Background(), newClient())
 
-	u, err := c.user(ctx, client)
 	expectNil(t, err)
-	expectEquals(t, u.Email, "some@email.com")
+	expectEquals(t, u.Login, "some-login")
 }
 
-func TestPreferredEmailDomainConfigured_Error_BothPrimaryAndPreferredDomainEmailNotFound(t *testing.T) {
-	ctx := context.Background()
-	s := newTestServer(map[string]testResponse{
-		"/user": {data: user{Login: "some-login", ID: 12345678, Name: "Joe Bloggs"}},
-		"/user/emails": {
-			data: []userEmail{
-				{
-					Email:    "some@email.com",
-					Verified: true,
-					Primary:  false,
-				},
-				{
-					Email:    "another@email.com",
-					Verified: true,
-					Primary:  false,
-				},
-				{
-					Email:    "some@preferred-domain.com",
-					Verified: true,
-					Primary:  false,
-				},
-			},
-		},
-	})
-	defer s.Close()
+func Test_Open_MaxResponseBytesConfig(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
 
-	hostURL, err := url.Parse(s.URL)
+	c := Config{}
+	conn, err := c.Open("id", log)
 	expectNil(t, err)
+	expectEquals(t, conn.(*githubConnector).maxResponseBytes, int64(defaultMaxResponseBytes))
 
-	client := newClient()
-	c := githubConnector{apiURL: s.URL, hostName: hostURL.Host, httpClient: client, preferredEmailDomain: "foo.bar"}
+	c.MaxResponseBytes = -1
+	_, err = c.Open("id", log)
+	expectEquals(t, err, errors.New("invalid connector config: maxResponseBytes cannot be negative"))
+}
 
-	_, err = c.user(ctx, client)
-	expectNotNil(t, err, "Email not found error")
-	expectEquals(t, err.Error(), "github: user has no verified, primary email or preferred-domain email")
+func Test_EvaluateOrgAuthorization(t *testing.T) {
+	tests := []struct {
+		name        string
+		orgs        []Org
+		memberships map[string][]string
+		groups      []string
+		authorized  bool
+	}{
+		{
+			name:        "not in any org",
+			orgs:        []Org{{Name: "org-1"}, {Name: "org-2"}},
+			memberships: map[string][]string{},
+			groups:      []string{},
+		},
+		{
+			name:        "in org without teams",
+			orgs:        []Org{{Name: "org-1"}, {Name: "org-2"}},
+			memberships: map[string][]string{"org-2": nil},
+			groups:      []string{},
+			authorized:  true,
+		},
+		{
+			name:        "in org without teams keeps all teams",
+			orgs:        []Org{{Name: "org-1"}},
+			memberships: map[string][]string{"org-1": {"team-1", "team-2"}},
+			groups:      []string{"org-1:team-1", "org-1:team-2"},
+			authorized:  true,
+		},
+		{
+			name:        "in configured team",
+			orgs:        []Org{{Name: "org-1", Teams: []string{"team-1"}}},
+			memberships: map[string][]string{"org-1": {"team-1", "team-2"}},
+			groups:      []string{"org-1:team-1"},
+			authorized:  true,
+		},
+		{
+			name:        "in org but not in configured team",
+			orgs:        []Org{{Name: "org-1", Teams: []string{"team-1"}}},
+			memberships: map[string][]string{"org-1": {"team-2"}},
+			groups:      []string{},
+		},
+		{
+			name: "in configured team of one org only",
+			orgs: []Org{
+				{Name: "org-1", Teams: []string{"team-1"}},
+				{Name: "org-2", Teams: []string{"team-2"}},
+			},
+			memberships: map[string][]string{"org-1": {"team-3"}, "org-2": {"team-2"}},
+			groups:      []string{"org-2:team-2"},
+			authorized:  true,
+		},
+		{
+			name: "in org without teams but not in configured team of other org",
+			orgs: []Org{
+				{Name: "org-1", Teams: []string{"team-1"}},
+				{Name: "org-2"},
+			},
+			memberships: map[string][]string{"org-1": {"team-2"}, "org-2": {"team-3"}},
+			groups:      []string{"org-2:team-3"},
+			authorized:  true,
+		},
+		{
+			name:        "in team matching glob",
+			orgs:        []Org{{Name: "org-1", Teams: []string{"ops", "platform-*"}}},
+			memberships: map[string][]string{"org-1": {"platform-api", "web", "ops"}},
+			groups:      []string{"org-1:platform-api", "org-1:ops"},
+			authorized:  true,
+		},
+		{
+			name:        "in team matching regex",
+			orgs:        []Org{{Name: "org-1", Teams: []string{"regex:team-[0-9]+"}}},
+			memberships: map[string][]string{"org-1": {"team-12", "team-x", "my-team-1"}},
+			groups:      []string{"org-1:team-12"},
+			authorized:  true,
+		},
+		{
+			name:        "in no team matching literal or glob",
+			orgs:        []Org{{Name: "org-1", Teams: []string{"ops", "platform-*"}}},
+			memberships: map[string][]string{"org-1": {"platform", "ops-2"}},
+			groups:      []string{},
+		},
+		{
+			name:        "teams of unconfigured orgs are ignored",
+			orgs:        []Org{{Name: "org-1", Teams: []string{"team-1"}}},
+			memberships: map[string][]string{"org-2": {"team-1"}},
+			groups:      []string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			groups, authorized := EvaluateOrgAuthorization(test.orgs, test.memberships)
+
+			expectEquals(t, groups, test.groups)
+			expectEquals(t, authorized, test.authorized)
+		})
+	}
 }
 
 func Test_isPreferredEmailDomain(t *testing.T) {
 	client := newClient()
 	tests := []struct {
 		preferredEmailDomain string
+		suffixMatch          bool
 		email                string
 		expected             bool
 	}{
@@ -421,6 +3179,31 @@ func Test_isPreferredEmailDomain(t *testing.T) {
 		{
 			preferredEmailDomain: "*.example.com",
 			email:                "test@my.domain.example.com",
+			expected:             true,
+		},
+		{
+			preferredEmailDomain: "*.example.com",
+			email:                "test@example.com",
+			expected:             false,
+		},
+		{
+			preferredEmailDomain: "*.example.com",
+			email:                "test@myexample.com",
+			expected:             false,
+		},
+		{
+			preferredEmailDomain: "*.*.example.com",
+			email:                "test@a.sub.my.example.com",
+			expected:             false,
+		},
+		{
+			preferredEmailDomain: "mail.*.example.com",
+			email:                "test@mail.eng.example.com",
+			expected:             true,
+		},
+		{
+			preferredEmailDomain: "mail.*.example.com",
+			email:                "test@mail.a.eng.example.com",
 			expected:             false,
 		},
 		{
@@ -438,12 +3221,40 @@ func Test_isPreferredEmailDomain(t *testing.T) {
 			email:                "test@a.my.google.com",
 			expected:             false,
 		},
+		{
+			preferredEmailDomain: "example.com",
+			email:                "test@mail.eng.example.com",
+			expected:             false,
+		},
+		{
+			preferredEmailDomain: "example.com",
+			suffixMatch:          true,
+			email:                "test@example.com",
+			expected:             true,
+		},
+		{
+			preferredEmailDomain: "example.com",
+			suffixMatch:          true,
+			email:                "test@mail.eng.example.com",
+			expected:             true,
+		},
+		{
+			preferredEmailDomain: "example.com",
+			suffixMatch:          true,
+			email:                "test@notexample.com",
+			expected:             false,
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.preferredEmailDomain, func(t *testing.T) {
-			c := githubConnector{apiURL: "apiURL", hostName: "github.com", httpClient: client, preferredEmailDomain: test.preferredEmailDomain}
+			c := githubConnector{
+				apiURL:                          "apiURL",
+				hostName:                        "github.com",
+				httpClient:                      client,
+				preferredEmailDomainSuffixMatch: test.suffixMatch,
+			}
 			_, domainPart, _ := strings.Cut(test.email, "@")
-			res := c.isPreferredEmailDomain(domainPart)
+			res := c.isPreferredEmailDomain(test.preferredEmailDomain, domainPart)
 
 			expectEquals(t, res, test.expected)
 		})
@@ -454,6 +3265,7 @@ func Test_Open_PreferredDomainConfig(t *testing.T) {
 	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
 	tests := []struct {
 		preferredEmailDomain string
+		suffixMatch          bool
 		email                string
 		expected             error
 	}{
@@ -473,11 +3285,34 @@ func Test_Open_PreferredDomainConfig(t *testing.T) {
 			preferredEmailDomain: "example.*",
 			expected:             errors.New("invalid PreferredEmailDomain: glob pattern cannot end with \"*\""),
 		},
+		{
+			preferredEmailDomain: "example.com",
+			suffixMatch:          true,
+			expected:             nil,
+		},
+		{
+			preferredEmailDomain: "*.example.com",
+			suffixMatch:          true,
+			expected:             errors.New("invalid PreferredEmailDomain: glob pattern cannot be used with preferredEmailDomainSuffixMatch"),
+		},
+		{
+			suffixMatch: true,
+			expected:    errors.New("invalid connector config: preferredEmailDomainSuffixMatch requires preferredEmailDomain"),
+		},
+		{
+			preferredEmailDomain: "mail*.example.com",
+			expected:             errors.New(`invalid PreferredEmailDomain: "mail*.example.com" must use "*" as a whole label`),
+		},
+		{
+			preferredEmailDomain: "*..example.com",
+			expected:             errors.New(`invalid PreferredEmailDomain: "*..example.com" has an empty label`),
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.preferredEmailDomain, func(t *testing.T) {
 			c := Config{
-				PreferredEmailDomain: test.preferredEmailDomain,
+				PreferredEmailDomain:            test.preferredEmailDomain,
+				PreferredEmailDomainSuffixMatch: test.suffixMatch,
 			}
 			_, err := c.Open("id", log)
 
@@ -500,7 +3335,13 @@ func newTestServer(responses map[string]testResponse) *httptest.Server {
 		if len(linkParts) > 0 {
 			w.Header().Add("Link", strings.Join(linkParts, ", "))
 		}
+		for k, v := range response.header {
+			w.Header()[k] = v
+		}
 		w.Header().Add("Content-Type", "application/json")
+		if response.statusCode != 0 {
+			w.WriteHeader(response.statusCode)
+		}
 		json.NewEncoder(w).Encode(response.data)
 	}))
 	return s
@@ -531,6 +3372,136 @@ func expectEquals(t *testing.T, a interface{}, b interface{}) {
 	}
 }
 
+func TestEnterpriseEmailVerification(t *testing.T) {
+	s := newTestServer(map[string]testResponse{
+		"/user/emails": {data: []userEmail{
+			{Email: "unverified@email.com", Verified: false, Primary: true},
+			{Email: "verified@preferred-domain.com", Verified: true, Primary: false},
+		}},
+	})
+	defer s.Close()
+
+	hostURL, err := url.Parse(s.URL)
+	expectNil(t, err)
+
+	// By default every email of an enterprise user is trusted.
+	c := githubConnector{apiURL: s.URL, hostName: hostURL.Host}
+	email, err := c.userEmail(context.Background(), newClient())
+	expectNil(t, err)
+	expectEquals(t, email, "unverified@email.com")
+
+	// Otherwise the unverified primary email is skipped.
+	c.honorEnterpriseEmailVerification = true
+	_, err = c.userEmail(context.Background(), newClient())
+	expectNotNil(t, err, "userEmail should reject an unverified primary email")
+
+	c.preferredEmailDomain = "preferred-domain.com"
+	email, err = c.userEmail(context.Background(), newClient())
+	expectNil(t, err)
+	expectEquals(t, email, "verified@preferred-domain.com")
+}
+
+func TestEmailStrategy(t *testing.T) {
+	withPrimary := []userEmail{
+		{Email: "unverified@email.com"},
+		{Email: "verified@email.com", Verified: true},
+		{Email: "primary@email.com", Verified: true, Primary: true},
+	}
+	withoutPrimary := []userEmail{
+		{Email: "unverified@email.com", Primary: true},
+		{Email: "verified@email.com", Verified: true},
+		{Email: "another@email.com", Verified: true},
+	}
+	unverified := []userEmail{
+		{Email: "unverified@email.com", Primary: true},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		strategy string
+		emails   []userEmail
+		want     string // empty if an error is expected
+	}{
+		{name: "default with primary", emails: withPrimary, want: "primary@email.com"},
+		{name: "default without primary", emails: withoutPrimary},
+		{name: "primary with primary", strategy: "primary", emails: withPrimary, want: "primary@email.com"},
+		{name: "primary without primary", strategy: "primary", emails: withoutPrimary},
+		{name: "primary-then-any-verified with primary", strategy: "primary-then-any-verified", emails: withPrimary, want: "primary@email.com"},
+		{name: "primary-then-any-verified without primary", strategy: "primary-then-any-verified", emails: withoutPrimary, want: "verified@email.com"},
+		{name: "primary-then-any-verified unverified", strategy: "primary-then-any-verified", emails: unverified},
+		{name: "first-verified with primary", strategy: "first-verified", emails: withPrimary, want: "verified@email.com"},
+		{name: "first-verified without primary", strategy: "first-verified", emails: withoutPrimary, want: "verified@email.com"},
+		{name: "first-verified unverified", strategy: "first-verified", emails: unverified},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newTestServer(map[string]testResponse{
+				"/user/emails": {data: tc.emails},
+			})
+			defer s.Close()
+
+			c := githubConnector{apiURL: s.URL, emailStrategy: tc.strategy}
+			email, err := c.userEmail(context.Background(), newClient())
+			if tc.want == "" {
+				expectNotNil(t, err, "email error")
+				return
+			}
+			expectNil(t, err)
+			expectEquals(t, email, tc.want)
+		})
+	}
+}
+
+func TestEmailStrategyPreferredDomain(t *testing.T) {
+	s := newTestServer(map[string]testResponse{
+		"/user/emails": {data: []userEmail{
+			{Email: "verified@email.com", Verified: true},
+			{Email: "some@preferred-domain.com", Verified: true},
+		}},
+	})
+	defer s.Close()
+
+	// A preferred-domain email is still selected first.
+	c := githubConnector{apiURL: s.URL, emailStrategy: "first-verified", preferredEmailDomain: "preferred-domain.com"}
+	email, err := c.userEmail(context.Background(), newClient())
+	expectNil(t, err)
+	expectEquals(t, email, "some@preferred-domain.com")
+}
+
+func Test_Open_EmailStrategyConfig(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	for _, strategy := range []string{"", "primary", "primary-then-any-verified", "first-verified"} {
+		c := Config{EmailStrategy: strategy}
+		conn, err := c.Open("id", log)
+		expectNil(t, err)
+		expectEquals(t, conn.(*githubConnector).emailStrategy, strategy)
+	}
+
+	c := Config{EmailStrategy: "any"}
+	_, err := c.Open("id", log)
+	expectNotNil(t, err, "invalid emailStrategy error")
+}
+
+func Test_Open_TrustEnterpriseEmailVerificationConfig(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	c := Config{HostName: "github.example.com"}
+	conn, err := c.Open("id", log)
+	expectNil(t, err)
+	expectEquals(t, conn.(*githubConnector).honorEnterpriseEmailVerification, false)
+
+	trust := true
+	c.TrustEnterpriseEmailVerification = &trust
+	conn, err = c.Open("id", log)
+	expectNil(t, err)
+	expectEquals(t, conn.(*githubConnector).honorEnterpriseEmailVerification, false)
+
+	trust = false
+	conn, err = c.Open("id", log)
+	expectNil(t, err)
+	expectEquals(t, conn.(*githubConnector).honorEnterpriseEmailVerification, true)
+}
+
 func TestNoreplyUserEmail(t *testing.T) {
 	ctx := context.Background()
 	privateS := newTestServer(map[string]testResponse{
@@ -598,3 +3569,130 @@ func TestNoreplyUserEmail(t *testing.T) {
 		})
 	}
 }
+
+func TestPing(t *testing.T) {
+	var _ connector.PingConnector = (*githubConnector)(nil)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("success", func(t *testing.T) {
+		s := newTestServer(map[string]testResponse{
+			"/rate_limit": {data: map[string]interface{}{"resources": map[string]interface{}{}}},
+		})
+		defer s.Close()
+
+		c := githubConnector{apiURL: s.URL, httpClient: newClient(), logger: logger}
+		expectNil(t, c.Ping(context.Background()))
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		s := newTestServer(map[string]testResponse{
+			"/rate_limit": {data: map[string]string{"message": "Server Error"}, statusCode: http.StatusServiceUnavailable},
+		})
+		defer s.Close()
+
+		c := githubConnector{apiURL: s.URL, httpClient: newClient(), logger: logger}
+		err := c.Ping(context.Background())
+		expectNotNil(t, err, "ping error")
+		expectEquals(t, errors.Is(err, ErrUpstreamUnavailable), true)
+		expectEquals(t, strings.HasPrefix(err.Error(), "github: ping: "), true)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		s := newTestServer(map[string]testResponse{
+			"/rate_limit": {data: map[string]string{"message": "Bad credentials"}, statusCode: http.StatusUnauthorized},
+		})
+		defer s.Close()
+
+		c := githubConnector{apiURL: s.URL, httpClient: newClient(), logger: logger}
+		err := c.Ping(context.Background())
+		expectNotNil(t, err, "ping error")
+		expectEquals(t, errors.Is(err, ErrUpstreamUnavailable), true)
+	})
+}
+
+func TestRateLimitConnectorData(t *testing.T) {
+	rateLimit := func(remaining int, reset int64) http.Header {
+		return http.Header{
+			"X-Ratelimit-Remaining": {strconv.Itoa(remaining)},
+			"X-Ratelimit-Reset":     {strconv.FormatInt(reset, 10)},
+		}
+	}
+	callback := func(t *testing.T, s connector.Scopes, responses map[string]testResponse) connector.Identity {
+		t.Helper()
+		responses["/login/oauth/access_token"] = testResponse{data: map[string]interface{}{
+			"access_token": "eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9",
+			"expires_in":   "30",
+		}}
+		srv := newTestServer(responses)
+		t.Cleanup(srv.Close)
+
+		hostURL, err := url.Parse(srv.URL)
+		expectNil(t, err)
+		req, err := http.NewRequest("GET", hostURL.String(), nil)
+		expectNil(t, err)
+
+		c := githubConnector{apiURL: srv.URL, hostName: hostURL.Host, httpClient: newClient(), loadAllGroups: true}
+		identity, err := c.HandleCallback(s, req)
+		expectNil(t, err)
+		return identity
+	}
+
+	t.Run("persisted with offline access", func(t *testing.T) {
+		identity := callback(t, connector.Scopes{Groups: true, OfflineAccess: true}, map[string]testResponse{
+			"/user": {
+				data:   user{Login: "some-login", ID: 12345678, Email: "some@email.com"},
+				header: rateLimit(4990, 1700000000),
+			},
+			"/user/orgs": {
+				data:   []org{{Login: "org-1"}},
+				header: rateLimit(4989, 1700000000),
+			},
+			"/user/teams": {
+				data:   []team{},
+				header: rateLimit(4988, 1700000000),
+			},
+		})
+
+		var data connectorData
+		expectNil(t, json.Unmarshal(identity.ConnectorData, &data))
+		expectEquals(t, data.RateLimitRemaining, 4988)
+		expectEquals(t, data.RateLimitReset, int64(1700000000))
+	})
+
+	t.Run("not persisted without offline access", func(t *testing.T) {
+		identity := callback(t, connector.Scopes{}, map[string]testResponse{
+			"/user": {
+				data:   user{Login: "some-login", ID: 12345678, Email: "some@email.com"},
+				header: rateLimit(4990, 1700000000),
+			},
+		})
+		expectEquals(t, len(identity.ConnectorData), 0)
+	})
+
+	t.Run("omitted without headers", func(t *testing.T) {
+		identity := callback(t, connector.Scopes{OfflineAccess: true}, map[string]testResponse{
+			"/user": {data: user{Login: "some-login", ID: 12345678, Email: "some@email.com"}},
+		})
+		expectEquals(t, string(identity.ConnectorData), `{"accessToken":"eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9"}`)
+	})
+
+	t.Run("stored data without rate limit", func(t *testing.T) {
+		var data connectorData
+		expectNil(t, json.Unmarshal([]byte(`{"accessToken":"some-token"}`), &data))
+		expectEquals(t, data, connectorData{AccessToken: "some-token"})
+	})
+
+	t.Run("lowest budget of latest reset", func(t *testing.T) {
+		status := &rateLimitStatus{}
+		status.record(rateLimit(10, 200))
+		status.record(rateLimit(4999, 300))
+		status.record(rateLimit(4998, 300))
+		status.record(rateLimit(4997, 100))
+		status.record(http.Header{"X-Ratelimit-Remaining": {"invalid"}})
+
+		var data connectorData
+		status.apply(&data)
+		expectEquals(t, data.RateLimitRemaining, 4998)
+		expectEquals(t, data.RateLimitReset, int64(300))
+	})
+}
@@ -0,0 +1,170 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// graphQLPageSize is the number of orgs, and of teams per org, requested per
+// page of userGroupsQuery. It's the most GitHub allows.
+const graphQLPageSize = 100
+
+// userGroupsQuery fetches a page of the viewer's orgs along with the teams of
+// each org the user is in.
+//
+// See https://docs.github.com/en/graphql/reference/objects#organization
+var userGroupsQuery = fmt.Sprintf(`query($login: String!, $cursor: String) {
+  viewer {
+    organizations(first: %[1]d, after: $cursor) {
+      pageInfo { hasNextPage endCursor }
+      nodes {
+        login
+        teams(first: %[1]d, userLogins: [$login]) {
+          pageInfo { hasNextPage }
+          nodes { name slug parentTeam { name slug } }
+        }
+      }
+    }
+  }
+}`, graphQLPageSize)
+
+type graphQLPageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+type graphQLTeam struct {
+	Name       string `json:"name"`
+	Slug       string `json:"slug"`
+	ParentTeam *team  `json:"parentTeam"`
+}
+
+// graphQLURL returns the URL of the GraphQL API of the GitHub instance whose
+// REST API is at apiURL. GitHub Enterprise serves it outside of the REST API's
+// path.
+func graphQLURL(apiURL, hostName string) string {
+	if hostName != "" {
+		return "https://" + hostName + "/api/graphql"
+	}
+	return apiURL + "/graphql"
+}
+
+// userOrgTeamsGraphQL returns the same orgs and team claims as userOrgs and
+// userOrgTeams, but fetches them with the GraphQL API.
+func (c *githubConnector) userOrgTeamsGraphQL(ctx context.Context, client *http.Client, userName string) ([]string, map[string][]string, error) {
+	orgs := make([]string, 0)
+	orgTeams := make(map[string][]team)
+	var cursor *string
+	for {
+		var data struct {
+			Viewer struct {
+				Organizations struct {
+					PageInfo graphQLPageInfo `json:"pageInfo"`
+					Nodes    []struct {
+						Login string `json:"login"`
+						Teams struct {
+							PageInfo graphQLPageInfo `json:"pageInfo"`
+							Nodes    []graphQLTeam   `json:"nodes"`
+						} `json:"teams"`
+					} `json:"nodes"`
+				} `json:"organizations"`
+			} `json:"viewer"`
+		}
+		variables := map[string]interface{}{"login": userName, "cursor": cursor}
+		if err := c.graphQL(ctx, client, userGroupsQuery, variables, &data); err != nil {
+			return nil, nil, err
+		}
+
+		for _, o := range data.Viewer.Organizations.Nodes {
+			if c.isExcludedOrg(o.Login) {
+				continue
+			}
+			if o.Teams.PageInfo.HasNextPage {
+				return nil, nil, fmt.Errorf("github: user in more than %d teams of org %q", graphQLPageSize, o.Login)
+			}
+			orgs = append(orgs, o.Login)
+			for _, t := range o.Teams.Nodes {
+				orgTeams[o.Login] = append(orgTeams[o.Login], team{Name: t.Name, Slug: t.Slug, Org: org{Login: o.Login}, Parent: t.ParentTeam})
+			}
+		}
+
+		pageInfo := data.Viewer.Organizations.PageInfo
+		if !pageInfo.HasNextPage {
+			break
+		}
+		cursor = &pageInfo.EndCursor
+	}
+
+	groups, err := c.orgTeamsGroupClaims(ctx, client, userName, orgTeams)
+	if err != nil {
+		return nil, nil, err
+	}
+	return orgs, groups, nil
+}
+
+// graphQL sends query to the GraphQL API and unmarshals the data of the
+// response into v.
+func (c *githubConnector) graphQL(ctx context.Context, client *http.Client, query string, variables map[string]interface{}, v interface{}) error {
+	reqBody, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return fmt.Errorf("github: marshal GraphQL query: %v", err)
+	}
+
+	if c.httpTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.httpTimeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.graphQLURL, bytes.NewReader(reqBody))
+	if err != nil {
+		return fmt.Errorf("github: new req: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var start time.Time
+	if c.metrics != nil {
+		start = time.Now()
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		c.metrics.observeRequest(c.graphQLURL, 0, time.Since(start))
+		return fmt.Errorf("github: post GraphQL query: %w", err)
+	}
+	defer resp.Body.Close()
+	c.metrics.observeRequest(c.graphQLURL, resp.StatusCode, time.Since(start))
+
+	body, err := readBody(resp.Body, c.maxResponseBytes)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &apiError{statusCode: resp.StatusCode, status: resp.Status, header: resp.Header, body: body}
+	}
+
+	var gqlResp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &gqlResp); err != nil {
+		return fmt.Errorf("github: unmarshal GraphQL response: %v", err)
+	}
+	// GitHub may return partial data along with errors, which isn't enough to
+	// build the groups.
+	if len(gqlResp.Errors) > 0 {
+		return fmt.Errorf("github: GraphQL query: %s", gqlResp.Errors[0].Message)
+	}
+	if len(gqlResp.Data) == 0 {
+		return errors.New("github: GraphQL response has no data")
+	}
+	if err := json.Unmarshal(gqlResp.Data, v); err != nil {
+		return fmt.Errorf("github: unmarshal GraphQL data: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUserGroupsGraphQL(t *testing.T) {
+	restRequests := 0
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/graphql" || r.Method != http.MethodPost {
+			restRequests++
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var req struct {
+			Variables struct {
+				Login  string  `json:"login"`
+				Cursor *string `json:"cursor"`
+			} `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		expectEquals(t, req.Variables.Login, "some-login")
+
+		w.Header().Add("Content-Type", "application/json")
+		if req.Variables.Cursor == nil {
+			io.WriteString(w, `{"data": {"viewer": {"organizations": {
+				"pageInfo": {"hasNextPage": true, "endCursor": "page-2"},
+				"nodes": [
+					{"login": "org-1", "teams": {"pageInfo": {"hasNextPage": false}, "nodes": [
+						{"name": "team-1", "slug": "team-1"},
+						{"name": "team-2", "slug": "team-2", "parentTeam": {"name": "team-1", "slug": "team-1"}}
+					]}},
+					{"login": "org-2", "teams": {"pageInfo": {"hasNextPage": false}, "nodes": []}}
+				]
+			}}}}`)
+			return
+		}
+		expectEquals(t, *req.Variables.Cursor, "page-2")
+		io.WriteString(w, `{"data": {"viewer": {"organizations": {
+			"pageInfo": {"hasNextPage": false, "endCursor": "page-3"},
+			"nodes": [
+				{"login": "org-3", "teams": {"pageInfo": {"hasNextPage": false}, "nodes": [{"name": "team-3", "slug": "team-3"}]}}
+			]
+		}}}}`)
+	}))
+	defer s.Close()
+
+	c := githubConnector{apiURL: s.URL, useGraphQL: true, graphQLURL: s.URL + "/graphql"}
+	groups, orgs, err := c.userGroups(context.Background(), newClient(), "some-login")
+
+	expectNil(t, err)
+	expectEquals(t, groups, []string{"org-1", "org-1:team-1", "org-1:team-2", "org-2", "org-3", "org-3:team-3"})
+	expectEquals(t, orgs, []string{"org-1", "org-2", "org-3"})
+	expectEquals(t, restRequests, 0)
+}
+
+func TestUserGroupsGraphQLFallback(t *testing.T) {
+	s := newTestServer(map[string]testResponse{
+		"/graphql":    {data: map[string]interface{}{"errors": []map[string]string{{"message": "Resource not accessible by integration"}}}},
+		"/user/orgs":  {data: []org{{Login: "org-1"}}},
+		"/user/teams": {data: []team{{Name: "team-1", Org: org{Login: "org-1"}}}},
+	})
+	defer s.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	c := githubConnector{apiURL: s.URL, logger: logger, useGraphQL: true, graphQLURL: s.URL + "/graphql"}
+	groups, _, err := c.userGroups(context.Background(), newClient(), "some-login")
+
+	expectNil(t, err)
+	expectEquals(t, groups, []string{"org-1", "org-1:team-1"})
+}
+
+func Test_graphQLURL(t *testing.T) {
+	expectEquals(t, graphQLURL("https://api.github.com", ""), "https://api.github.com/graphql")
+	expectEquals(t, graphQLURL("https://github.example.com/api/v3", "github.example.com"), "https://github.example.com/api/graphql")
+}
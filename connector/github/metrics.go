@@ -0,0 +1,142 @@
+package github
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metrics instruments the connector's requests to the GitHub API. A nil
+// *metrics records nothing.
+type metrics struct {
+	requests    *prometheus.CounterVec
+	duration    prometheus.ObserverVec
+	rateLimited *prometheus.CounterVec
+}
+
+// newMetrics registers the GitHub API metrics with reg, labelled with the ID
+// of the connector. Connectors registering with the same registry share the
+// collectors.
+func newMetrics(reg prometheus.Registerer, connectorID string) (*metrics, error) {
+	requests, err := registerCollector(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "github_api_requests_total",
+		Help: "Count of requests to the GitHub API.",
+	}, []string{"connector", "endpoint", "code"}))
+	if err != nil {
+		return nil, err
+	}
+	duration, err := registerCollector(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "github_api_request_duration_seconds",
+		Help:    "A histogram of latencies for requests to the GitHub API.",
+		Buckets: []float64{.05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"connector", "endpoint"}))
+	if err != nil {
+		return nil, err
+	}
+	rateLimited, err := registerCollector(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "github_api_rate_limited_total",
+		Help: "Count of requests to the GitHub API rejected because of a rate limit.",
+	}, []string{"connector", "endpoint"}))
+	if err != nil {
+		return nil, err
+	}
+
+	labels := prometheus.Labels{"connector": connectorID}
+	return &metrics{
+		requests:    requests.MustCurryWith(labels),
+		duration:    duration.MustCurryWith(labels),
+		rateLimited: rateLimited.MustCurryWith(labels),
+	}, nil
+}
+
+// registerCollector registers c with reg, returning the collector registered
+// before if there is one.
+func registerCollector[T prometheus.Collector](reg prometheus.Registerer, c T) (T, error) {
+	if err := reg.Register(c); err != nil {
+		var alreadyRegistered prometheus.AlreadyRegisteredError
+		if errors.As(err, &alreadyRegistered) {
+			if existing, ok := alreadyRegistered.ExistingCollector.(T); ok {
+				return existing, nil
+			}
+		}
+		return c, err
+	}
+	return c, nil
+}
+
+// RegisterMetrics registers the connector's metrics with reg. Until it's
+// called, the connector records no metrics.
+func (c *githubConnector) RegisterMetrics(reg prometheus.Registerer) error {
+	m, err := newMetrics(reg, c.id)
+	if err != nil {
+		return err
+	}
+	c.metrics = m
+	return nil
+}
+
+// observeRequest records a request to apiURL that took d and either got a
+// response with statusCode or, if statusCode is 0, failed.
+func (m *metrics) observeRequest(apiURL string, statusCode int, d time.Duration) {
+	if m == nil {
+		return
+	}
+	code := "error"
+	if statusCode != 0 {
+		code = strconv.Itoa(statusCode)
+	}
+	endpoint := endpointClass(apiURL)
+	m.requests.WithLabelValues(endpoint, code).Inc()
+	m.duration.WithLabelValues(endpoint).Observe(d.Seconds())
+}
+
+// observeRateLimited records a request to apiURL rejected by a rate limit.
+func (m *metrics) observeRateLimited(apiURL string) {
+	if m == nil {
+		return
+	}
+	m.rateLimited.WithLabelValues(endpointClass(apiURL)).Inc()
+}
+
+// endpointClass returns the kind of GitHub API endpoint apiURL belongs to, so
+// that metrics aren't labelled with user, org or team names.
+func endpointClass(apiURL string) string {
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return "other"
+	}
+	// Drop the base path of GitHub Enterprise APIs, which is "/api/v3" unless
+	// configured otherwise.
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i, part := range parts {
+		if part == "user" || part == "orgs" || part == "graphql" {
+			parts = parts[i:]
+			break
+		}
+	}
+	path := "/" + strings.Join(parts, "/")
+	switch {
+	case path == "/user":
+		return "user"
+	case path == "/user/emails":
+		return "emails"
+	case path == "/user/orgs":
+		return "orgs"
+	case path == "/user/teams":
+		return "teams"
+	case path == "/graphql":
+		return "graphql"
+	case len(parts) == 4 && parts[0] == "orgs" && parts[2] == "members",
+		len(parts) == 6 && parts[0] == "orgs" && parts[2] == "teams" && parts[4] == "memberships":
+		return "membership"
+	case len(parts) >= 3 && parts[0] == "orgs" && parts[2] == "teams":
+		return "teams"
+	case len(parts) == 2 && parts[0] == "orgs":
+		return "orgs"
+	}
+	return "other"
+}
@@ -0,0 +1,90 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics(t *testing.T) {
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case "/user":
+			w.Header().Add("Content-Type", "application/json")
+			w.Write([]byte(`{"login": "some-login", "email": "some@email.com"}`))
+		case "/orgs/org-1/members/some-login":
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer s.Close()
+
+	reg := prometheus.NewRegistry()
+	c := githubConnector{id: "github", apiURL: s.URL}
+	expectNil(t, c.RegisterMetrics(reg))
+
+	_, err := c.user(context.Background(), newClient())
+	expectNil(t, err)
+	_, err = c.userInOrg(context.Background(), newClient(), "some-login", "org-1")
+	expectEquals(t, errors.Is(err, ErrRateLimited), true)
+
+	expectEquals(t, testutil.ToFloat64(c.metrics.requests.WithLabelValues("user", "200")), float64(1))
+	expectEquals(t, testutil.ToFloat64(c.metrics.requests.WithLabelValues("membership", "403")), float64(1))
+	expectEquals(t, testutil.ToFloat64(c.metrics.rateLimited.WithLabelValues("membership")), float64(1))
+	expectEquals(t, testutil.ToFloat64(c.metrics.rateLimited.WithLabelValues("user")), float64(0))
+
+	// A connector opened again with the same ID keeps counting where the
+	// previous one left off.
+	c2 := githubConnector{id: "github", apiURL: s.URL}
+	expectNil(t, c2.RegisterMetrics(reg))
+	_, err = c2.user(context.Background(), newClient())
+	expectNil(t, err)
+
+	expectEquals(t, testutil.ToFloat64(c2.metrics.requests.WithLabelValues("user", "200")), float64(2))
+}
+
+func TestMetricsDisabled(t *testing.T) {
+	s := newTestServer(map[string]testResponse{
+		"/user": {data: user{Login: "some-login", Email: "some@email.com"}},
+	})
+	defer s.Close()
+
+	c := githubConnector{apiURL: s.URL}
+	_, err := c.user(context.Background(), newClient())
+	expectNil(t, err)
+}
+
+func Test_endpointClass(t *testing.T) {
+	tests := []struct {
+		apiURL   string
+		endpoint string
+	}{
+		{"https://api.github.com/user", "user"},
+		{"https://api.github.com/user/emails?per_page=100", "emails"},
+		{"https://api.github.com/user/orgs?page=2", "orgs"},
+		{"https://api.github.com/user/teams", "teams"},
+		{"https://api.github.com/graphql", "graphql"},
+		{"https://github.example.com/api/graphql", "graphql"},
+		{"https://api.github.com/orgs/org-1", "orgs"},
+		{"https://api.github.com/orgs/org-1/members/some-login", "membership"},
+		{"https://api.github.com/orgs/org-1/teams", "teams"},
+		{"https://api.github.com/orgs/org-1/teams/team-1", "teams"},
+		{"https://api.github.com/orgs/org-1/teams/team-1/memberships/some-login", "membership"},
+		{"https://github.example.com/api/v3/user/orgs", "orgs"},
+		{"https://github.example.com/github/api/orgs/org-1/teams", "teams"},
+		{"https://api.github.com/app/installations/42/access_tokens", "other"},
+	}
+	for _, tc := range tests {
+		expectEquals(t, endpointClass(tc.apiURL), tc.endpoint)
+	}
+}
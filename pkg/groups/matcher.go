@@ -0,0 +1,92 @@
+package groups
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// regexPrefix marks a Matcher pattern as a regular expression.
+const regexPrefix = "regex:"
+
+// Matcher matches group names against a list of patterns. Every pattern
+// matches the name equal to it. In addition, patterns holding glob
+// metacharacters match the names the glob matches, using path.Match syntax,
+// e.g. "platform-*", and patterns prefixed with "regex:" match the names fully
+// matched by the regular expression that follows, e.g. "regex:team-[0-9]+".
+// Malformed globs only match the name equal to them.
+type Matcher struct {
+	ignoreCase bool
+	names      map[string]struct{}
+	globs      []string
+	regexps    []*regexp.Regexp
+}
+
+// NewMatcher returns a Matcher for patterns, which compares names
+// case-insensitively if ignoreCase is set. It returns an error if a regular
+// expression is malformed.
+func NewMatcher(patterns []string, ignoreCase bool) (*Matcher, error) {
+	m := &Matcher{ignoreCase: ignoreCase, names: make(map[string]struct{}, len(patterns))}
+	for _, pattern := range patterns {
+		m.names[m.fold(pattern)] = struct{}{}
+
+		if expr, ok := strings.CutPrefix(pattern, regexPrefix); ok {
+			expr = "^(?:" + expr + ")$"
+			if ignoreCase {
+				expr = "(?i)" + expr
+			}
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+			}
+			m.regexps = append(m.regexps, re)
+			continue
+		}
+		if strings.ContainsAny(pattern, `*?[\`) {
+			if _, err := path.Match(pattern, ""); err == nil {
+				m.globs = append(m.globs, m.fold(pattern))
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m *Matcher) fold(s string) string {
+	if m.ignoreCase {
+		return strings.ToLower(s)
+	}
+	return s
+}
+
+// Match reports whether name matches any of the patterns.
+func (m *Matcher) Match(name string) bool {
+	folded := m.fold(name)
+	if _, ok := m.names[folded]; ok {
+		return true
+	}
+	for _, glob := range m.globs {
+		// The globs were validated by NewMatcher.
+		if ok, _ := path.Match(glob, folded); ok {
+			return true
+		}
+	}
+	for _, re := range m.regexps {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns the groups of given that match any of the patterns, in
+// order.
+func (m *Matcher) Filter(given []string) []string {
+	groups := []string{}
+	for _, group := range given {
+		if m.Match(group) {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
@@ -0,0 +1,41 @@
+package groups_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dexidp/dex/pkg/groups"
+)
+
+func TestMatcher(t *testing.T) {
+	cases := map[string]struct {
+		patterns   []string
+		ignoreCase bool
+		given      []string
+		expected   []string
+	}{
+		"literal":                  {patterns: []string{"ops"}, given: []string{"ops", "ops-2", "dev"}, expected: []string{"ops"}},
+		"glob":                     {patterns: []string{"platform-*"}, given: []string{"platform-api", "platform", "web"}, expected: []string{"platform-api"}},
+		"literal and glob":         {patterns: []string{"ops", "platform-?"}, given: []string{"ops", "platform-a", "platform-ab"}, expected: []string{"ops", "platform-a"}},
+		"literal with glob chars":  {patterns: []string{"team[1]"}, given: []string{"team[1]", "team1"}, expected: []string{"team[1]", "team1"}},
+		"malformed glob":           {patterns: []string{"team[1"}, given: []string{"team[1", "team1"}, expected: []string{"team[1"}},
+		"regex":                    {patterns: []string{"regex:team-[0-9]+"}, given: []string{"team-1", "team-12", "team-x", "my-team-1"}, expected: []string{"team-1", "team-12"}},
+		"case sensitive":           {patterns: []string{"Ops", "Platform-*", "regex:Team-.*"}, given: []string{"ops", "platform-a", "team-a"}, expected: []string{}},
+		"case insensitive":         {patterns: []string{"Ops", "Platform-*", "regex:Team-.*"}, ignoreCase: true, given: []string{"ops", "platform-a", "team-a"}, expected: []string{"ops", "platform-a", "team-a"}},
+		"no patterns match nobody": {given: []string{"ops"}, expected: []string{}},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			m, err := groups.NewMatcher(tc.patterns, tc.ignoreCase)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, m.Filter(tc.given))
+		})
+	}
+}
+
+func TestMatcherInvalidPattern(t *testing.T) {
+	_, err := groups.NewMatcher([]string{"regex:team-("}, false)
+	assert.Error(t, err)
+}
@@ -1,14 +1,27 @@
 package server
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"math"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/dexidp/dex/api/v2"
 	"github.com/dexidp/dex/pkg/featureflags"
@@ -18,7 +31,23 @@ import (
 
 // apiVersion increases every time a new call is added to the API. Clients should use this info
 // to determine if the server supports specific features.
-const apiVersion = 2
+//
+// Version 3 added FindClientsByName, GetClientGrantedScopes, ListClients,
+// RotateClientSecret and plaintext passwords.
+// Version 4 added BatchCreateClients.
+// Version 5 added DeleteRefreshTokensForUser.
+// Version 6 added GetClientByName.
+// Version 7 added WatchClients.
+// Version 8 added BatchDeletePasswords.
+const apiVersion = 8
+
+// defaultIdempotencyKeysValidFor is how long API responses are replayed for
+// retries with the same idempotency key if the server doesn't configure it.
+const defaultIdempotencyKeysValidFor = 24 * time.Hour
+
+// defaultClientWatchInterval is how often WatchClients polls storage for
+// changes if the server doesn't configure it.
+const defaultClientWatchInterval = 5 * time.Second
 
 const (
 	// recCost is the recommended bcrypt cost, which balances hash strength and
@@ -31,6 +60,10 @@ const (
 	upBoundCost = 16
 )
 
+// APICallerMetadataKey is the gRPC metadata key API callers identify
+// themselves with in audit logs, e.g. set by a client interceptor.
+const APICallerMetadataKey = "x-dex-caller"
+
 // NewAPI returns a server which implements the gRPC API interface.
 func NewAPI(s storage.Storage, logger *slog.Logger, version string, server *Server) api.DexServer {
 	return dexAPI{
@@ -50,56 +83,505 @@ type dexAPI struct {
 	server  *Server
 }
 
+// audit logs a change made through the API to the server's API audit logger,
+// if any, along with the caller making it.
+func (d dexAPI) audit(ctx context.Context, action string, args ...any) {
+	if d.server == nil || d.server.apiAuditLogger == nil {
+		return
+	}
+	attrs := []any{"action", action, "caller", apiCaller(ctx)}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		attrs = append(attrs, "peer", p.Addr.String())
+	}
+	d.server.apiAuditLogger.InfoContext(ctx, "api call", append(attrs, args...)...)
+}
+
+// apiCaller returns the caller identifier set in the incoming gRPC metadata
+// of ctx, or an empty string.
+func apiCaller(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(APICallerMetadataKey); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// toAPIClient converts a storage client to its API representation.
+func toAPIClient(c storage.Client) *api.Client {
+	return &api.Client{
+		Id:                   c.ID,
+		Name:                 c.Name,
+		Secret:               c.Secret,
+		RedirectUris:         c.RedirectURIs,
+		TrustedPeers:         c.TrustedPeers,
+		Public:               c.Public,
+		LogoUrl:              c.LogoURL,
+		AccessTokenLifetime:  c.AccessTokenLifetime,
+		RefreshTokenLifetime: c.RefreshTokenLifetime,
+		CreatedAt:            unixTime(c.CreatedAt),
+		UpdatedAt:            unixTime(c.UpdatedAt),
+		Disabled:             c.Disabled,
+		AllowedConnectors:    c.AllowedConnectors,
+	}
+}
+
+// unixTime returns t as a Unix timestamp, or zero if t is unset.
+func unixTime(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+// idempotent returns the response of call, recording it under key so that
+// later calls of method with the same key and request get the recorded
+// response instead of calling call again. It also reports whether the response
+// was replayed. Failed calls aren't recorded, so they can be retried. Only a
+// hash of request is recorded, and reusing the key with a different request is
+// an error. If redact is set, it strips secrets from a copy of the response
+// before it is recorded.
+func idempotent[M any, T interface {
+	*M
+	proto.Message
+}](ctx context.Context, d dexAPI, method, key string, request proto.Message, call func() (T, error), redact func(T)) (T, bool, error) {
+	if key == "" {
+		resp, err := call()
+		return resp, false, err
+	}
+	id := method + "/" + key
+	hash, err := requestHash(request)
+	if err != nil {
+		d.logger.Error("failed to hash request", "method", method, "err", err)
+		return nil, false, fmt.Errorf("hash %s request: %v", method, err)
+	}
+
+	now := time.Now()
+	record, err := d.s.GetIdempotencyKey(ctx, id)
+	switch {
+	case err == nil && now.Before(record.Expiry):
+		if !bytes.Equal(record.RequestHash, hash) {
+			return nil, false, status.Errorf(codes.InvalidArgument, "idempotency key %q was used with a different request", key)
+		}
+		resp := T(new(M))
+		if err := proto.Unmarshal(record.Response, resp); err != nil {
+			d.logger.Error("failed to unmarshal recorded response", "method", method, "err", err)
+			return nil, false, fmt.Errorf("replay %s: %v", method, err)
+		}
+		return resp, true, nil
+	case err == nil:
+		// The key expired but hasn't been garbage collected yet, so it's free
+		// to be used again.
+		if err := d.s.DeleteIdempotencyKey(ctx, id); err != nil && err != storage.ErrNotFound {
+			d.logger.Error("failed to delete expired idempotency key", "method", method, "err", err)
+			return nil, false, fmt.Errorf("delete idempotency key: %v", err)
+		}
+	case err != storage.ErrNotFound:
+		d.logger.Error("failed to get idempotency key", "method", method, "err", err)
+		return nil, false, fmt.Errorf("get idempotency key: %v", err)
+	}
+
+	resp, err := call()
+	if err != nil {
+		return nil, false, err
+	}
+
+	recorded := resp
+	if redact != nil {
+		recorded = proto.Clone(resp).(T)
+		redact(recorded)
+	}
+	data, err := proto.Marshal(recorded)
+	if err != nil {
+		d.logger.Error("failed to marshal response", "method", method, "err", err)
+		return resp, false, nil
+	}
+	validFor := defaultIdempotencyKeysValidFor
+	if d.server != nil && d.server.idempotencyValidFor > 0 {
+		validFor = d.server.idempotencyValidFor
+	}
+	// The call already succeeded, so failing to record it is only logged. A
+	// concurrent call with the same key may have recorded its response first.
+	err = d.s.CreateIdempotencyKey(ctx, storage.IdempotencyKey{
+		Key:         id,
+		Response:    data,
+		RequestHash: hash,
+		Expiry:      now.Add(validFor),
+	})
+	if err != nil && err != storage.ErrAlreadyExists {
+		d.logger.Error("failed to create idempotency key", "method", method, "err", err)
+	}
+	return resp, false, nil
+}
+
+// requestHash returns a SHA-256 hash of the deterministic encoding of request.
+func requestHash(request proto.Message) ([]byte, error) {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	return sum[:], nil
+}
+
 func (d dexAPI) GetClient(ctx context.Context, req *api.GetClientReq) (*api.GetClientResp, error) {
 	c, err := d.s.GetClient(ctx, req.Id)
 	if err != nil {
-		return nil, err
+		if err == storage.ErrNotFound {
+			return &api.GetClientResp{NotFound: true}, nil
+		}
+		d.logger.Error("failed to get client", "err", err)
+		return nil, fmt.Errorf("get client: %v", err)
 	}
 
 	return &api.GetClientResp{
-		Client: &api.Client{
-			Id:           c.ID,
-			Name:         c.Name,
-			Secret:       c.Secret,
-			RedirectUris: c.RedirectURIs,
-			TrustedPeers: c.TrustedPeers,
-			Public:       c.Public,
-			LogoUrl:      c.LogoURL,
-		},
+		Client: toAPIClient(c),
 	}, nil
 }
 
+func (d dexAPI) FindClientsByName(ctx context.Context, req *api.FindClientsByNameReq) (*api.FindClientsByNameResp, error) {
+	clients, err := d.s.GetClientsByName(ctx, req.Name, req.CaseInsensitive)
+	if err != nil {
+		d.logger.Error("failed to find clients by name", "err", err)
+		return nil, fmt.Errorf("find clients by name: %v", err)
+	}
+
+	apiClients := make([]*api.Client, 0, len(clients))
+	for _, c := range clients {
+		apiClients = append(apiClients, toAPIClient(c))
+	}
+
+	return &api.FindClientsByNameResp{
+		Clients: apiClients,
+	}, nil
+}
+
+func (d dexAPI) GetClientByName(ctx context.Context, req *api.GetClientByNameReq) (*api.GetClientByNameResp, error) {
+	if req.Name == "" {
+		return nil, errors.New("no client name supplied")
+	}
+
+	clients, err := d.s.GetClientsByName(ctx, req.Name, false)
+	if err != nil {
+		d.logger.Error("failed to get client by name", "err", err)
+		return nil, fmt.Errorf("get client by name: %v", err)
+	}
+
+	switch len(clients) {
+	case 0:
+		return &api.GetClientByNameResp{NotFound: true}, nil
+	case 1:
+		return &api.GetClientByNameResp{Client: toAPIClient(clients[0])}, nil
+	default:
+		return &api.GetClientByNameResp{Ambiguous: true}, nil
+	}
+}
+
+// defaultPageSize and maxPageSize bound the number of results returned by a
+// paginated list call.
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+// paginate sorts items by key and returns the page requested along with the
+// token of the following one. The page token is the key of the last item of
+// the previous page, so items added or removed between calls neither shift
+// nor repeat the remaining results.
+func paginate[T any](items []T, key func(T) string, page *api.PageRequest) ([]T, *api.PageResponse, error) {
+	pageSize := int(page.GetPageSize())
+	if pageSize < 0 || pageSize > maxPageSize {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "page size must be between 1 and %d", maxPageSize)
+	}
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+	after, err := base64.RawURLEncoding.DecodeString(page.GetPageToken())
+	if err != nil {
+		return nil, nil, status.Error(codes.InvalidArgument, "invalid page token")
+	}
+
+	slices.SortFunc(items, func(a, b T) int {
+		return strings.Compare(key(a), key(b))
+	})
+	if page.GetPageToken() != "" {
+		start, _ := slices.BinarySearchFunc(items, string(after), func(item T, k string) int {
+			if key(item) <= k {
+				return -1
+			}
+			return 1
+		})
+		items = items[start:]
+	}
+
+	resp := &api.PageResponse{}
+	if len(items) > pageSize {
+		items = items[:pageSize]
+		resp.NextPageToken = base64.RawURLEncoding.EncodeToString([]byte(key(items[pageSize-1])))
+	}
+	return items, resp, nil
+}
+
+func (d dexAPI) ListClients(ctx context.Context, req *api.ListClientsReq) (*api.ListClientsResp, error) {
+	clients, err := d.s.ListClients(ctx)
+	if err != nil {
+		d.logger.Error("failed to list clients", "err", err)
+		return nil, fmt.Errorf("list clients: %v", err)
+	}
+	// Filter before paginating so page tokens refer to the filtered view.
+	if req.Public != nil {
+		clients = slices.DeleteFunc(clients, func(c storage.Client) bool { return c.Public != *req.Public })
+	}
+	clients, page, err := paginate(clients, func(c storage.Client) string { return c.ID }, req.Page)
+	if err != nil {
+		return nil, err
+	}
+
+	// Listings tend to end up in dashboards and audit logs, so leave secrets
+	// to GetClient.
+	apiClients := make([]*api.Client, 0, len(clients))
+	for _, c := range clients {
+		client := toAPIClient(c)
+		client.Secret = ""
+		apiClients = append(apiClients, client)
+	}
+
+	return &api.ListClientsResp{
+		Clients: apiClients,
+		Page:    page,
+	}, nil
+}
+
+func (d dexAPI) WatchClients(req *api.WatchClientsReq, stream grpc.ServerStreamingServer[api.ClientEvent]) error {
+	ctx := stream.Context()
+	interval := defaultClientWatchInterval
+	if d.server != nil && d.server.clientWatchInterval > 0 {
+		interval = d.server.clientWatchInterval
+	}
+
+	clients, err := d.clientsByID(ctx)
+	if err != nil {
+		return err
+	}
+	// Tell the caller changes from now on are reported.
+	if err := stream.SendHeader(nil); err != nil {
+		return err
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		current, err := d.clientsByID(ctx)
+		if err != nil {
+			return err
+		}
+		for _, event := range diffClients(clients, current) {
+			// Clients are diffed with their secrets so rotations are reported,
+			// but like ListClients, events don't carry them.
+			event.Client = proto.Clone(event.Client).(*api.Client)
+			event.Client.Secret = ""
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+		clients = current
+	}
+}
+
+// clientsByID returns all clients, keyed by ID, in their API representation.
+func (d dexAPI) clientsByID(ctx context.Context) (map[string]*api.Client, error) {
+	clients, err := d.s.ListClients(ctx)
+	if err != nil {
+		d.logger.Error("failed to list clients", "err", err)
+		return nil, fmt.Errorf("list clients: %v", err)
+	}
+	byID := make(map[string]*api.Client, len(clients))
+	for _, c := range clients {
+		byID[c.ID] = toAPIClient(c)
+	}
+	return byID, nil
+}
+
+// diffClients returns the events turning the previous clients into the current
+// ones, ordered by client ID.
+func diffClients(prev, cur map[string]*api.Client) []*api.ClientEvent {
+	ids := make([]string, 0, len(cur))
+	for id := range cur {
+		ids = append(ids, id)
+	}
+	for id := range prev {
+		if _, ok := cur[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+
+	var events []*api.ClientEvent
+	for _, id := range ids {
+		before, existed := prev[id]
+		after, exists := cur[id]
+		switch {
+		case !existed:
+			events = append(events, &api.ClientEvent{Type: api.ClientEvent_CREATED, Client: after})
+		case !exists:
+			events = append(events, &api.ClientEvent{Type: api.ClientEvent_DELETED, Client: before})
+		case !proto.Equal(before, after):
+			events = append(events, &api.ClientEvent{Type: api.ClientEvent_UPDATED, Client: after})
+		}
+	}
+	return events
+}
+
 func (d dexAPI) CreateClient(ctx context.Context, req *api.CreateClientReq) (*api.CreateClientResp, error) {
+	resp, replayed, err := idempotent(ctx, d, "CreateClient", req.IdempotencyKey, req, func() (*api.CreateClientResp, error) {
+		return d.checkAndCreateClient(ctx, req)
+	}, func(resp *api.CreateClientResp) {
+		// The secret is only kept with the client.
+		if resp.Client != nil {
+			resp.Client.Secret = ""
+		}
+	})
+	if err != nil || !replayed || resp.AlreadyExists || resp.Client == nil {
+		return resp, err
+	}
+
+	// Recorded responses don't hold the secret of the created client, read it
+	// back from the client.
+	c, err := d.s.GetClient(ctx, resp.Client.Id)
+	switch {
+	case err == nil:
+		resp.Client.Secret = c.Secret
+	case err != storage.ErrNotFound:
+		d.logger.Error("failed to get client", "err", err)
+		return nil, fmt.Errorf("get client: %v", err)
+	}
+	return resp, nil
+}
+
+// checkAndCreateClient validates and stores the client of a CreateClient
+// request.
+func (d dexAPI) checkAndCreateClient(ctx context.Context, req *api.CreateClientReq) (*api.CreateClientResp, error) {
 	if req.Client == nil {
 		return nil, errors.New("no client supplied")
 	}
 
-	if req.Client.Id == "" {
-		req.Client.Id = storage.NewID()
+	if err := d.checkAccessTokenLifetime(req.Client.AccessTokenLifetime); err != nil {
+		return nil, err
+	}
+	if err := checkRefreshTokenLifetime(req.Client.RefreshTokenLifetime); err != nil {
+		return nil, err
+	}
+	if err := d.checkNewClientSecret(req.Client); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return d.createClient(ctx, req.Client)
+}
+
+func (d dexAPI) BatchCreateClients(ctx context.Context, req *api.BatchCreateClientsReq) (*api.BatchCreateClientsResp, error) {
+	// Validate the whole batch first so an invalid client doesn't leave it
+	// half created.
+	for i, c := range req.Clients {
+		if c == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "client %d: no client supplied", i)
+		}
+		if err := d.checkAccessTokenLifetime(c.AccessTokenLifetime); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "client %d: %v", i, err)
+		}
+		if err := checkRefreshTokenLifetime(c.RefreshTokenLifetime); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "client %d: %v", i, err)
+		}
+		if err := d.checkNewClientSecret(c); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "client %d: %v", i, err)
+		}
+	}
+
+	// Storage has no transactions, so report failures per client rather than
+	// losing the results, and generated secrets, of the clients already created.
+	results := make([]*api.CreateClientResp, 0, len(req.Clients))
+	for _, c := range req.Clients {
+		resp, err := d.createClient(ctx, c)
+		if err != nil {
+			resp = &api.CreateClientResp{Error: err.Error()}
+		}
+		results = append(results, resp)
+	}
+
+	return &api.BatchCreateClientsResp{
+		Results: results,
+	}, nil
+}
+
+// checkNewClientSecret returns an error if the secret supplied for a new
+// client doesn't meet the server's client secret policy.
+func (d dexAPI) checkNewClientSecret(c *api.Client) error {
+	// Public clients have no secret to protect.
+	if c.Secret == "" || c.Public {
+		return nil
+	}
+	return d.checkClientSecret(c.Secret)
+}
+
+// createClient stores a validated client, generating its ID and secret if
+// they weren't supplied.
+func (d dexAPI) createClient(ctx context.Context, client *api.Client) (*api.CreateClientResp, error) {
+	if client.Id == "" {
+		client.Id = storage.NewID()
 	}
-	if req.Client.Secret == "" && !req.Client.Public {
-		req.Client.Secret = storage.NewID() + storage.NewID()
+	if client.Secret == "" && !client.Public {
+		client.Secret = storage.NewID() + storage.NewID()
 	}
 
+	now := time.Now()
 	c := storage.Client{
-		ID:           req.Client.Id,
-		Secret:       req.Client.Secret,
-		RedirectURIs: req.Client.RedirectUris,
-		TrustedPeers: req.Client.TrustedPeers,
-		Public:       req.Client.Public,
-		Name:         req.Client.Name,
-		LogoURL:      req.Client.LogoUrl,
+		ID:                   client.Id,
+		Secret:               client.Secret,
+		RedirectURIs:         client.RedirectUris,
+		TrustedPeers:         client.TrustedPeers,
+		Public:               client.Public,
+		Name:                 client.Name,
+		LogoURL:              client.LogoUrl,
+		AccessTokenLifetime:  client.AccessTokenLifetime,
+		RefreshTokenLifetime: client.RefreshTokenLifetime,
+		Disabled:             client.Disabled,
+		AllowedConnectors:    client.AllowedConnectors,
+		CreatedAt:            now,
+		UpdatedAt:            now,
 	}
 	if err := d.s.CreateClient(ctx, c); err != nil {
 		if err == storage.ErrAlreadyExists {
-			return &api.CreateClientResp{AlreadyExists: true}, nil
+			return d.existingClient(ctx, c.ID)
 		}
 		d.logger.Error("failed to create client", "err", err)
 		return nil, fmt.Errorf("create client: %v", err)
 	}
+	d.audit(ctx, "create client", "client_id", c.ID)
+
+	return &api.CreateClientResp{
+		Client: toAPIClient(c),
+	}, nil
+}
+
+// existingClient reports that the client with the given ID already exists,
+// returning it without its secret.
+func (d dexAPI) existingClient(ctx context.Context, id string) (*api.CreateClientResp, error) {
+	existing, err := d.s.GetClient(ctx, id)
+	if err != nil {
+		d.logger.Error("failed to get existing client", "err", err)
+		return nil, fmt.Errorf("create client: %v", err)
+	}
+	existing.Secret = ""
 
 	return &api.CreateClientResp{
-		Client: req.Client,
+		AlreadyExists: true,
+		Client:        toAPIClient(existing),
 	}, nil
 }
 
@@ -107,6 +589,15 @@ func (d dexAPI) UpdateClient(ctx context.Context, req *api.UpdateClientReq) (*ap
 	if req.Id == "" {
 		return nil, errors.New("update client: no client ID supplied")
 	}
+	if err := d.checkAccessTokenLifetime(req.GetAccessTokenLifetime()); err != nil {
+		return nil, fmt.Errorf("update client: %v", err)
+	}
+	if err := checkRefreshTokenLifetime(req.GetRefreshTokenLifetime()); err != nil {
+		return nil, fmt.Errorf("update client: %v", err)
+	}
+	if req.ClearAllowedConnectors && len(req.AllowedConnectors) > 0 {
+		return nil, errors.New("update client: allowed connectors can't be both set and cleared")
+	}
 
 	err := d.s.UpdateClient(ctx, req.Id, func(old storage.Client) (storage.Client, error) {
 		if req.RedirectUris != nil {
@@ -121,6 +612,21 @@ func (d dexAPI) UpdateClient(ctx context.Context, req *api.UpdateClientReq) (*ap
 		if req.LogoUrl != "" {
 			old.LogoURL = req.LogoUrl
 		}
+		if req.AccessTokenLifetime != nil {
+			old.AccessTokenLifetime = *req.AccessTokenLifetime
+		}
+		if req.RefreshTokenLifetime != nil {
+			old.RefreshTokenLifetime = *req.RefreshTokenLifetime
+		}
+		if req.Disabled != nil {
+			old.Disabled = *req.Disabled
+		}
+		if req.ClearAllowedConnectors {
+			old.AllowedConnectors = nil
+		} else if len(req.AllowedConnectors) > 0 {
+			old.AllowedConnectors = req.AllowedConnectors
+		}
+		old.UpdatedAt = time.Now()
 		return old, nil
 	})
 	if err != nil {
@@ -130,9 +636,52 @@ func (d dexAPI) UpdateClient(ctx context.Context, req *api.UpdateClientReq) (*ap
 		d.logger.Error("failed to update the client", "err", err)
 		return nil, fmt.Errorf("update client: %v", err)
 	}
+	d.audit(ctx, "update client", "client_id", req.Id)
 	return &api.UpdateClientResp{}, nil
 }
 
+// errPublicClient is returned when rotating the secret of a public client.
+var errPublicClient = errors.New("public clients have no secret")
+
+func (d dexAPI) RotateClientSecret(ctx context.Context, req *api.RotateClientSecretReq) (*api.RotateClientSecretResp, error) {
+	if req.Id == "" {
+		return nil, errors.New("rotate client secret: no client ID supplied")
+	}
+
+	secret := req.NewSecret
+	if secret == "" {
+		secret = storage.NewID() + storage.NewID()
+	} else if err := d.checkClientSecret(secret); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var c storage.Client
+	err := d.s.UpdateClient(ctx, req.Id, func(old storage.Client) (storage.Client, error) {
+		if old.Public {
+			return old, errPublicClient
+		}
+		old.Secret = secret
+		old.UpdatedAt = time.Now()
+		c = old
+		return old, nil
+	})
+	if err != nil {
+		switch {
+		case err == storage.ErrNotFound:
+			return &api.RotateClientSecretResp{NotFound: true}, nil
+		case errors.Is(err, errPublicClient):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		d.logger.Error("failed to rotate the client secret", "err", err)
+		return nil, fmt.Errorf("rotate client secret: %v", err)
+	}
+	d.audit(ctx, "rotate client secret", "client_id", req.Id)
+
+	return &api.RotateClientSecretResp{
+		Client: toAPIClient(c),
+	}, nil
+}
+
 func (d dexAPI) DeleteClient(ctx context.Context, req *api.DeleteClientReq) (*api.DeleteClientResp, error) {
 	err := d.s.DeleteClient(ctx, req.Id)
 	if err != nil {
@@ -142,9 +691,82 @@ func (d dexAPI) DeleteClient(ctx context.Context, req *api.DeleteClientReq) (*ap
 		d.logger.Error("failed to delete client", "err", err)
 		return nil, fmt.Errorf("delete client: %v", err)
 	}
+	d.audit(ctx, "delete client", "client_id", req.Id)
 	return &api.DeleteClientResp{}, nil
 }
 
+// checkAccessTokenLifetime returns an error if the access token lifetime, in
+// seconds, is negative or above the server's configured maximum.
+func (d dexAPI) checkAccessTokenLifetime(lifetime int64) error {
+	if lifetime < 0 {
+		return fmt.Errorf("access token lifetime must not be negative, got %d", lifetime)
+	}
+	if d.server == nil || d.server.maxAccessTokenLifetime <= 0 {
+		return nil
+	}
+	if maxLifetime := int64(d.server.maxAccessTokenLifetime / time.Second); lifetime > maxLifetime {
+		return fmt.Errorf("access token lifetime = %d is above the maximum allowed lifetime = %d", lifetime, maxLifetime)
+	}
+	return nil
+}
+
+// checkRefreshTokenLifetime returns an error if the refresh token lifetime, in
+// seconds, is negative.
+func checkRefreshTokenLifetime(lifetime int64) error {
+	if lifetime < 0 {
+		return fmt.Errorf("refresh token lifetime must not be negative, got %d", lifetime)
+	}
+	return nil
+}
+
+// checkClientSecret returns an error if the secret is shorter or carries less
+// entropy than the server's client secret policy requires.
+func (d dexAPI) checkClientSecret(secret string) error {
+	if d.server == nil {
+		return nil
+	}
+	if minLength := d.server.minClientSecretLength; minLength > 0 && len(secret) < minLength {
+		return fmt.Errorf("client secret is %d characters long, the minimum is %d", len(secret), minLength)
+	}
+	if minEntropy := d.server.minClientSecretEntropy; minEntropy > 0 {
+		if entropy := secretEntropy(secret); entropy < minEntropy {
+			return fmt.Errorf("client secret entropy of %.1f bits is below the minimum of %.1f bits", entropy, minEntropy)
+		}
+	}
+	return nil
+}
+
+// secretEntropy estimates the entropy of a secret in bits from the frequency of
+// its characters.
+func secretEntropy(secret string) float64 {
+	counts := make(map[rune]int)
+	n := 0
+	for _, r := range secret {
+		counts[r]++
+		n++
+	}
+	var perChar float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		perChar -= p * math.Log2(p)
+	}
+	return perChar * float64(n)
+}
+
+// hashPassword hashes a password supplied in plain text with the configured
+// bcrypt cost, recCost by default.
+func (d dexAPI) hashPassword(plaintext string) ([]byte, error) {
+	cost := recCost
+	if d.server != nil && d.server.passwordHashCost != 0 {
+		cost = d.server.passwordHashCost
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(plaintext), cost)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "hashing password: %v", err)
+	}
+	return hash, nil
+}
+
 // checkCost returns an error if the hash provided does not meet lower or upper
 // bound cost requirements.
 func checkCost(hash []byte) error {
@@ -162,22 +784,45 @@ func checkCost(hash []byte) error {
 }
 
 func (d dexAPI) CreatePassword(ctx context.Context, req *api.CreatePasswordReq) (*api.CreatePasswordResp, error) {
+	// A plain hash of the plaintext password could be guessed offline, so it
+	// is left out of the recorded request hash.
+	request := proto.Clone(req).(*api.CreatePasswordReq)
+	request.Plaintext = ""
+	resp, _, err := idempotent(ctx, d, "CreatePassword", req.IdempotencyKey, request, func() (*api.CreatePasswordResp, error) {
+		return d.createPassword(ctx, req)
+	}, nil)
+	return resp, err
+}
+
+// createPassword validates and stores the password of a CreatePassword
+// request.
+func (d dexAPI) createPassword(ctx context.Context, req *api.CreatePasswordReq) (*api.CreatePasswordResp, error) {
 	if req.Password == nil {
 		return nil, errors.New("no password supplied")
 	}
 	if req.Password.UserId == "" {
 		return nil, errors.New("no user ID supplied")
 	}
-	if req.Password.Hash != nil {
+	switch {
+	case req.Plaintext != "":
+		if len(req.Password.Hash) > 0 {
+			return nil, status.Error(codes.InvalidArgument, "both a hash and a plaintext password supplied")
+		}
+		hash, err := d.hashPassword(req.Plaintext)
+		if err != nil {
+			return nil, err
+		}
+		req.Password.Hash = hash
+	case req.Password.Hash != nil:
 		if err := checkCost(req.Password.Hash); err != nil {
 			return nil, err
 		}
-	} else {
+	default:
 		return nil, errors.New("no hash of password supplied")
 	}
 
 	p := storage.Password{
-		Email:    req.Password.Email,
+		Email:    normalizeEmail(req.Password.Email),
 		Hash:     req.Password.Hash,
 		Username: req.Password.Username,
 		UserID:   req.Password.UserId,
@@ -189,19 +834,31 @@ func (d dexAPI) CreatePassword(ctx context.Context, req *api.CreatePasswordReq)
 		d.logger.Error("failed to create password", "err", err)
 		return nil, fmt.Errorf("create password: %v", err)
 	}
+	d.audit(ctx, "create password", "email", p.Email)
 
 	return &api.CreatePasswordResp{}, nil
 }
 
 func (d dexAPI) UpdatePassword(ctx context.Context, req *api.UpdatePasswordReq) (*api.UpdatePasswordResp, error) {
-	if req.Email == "" {
+	email := normalizeEmail(req.Email)
+	if email == "" {
 		return nil, errors.New("no email supplied")
 	}
-	if req.NewHash == nil && req.NewUsername == "" {
+	if req.NewHash == nil && req.Plaintext == "" && req.NewUsername == "" {
 		return nil, errors.New("nothing to update")
 	}
 
-	if req.NewHash != nil {
+	switch {
+	case req.Plaintext != "":
+		if len(req.NewHash) > 0 {
+			return nil, status.Error(codes.InvalidArgument, "both a hash and a plaintext password supplied")
+		}
+		hash, err := d.hashPassword(req.Plaintext)
+		if err != nil {
+			return nil, err
+		}
+		req.NewHash = hash
+	case req.NewHash != nil:
 		if err := checkCost(req.NewHash); err != nil {
 			return nil, err
 		}
@@ -219,23 +876,25 @@ func (d dexAPI) UpdatePassword(ctx context.Context, req *api.UpdatePasswordReq)
 		return old, nil
 	}
 
-	if err := d.s.UpdatePassword(ctx, req.Email, updater); err != nil {
+	if err := d.s.UpdatePassword(ctx, email, updater); err != nil {
 		if err == storage.ErrNotFound {
 			return &api.UpdatePasswordResp{NotFound: true}, nil
 		}
 		d.logger.Error("failed to update password", "err", err)
 		return nil, fmt.Errorf("update password: %v", err)
 	}
+	d.audit(ctx, "update password", "email", email)
 
 	return &api.UpdatePasswordResp{}, nil
 }
 
 func (d dexAPI) DeletePassword(ctx context.Context, req *api.DeletePasswordReq) (*api.DeletePasswordResp, error) {
-	if req.Email == "" {
+	email := normalizeEmail(req.Email)
+	if email == "" {
 		return nil, errors.New("no email supplied")
 	}
 
-	err := d.s.DeletePassword(ctx, req.Email)
+	err := d.s.DeletePassword(ctx, email)
 	if err != nil {
 		if err == storage.ErrNotFound {
 			return &api.DeletePasswordResp{NotFound: true}, nil
@@ -243,9 +902,42 @@ func (d dexAPI) DeletePassword(ctx context.Context, req *api.DeletePasswordReq)
 		d.logger.Error("failed to delete password", "err", err)
 		return nil, fmt.Errorf("delete password: %v", err)
 	}
+	d.audit(ctx, "delete password", "email", email)
 	return &api.DeletePasswordResp{}, nil
 }
 
+func (d dexAPI) BatchDeletePasswords(ctx context.Context, req *api.BatchDeletePasswordsReq) (*api.BatchDeletePasswordsResp, error) {
+	// Validate the whole batch first so an invalid email doesn't leave it
+	// half deleted.
+	for i, email := range req.Emails {
+		if normalizeEmail(email) == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "email %d: no email supplied", i)
+		}
+	}
+
+	// Storage has no transactions, so report failures per email rather than
+	// losing the results of the deletions already done.
+	results := make([]*api.DeletePasswordResp, 0, len(req.Emails))
+	for _, email := range req.Emails {
+		resp, err := d.DeletePassword(ctx, &api.DeletePasswordReq{Email: email})
+		if err != nil {
+			resp = &api.DeletePasswordResp{Error: err.Error()}
+		}
+		results = append(results, resp)
+	}
+
+	return &api.BatchDeletePasswordsResp{
+		Results: results,
+	}, nil
+}
+
+// normalizeEmail returns the canonical form of a password's email, which is
+// how passwords are keyed. Storage already compares emails case-insensitively,
+// so records created with mixed-case emails remain reachable.
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
 func (d dexAPI) GetVersion(ctx context.Context, req *api.VersionReq) (*api.VersionResp, error) {
 	return &api.VersionResp{
 		Server: d.version,
@@ -273,6 +965,13 @@ func (d dexAPI) ListPasswords(ctx context.Context, req *api.ListPasswordReq) (*a
 		d.logger.Error("failed to list passwords", "err", err)
 		return nil, fmt.Errorf("list passwords: %v", err)
 	}
+	var page *api.PageResponse
+	if req.Page != nil {
+		passwordList, page, err = paginate(passwordList, func(p storage.Password) string { return p.Email }, req.Page)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	passwords := make([]*api.Password, 0, len(passwordList))
 	for _, password := range passwordList {
@@ -286,11 +985,13 @@ func (d dexAPI) ListPasswords(ctx context.Context, req *api.ListPasswordReq) (*a
 
 	return &api.ListPasswordResp{
 		Passwords: passwords,
+		Page:      page,
 	}, nil
 }
 
 func (d dexAPI) VerifyPassword(ctx context.Context, req *api.VerifyPasswordReq) (*api.VerifyPasswordResp, error) {
-	if req.Email == "" {
+	email := normalizeEmail(req.Email)
+	if email == "" {
 		return nil, errors.New("no email supplied")
 	}
 
@@ -298,7 +999,7 @@ func (d dexAPI) VerifyPassword(ctx context.Context, req *api.VerifyPasswordReq)
 		return nil, errors.New("no password to verify supplied")
 	}
 
-	password, err := d.s.GetPassword(ctx, req.Email)
+	password, err := d.s.GetPassword(ctx, email)
 	if err != nil {
 		if err == storage.ErrNotFound {
 			return &api.VerifyPasswordResp{
@@ -338,19 +1039,42 @@ func (d dexAPI) ListRefresh(ctx context.Context, req *api.ListRefreshReq) (*api.
 		return nil, err
 	}
 
-	refreshTokenRefs := make([]*api.RefreshTokenRef, 0, len(offlineSessions.Refresh))
+	sessions := make([]*storage.RefreshTokenRef, 0, len(offlineSessions.Refresh))
 	for _, session := range offlineSessions.Refresh {
+		sessions = append(sessions, session)
+	}
+	var page *api.PageResponse
+	if req.Page != nil {
+		sessions, page, err = paginate(sessions, func(r *storage.RefreshTokenRef) string { return r.ClientID }, req.Page)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	refreshTokenRefs := make([]*api.RefreshTokenRef, 0, len(sessions))
+	for _, session := range sessions {
 		r := api.RefreshTokenRef{
 			Id:        session.ID,
 			ClientId:  session.ClientID,
 			CreatedAt: session.CreatedAt.Unix(),
 			LastUsed:  session.LastUsed.Unix(),
 		}
+		// The offline session only references the token, the scopes live on
+		// the token itself.
+		refresh, err := d.s.GetRefresh(ctx, session.ID)
+		switch {
+		case err == nil:
+			r.Scopes = refresh.Scopes
+		case err != storage.ErrNotFound:
+			d.logger.Error("failed to get refresh token", "err", err)
+			return nil, fmt.Errorf("get refresh token: %v", err)
+		}
 		refreshTokenRefs = append(refreshTokenRefs, &r)
 	}
 
 	return &api.ListRefreshResp{
 		RefreshTokens: refreshTokenRefs,
+		Page:          page,
 	}, nil
 }
 
@@ -401,10 +1125,80 @@ func (d dexAPI) RevokeRefresh(ctx context.Context, req *api.RevokeRefreshReq) (*
 		d.logger.Error("failed to delete refresh token", "err", err)
 		return nil, err
 	}
+	d.audit(ctx, "revoke refresh token", "user_id", id.UserId, "connector_id", id.ConnId, "client_id", req.ClientId)
 
 	return &api.RevokeRefreshResp{}, nil
 }
 
+func (d dexAPI) DeleteRefreshTokensForUser(ctx context.Context, req *api.DeleteRefreshTokensForUserReq) (*api.DeleteRefreshTokensForUserResp, error) {
+	id := new(internal.IDTokenSubject)
+	if err := internal.Unmarshal(req.UserId, id); err != nil {
+		d.logger.Error("failed to unmarshal ID Token subject", "err", err)
+		return nil, err
+	}
+
+	// Remove the references first, like RevokeRefresh does, so no refresh
+	// token is left referenced without existing.
+	updater := func(old storage.OfflineSessions) (storage.OfflineSessions, error) {
+		old.Refresh = make(map[string]*storage.RefreshTokenRef)
+		return old, nil
+	}
+	if err := d.s.UpdateOfflineSessions(ctx, id.UserId, id.ConnId, updater); err != nil && err != storage.ErrNotFound {
+		d.logger.Error("failed to update offline session object", "err", err)
+		return nil, err
+	}
+
+	n, err := d.s.DeleteRefreshTokensForUser(ctx, id.UserId, id.ConnId)
+	if err != nil {
+		d.logger.Error("failed to delete refresh tokens", "err", err)
+		return nil, err
+	}
+	d.audit(ctx, "delete refresh tokens", "user_id", id.UserId, "connector_id", id.ConnId, "count", n)
+
+	return &api.DeleteRefreshTokensForUserResp{Count: int64(n)}, nil
+}
+
+func (d dexAPI) GetClientGrantedScopes(ctx context.Context, req *api.ClientGrantedScopesReq) (*api.ClientGrantedScopesResp, error) {
+	if req.ClientId == "" {
+		return nil, errors.New("no client ID supplied")
+	}
+	if _, err := d.s.GetClient(ctx, req.ClientId); err != nil {
+		if err == storage.ErrNotFound {
+			return &api.ClientGrantedScopesResp{NotFound: true}, nil
+		}
+		d.logger.Error("failed to get client", "err", err)
+		return nil, fmt.Errorf("get client: %v", err)
+	}
+
+	refreshTokens, err := d.s.ListRefreshTokens(ctx)
+	if err != nil {
+		d.logger.Error("failed to list refresh tokens", "err", err)
+		return nil, fmt.Errorf("list refresh tokens: %v", err)
+	}
+
+	// Users are identified the same way as in the subject of their ID tokens.
+	type user struct{ userID, connectorID string }
+	users := make(map[user]bool)
+	var scopes []string
+	for _, r := range refreshTokens {
+		if r.ClientID != req.ClientId {
+			continue
+		}
+		users[user{r.Claims.UserID, r.ConnectorID}] = true
+		for _, scope := range r.Scopes {
+			if !slices.Contains(scopes, scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+	slices.Sort(scopes)
+
+	return &api.ClientGrantedScopesResp{
+		Scopes:    scopes,
+		UserCount: int64(len(users)),
+	}, nil
+}
+
 func (d dexAPI) CreateConnector(ctx context.Context, req *api.CreateConnectorReq) (*api.CreateConnectorResp, error) {
 	if !featureflags.APIConnectorsCRUD.Enabled() {
 		return nil, fmt.Errorf("%s feature flag is not enabled", featureflags.APIConnectorsCRUD.Name)
@@ -426,8 +1220,8 @@ func (d dexAPI) CreateConnector(ctx context.Context, req *api.CreateConnectorReq
 		return nil, errors.New("no config supplied")
 	}
 
-	if !json.Valid(req.Connector.Config) {
-		return nil, errors.New("invalid config supplied")
+	if err := validateConnectorConfig(req.Connector.Type, req.Connector.Config); err != nil {
+		return nil, err
 	}
 
 	c := storage.Connector{
@@ -444,10 +1238,33 @@ func (d dexAPI) CreateConnector(ctx context.Context, req *api.CreateConnectorReq
 		d.logger.Error("api: failed to create connector", "err", err)
 		return nil, fmt.Errorf("create connector: %v", err)
 	}
+	d.audit(ctx, "create connector", "connector_id", c.ID)
 
 	return &api.CreateConnectorResp{}, nil
 }
 
+// validateConnectorConfig returns an error if config can't be parsed as the
+// configuration of a connector of type typ.
+func validateConnectorConfig(typ string, config []byte) error {
+	if !json.Valid(config) {
+		return errors.New("invalid config supplied")
+	}
+	// The local connector is backed by the password database and has no
+	// configuration of its own.
+	if typ == LocalConnector {
+		return nil
+	}
+
+	f, ok := ConnectorsConfig[typ]
+	if !ok {
+		return fmt.Errorf("unknown connector type %q", typ)
+	}
+	if err := json.Unmarshal(config, f()); err != nil {
+		return fmt.Errorf("invalid config supplied: %v", err)
+	}
+	return nil
+}
+
 func (d dexAPI) UpdateConnector(ctx context.Context, req *api.UpdateConnectorReq) (*api.UpdateConnectorResp, error) {
 	if !featureflags.APIConnectorsCRUD.Enabled() {
 		return nil, fmt.Errorf("%s feature flag is not enabled", featureflags.APIConnectorsCRUD.Name)
@@ -461,10 +1278,6 @@ func (d dexAPI) UpdateConnector(ctx context.Context, req *api.UpdateConnectorReq
 		return nil, errors.New("nothing to update")
 	}
 
-	if !json.Valid(req.NewConfig) {
-		return nil, errors.New("invalid config supplied")
-	}
-
 	updater := func(old storage.Connector) (storage.Connector, error) {
 		if req.NewType != "" {
 			old.Type = req.NewType
@@ -478,6 +1291,12 @@ func (d dexAPI) UpdateConnector(ctx context.Context, req *api.UpdateConnectorReq
 			old.Config = req.NewConfig
 		}
 
+		// A new type must accept the existing config, and a new config must
+		// suit the connector's type.
+		if err := validateConnectorConfig(old.Type, old.Config); err != nil {
+			return old, err
+		}
+
 		if rev, err := strconv.Atoi(defaultTo(old.ResourceVersion, "0")); err == nil {
 			old.ResourceVersion = strconv.Itoa(rev + 1)
 		}
@@ -492,6 +1311,7 @@ func (d dexAPI) UpdateConnector(ctx context.Context, req *api.UpdateConnectorReq
 		d.logger.Error("api: failed to update connector", "err", err)
 		return nil, fmt.Errorf("update connector: %v", err)
 	}
+	d.audit(ctx, "update connector", "connector_id", req.Id)
 
 	return &api.UpdateConnectorResp{}, nil
 }
@@ -513,6 +1333,7 @@ func (d dexAPI) DeleteConnector(ctx context.Context, req *api.DeleteConnectorReq
 		d.logger.Error("api: failed to delete connector", "err", err)
 		return nil, fmt.Errorf("delete connector: %v", err)
 	}
+	d.audit(ctx, "delete connector", "connector_id", req.Id)
 	return &api.DeleteConnectorResp{}, nil
 }
 
@@ -1,17 +1,25 @@
 package server
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"io"
 	"log/slog"
 	"net"
 	"os"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
+	"golang.org/x/crypto/bcrypt"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/dexidp/dex/api/v2"
 	"github.com/dexidp/dex/server/internal"
@@ -32,13 +40,19 @@ type apiClient struct {
 
 // newAPI constructs a gRCP client connected to a backing server.
 func newAPI(s storage.Storage, logger *slog.Logger, t *testing.T) *apiClient {
+	return newAPIWithServer(s, logger, nil, t)
+}
+
+// newAPIWithServer is like newAPI, but the API uses the settings of server
+// and the gRPC server is created with opts.
+func newAPIWithServer(s storage.Storage, logger *slog.Logger, server *Server, t *testing.T, opts ...grpc.ServerOption) *apiClient {
 	l, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	serv := grpc.NewServer()
-	api.RegisterDexServer(serv, NewAPI(s, logger, "test", nil))
+	serv := grpc.NewServer(opts...)
+	api.RegisterDexServer(serv, NewAPI(s, logger, "test", server))
 	go serv.Serve(l)
 
 	// NewClient will retry automatically if the serv.Serve() goroutine
@@ -168,6 +182,263 @@ func TestPassword(t *testing.T) {
 }
 
 // Ensures checkCost returns expected values
+func TestPlaintextPassword(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	client := newAPI(s, logger, t)
+	defer client.Close()
+
+	ctx := context.Background()
+	email := "test@example.com"
+
+	if _, err := client.CreatePassword(ctx, &api.CreatePasswordReq{
+		Password:  &api.Password{Email: email, Username: "test", UserId: "test123"},
+		Plaintext: "first-password",
+	}); err != nil {
+		t.Fatalf("Unable to create password: %v", err)
+	}
+	verify := func(password string) bool {
+		resp, err := client.VerifyPassword(ctx, &api.VerifyPasswordReq{Email: email, Password: password})
+		if err != nil {
+			t.Fatalf("Unable to verify password: %v", err)
+		}
+		return resp.Verified
+	}
+	if !verify("first-password") {
+		t.Fatalf("Expected the plaintext password to be stored hashed")
+	}
+	p, err := s.GetPassword(ctx, email)
+	if err != nil {
+		t.Fatalf("Unable to get password: %v", err)
+	}
+	if cost, _ := bcrypt.Cost(p.Hash); cost != recCost {
+		t.Errorf("Expected hash cost %d, got %d", recCost, cost)
+	}
+
+	if _, err := client.UpdatePassword(ctx, &api.UpdatePasswordReq{Email: email, Plaintext: "second-password"}); err != nil {
+		t.Fatalf("Unable to update password: %v", err)
+	}
+	if verify("first-password") || !verify("second-password") {
+		t.Fatalf("Expected the password to be updated")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("third-password"), bcrypt.DefaultCost)
+	if err != nil {
+		t.Fatalf("Unable to generate bcrypt hash: %v", err)
+	}
+	if _, err := client.CreatePassword(ctx, &api.CreatePasswordReq{
+		Password:  &api.Password{Email: "other@example.com", Hash: hash, UserId: "other"},
+		Plaintext: "third-password",
+	}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected InvalidArgument when creating a password with both a hash and plaintext, got %v", err)
+	}
+	if _, err := client.UpdatePassword(ctx, &api.UpdatePasswordReq{Email: email, NewHash: hash, Plaintext: "third-password"}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected InvalidArgument when updating a password with both a hash and plaintext, got %v", err)
+	}
+}
+
+func TestPasswordEmailNormalization(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	client := newAPI(s, logger, t)
+	defer client.Close()
+
+	ctx := context.Background()
+
+	createReq := api.CreatePasswordReq{
+		Password: &api.Password{
+			Email:    " User@Example.com ",
+			Username: "user",
+			UserId:   "user-id",
+		},
+		Plaintext: "secret-password",
+	}
+	if _, err := client.CreatePassword(ctx, &createReq); err != nil {
+		t.Fatalf("Unable to create password: %v", err)
+	}
+
+	p, err := s.GetPassword(ctx, "user@example.com")
+	if err != nil {
+		t.Fatalf("Unable to get password: %v", err)
+	}
+	if p.Email != "user@example.com" {
+		t.Errorf("expected email to be stored normalized, got %q", p.Email)
+	}
+
+	createReq.Password.Email = "user@example.com"
+	resp, err := client.CreatePassword(ctx, &createReq)
+	if err != nil {
+		t.Fatalf("Unable to create password: %v", err)
+	}
+	if !resp.AlreadyExists {
+		t.Errorf("expected a differently cased email to conflict with the existing password")
+	}
+
+	updateResp, err := client.UpdatePassword(ctx, &api.UpdatePasswordReq{Email: "USER@example.COM ", NewUsername: "renamed"})
+	if err != nil {
+		t.Fatalf("Unable to update password: %v", err)
+	}
+	if updateResp.NotFound {
+		t.Errorf("expected update to match the password case-insensitively")
+	}
+	if p, err := s.GetPassword(ctx, "user@example.com"); err != nil || p.Username != "renamed" {
+		t.Errorf("expected username to be updated, got %v, %v", p, err)
+	}
+
+	if _, err := client.UpdatePassword(ctx, &api.UpdatePasswordReq{Email: "  ", NewUsername: "renamed"}); err == nil {
+		t.Errorf("expected an update with a blank email to fail")
+	}
+
+	verifyResp, err := client.VerifyPassword(ctx, &api.VerifyPasswordReq{Email: " User@example.com", Password: "secret-password"})
+	if err != nil {
+		t.Fatalf("Unable to verify password: %v", err)
+	}
+	if !verifyResp.Verified {
+		t.Errorf("expected verify to match the password regardless of case and surrounding whitespace")
+	}
+
+	if _, err := client.VerifyPassword(ctx, &api.VerifyPasswordReq{Email: "  ", Password: "secret-password"}); err == nil {
+		t.Errorf("expected a verify with a blank email to fail")
+	}
+
+	deleteResp, err := client.DeletePassword(ctx, &api.DeletePasswordReq{Email: " user@EXAMPLE.com"})
+	if err != nil {
+		t.Fatalf("Unable to delete password: %v", err)
+	}
+	if deleteResp.NotFound {
+		t.Errorf("expected delete to match the password case-insensitively")
+	}
+	if _, err := s.GetPassword(ctx, "user@example.com"); err != storage.ErrNotFound {
+		t.Errorf("expected password to be deleted, got %v", err)
+	}
+}
+
+func TestBatchDeletePasswords(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	client := newAPI(s, logger, t)
+	defer client.Close()
+
+	ctx := context.Background()
+
+	for _, email := range []string{"jane@example.com", "john@example.com"} {
+		if err := s.CreatePassword(ctx, storage.Password{Email: email, Username: email, UserID: email}); err != nil {
+			t.Fatalf("Unable to create password: %v", err)
+		}
+	}
+
+	resp, err := client.BatchDeletePasswords(ctx, &api.BatchDeletePasswordsReq{
+		Emails: []string{"jane@example.com", "missing@example.com", "john@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("Unable to batch delete passwords: %v", err)
+	}
+	if len(resp.Results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(resp.Results))
+	}
+	for i, wantNotFound := range []bool{false, true, false} {
+		if resp.Results[i].NotFound != wantNotFound {
+			t.Errorf("Result %d: expected not found %t, got %t", i, wantNotFound, resp.Results[i].NotFound)
+		}
+	}
+
+	passwords, err := s.ListPasswords(ctx)
+	if err != nil {
+		t.Fatalf("Unable to list passwords: %v", err)
+	}
+	if len(passwords) != 0 {
+		t.Errorf("Expected all passwords to be deleted, got %v", passwords)
+	}
+
+	// An empty email rejects the whole batch.
+	if err := s.CreatePassword(ctx, storage.Password{Email: "jane@example.com", Username: "jane", UserID: "jane"}); err != nil {
+		t.Fatalf("Unable to create password: %v", err)
+	}
+	_, err = client.BatchDeletePasswords(ctx, &api.BatchDeletePasswordsReq{
+		Emails: []string{"jane@example.com", ""},
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected InvalidArgument for an empty email, got %v", err)
+	}
+	if _, err := s.GetPassword(ctx, "jane@example.com"); err != nil {
+		t.Errorf("Expected jane@example.com not to be deleted, got %v", err)
+	}
+}
+
+// failingDeletePasswordStorage fails to delete the password of email.
+type failingDeletePasswordStorage struct {
+	storage.Storage
+	email string
+}
+
+func (s failingDeletePasswordStorage) DeletePassword(ctx context.Context, email string) error {
+	if email == s.email {
+		return errors.New("storage unavailable")
+	}
+	return s.Storage.DeletePassword(ctx, email)
+}
+
+func TestBatchDeletePasswordsPartialFailure(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	serv := NewAPI(failingDeletePasswordStorage{Storage: s, email: "john@example.com"}, logger, "test", nil)
+
+	ctx := context.Background()
+
+	for _, email := range []string{"jane@example.com", "john@example.com", "joan@example.com"} {
+		if err := s.CreatePassword(ctx, storage.Password{Email: email, Username: email, UserID: email}); err != nil {
+			t.Fatalf("Unable to create password: %v", err)
+		}
+	}
+
+	resp, err := serv.BatchDeletePasswords(ctx, &api.BatchDeletePasswordsReq{
+		Emails: []string{"jane@example.com", "john@example.com", "joan@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("Unable to batch delete passwords: %v", err)
+	}
+	if len(resp.Results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(resp.Results))
+	}
+	for i, wantErr := range []bool{false, true, false} {
+		if gotErr := resp.Results[i].Error != ""; gotErr != wantErr {
+			t.Errorf("Result %d: expected error %t, got %q", i, wantErr, resp.Results[i].Error)
+		}
+	}
+
+	// Only the failed password is left.
+	passwords, err := s.ListPasswords(ctx)
+	if err != nil {
+		t.Fatalf("Unable to list passwords: %v", err)
+	}
+	if len(passwords) != 1 || passwords[0].Email != "john@example.com" {
+		t.Errorf("Expected only john@example.com to be left, got %v", passwords)
+	}
+}
+
+func TestGetVersion(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	client := newAPI(s, logger, t)
+	defer client.Close()
+
+	resp, err := client.GetVersion(context.Background(), &api.VersionReq{})
+	if err != nil {
+		t.Fatalf("Unable to get version: %v", err)
+	}
+	if resp.Server != "test" {
+		t.Errorf("Expected server version %q, got %q", "test", resp.Server)
+	}
+	if resp.Api != apiVersion {
+		t.Errorf("Expected API version %d, got %d", apiVersion, resp.Api)
+	}
+}
+
 func TestCheckCost(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
 
@@ -330,6 +601,204 @@ func TestRefreshToken(t *testing.T) {
 	}
 }
 
+func TestRefreshTokenMultipleClients(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	client := newAPI(s, logger, t)
+	defer client.Close()
+
+	ctx := context.Background()
+
+	session := storage.OfflineSessions{
+		UserID:  "1",
+		ConnID:  "conn",
+		Refresh: make(map[string]*storage.RefreshTokenRef),
+	}
+	scopes := map[string][]string{
+		"client-a": {"openid", "email"},
+		"client-b": {"openid", "groups", "offline_access"},
+	}
+	for clientID, tokenScopes := range scopes {
+		r := storage.RefreshToken{
+			ID:          storage.NewID(),
+			Token:       "secret-" + clientID,
+			ClientID:    clientID,
+			ConnectorID: session.ConnID,
+			Scopes:      tokenScopes,
+			CreatedAt:   time.Now().UTC().Round(time.Millisecond),
+			LastUsed:    time.Now().UTC().Round(time.Millisecond),
+			Claims:      storage.Claims{UserID: session.UserID},
+		}
+		if err := s.CreateRefresh(ctx, r); err != nil {
+			t.Fatalf("create refresh token: %v", err)
+		}
+		session.Refresh[clientID] = &storage.RefreshTokenRef{
+			ID:        r.ID,
+			ClientID:  r.ClientID,
+			CreatedAt: r.CreatedAt,
+			LastUsed:  r.LastUsed,
+		}
+	}
+	if err := s.CreateOfflineSessions(ctx, session); err != nil {
+		t.Fatalf("create offline session: %v", err)
+	}
+
+	subjectString, err := internal.Marshal(&internal.IDTokenSubject{
+		UserId: session.UserID,
+		ConnId: session.ConnID,
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal offline session ID: %v", err)
+	}
+
+	listReq := api.ListRefreshReq{UserId: subjectString}
+	listResp, err := client.ListRefresh(ctx, &listReq)
+	if err != nil {
+		t.Fatalf("Unable to list refresh tokens for user: %v", err)
+	}
+	if len(listResp.RefreshTokens) != len(scopes) {
+		t.Fatalf("Expected %d refresh tokens, got %d", len(scopes), len(listResp.RefreshTokens))
+	}
+	for _, tok := range listResp.RefreshTokens {
+		if tok.Id != session.Refresh[tok.ClientId].ID {
+			t.Errorf("Expected refresh token ID %q for client %q, got %q", session.Refresh[tok.ClientId].ID, tok.ClientId, tok.Id)
+		}
+		if !slices.Equal(tok.Scopes, scopes[tok.ClientId]) {
+			t.Errorf("Expected scopes %v for client %q, got %v", scopes[tok.ClientId], tok.ClientId, tok.Scopes)
+		}
+	}
+
+	var clientIDs []string
+	pageReq := &api.ListRefreshReq{UserId: subjectString, Page: &api.PageRequest{PageSize: 1}}
+	for {
+		resp, err := client.ListRefresh(ctx, pageReq)
+		if err != nil {
+			t.Fatalf("Unable to list refresh tokens for user: %v", err)
+		}
+		for _, tok := range resp.RefreshTokens {
+			clientIDs = append(clientIDs, tok.ClientId)
+		}
+		if resp.Page.NextPageToken == "" {
+			break
+		}
+		pageReq.Page.PageToken = resp.Page.NextPageToken
+	}
+	if want := []string{"client-a", "client-b"}; !slices.Equal(clientIDs, want) {
+		t.Errorf("Expected refresh tokens of %v one page at a time, got %v", want, clientIDs)
+	}
+
+	resp, err := client.RevokeRefresh(ctx, &api.RevokeRefreshReq{UserId: subjectString, ClientId: "client-a"})
+	if err != nil {
+		t.Fatalf("Unable to revoke refresh token for user: %v", err)
+	}
+	if resp.NotFound {
+		t.Errorf("refresh token session wasn't found")
+	}
+
+	listResp, err = client.ListRefresh(ctx, &listReq)
+	if err != nil {
+		t.Fatalf("Unable to list refresh tokens for user: %v", err)
+	}
+	if len(listResp.RefreshTokens) != 1 || listResp.RefreshTokens[0].ClientId != "client-b" {
+		t.Fatalf("Expected only the refresh token of client-b to remain, got %v", listResp.RefreshTokens)
+	}
+
+	resp, err = client.RevokeRefresh(ctx, &api.RevokeRefreshReq{UserId: subjectString, ClientId: "client-c"})
+	if err != nil {
+		t.Fatalf("Unable to revoke refresh token for user: %v", err)
+	}
+	if !resp.NotFound {
+		t.Errorf("expected revoking an unknown client's refresh token to report not found")
+	}
+}
+
+func TestDeleteRefreshTokensForUser(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	client := newAPI(s, logger, t)
+	defer client.Close()
+
+	ctx := context.Background()
+
+	subject := func(userID, connID string) string {
+		subjectString, err := internal.Marshal(&internal.IDTokenSubject{UserId: userID, ConnId: connID})
+		if err != nil {
+			t.Fatalf("failed to marshal offline session ID: %v", err)
+		}
+		return subjectString
+	}
+
+	session := storage.OfflineSessions{
+		UserID:  "1",
+		ConnID:  "conn",
+		Refresh: make(map[string]*storage.RefreshTokenRef),
+	}
+	// A token of another user that must survive.
+	otherUser := storage.RefreshToken{
+		ID:          storage.NewID(),
+		Token:       "secret-other",
+		ClientID:    "client-a",
+		ConnectorID: session.ConnID,
+		Claims:      storage.Claims{UserID: "2"},
+	}
+	if err := s.CreateRefresh(ctx, otherUser); err != nil {
+		t.Fatalf("create refresh token: %v", err)
+	}
+	for _, clientID := range []string{"client-a", "client-b", "client-c"} {
+		r := storage.RefreshToken{
+			ID:          storage.NewID(),
+			Token:       "secret-" + clientID,
+			ClientID:    clientID,
+			ConnectorID: session.ConnID,
+			CreatedAt:   time.Now().UTC().Round(time.Millisecond),
+			LastUsed:    time.Now().UTC().Round(time.Millisecond),
+			Claims:      storage.Claims{UserID: session.UserID},
+		}
+		if err := s.CreateRefresh(ctx, r); err != nil {
+			t.Fatalf("create refresh token: %v", err)
+		}
+		session.Refresh[clientID] = &storage.RefreshTokenRef{ID: r.ID, ClientID: r.ClientID}
+	}
+	if err := s.CreateOfflineSessions(ctx, session); err != nil {
+		t.Fatalf("create offline session: %v", err)
+	}
+
+	resp, err := client.DeleteRefreshTokensForUser(ctx, &api.DeleteRefreshTokensForUserReq{UserId: subject(session.UserID, session.ConnID)})
+	if err != nil {
+		t.Fatalf("Unable to delete refresh tokens for user: %v", err)
+	}
+	if resp.Count != 3 {
+		t.Errorf("Expected 3 refresh tokens to be deleted, got %d", resp.Count)
+	}
+	for _, ref := range session.Refresh {
+		if _, err := s.GetRefresh(ctx, ref.ID); err != storage.ErrNotFound {
+			t.Errorf("Expected refresh token of %q to be deleted, got %v", ref.ClientID, err)
+		}
+	}
+	if _, err := s.GetRefresh(ctx, otherUser.ID); err != nil {
+		t.Errorf("Expected refresh token of another user to remain: %v", err)
+	}
+
+	listResp, err := client.ListRefresh(ctx, &api.ListRefreshReq{UserId: subject(session.UserID, session.ConnID)})
+	if err != nil {
+		t.Fatalf("Unable to list refresh tokens for user: %v", err)
+	}
+	if len(listResp.RefreshTokens) != 0 {
+		t.Errorf("Expected no refresh tokens to be listed, got %v", listResp.RefreshTokens)
+	}
+
+	// A user without any refresh tokens or offline session.
+	resp, err = client.DeleteRefreshTokensForUser(ctx, &api.DeleteRefreshTokensForUserReq{UserId: subject("3", session.ConnID)})
+	if err != nil {
+		t.Fatalf("Unable to delete refresh tokens for user without any: %v", err)
+	}
+	if resp.Count != 0 {
+		t.Errorf("Expected no refresh tokens to be deleted, got %d", resp.Count)
+	}
+}
+
 func TestUpdateClient(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
 
@@ -484,19 +953,7 @@ func TestUpdateClient(t *testing.T) {
 	}
 }
 
-func find(item string, items []string) bool {
-	for _, i := range items {
-		if item == i {
-			return true
-		}
-	}
-	return false
-}
-
-func TestCreateConnector(t *testing.T) {
-	os.Setenv("DEX_API_CONNECTORS_CRUD", "true")
-	defer os.Unsetenv("DEX_API_CONNECTORS_CRUD")
-
+func TestUpdateClientDisabled(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
 
 	s := memory.New(logger)
@@ -504,9 +961,1089 @@ func TestCreateConnector(t *testing.T) {
 	defer client.Close()
 
 	ctx := context.Background()
-	connectorID := "connector123"
+
+	if _, err := client.CreateClient(ctx, &api.CreateClientReq{
+		Client: &api.Client{Id: "client1", Name: "Admin UI"},
+	}); err != nil {
+		t.Fatalf("Unable to create client: %v", err)
+	}
+
+	disabled := func() bool {
+		t.Helper()
+		resp, err := client.GetClient(ctx, &api.GetClientReq{Id: "client1"})
+		if err != nil {
+			t.Fatalf("Unable to get client: %v", err)
+		}
+		return resp.Client.Disabled
+	}
+	update := func(req *api.UpdateClientReq) {
+		t.Helper()
+		req.Id = "client1"
+		if _, err := client.UpdateClient(ctx, req); err != nil {
+			t.Fatalf("Unable to update client: %v", err)
+		}
+	}
+
+	if disabled() {
+		t.Fatal("Expected a new client to be enabled")
+	}
+
+	update(&api.UpdateClientReq{Disabled: proto.Bool(true)})
+	if !disabled() {
+		t.Fatal("Expected the client to be disabled")
+	}
+
+	// Updates that don't mention the field leave it alone.
+	update(&api.UpdateClientReq{Name: "Admin Console"})
+	if !disabled() {
+		t.Fatal("Expected the client to stay disabled")
+	}
+
+	update(&api.UpdateClientReq{Disabled: proto.Bool(false)})
+	if disabled() {
+		t.Fatal("Expected the client to be re-enabled")
+	}
+}
+
+func TestUpdateClientAllowedConnectors(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	client := newAPI(s, logger, t)
+	defer client.Close()
+
+	ctx := context.Background()
+
+	if _, err := client.CreateClient(ctx, &api.CreateClientReq{
+		Client: &api.Client{Id: "client1", Name: "Admin UI", AllowedConnectors: []string{"ldap"}},
+	}); err != nil {
+		t.Fatalf("Unable to create client: %v", err)
+	}
+
+	allowed := func() []string {
+		t.Helper()
+		resp, err := client.GetClient(ctx, &api.GetClientReq{Id: "client1"})
+		if err != nil {
+			t.Fatalf("Unable to get client: %v", err)
+		}
+		return resp.Client.AllowedConnectors
+	}
+	update := func(req *api.UpdateClientReq) error {
+		req.Id = "client1"
+		_, err := client.UpdateClient(ctx, req)
+		return err
+	}
+
+	if got, want := allowed(), []string{"ldap"}; !slices.Equal(got, want) {
+		t.Fatalf("Expected allowed connectors %v, got %v", want, got)
+	}
+
+	if err := update(&api.UpdateClientReq{AllowedConnectors: []string{"github", "ldap"}}); err != nil {
+		t.Fatalf("Unable to update client: %v", err)
+	}
+	if got, want := allowed(), []string{"github", "ldap"}; !slices.Equal(got, want) {
+		t.Fatalf("Expected allowed connectors %v, got %v", want, got)
+	}
+
+	// Updates that don't mention the field leave it alone.
+	if err := update(&api.UpdateClientReq{Name: "Admin Console"}); err != nil {
+		t.Fatalf("Unable to update client: %v", err)
+	}
+	if got, want := allowed(), []string{"github", "ldap"}; !slices.Equal(got, want) {
+		t.Fatalf("Expected allowed connectors %v, got %v", want, got)
+	}
+
+	if err := update(&api.UpdateClientReq{AllowedConnectors: []string{"github"}, ClearAllowedConnectors: true}); err == nil {
+		t.Fatal("Expected an error when setting and clearing allowed connectors")
+	}
+
+	if err := update(&api.UpdateClientReq{ClearAllowedConnectors: true}); err != nil {
+		t.Fatalf("Unable to update client: %v", err)
+	}
+	if got := allowed(); len(got) != 0 {
+		t.Fatalf("Expected all connectors to be allowed, got %v", got)
+	}
+}
+
+func TestFindClientsByName(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	client := newAPI(s, logger, t)
+	defer client.Close()
+
+	ctx := context.Background()
+
+	for _, c := range []*api.Client{
+		{Id: "client1", Name: "Admin UI"},
+		{Id: "client2", Name: "admin ui"},
+		{Id: "client3", Name: "Other"},
+	} {
+		if _, err := client.CreateClient(ctx, &api.CreateClientReq{Client: c}); err != nil {
+			t.Fatalf("Unable to create client: %v", err)
+		}
+	}
+
+	resp, err := client.FindClientsByName(ctx, &api.FindClientsByNameReq{Name: "Admin UI"})
+	if err != nil {
+		t.Fatalf("Unable to find clients by name: %v", err)
+	}
+	if len(resp.Clients) != 1 || resp.Clients[0].Id != "client1" {
+		t.Fatalf("Expected only client1, found %v", resp.Clients)
+	}
+
+	resp, err = client.FindClientsByName(ctx, &api.FindClientsByNameReq{Name: "Admin UI", CaseInsensitive: true})
+	if err != nil {
+		t.Fatalf("Unable to find clients by name: %v", err)
+	}
+	if len(resp.Clients) != 2 {
+		t.Fatalf("Expected 2 clients, found %d", len(resp.Clients))
+	}
+}
+
+func TestGetClientByName(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	client := newAPI(s, logger, t)
+	defer client.Close()
+
+	ctx := context.Background()
+
+	for _, c := range []*api.Client{
+		{Id: "client1", Name: "Admin UI"},
+		{Id: "client2", Name: "admin ui"},
+		{Id: "client3", Name: "Other"},
+		{Id: "client4", Name: "Other"},
+	} {
+		if _, err := client.CreateClient(ctx, &api.CreateClientReq{Client: c}); err != nil {
+			t.Fatalf("Unable to create client: %v", err)
+		}
+	}
+
+	resp, err := client.GetClientByName(ctx, &api.GetClientByNameReq{Name: "Admin UI"})
+	if err != nil {
+		t.Fatalf("Unable to get client by name: %v", err)
+	}
+	if resp.NotFound || resp.Ambiguous || resp.Client.GetId() != "client1" {
+		t.Fatalf("Expected client1, got %v", resp)
+	}
+
+	resp, err = client.GetClientByName(ctx, &api.GetClientByNameReq{Name: "Missing"})
+	if err != nil {
+		t.Fatalf("Unable to get client by name: %v", err)
+	}
+	if !resp.NotFound || resp.Ambiguous || resp.Client != nil {
+		t.Fatalf("Expected the client not to be found, got %v", resp)
+	}
+
+	resp, err = client.GetClientByName(ctx, &api.GetClientByNameReq{Name: "Other"})
+	if err != nil {
+		t.Fatalf("Unable to get client by name: %v", err)
+	}
+	if resp.NotFound || !resp.Ambiguous || resp.Client != nil {
+		t.Fatalf("Expected the name to be ambiguous, got %v", resp)
+	}
+
+	if _, err := client.GetClientByName(ctx, &api.GetClientByNameReq{}); err == nil {
+		t.Fatal("Expected an error for an empty name")
+	}
+}
+
+func TestGetClient(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	client := newAPI(s, logger, t)
+	defer client.Close()
+
+	ctx := context.Background()
+
+	if _, err := client.CreateClient(ctx, &api.CreateClientReq{
+		Client: &api.Client{Id: "client1", Name: "Admin UI", RedirectUris: []string{"https://admin.example.com/callback"}},
+	}); err != nil {
+		t.Fatalf("Unable to create client: %v", err)
+	}
+
+	resp, err := client.GetClient(ctx, &api.GetClientReq{Id: "client1"})
+	if err != nil {
+		t.Fatalf("Unable to get client: %v", err)
+	}
+	if resp.NotFound || resp.Client.Name != "Admin UI" || !slices.Equal(resp.Client.RedirectUris, []string{"https://admin.example.com/callback"}) {
+		t.Fatalf("Unexpected response %v", resp)
+	}
+
+	resp, err = client.GetClient(ctx, &api.GetClientReq{Id: "client2"})
+	if err != nil {
+		t.Fatalf("Unable to get client: %v", err)
+	}
+	if !resp.NotFound || resp.Client != nil {
+		t.Fatalf("Expected client2 not to be found, got %v", resp)
+	}
+}
+
+func TestClientTimestamps(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	client := newAPI(s, logger, t)
+	defer client.Close()
+
+	ctx := context.Background()
+
+	before := time.Now().Unix()
+	createResp, err := client.CreateClient(ctx, &api.CreateClientReq{
+		Client: &api.Client{Id: "client1", Name: "Admin UI"},
+	})
+	if err != nil {
+		t.Fatalf("Unable to create client: %v", err)
+	}
+	created := createResp.Client.CreatedAt
+	if created < before || created > time.Now().Unix() {
+		t.Fatalf("Expected created_at to be the current time, got %d", created)
+	}
+	if createResp.Client.UpdatedAt != created {
+		t.Errorf("Expected updated_at %d to equal created_at %d", createResp.Client.UpdatedAt, created)
+	}
+
+	// Move the stored timestamps back so the update is observable at second
+	// precision.
+	past := time.Now().Add(-time.Hour)
+	if err := s.UpdateClient(ctx, "client1", func(old storage.Client) (storage.Client, error) {
+		old.CreatedAt = past
+		old.UpdatedAt = past
+		return old, nil
+	}); err != nil {
+		t.Fatalf("Unable to update client in storage: %v", err)
+	}
+
+	if _, err := client.UpdateClient(ctx, &api.UpdateClientReq{Id: "client1", Name: "Admin"}); err != nil {
+		t.Fatalf("Unable to update client: %v", err)
+	}
+
+	resp, err := client.GetClient(ctx, &api.GetClientReq{Id: "client1"})
+	if err != nil {
+		t.Fatalf("Unable to get client: %v", err)
+	}
+	if resp.Client.CreatedAt != past.Unix() {
+		t.Errorf("Expected created_at to stay %d, got %d", past.Unix(), resp.Client.CreatedAt)
+	}
+	if resp.Client.UpdatedAt <= past.Unix() {
+		t.Errorf("Expected updated_at to be bumped past %d, got %d", past.Unix(), resp.Client.UpdatedAt)
+	}
+
+	// Clients stored without timestamps report zero.
+	if err := s.CreateClient(ctx, storage.Client{ID: "client2", Secret: "secret", Name: "Legacy"}); err != nil {
+		t.Fatalf("Unable to create client in storage: %v", err)
+	}
+	resp, err = client.GetClient(ctx, &api.GetClientReq{Id: "client2"})
+	if err != nil {
+		t.Fatalf("Unable to get client: %v", err)
+	}
+	if resp.Client.CreatedAt != 0 || resp.Client.UpdatedAt != 0 {
+		t.Errorf("Expected zero timestamps, got created_at %d and updated_at %d", resp.Client.CreatedAt, resp.Client.UpdatedAt)
+	}
+}
+
+func TestCreateClientAlreadyExists(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	serv := NewAPI(s, logger, "test", nil)
+	ctx := context.Background()
+
+	newClient := func() *api.Client {
+		return &api.Client{
+			Id:           "test",
+			Secret:       "secret",
+			RedirectUris: []string{"https://example.com/callback"},
+			Name:         "Test",
+		}
+	}
+	if _, err := serv.CreateClient(ctx, &api.CreateClientReq{Client: newClient()}); err != nil {
+		t.Fatalf("Unable to create client: %v", err)
+	}
+
+	// sameClient compares the fields set by newClient, ignoring the secret.
+	sameClient := func(a, b *api.Client) bool {
+		return a.Id == b.Id && a.Name == b.Name && slices.Equal(a.RedirectUris, b.RedirectUris)
+	}
+
+	// An exact duplicate reports the existing client, which matches.
+	want := newClient()
+	resp, err := serv.CreateClient(ctx, &api.CreateClientReq{Client: newClient()})
+	if err != nil {
+		t.Fatalf("Unable to create client: %v", err)
+	}
+	if !resp.AlreadyExists || resp.Client == nil {
+		t.Fatalf("Expected the existing client to be reported, got %v", resp)
+	}
+	if !sameClient(resp.Client, want) {
+		t.Errorf("Expected the existing client to match %v, got %v", want, resp.Client)
+	}
+	if resp.Client.Secret != "" {
+		t.Errorf("Expected the secret of the existing client to be omitted, got %q", resp.Client.Secret)
+	}
+
+	// A conflicting client reports the existing client, which doesn't match.
+	conflicting := newClient()
+	conflicting.RedirectUris = []string{"https://other.example.com/callback"}
+	resp, err = serv.CreateClient(ctx, &api.CreateClientReq{Client: conflicting})
+	if err != nil {
+		t.Fatalf("Unable to create client: %v", err)
+	}
+	if !resp.AlreadyExists || resp.Client == nil {
+		t.Fatalf("Expected the existing client to be reported, got %v", resp)
+	}
+	if sameClient(resp.Client, conflicting) || !sameClient(resp.Client, want) {
+		t.Errorf("Expected the existing client %v, got %v", want, resp.Client)
+	}
+
+	if c, err := s.GetClient(ctx, "test"); err != nil || c.Secret != "secret" {
+		t.Errorf("Expected existing client to be left untouched, got %v, %v", c, err)
+	}
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	serv := NewAPI(s, logger, "test", nil)
+	ctx := context.Background()
+
+	countClients := func() int {
+		clients, err := s.ListClients(ctx)
+		if err != nil {
+			t.Fatalf("Unable to list clients: %v", err)
+		}
+		return len(clients)
+	}
+
+	// A failed call isn't recorded, so its retry is executed.
+	if _, err := serv.CreateClient(ctx, &api.CreateClientReq{IdempotencyKey: "key-1"}); err == nil {
+		t.Fatal("Expected creating no client to fail")
+	}
+
+	first, err := serv.CreateClient(ctx, &api.CreateClientReq{Client: &api.Client{Name: "Test"}, IdempotencyKey: "key-1"})
+	if err != nil {
+		t.Fatalf("Unable to create client: %v", err)
+	}
+
+	// Replaying the key returns the same client, with the same generated ID
+	// and secret, without creating another one.
+	replayed, err := serv.CreateClient(ctx, &api.CreateClientReq{Client: &api.Client{Name: "Test"}, IdempotencyKey: "key-1"})
+	if err != nil {
+		t.Fatalf("Unable to replay client creation: %v", err)
+	}
+	if !proto.Equal(first, replayed) {
+		t.Errorf("Expected replayed response %v, got %v", first, replayed)
+	}
+	if n := countClients(); n != 1 {
+		t.Errorf("Expected 1 client after replaying the key, got %d", n)
+	}
+
+	// The secret isn't recorded with the response.
+	record, err := s.GetIdempotencyKey(ctx, "CreateClient/key-1")
+	if err != nil {
+		t.Fatalf("Unable to get idempotency key: %v", err)
+	}
+	var recorded api.CreateClientResp
+	if err := proto.Unmarshal(record.Response, &recorded); err != nil {
+		t.Fatalf("Unable to unmarshal recorded response: %v", err)
+	}
+	if recorded.Client.GetId() != first.Client.Id || recorded.Client.GetSecret() != "" {
+		t.Errorf("Expected the recorded response to hold the client without its secret, got %v", &recorded)
+	}
+
+	// Reusing the key for another request is rejected.
+	_, err = serv.CreateClient(ctx, &api.CreateClientReq{Client: &api.Client{Name: "Other"}, IdempotencyKey: "key-1"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for a reused key, got %v", err)
+	}
+	if n := countClients(); n != 1 {
+		t.Errorf("Expected 1 client after reusing the key, got %d", n)
+	}
+
+	// Another key creates another client.
+	if _, err := serv.CreateClient(ctx, &api.CreateClientReq{Client: &api.Client{Name: "Test"}, IdempotencyKey: "key-2"}); err != nil {
+		t.Fatalf("Unable to create client: %v", err)
+	}
+	if n := countClients(); n != 2 {
+		t.Errorf("Expected 2 clients after using another key, got %d", n)
+	}
+
+	// Keys are scoped to the call they're used with.
+	createPassword := func() {
+		t.Helper()
+		resp, err := serv.CreatePassword(ctx, &api.CreatePasswordReq{
+			Password:       &api.Password{Email: "test@example.com", Username: "test", UserId: "test"},
+			Plaintext:      "password",
+			IdempotencyKey: "key-1",
+		})
+		if err != nil || resp.AlreadyExists {
+			t.Fatalf("Unable to create password: %v, %v", resp, err)
+		}
+	}
+	createPassword()
+	if err := s.DeletePassword(ctx, "test@example.com"); err != nil {
+		t.Fatalf("Unable to delete password: %v", err)
+	}
+	// The replay returns the recorded response without creating the password
+	// again.
+	createPassword()
+	if _, err := s.GetPassword(ctx, "test@example.com"); err != storage.ErrNotFound {
+		t.Errorf("Expected the password not to be created again, got %v", err)
+	}
+}
+
+func TestIdempotencyKeyExpiry(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	serv := NewAPI(s, logger, "test", &Server{idempotencyValidFor: time.Nanosecond})
+	ctx := context.Background()
+
+	var resp *api.CreateClientResp
+	for range 2 {
+		var err error
+		resp, err = serv.CreateClient(ctx, &api.CreateClientReq{Client: &api.Client{Name: "Test"}, IdempotencyKey: "key"})
+		if err != nil {
+			t.Fatalf("Unable to create client: %v", err)
+		}
+	}
+	// The key expired before it was replayed, so the call was executed again.
+	if clients, err := s.ListClients(ctx); err != nil || len(clients) != 2 {
+		t.Errorf("Expected 2 clients, got %v, %v", clients, err)
+	}
+
+	// The expired key was replaced by the response to the second call.
+	record, err := s.GetIdempotencyKey(ctx, "CreateClient/key")
+	if err != nil {
+		t.Fatalf("Unable to get idempotency key: %v", err)
+	}
+	var recorded api.CreateClientResp
+	if err := proto.Unmarshal(record.Response, &recorded); err != nil {
+		t.Fatalf("Unable to unmarshal recorded response: %v", err)
+	}
+	if recorded.Client.GetId() != resp.Client.Id {
+		t.Errorf("Expected the key to record client %q, got %q", resp.Client.Id, recorded.Client.GetId())
+	}
+}
+
+func TestBatchCreateClients(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	serv := NewAPI(s, logger, "test", &Server{minClientSecretLength: 16})
+
+	ctx := context.Background()
+
+	if _, err := serv.CreateClient(ctx, &api.CreateClientReq{
+		Client: &api.Client{Id: "existing", Name: "Existing"},
+	}); err != nil {
+		t.Fatalf("Unable to create client: %v", err)
+	}
+
+	resp, err := serv.BatchCreateClients(ctx, &api.BatchCreateClientsReq{
+		Clients: []*api.Client{
+			{Id: "new-1", Name: "New 1"},
+			{Id: "existing", Name: "Replacement"},
+			{Name: "New 2", Public: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unable to batch create clients: %v", err)
+	}
+	if len(resp.Results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(resp.Results))
+	}
+	if r := resp.Results[0]; r.AlreadyExists || r.Client.Id != "new-1" || r.Client.Secret == "" {
+		t.Errorf("Expected new-1 to be created with a generated secret, got %v", r)
+	}
+	if r := resp.Results[1]; !r.AlreadyExists || r.Client.Name != "Existing" {
+		t.Errorf("Expected existing to be reported as already existing, got %v", r)
+	}
+	if r := resp.Results[2]; r.AlreadyExists || r.Client.Id == "" {
+		t.Errorf("Expected New 2 to be created with a generated ID, got %v", r)
+	}
+
+	if c, err := s.GetClient(ctx, "existing"); err != nil || c.Name != "Existing" {
+		t.Errorf("Expected existing client to be left untouched, got %v, %v", c, err)
+	}
+	clients, err := s.ListClients(ctx)
+	if err != nil {
+		t.Fatalf("Unable to list clients: %v", err)
+	}
+	if len(clients) != 3 {
+		t.Errorf("Expected 3 clients in storage, got %d", len(clients))
+	}
+
+	// A single invalid client rejects the whole batch.
+	_, err = serv.BatchCreateClients(ctx, &api.BatchCreateClientsReq{
+		Clients: []*api.Client{
+			{Id: "new-3", Name: "New 3"},
+			{Id: "weak", Name: "Weak", Secret: "s3cr3t"},
+		},
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected InvalidArgument for a weak secret, got %v", err)
+	}
+	if _, err := s.GetClient(ctx, "new-3"); err != storage.ErrNotFound {
+		t.Errorf("Expected new-3 not to be created, got %v", err)
+	}
+}
+
+type failingCreateClientStorage struct {
+	storage.Storage
+	id string
+}
+
+func (s failingCreateClientStorage) CreateClient(ctx context.Context, c storage.Client) error {
+	if c.ID == s.id {
+		return errors.New("storage unavailable")
+	}
+	return s.Storage.CreateClient(ctx, c)
+}
+
+func TestBatchCreateClientsPartialFailure(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	serv := NewAPI(failingCreateClientStorage{Storage: s, id: "new-2"}, logger, "test", nil)
+
+	ctx := context.Background()
+
+	resp, err := serv.BatchCreateClients(ctx, &api.BatchCreateClientsReq{
+		Clients: []*api.Client{
+			{Id: "new-1", Name: "New 1"},
+			{Id: "new-2", Name: "New 2"},
+			{Id: "new-3", Name: "New 3"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unable to batch create clients: %v", err)
+	}
+	if len(resp.Results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(resp.Results))
+	}
+	for i, id := range []string{"new-1", "new-3"} {
+		r := resp.Results[i*2]
+		if r.Error != "" || r.Client.GetId() != id || r.Client.GetSecret() == "" {
+			t.Errorf("Expected %s to be created with a generated secret, got %v", id, r)
+		}
+	}
+	if r := resp.Results[1]; r.Error == "" || r.Client != nil {
+		t.Errorf("Expected new-2 to report its error, got %v", r)
+	}
+
+	// The clients around the failed one are created.
+	clients, err := s.ListClients(ctx)
+	if err != nil {
+		t.Fatalf("Unable to list clients: %v", err)
+	}
+	if len(clients) != 2 {
+		t.Errorf("Expected 2 clients in storage, got %d", len(clients))
+	}
+}
+
+func TestListClients(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	client := newAPI(s, logger, t)
+	defer client.Close()
+
+	ctx := context.Background()
+
+	// listAll lists all clients, returning their IDs and the number of pages.
+	listAll := func(pageSize int32) (ids []string, pages int) {
+		req := &api.ListClientsReq{Page: &api.PageRequest{PageSize: pageSize}}
+		for {
+			resp, err := client.ListClients(ctx, req)
+			if err != nil {
+				t.Fatalf("Unable to list clients: %v", err)
+			}
+			pages++
+			for _, c := range resp.Clients {
+				ids = append(ids, c.Id)
+			}
+			if resp.Page.NextPageToken == "" {
+				return ids, pages
+			}
+			req.Page.PageToken = resp.Page.NextPageToken
+		}
+	}
+
+	ids, pages := listAll(0)
+	if len(ids) != 0 || pages != 1 {
+		t.Fatalf("Expected a single empty page, got %v in %d pages", ids, pages)
+	}
+
+	for _, id := range []string{"client3", "client1", "client5", "client2", "client4"} {
+		if _, err := client.CreateClient(ctx, &api.CreateClientReq{Client: &api.Client{Id: id, Name: id}}); err != nil {
+			t.Fatalf("Unable to create client: %v", err)
+		}
+	}
+	want := []string{"client1", "client2", "client3", "client4", "client5"}
+
+	// Clients get generated secrets, which listings leave out.
+	resp, err := client.ListClients(ctx, &api.ListClientsReq{})
+	if err != nil {
+		t.Fatalf("Unable to list clients: %v", err)
+	}
+	for _, c := range resp.Clients {
+		if c.Secret != "" {
+			t.Errorf("Expected client %s to be listed without its secret", c.Id)
+		}
+	}
+
+	ids, pages = listAll(0)
+	if !slices.Equal(ids, want) || pages != 1 {
+		t.Fatalf("Expected %v in a single page, got %v in %d pages", want, ids, pages)
+	}
+
+	ids, pages = listAll(2)
+	if !slices.Equal(ids, want) || pages != 3 {
+		t.Fatalf("Expected %v in 3 pages, got %v in %d pages", want, ids, pages)
+	}
+
+	// A page ending exactly on the last client has no next page.
+	ids, pages = listAll(5)
+	if !slices.Equal(ids, want) || pages != 1 {
+		t.Fatalf("Expected %v in a single page, got %v in %d pages", want, ids, pages)
+	}
+
+	if _, err := client.ListClients(ctx, &api.ListClientsReq{Page: &api.PageRequest{PageSize: -1}}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected InvalidArgument for a negative page size, got %v", err)
+	}
+	if _, err := client.ListClients(ctx, &api.ListClientsReq{Page: &api.PageRequest{PageSize: maxPageSize + 1}}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected InvalidArgument for a page size above the maximum, got %v", err)
+	}
+	if _, err := client.ListClients(ctx, &api.ListClientsReq{Page: &api.PageRequest{PageToken: "not a token!"}}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected InvalidArgument for a malformed page token, got %v", err)
+	}
+}
+
+func TestListClientsPublicFilter(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	client := newAPI(s, logger, t)
+	defer client.Close()
+
+	ctx := context.Background()
+
+	for _, c := range []*api.Client{
+		{Id: "confidential1", Name: "confidential1"},
+		{Id: "public1", Name: "public1", Public: true},
+		{Id: "confidential2", Name: "confidential2"},
+		{Id: "public2", Name: "public2", Public: true},
+		{Id: "public3", Name: "public3", Public: true},
+	} {
+		if _, err := client.CreateClient(ctx, &api.CreateClientReq{Client: c}); err != nil {
+			t.Fatalf("Unable to create client: %v", err)
+		}
+	}
+
+	tests := map[string]struct {
+		public *bool
+		want   []string
+	}{
+		"unset":        {want: []string{"confidential1", "confidential2", "public1", "public2", "public3"}},
+		"public":       {public: proto.Bool(true), want: []string{"public1", "public2", "public3"}},
+		"confidential": {public: proto.Bool(false), want: []string{"confidential1", "confidential2"}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// A page size of 2 checks page tokens walk the filtered view.
+			req := &api.ListClientsReq{Page: &api.PageRequest{PageSize: 2}, Public: tc.public}
+			var ids []string
+			for {
+				resp, err := client.ListClients(ctx, req)
+				if err != nil {
+					t.Fatalf("Unable to list clients: %v", err)
+				}
+				for _, c := range resp.Clients {
+					ids = append(ids, c.Id)
+				}
+				if resp.Page.NextPageToken == "" {
+					break
+				}
+				req.Page.PageToken = resp.Page.NextPageToken
+			}
+			if !slices.Equal(ids, tc.want) {
+				t.Errorf("Expected %v, got %v", tc.want, ids)
+			}
+		})
+	}
+}
+
+func TestListPasswordsPagination(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	client := newAPI(s, logger, t)
+	defer client.Close()
+
+	ctx := context.Background()
+
+	createPassword := func(email string) {
+		if err := s.CreatePassword(ctx, storage.Password{Email: email, Username: email, UserID: email}); err != nil {
+			t.Fatalf("Unable to create password: %v", err)
+		}
+	}
+	for _, email := range []string{"c@example.com", "a@example.com", "e@example.com", "b@example.com", "d@example.com"} {
+		createPassword(email)
+	}
+
+	resp, err := client.ListPasswords(ctx, &api.ListPasswordReq{})
+	if err != nil {
+		t.Fatalf("Unable to list passwords: %v", err)
+	}
+	if len(resp.Passwords) != 5 || resp.Page != nil {
+		t.Fatalf("Expected all 5 passwords without pagination, got %d and %v", len(resp.Passwords), resp.Page)
+	}
+
+	var emails []string
+	req := &api.ListPasswordReq{Page: &api.PageRequest{PageSize: 2}}
+	for pages := 1; ; pages++ {
+		resp, err := client.ListPasswords(ctx, req)
+		if err != nil {
+			t.Fatalf("Unable to list passwords: %v", err)
+		}
+		for _, p := range resp.Passwords {
+			emails = append(emails, p.Email)
+		}
+		if resp.Page.NextPageToken == "" {
+			break
+		}
+		req.Page.PageToken = resp.Page.NextPageToken
+
+		// Concurrent writes ahead of the cursor don't cause duplicates or gaps.
+		if pages == 1 {
+			if err := s.DeletePassword(ctx, "a@example.com"); err != nil {
+				t.Fatalf("Unable to delete password: %v", err)
+			}
+			createPassword("aa@example.com")
+			createPassword("ca@example.com")
+		}
+	}
+
+	want := []string{"a@example.com", "b@example.com", "c@example.com", "ca@example.com", "d@example.com", "e@example.com"}
+	if !slices.Equal(emails, want) {
+		t.Fatalf("Expected %v, got %v", want, emails)
+	}
+
+	if _, err := client.ListPasswords(ctx, &api.ListPasswordReq{Page: &api.PageRequest{PageSize: maxPageSize + 1}}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected InvalidArgument for a page size above the maximum, got %v", err)
+	}
+}
+
+func TestRotateClientSecret(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	client := newAPI(s, logger, t)
+	defer client.Close()
+
+	ctx := context.Background()
+
+	for _, c := range []*api.Client{
+		{Id: "client1", Name: "Admin UI", Secret: "old-secret"},
+		{Id: "public", Public: true},
+	} {
+		if _, err := client.CreateClient(ctx, &api.CreateClientReq{Client: c}); err != nil {
+			t.Fatalf("Unable to create client: %v", err)
+		}
+	}
+
+	// The server generates a secret if none is supplied.
+	resp, err := client.RotateClientSecret(ctx, &api.RotateClientSecretReq{Id: "client1"})
+	if err != nil {
+		t.Fatalf("Unable to rotate client secret: %v", err)
+	}
+	generated := resp.Client.Secret
+	if resp.NotFound || generated == "" || generated == "old-secret" || resp.Client.Name != "Admin UI" {
+		t.Fatalf("Unexpected response %v", resp)
+	}
+	stored, err := s.GetClient(ctx, "client1")
+	if err != nil {
+		t.Fatalf("Unable to get client: %v", err)
+	}
+	if stored.Secret != generated {
+		t.Fatalf("Expected stored secret %q, got %q", generated, stored.Secret)
+	}
+
+	resp, err = client.RotateClientSecret(ctx, &api.RotateClientSecretReq{Id: "client1", NewSecret: "new-secret"})
+	if err != nil {
+		t.Fatalf("Unable to rotate client secret: %v", err)
+	}
+	if resp.Client.Secret != "new-secret" {
+		t.Fatalf("Expected secret %q, got %q", "new-secret", resp.Client.Secret)
+	}
+	if stored, err = s.GetClient(ctx, "client1"); err != nil || stored.Secret != "new-secret" {
+		t.Fatalf("Expected stored secret %q, got %q (err %v)", "new-secret", stored.Secret, err)
+	}
+
+	resp, err = client.RotateClientSecret(ctx, &api.RotateClientSecretReq{Id: "client2"})
+	if err != nil {
+		t.Fatalf("Unable to rotate client secret: %v", err)
+	}
+	if !resp.NotFound {
+		t.Fatalf("Expected client2 not to be found")
+	}
+
+	if _, err := client.RotateClientSecret(ctx, &api.RotateClientSecretReq{Id: "public"}); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("Expected FailedPrecondition for a public client, got %v", err)
+	}
+}
+
+func TestGetClientGrantedScopes(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	client := newAPI(s, logger, t)
+	defer client.Close()
+
+	ctx := context.Background()
+
+	if _, err := client.CreateClient(ctx, &api.CreateClientReq{Client: &api.Client{Id: "client1", Name: "App"}}); err != nil {
+		t.Fatalf("Unable to create client: %v", err)
+	}
+
+	for _, r := range []storage.RefreshToken{
+		{ClientID: "client1", ConnectorID: "github", Scopes: []string{"openid", "groups"}, Claims: storage.Claims{UserID: "1"}},
+		{ClientID: "client1", ConnectorID: "github", Scopes: []string{"openid", "offline_access"}, Claims: storage.Claims{UserID: "1"}},
+		{ClientID: "client1", ConnectorID: "ldap", Scopes: []string{"email", "openid"}, Claims: storage.Claims{UserID: "1"}},
+		{ClientID: "client2", ConnectorID: "github", Scopes: []string{"admin"}, Claims: storage.Claims{UserID: "2"}},
+	} {
+		r.ID = storage.NewID()
+		if err := s.CreateRefresh(ctx, r); err != nil {
+			t.Fatalf("create refresh token: %v", err)
+		}
+	}
+
+	resp, err := client.GetClientGrantedScopes(ctx, &api.ClientGrantedScopesReq{ClientId: "client1"})
+	if err != nil {
+		t.Fatalf("Unable to get client granted scopes: %v", err)
+	}
+	wantScopes := []string{"email", "groups", "offline_access", "openid"}
+	if !slices.Equal(resp.Scopes, wantScopes) {
+		t.Errorf("Expected scopes %v, got %v", wantScopes, resp.Scopes)
+	}
+	// The same user ID from different connectors is a different user.
+	if resp.UserCount != 2 {
+		t.Errorf("Expected 2 users, got %d", resp.UserCount)
+	}
+
+	resp, err = client.GetClientGrantedScopes(ctx, &api.ClientGrantedScopesReq{ClientId: "client2"})
+	if err != nil {
+		t.Fatalf("Unable to get client granted scopes: %v", err)
+	}
+	if !resp.NotFound {
+		t.Errorf("Expected client2 to be reported as not found")
+	}
+}
+
+func TestAccessTokenLifetime(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	serv := NewAPI(s, logger, "test", &Server{maxAccessTokenLifetime: time.Hour})
+
+	ctx := context.Background()
+
+	for name, lifetime := range map[string]int64{
+		"negative":      -1,
+		"above maximum": 3601,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := serv.CreateClient(ctx, &api.CreateClientReq{
+				Client: &api.Client{Id: "invalid", AccessTokenLifetime: lifetime},
+			})
+			if err == nil {
+				t.Errorf("expected creating a client with lifetime %d to fail", lifetime)
+			}
+		})
+	}
+
+	if _, err := serv.CreateClient(ctx, &api.CreateClientReq{
+		Client: &api.Client{Id: "cli", Public: true, AccessTokenLifetime: 300},
+	}); err != nil {
+		t.Fatalf("Unable to create client: %v", err)
+	}
+
+	resp, err := serv.GetClient(ctx, &api.GetClientReq{Id: "cli"})
+	if err != nil {
+		t.Fatalf("Unable to get client: %v", err)
+	}
+	if resp.Client.AccessTokenLifetime != 300 {
+		t.Errorf("expected access token lifetime 300, got %d", resp.Client.AccessTokenLifetime)
+	}
+
+	if _, err := serv.UpdateClient(ctx, &api.UpdateClientReq{Id: "cli", AccessTokenLifetime: proto.Int64(7200)}); err == nil {
+		t.Errorf("expected update above the maximum lifetime to fail")
+	}
+
+	for _, tc := range []struct {
+		name     string
+		lifetime *int64
+		want     int64
+	}{
+		// An unset lifetime leaves the existing value untouched.
+		{name: "unset", want: 300},
+		{name: "set", lifetime: proto.Int64(3600), want: 3600},
+		// Zero removes the override.
+		{name: "cleared", lifetime: proto.Int64(0), want: 0},
+	} {
+		if _, err := serv.UpdateClient(ctx, &api.UpdateClientReq{Id: "cli", Name: "CLI", AccessTokenLifetime: tc.lifetime}); err != nil {
+			t.Fatalf("%s: Unable to update client: %v", tc.name, err)
+		}
+		c, err := s.GetClient(ctx, "cli")
+		if err != nil {
+			t.Fatalf("%s: Unable to get client: %v", tc.name, err)
+		}
+		if c.AccessTokenLifetime != tc.want {
+			t.Errorf("%s: expected access token lifetime %d, got %d", tc.name, tc.want, c.AccessTokenLifetime)
+		}
+	}
+}
+
+func TestRefreshTokenLifetime(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	serv := NewAPI(s, logger, "test", nil)
+
+	ctx := context.Background()
+
+	if _, err := serv.CreateClient(ctx, &api.CreateClientReq{
+		Client: &api.Client{Id: "invalid", Public: true, RefreshTokenLifetime: -1},
+	}); err == nil {
+		t.Errorf("expected creating a client with a negative refresh token lifetime to fail")
+	}
+
+	if _, err := serv.CreateClient(ctx, &api.CreateClientReq{
+		Client: &api.Client{Id: "cli", Public: true, RefreshTokenLifetime: 3600},
+	}); err != nil {
+		t.Fatalf("Unable to create client: %v", err)
+	}
+
+	resp, err := serv.GetClient(ctx, &api.GetClientReq{Id: "cli"})
+	if err != nil {
+		t.Fatalf("Unable to get client: %v", err)
+	}
+	if resp.Client.RefreshTokenLifetime != 3600 {
+		t.Errorf("expected refresh token lifetime 3600, got %d", resp.Client.RefreshTokenLifetime)
+	}
+
+	if _, err := serv.UpdateClient(ctx, &api.UpdateClientReq{Id: "cli", RefreshTokenLifetime: proto.Int64(-1)}); err == nil {
+		t.Errorf("expected update with a negative refresh token lifetime to fail")
+	}
+
+	for _, tc := range []struct {
+		name     string
+		lifetime *int64
+		want     int64
+	}{
+		// An unset lifetime leaves the existing value untouched.
+		{name: "unset", want: 3600},
+		{name: "set", lifetime: proto.Int64(60), want: 60},
+		// Zero removes the override.
+		{name: "cleared", lifetime: proto.Int64(0), want: 0},
+	} {
+		if _, err := serv.UpdateClient(ctx, &api.UpdateClientReq{Id: "cli", Name: "CLI", RefreshTokenLifetime: tc.lifetime}); err != nil {
+			t.Fatalf("%s: Unable to update client: %v", tc.name, err)
+		}
+		c, err := s.GetClient(ctx, "cli")
+		if err != nil {
+			t.Fatalf("%s: Unable to get client: %v", tc.name, err)
+		}
+		if c.RefreshTokenLifetime != tc.want {
+			t.Errorf("%s: expected refresh token lifetime %d, got %d", tc.name, tc.want, c.RefreshTokenLifetime)
+		}
+	}
+}
+
+func TestClientSecretPolicy(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	serv := NewAPI(s, logger, "test", &Server{minClientSecretLength: 16, minClientSecretEntropy: 64})
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		client  *api.Client
+		wantErr bool
+	}{
+		{
+			name:    "too short",
+			client:  &api.Client{Id: "short", Secret: "s3cr3t"},
+			wantErr: true,
+		},
+		{
+			name:    "low entropy",
+			client:  &api.Client{Id: "repeated", Secret: "aaaaaaaaaaaaaaaaaaaaaaaa"},
+			wantErr: true,
+		},
+		{
+			name:   "strong secret",
+			client: &api.Client{Id: "strong", Secret: "Zx8qP3mW7vK2tR9yL4nB6hF1"},
+		},
+		{
+			name:   "generated secret",
+			client: &api.Client{Id: "generated"},
+		},
+		{
+			name:   "public client",
+			client: &api.Client{Id: "public", Secret: "weak", Public: true},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := serv.CreateClient(ctx, &api.CreateClientReq{Client: tc.client})
+			if tc.wantErr {
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("expected InvalidArgument error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unable to create client: %v", err)
+			}
+			if !tc.client.Public && secretEntropy(resp.Client.Secret) < 64 {
+				t.Errorf("expected a strong secret, got %q", resp.Client.Secret)
+			}
+		})
+	}
+}
+
+func find(item string, items []string) bool {
+	for _, i := range items {
+		if item == i {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCreateConnector(t *testing.T) {
+	os.Setenv("DEX_API_CONNECTORS_CRUD", "true")
+	defer os.Unsetenv("DEX_API_CONNECTORS_CRUD")
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	client := newAPI(s, logger, t)
+	defer client.Close()
+
+	ctx := context.Background()
+	connectorID := "connector123"
 	connectorName := "TestConnector"
-	connectorType := "TestType"
+	connectorType := "mockCallback"
 	connectorConfig := []byte(`{"key": "value"}`)
 
 	createReq := api.CreateConnectorReq{
@@ -556,7 +2093,7 @@ func TestUpdateConnector(t *testing.T) {
 	ctx := context.Background()
 	connectorID := "connector123"
 	newConnectorName := "UpdatedConnector"
-	newConnectorType := "UpdatedType"
+	newConnectorType := "mockPassword"
 	newConnectorConfig := []byte(`{"updated_key": "updated_value"}`)
 
 	// Create a connector for testing
@@ -564,7 +2101,7 @@ func TestUpdateConnector(t *testing.T) {
 		Connector: &api.Connector{
 			Id:     connectorID,
 			Name:   "TestConnector",
-			Type:   "TestType",
+			Type:   "mockCallback",
 			Config: []byte(`{"key": "value"}`),
 		},
 	}
@@ -629,7 +2166,7 @@ func TestDeleteConnector(t *testing.T) {
 		Connector: &api.Connector{
 			Id:     connectorID,
 			Name:   "TestConnector",
-			Type:   "TestType",
+			Type:   "mockCallback",
 			Config: []byte(`{"key": "value"}`),
 		},
 	}
@@ -672,7 +2209,7 @@ func TestListConnectors(t *testing.T) {
 		Connector: &api.Connector{
 			Id:     "connector1",
 			Name:   "Connector1",
-			Type:   "Type1",
+			Type:   "mockCallback",
 			Config: []byte(`{"key": "value1"}`),
 		},
 	}
@@ -682,7 +2219,7 @@ func TestListConnectors(t *testing.T) {
 		Connector: &api.Connector{
 			Id:     "connector2",
 			Name:   "Connector2",
-			Type:   "Type2",
+			Type:   "mockPassword",
 			Config: []byte(`{"key": "value2"}`),
 		},
 	}
@@ -698,6 +2235,78 @@ func TestListConnectors(t *testing.T) {
 	}
 }
 
+func TestConnectorConfigValidation(t *testing.T) {
+	os.Setenv("DEX_API_CONNECTORS_CRUD", "true")
+	defer os.Unsetenv("DEX_API_CONNECTORS_CRUD")
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	s := memory.New(logger)
+	client := newAPI(s, logger, t)
+	defer client.Close()
+
+	ctx := context.Background()
+
+	githubConfig := []byte(`{"clientID": "client", "clientSecret": "secret", "redirectURI": "https://dex.example.com/callback", "orgs": [{"name": "org-1"}]}`)
+	if _, err := client.CreateConnector(ctx, &api.CreateConnectorReq{
+		Connector: &api.Connector{Id: "github", Name: "GitHub", Type: "github", Config: githubConfig},
+	}); err != nil {
+		t.Fatalf("Unable to create connector: %v", err)
+	}
+
+	resp, err := client.ListConnectors(ctx, &api.ListConnectorReq{})
+	if err != nil {
+		t.Fatalf("Unable to list connectors: %v", err)
+	}
+	if len(resp.Connectors) != 1 {
+		t.Fatalf("Expected 1 connector, found %d", len(resp.Connectors))
+	}
+	if c := resp.Connectors[0]; c.Id != "github" || c.Type != "github" || c.Name != "GitHub" || string(c.Config) != string(githubConfig) {
+		t.Fatalf("Unexpected connector %v", c)
+	}
+
+	tests := []struct {
+		name      string
+		connector *api.Connector
+		wantErr   string
+	}{
+		{
+			name:      "config of the wrong shape",
+			connector: &api.Connector{Id: "github2", Name: "GitHub", Type: "github", Config: []byte(`{"clientID": 42}`)},
+			wantErr:   "invalid config supplied",
+		},
+		{
+			name:      "unknown type",
+			connector: &api.Connector{Id: "unknown", Name: "Unknown", Type: "unknown", Config: []byte(`{}`)},
+			wantErr:   `unknown connector type "unknown"`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := client.CreateConnector(ctx, &api.CreateConnectorReq{Connector: tc.connector})
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("Expected error containing %q, got %v", tc.wantErr, err)
+			}
+			if _, err := s.GetConnector(ctx, tc.connector.Id); err != storage.ErrNotFound {
+				t.Errorf("Expected connector %q not to be stored, got %v", tc.connector.Id, err)
+			}
+		})
+	}
+
+	_, err = client.UpdateConnector(ctx, &api.UpdateConnectorReq{Id: "github", NewConfig: []byte(`{"orgs": "org-1"}`)})
+	if err == nil || !strings.Contains(err.Error(), "invalid config supplied") {
+		t.Fatalf("Expected an error for a config of the wrong shape, got %v", err)
+	}
+	if c, err := s.GetConnector(ctx, "github"); err != nil || string(c.Config) != string(githubConfig) {
+		t.Errorf("Expected connector config to be left untouched, got %s, %v", c.Config, err)
+	}
+
+	// Updating only the name keeps the existing config.
+	if _, err := client.UpdateConnector(ctx, &api.UpdateConnectorReq{Id: "github", NewName: "GitHub.com"}); err != nil {
+		t.Fatalf("Unable to update connector: %v", err)
+	}
+}
+
 func TestMissingConnectorsCRUDFeatureFlag(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
 
@@ -734,3 +2343,106 @@ func TestMissingConnectorsCRUDFeatureFlag(t *testing.T) {
 		t.Fatal("ListConnectors should have returned an error")
 	}
 }
+
+func TestAPIAuditLog(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	var buf bytes.Buffer
+	auditLogger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{}))
+
+	// Stands in for an interceptor authenticating the caller.
+	injectCaller := func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(metadata.NewIncomingContext(ctx, metadata.Pairs(APICallerMetadataKey, "ci-bot")), req)
+	}
+	client := newAPIWithServer(memory.New(logger), logger, &Server{apiAuditLogger: auditLogger}, t, grpc.UnaryInterceptor(injectCaller))
+	defer client.Close()
+
+	ctx := context.Background()
+	if _, err := client.CreateClient(ctx, &api.CreateClientReq{Client: &api.Client{Id: "test", RedirectUris: []string{"https://example.com/callback"}}}); err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+	if _, err := client.UpdateClient(ctx, &api.UpdateClientReq{Id: "test", Name: "Test"}); err != nil {
+		t.Fatalf("update client: %v", err)
+	}
+	if _, err := client.DeleteClient(ctx, &api.DeleteClientReq{Id: "test"}); err != nil {
+		t.Fatalf("delete client: %v", err)
+	}
+	// Nothing is deleted, so nothing is logged.
+	if _, err := client.DeleteClient(ctx, &api.DeleteClientReq{Id: "test"}); err != nil {
+		t.Fatalf("delete client: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 audit log lines, got %q", lines)
+	}
+	for i, action := range []string{"create client", "update client", "delete client"} {
+		for _, want := range []string{`action="` + action + `"`, "caller=ci-bot", "client_id=test", "peer=127.0.0.1:"} {
+			if !strings.Contains(lines[i], want) {
+				t.Errorf("expected audit log line %q to contain %q", lines[i], want)
+			}
+		}
+	}
+}
+
+func TestAPIAuditLogDisabled(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	serv := NewAPI(memory.New(logger), logger, "test", &Server{})
+
+	// Without an audit logger, changes are made without logging them.
+	if _, err := serv.CreateClient(context.Background(), &api.CreateClientReq{Client: &api.Client{Id: "test"}}); err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+}
+
+func TestWatchClients(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	s := memory.New(logger)
+	client := newAPIWithServer(s, logger, &Server{clientWatchInterval: 10 * time.Millisecond}, t)
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.WatchClients(ctx, &api.WatchClientsReq{})
+	if err != nil {
+		t.Fatalf("watch clients: %v", err)
+	}
+	// Receiving the stream's headers ensures the server took its snapshot.
+	if _, err := stream.Header(); err != nil {
+		t.Fatalf("stream header: %v", err)
+	}
+
+	recv := func(wantType api.ClientEvent_Type, wantName string) {
+		t.Helper()
+		event, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("receive event: %v", err)
+		}
+		if event.Type != wantType || event.Client.Id != "test" || event.Client.Name != wantName {
+			t.Fatalf("expected %s event for client test named %q, got %v", wantType, wantName, event)
+		}
+		if event.Client.Secret != "" {
+			t.Fatalf("expected %s event without the client's secret, got %v", wantType, event)
+		}
+	}
+
+	if _, err := client.CreateClient(ctx, &api.CreateClientReq{Client: &api.Client{Id: "test", Name: "Test"}}); err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+	recv(api.ClientEvent_CREATED, "Test")
+
+	if _, err := client.RotateClientSecret(ctx, &api.RotateClientSecretReq{Id: "test"}); err != nil {
+		t.Fatalf("rotate client secret: %v", err)
+	}
+	recv(api.ClientEvent_UPDATED, "Test")
+
+	if _, err := client.UpdateClient(ctx, &api.UpdateClientReq{Id: "test", Name: "Renamed"}); err != nil {
+		t.Fatalf("update client: %v", err)
+	}
+	recv(api.ClientEvent_UPDATED, "Renamed")
+
+	if _, err := client.DeleteClient(ctx, &api.DeleteClientReq{Id: "test"}); err != nil {
+		t.Fatalf("delete client: %v", err)
+	}
+	recv(api.ClientEvent_DELETED, "Renamed")
+}
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"context"
+	"time"
+)
+
+// AuditEventType identifies the kind of an AuditEvent.
+type AuditEventType string
+
+// AuditRefreshTokenReplay is emitted when a refresh token that has already been
+// rotated is presented again.
+const AuditRefreshTokenReplay AuditEventType = "refresh_token_replay"
+
+// AuditEvent describes a security relevant event observed by the server.
+type AuditEvent struct {
+	Type AuditEventType
+	Time time.Time
+
+	ClientID    string
+	UserID      string
+	ConnectorID string
+	RefreshID   string
+
+	// Revoked reports whether the server revoked the affected refresh token
+	// in response to the event.
+	Revoked bool
+}
+
+// AuditInterceptor is called for every AuditEvent emitted by the server. It runs
+// on the request path and must not block.
+type AuditInterceptor func(ctx context.Context, event AuditEvent)
+
+func (s *Server) audit(ctx context.Context, event AuditEvent) {
+	if s.auditInterceptor == nil {
+		return
+	}
+	event.Time = s.now()
+	s.auditInterceptor(ctx, event)
+}
@@ -11,11 +11,16 @@ import (
 	"strings"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/net/html"
 
 	"github.com/dexidp/dex/storage"
 )
 
+// devicePollIntervalSeconds is how often devices are told to poll for a
+// token, in seconds.
+const devicePollIntervalSeconds = 5
+
 type deviceCodeResponse struct {
 	// The unique device code for device authentication
 	DeviceCode string `json:"device_code"`
@@ -58,7 +63,6 @@ func (s *Server) handleDeviceExchange(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) handleDeviceCode(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	pollIntervalSeconds := 5
 
 	switch r.Method {
 	case http.MethodPost:
@@ -88,22 +92,24 @@ func (s *Server) handleDeviceCode(w http.ResponseWriter, r *http.Request) {
 		s.logger.InfoContext(r.Context(), "received device request", "client_id", clientID, "scoped", scopes)
 
 		// Make device code
-		deviceCode := storage.NewDeviceCode()
+		deviceCode := s.deviceCodeFormat.NewDeviceCode()
 
 		// make user code
-		userCode := storage.NewUserCode()
+		userCode := s.deviceCodeFormat.NewUserCode()
 
 		// Generate the expire time
 		expireTime := time.Now().Add(s.deviceRequestsValidFor)
 
 		// Store the Device Request
 		deviceReq := storage.DeviceRequest{
-			UserCode:     userCode,
-			DeviceCode:   deviceCode,
-			ClientID:     clientID,
-			ClientSecret: clientSecret,
-			Scopes:       scopes,
-			Expiry:       expireTime,
+			UserCode:            userCode,
+			DeviceCode:          deviceCode,
+			ClientID:            clientID,
+			ClientSecret:        clientSecret,
+			Scopes:              scopes,
+			Expiry:              expireTime,
+			PollIntervalSeconds: devicePollIntervalSeconds,
+			CreatedAt:           s.now(),
 		}
 
 		if err := s.storage.CreateDeviceRequest(ctx, deviceReq); err != nil {
@@ -118,7 +124,7 @@ func (s *Server) handleDeviceCode(w http.ResponseWriter, r *http.Request) {
 			Status:              deviceTokenPending,
 			Expiry:              expireTime,
 			LastRequestTime:     s.now(),
-			PollIntervalSeconds: 0,
+			PollIntervalSeconds: deviceReq.PollIntervalSeconds,
 			PKCE: storage.PKCE{
 				CodeChallenge:       codeChallenge,
 				CodeChallengeMethod: codeChallengeMethod,
@@ -151,7 +157,7 @@ func (s *Server) handleDeviceCode(w http.ResponseWriter, r *http.Request) {
 			VerificationURI:         vURI,
 			VerificationURIComplete: vURIComplete,
 			ExpireTime:              int(s.deviceRequestsValidFor.Seconds()),
-			PollInterval:            pollIntervalSeconds,
+			PollInterval:            deviceReq.PollIntervalSeconds,
 		}
 
 		// Device Authorization Response can contain cache control header according to
@@ -230,7 +236,7 @@ func (s *Server) handleDeviceToken(w http.ResponseWriter, r *http.Request) {
 		// Continually increase the poll interval until the user waits the proper time
 		pollInterval += 5
 	} else {
-		pollInterval = 5
+		pollInterval = devicePollIntervalSeconds
 	}
 
 	switch deviceToken.Status {
@@ -247,7 +253,8 @@ func (s *Server) handleDeviceToken(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		if slowDown {
-			s.tokenErrHelper(w, deviceTokenSlowDown, "", http.StatusBadRequest)
+			description := fmt.Sprintf("Poll at most every %d seconds.", pollInterval)
+			s.tokenErrHelper(w, deviceTokenSlowDown, description, http.StatusBadRequest)
 		} else {
 			s.tokenErrHelper(w, deviceTokenPending, "", http.StatusUnauthorized)
 		}
@@ -276,6 +283,17 @@ func (s *Server) handleDeviceToken(w http.ResponseWriter, r *http.Request) {
 			s.tokenErrHelper(w, errInvalidGrant, "Expecting parameter code_verifier in PKCE flow.", http.StatusBadRequest)
 			return
 		}
+
+		// A device code can only be exchanged once.
+		if err := s.storage.ConsumeDeviceRequest(ctx, deviceCode); err != nil {
+			if err == storage.ErrAlreadyConsumed || err == storage.ErrNotFound {
+				s.tokenErrHelper(w, errInvalidGrant, "Device code is invalid or has already been used.", http.StatusBadRequest)
+				return
+			}
+			s.logger.ErrorContext(r.Context(), "failed to consume device request", "err", err)
+			s.tokenErrHelper(w, errServerError, "", http.StatusInternalServerError)
+			return
+		}
 		w.Write([]byte(deviceToken.Token))
 	}
 }
@@ -312,10 +330,10 @@ func (s *Server) handleDeviceCallback(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Grab the device request from storage
-		deviceReq, err := s.storage.GetDeviceRequest(ctx, userCode)
-		if err != nil || s.now().After(deviceReq.Expiry) {
+		deviceReq, err := s.deviceCodeFormat.LookupDeviceRequest(ctx, s.storage, userCode, s.now())
+		if err != nil {
 			errCode := http.StatusBadRequest
-			if err != nil && err != storage.ErrNotFound {
+			if err != storage.ErrNotFound && err != storage.ErrExpired {
 				s.logger.ErrorContext(r.Context(), "failed to get device code", "err", err)
 				errCode = http.StatusInternalServerError
 			}
@@ -337,6 +355,10 @@ func (s *Server) handleDeviceCallback(w http.ResponseWriter, r *http.Request) {
 			s.tokenErrHelper(w, errInvalidClient, "Invalid client credentials.", http.StatusUnauthorized)
 			return
 		}
+		if client.Disabled {
+			s.tokenErrHelper(w, errUnauthorizedClient, "Client is disabled.", http.StatusBadRequest)
+			return
+		}
 
 		resp, err := s.exchangeAuthCode(ctx, w, authCode, client)
 		if err != nil {
@@ -408,12 +430,10 @@ func (s *Server) verifyUserCode(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		userCode = strings.ToUpper(userCode)
-
 		// Find the user code in the available requests
-		deviceRequest, err := s.storage.GetDeviceRequest(ctx, userCode)
-		if err != nil || s.now().After(deviceRequest.Expiry) {
-			if err != nil && err != storage.ErrNotFound {
+		deviceRequest, err := s.deviceCodeFormat.LookupDeviceRequest(ctx, s.storage, userCode, s.now())
+		if err != nil {
+			if err != storage.ErrNotFound && err != storage.ErrExpired {
 				s.logger.ErrorContext(r.Context(), "failed to get device request", "err", err)
 			}
 			if err := s.templates.device(r, w, s.getDeviceVerificationURI(), userCode, true); err != nil {
@@ -445,3 +465,42 @@ func (s *Server) verifyUserCode(w http.ResponseWriter, r *http.Request) {
 		s.renderError(r, w, http.StatusBadRequest, "Requested resource does not exist.")
 	}
 }
+
+// deviceRequestMetrics exports the lifecycle of device requests as Prometheus
+// counters.
+type deviceRequestMetrics struct {
+	created          prometheus.Counter
+	consumed         prometheus.Counter
+	expired          prometheus.Counter
+	redemptionFailed prometheus.Counter
+}
+
+func newDeviceRequestMetrics() *deviceRequestMetrics {
+	return &deviceRequestMetrics{
+		created: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "device_requests_created_total",
+			Help: "Count of device authorization requests created.",
+		}),
+		consumed: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "device_requests_consumed_total",
+			Help: "Count of device codes redeemed for tokens.",
+		}),
+		expired: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "device_requests_expired_total",
+			Help: "Count of expired device authorization requests deleted by garbage collection.",
+		}),
+		redemptionFailed: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "device_request_redemption_failures_total",
+			Help: "Count of device codes that couldn't be redeemed because they were unknown or already used.",
+		}),
+	}
+}
+
+func (m *deviceRequestMetrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.created, m.consumed, m.expired, m.redemptionFailed}
+}
+
+func (m *deviceRequestMetrics) DeviceRequestCreated()          { m.created.Inc() }
+func (m *deviceRequestMetrics) DeviceRequestConsumed()         { m.consumed.Inc() }
+func (m *deviceRequestMetrics) DeviceRequestsExpired(n int64)  { m.expired.Add(float64(n)) }
+func (m *deviceRequestMetrics) DeviceRequestRedemptionFailed() { m.redemptionFailed.Inc() }
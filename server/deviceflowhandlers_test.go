@@ -9,11 +9,15 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"path"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
 	"github.com/dexidp/dex/storage"
+	"github.com/dexidp/dex/storage/memory"
 )
 
 func TestDeviceVerificationURI(t *testing.T) {
@@ -137,11 +141,73 @@ func TestHandleDeviceCode(t *testing.T) {
 				if err := json.Unmarshal(body, &resp); err != nil {
 					t.Errorf("Unexpected Device Code Response Format %v", string(body))
 				}
+				if resp.PollInterval != devicePollIntervalSeconds {
+					t.Errorf("Unexpected poll interval. Expected %d got %d", devicePollIntervalSeconds, resp.PollInterval)
+				}
+
+				deviceReq, err := s.storage.GetDeviceRequest(ctx, resp.UserCode)
+				if err != nil {
+					t.Fatalf("Failed to get device request %v", err)
+				}
+				if deviceReq.PollIntervalSeconds != resp.PollInterval {
+					t.Errorf("Unexpected stored poll interval. Expected %d got %d", resp.PollInterval, deviceReq.PollIntervalSeconds)
+				}
+
+				// The device token enforces the same interval from the first poll.
+				deviceToken, err := s.storage.GetDeviceToken(ctx, resp.DeviceCode)
+				if err != nil {
+					t.Fatalf("Failed to get device token %v", err)
+				}
+				if deviceToken.PollIntervalSeconds != resp.PollInterval {
+					t.Errorf("Unexpected device token poll interval. Expected %d got %d", resp.PollInterval, deviceToken.PollIntervalSeconds)
+				}
 			}
 		})
 	}
 }
 
+func TestHandleDeviceCodeFormat(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	format := storage.DeviceCodeFormat{UserCodeLength: 9, UserCodeGroupSize: 3, DeviceCodeLength: 24}
+	httpServer, s := newTestServer(ctx, t, func(c *Config) {
+		c.DeviceCodeFormat = format
+	})
+	defer httpServer.Close()
+
+	u, err := url.Parse(s.issuerURL.String())
+	if err != nil {
+		t.Fatalf("Could not parse issuer URL %v", err)
+	}
+	u.Path = path.Join(u.Path, "device/code")
+
+	data := url.Values{}
+	data.Set("client_id", "test")
+	req, _ := http.NewRequest("POST", u.String(), bytes.NewBufferString(data.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	rr := httptest.NewRecorder()
+	s.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Unexpected Response Type.  Expected %v got %v", http.StatusOK, rr.Code)
+	}
+
+	var resp deviceCodeResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("Unexpected Device Code Response Format %v", err)
+	}
+	if err := format.ValidateUserCode(resp.UserCode); err != nil {
+		t.Errorf("Unexpected user code %q: %v", resp.UserCode, err)
+	}
+	if err := format.ValidateDeviceCode(resp.DeviceCode); err != nil {
+		t.Errorf("Unexpected device code %q: %v", resp.DeviceCode, err)
+	}
+	if err := (storage.DeviceCodeFormat{}).ValidateUserCode(resp.UserCode); err == nil {
+		t.Errorf("User code %q unexpectedly matches the default format", resp.UserCode)
+	}
+}
+
 func TestDeviceCallback(t *testing.T) {
 	t0 := time.Now()
 
@@ -706,6 +772,105 @@ func TestDeviceTokenResponse(t *testing.T) {
 	}
 }
 
+func TestDeviceTokenSingleUse(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, s := newTestServer(ctx, t, nil)
+	defer httpServer.Close()
+
+	if err := s.storage.CreateDeviceRequest(ctx, storage.DeviceRequest{
+		UserCode:   "ABCD-WXYZ",
+		DeviceCode: "foo",
+		ClientID:   "testclient",
+		Scopes:     []string{"openid"},
+		Expiry:     time.Now().Add(5 * time.Minute),
+	}); err != nil {
+		t.Fatalf("Failed to store device request %v", err)
+	}
+	if err := s.storage.CreateDeviceToken(ctx, storage.DeviceToken{
+		DeviceCode: "foo",
+		Status:     deviceTokenComplete,
+		Token:      "{\"access_token\": \"foobar\"}",
+		Expiry:     time.Now().Add(5 * time.Minute),
+	}); err != nil {
+		t.Fatalf("Failed to store device token %v", err)
+	}
+
+	u, err := url.Parse(s.issuerURL.String())
+	if err != nil {
+		t.Fatalf("Could not parse issuer URL %v", err)
+	}
+	u.Path = path.Join(u.Path, "device/token")
+
+	exchange := func() *httptest.ResponseRecorder {
+		data := url.Values{}
+		data.Set("grant_type", grantTypeDeviceCode)
+		data.Set("device_code", "foo")
+		req, _ := http.NewRequest("POST", u.String(), bytes.NewBufferString(data.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		rr := httptest.NewRecorder()
+		s.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := exchange(); rr.Code != http.StatusOK {
+		t.Fatalf("Unexpected Response Type.  Expected %v got %v", http.StatusOK, rr.Code)
+	}
+
+	// Replaying the device code must not hand out the token again.
+	rr := exchange()
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Unexpected Response Type.  Expected %v got %v", http.StatusBadRequest, rr.Code)
+	}
+	expectJSONErrorResponse("Replayed device code", rr.Body.Bytes(), errInvalidGrant, t)
+}
+
+func TestDeviceRequestMetrics(t *testing.T) {
+	ctx := context.Background()
+	m := newDeviceRequestMetrics()
+	s := storage.WithDeviceRequestMetrics(memory.New(logger), m)
+
+	now := time.Now()
+	for i, expiry := range []time.Time{now.Add(time.Minute), now.Add(-time.Minute), now.Add(-time.Minute)} {
+		if err := s.CreateDeviceRequest(ctx, storage.DeviceRequest{
+			UserCode:   storage.NewUserCode(),
+			DeviceCode: strconv.Itoa(i),
+			ClientID:   "testclient",
+			Expiry:     expiry,
+		}); err != nil {
+			t.Fatalf("Failed to store device request %v", err)
+		}
+	}
+	if err := s.ConsumeDeviceRequest(ctx, "0"); err != nil {
+		t.Fatalf("Failed to consume device request %v", err)
+	}
+	if err := s.ConsumeDeviceRequest(ctx, "0"); err != storage.ErrAlreadyConsumed {
+		t.Fatalf("Expected storage.ErrAlreadyConsumed, got %v", err)
+	}
+	if err := s.ConsumeDeviceRequest(ctx, "unknown"); err != storage.ErrNotFound {
+		t.Fatalf("Expected storage.ErrNotFound, got %v", err)
+	}
+	if _, err := s.GarbageCollect(ctx, now); err != nil {
+		t.Fatalf("Garbage collection failed %v", err)
+	}
+
+	for name, tc := range map[string]struct {
+		got  float64
+		want float64
+	}{
+		"created":           {testutil.ToFloat64(m.created), 3},
+		"consumed":          {testutil.ToFloat64(m.consumed), 1},
+		"expired":           {testutil.ToFloat64(m.expired), 2},
+		"redemption failed": {testutil.ToFloat64(m.redemptionFailed), 2},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("Unexpected %s count.  Expected %v got %v", name, tc.want, tc.got)
+		}
+	}
+}
+
 func expectJSONErrorResponse(testCase string, body []byte, expectedError string, t *testing.T) {
 	jsonMap := make(map[string]interface{})
 	err := json.Unmarshal(body, &jsonMap)
@@ -781,6 +946,19 @@ func TestVerifyCodeResponse(t *testing.T) {
 			expectedResponseCode: http.StatusFound,
 			expectedRedirectPath: "/auth",
 		},
+		{
+			testName: "Lowercase user code without separator, expect redirect to auth endpoint",
+			testDeviceRequest: storage.DeviceRequest{
+				UserCode:   "ABCD-WXYZ",
+				DeviceCode: "f00bar",
+				ClientID:   "testclient",
+				Scopes:     []string{"openid", "profile", "offline_access"},
+				Expiry:     now().Add(5 * time.Minute),
+			},
+			userCode:             "abcdwxyz",
+			expectedResponseCode: http.StatusFound,
+			expectedRedirectPath: "/auth",
+		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.testName, func(t *testing.T) {
@@ -7,6 +7,7 @@ import (
 	"crypto/subtle"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"net/http"
@@ -153,6 +154,31 @@ func (s *Server) handleAuthorization(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Only offer the connectors the client allows. Unknown clients are
+	// reported once the connector has been chosen.
+	if clientID := r.Form.Get("client_id"); clientID != "" {
+		client, err := s.storage.GetClient(ctx, clientID)
+		switch {
+		case err == nil:
+			var allowed []storage.Connector
+			for _, c := range connectors {
+				if connectorAllowed(client, c.ID) {
+					allowed = append(allowed, c)
+				}
+			}
+			if len(allowed) == 0 {
+				s.logger.ErrorContext(r.Context(), "no allowed connectors configured for client", "client_id", clientID)
+				s.renderError(r, w, http.StatusBadRequest, "No connectors are available for this client.")
+				return
+			}
+			connectors = allowed
+		case err != storage.ErrNotFound:
+			s.logger.ErrorContext(r.Context(), "failed to get client", "err", err)
+			s.renderError(r, w, http.StatusInternalServerError, "Database error.")
+			return
+		}
+	}
+
 	// We don't need connector_id any more
 	r.Form.Del("connector_id")
 
@@ -235,6 +261,19 @@ func (s *Server) handleConnectorLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	client, err := s.storage.GetClient(ctx, authReq.ClientID)
+	if err != nil {
+		s.logger.ErrorContext(r.Context(), "failed to get client", "err", err)
+		s.renderError(r, w, http.StatusInternalServerError, "Database error.")
+		return
+	}
+	if !connectorAllowed(client, connID) {
+		s.logger.ErrorContext(r.Context(), "connector not allowed for client",
+			"client_id", authReq.ClientID, "connector_id", connID)
+		s.renderError(r, w, http.StatusBadRequest, "Requested connector is not allowed for this client.")
+		return
+	}
+
 	authReq.ConnectorID = connID
 
 	// Actually create the auth request
@@ -479,7 +518,12 @@ func (s *Server) handleConnectorCallback(w http.ResponseWriter, r *http.Request)
 	}
 
 	if err != nil {
-		s.logger.ErrorContext(r.Context(), "failed to authenticate", "err", err)
+		attrs := []any{"err", err}
+		var authzErr *connector.AuthorizationError
+		if errors.As(err, &authzErr) {
+			attrs = append(attrs, "denied", authzErr.Denied)
+		}
+		s.logger.ErrorContext(r.Context(), "failed to authenticate", attrs...)
 		s.renderError(r, w, http.StatusInternalServerError, fmt.Sprintf("Failed to authenticate: %v", err))
 		return
 	}
@@ -515,6 +559,7 @@ func (s *Server) finalizeLogin(ctx context.Context, identity connector.Identity,
 		Email:             identity.Email,
 		EmailVerified:     identity.EmailVerified,
 		Groups:            identity.Groups,
+		ExtraClaims:       identity.ExtraClaims,
 	}
 
 	updater := func(a storage.AuthRequest) (storage.AuthRequest, error) {
@@ -826,6 +871,11 @@ func (s *Server) withClientFromStorage(w http.ResponseWriter, r *http.Request, h
 		s.tokenErrHelper(w, errInvalidClient, "Invalid client credentials.", http.StatusUnauthorized)
 		return
 	}
+	if client.Disabled {
+		s.logger.InfoContext(r.Context(), "token request for disabled client", "client_id", client.ID)
+		s.tokenErrHelper(w, errUnauthorizedClient, "Client is disabled.", http.StatusBadRequest)
+		return
+	}
 
 	handler(w, r, client)
 }
@@ -940,14 +990,14 @@ func (s *Server) handleAuthCode(w http.ResponseWriter, r *http.Request, client s
 }
 
 func (s *Server) exchangeAuthCode(ctx context.Context, w http.ResponseWriter, authCode storage.AuthCode, client storage.Client) (*accessTokenResponse, error) {
-	accessToken, _, err := s.newAccessToken(ctx, client.ID, authCode.Claims, authCode.Scopes, authCode.Nonce, authCode.ConnectorID)
+	accessToken, expiry, err := s.newAccessToken(ctx, client.ID, authCode.Claims, authCode.Scopes, authCode.Nonce, authCode.ConnectorID)
 	if err != nil {
 		s.logger.ErrorContext(ctx, "failed to create new access token", "err", err)
 		s.tokenErrHelper(w, errServerError, "", http.StatusInternalServerError)
 		return nil, err
 	}
 
-	idToken, expiry, err := s.newIDToken(ctx, client.ID, authCode.Claims, authCode.Scopes, authCode.Nonce, accessToken, authCode.ID, authCode.ConnectorID)
+	idToken, _, err := s.newIDToken(ctx, client.ID, authCode.Claims, authCode.Scopes, authCode.Nonce, accessToken, authCode.ID, authCode.ConnectorID)
 	if err != nil {
 		s.logger.ErrorContext(ctx, "failed to create ID token", "err", err)
 		s.tokenErrHelper(w, errServerError, "", http.StatusInternalServerError)
@@ -1169,6 +1219,10 @@ func (s *Server) handlePasswordGrant(w http.ResponseWriter, r *http.Request, cli
 
 	// Which connector
 	connID := s.passwordConnector
+	if !connectorAllowed(client, connID) {
+		s.tokenErrHelper(w, errInvalidRequest, "Requested connector is not allowed for this client.", http.StatusBadRequest)
+		return
+	}
 	conn, err := s.getConnector(ctx, connID)
 	if err != nil {
 		s.tokenErrHelper(w, errInvalidRequest, "Requested connector does not exist.", http.StatusBadRequest)
@@ -1203,16 +1257,17 @@ func (s *Server) handlePasswordGrant(w http.ResponseWriter, r *http.Request, cli
 		Email:             identity.Email,
 		EmailVerified:     identity.EmailVerified,
 		Groups:            identity.Groups,
+		ExtraClaims:       identity.ExtraClaims,
 	}
 
-	accessToken, _, err := s.newAccessToken(ctx, client.ID, claims, scopes, nonce, connID)
+	accessToken, expiry, err := s.newAccessToken(ctx, client.ID, claims, scopes, nonce, connID)
 	if err != nil {
 		s.logger.ErrorContext(r.Context(), "password grant failed to create new access token", "err", err)
 		s.tokenErrHelper(w, errServerError, "", http.StatusInternalServerError)
 		return
 	}
 
-	idToken, expiry, err := s.newIDToken(ctx, client.ID, claims, scopes, nonce, accessToken, "", connID)
+	idToken, _, err := s.newIDToken(ctx, client.ID, claims, scopes, nonce, accessToken, "", connID)
 	if err != nil {
 		s.logger.ErrorContext(r.Context(), "password grant failed to create new ID token", "err", err)
 		s.tokenErrHelper(w, errServerError, "", http.StatusInternalServerError)
@@ -1375,6 +1430,10 @@ func (s *Server) handleTokenExchange(w http.ResponseWriter, r *http.Request, cli
 		return
 	}
 
+	if !connectorAllowed(client, connID) {
+		s.tokenErrHelper(w, errInvalidRequest, "Requested connector is not allowed for this client.", http.StatusBadRequest)
+		return
+	}
 	conn, err := s.getConnector(ctx, connID)
 	if err != nil {
 		s.logger.ErrorContext(r.Context(), "failed to get connector", "err", err)
@@ -1401,6 +1460,7 @@ func (s *Server) handleTokenExchange(w http.ResponseWriter, r *http.Request, cli
 		Email:             identity.Email,
 		EmailVerified:     identity.EmailVerified,
 		Groups:            identity.Groups,
+		ExtraClaims:       identity.ExtraClaims,
 	}
 	resp := accessTokenResponse{
 		IssuedTokenType: requestedTokenType,
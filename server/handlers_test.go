@@ -419,6 +419,183 @@ func TestHandlePassword(t *testing.T) {
 	}
 }
 
+func TestHandleDisabledClient(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, s := newTestServer(ctx, t, func(c *Config) {
+		c.PasswordConnector = "test"
+		c.Now = time.Now
+	})
+	defer httpServer.Close()
+
+	mockConnectorDataTestStorage(t, s.storage)
+
+	authorize := func() *httptest.ResponseRecorder {
+		u, err := url.Parse(s.issuerURL.String())
+		require.NoError(t, err)
+
+		u.Path = path.Join(u.Path, "/auth/test")
+		u.RawQuery = url.Values{
+			"client_id":     {"test"},
+			"redirect_uri":  {"https://auth.example.com"},
+			"response_type": {"code"},
+			"scope":         {"openid"},
+		}.Encode()
+
+		rr := httptest.NewRecorder()
+		s.ServeHTTP(rr, httptest.NewRequest("GET", u.String(), nil))
+		return rr
+	}
+	token := func() *httptest.ResponseRecorder {
+		u, err := url.Parse(s.issuerURL.String())
+		require.NoError(t, err)
+
+		u.Path = path.Join(u.Path, "/token")
+		v := url.Values{
+			"scope":      {"openid"},
+			"grant_type": {"password"},
+			"username":   {"test"},
+			"password":   {"test"},
+		}
+
+		req, _ := http.NewRequest("POST", u.String(), bytes.NewBufferString(v.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth("test", "barfoo")
+
+		rr := httptest.NewRecorder()
+		s.ServeHTTP(rr, req)
+		return rr
+	}
+	setDisabled := func(disabled bool) {
+		err := s.storage.UpdateClient(ctx, "test", func(old storage.Client) (storage.Client, error) {
+			old.Disabled = disabled
+			return old, nil
+		})
+		require.NoError(t, err)
+	}
+
+	setDisabled(true)
+
+	rr := authorize()
+	require.Equal(t, http.StatusForbidden, rr.Code)
+
+	rr = token()
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	var tokenErr struct {
+		Error string `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &tokenErr))
+	require.Equal(t, errUnauthorizedClient, tokenErr.Error)
+
+	setDisabled(false)
+
+	rr = authorize()
+	require.NotEqual(t, http.StatusForbidden, rr.Code)
+	require.Less(t, rr.Code, http.StatusBadRequest)
+
+	rr = token()
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestHandleAllowedConnectors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer, s := newTestServer(ctx, t, func(c *Config) {
+		c.PasswordConnector = "test"
+		c.Now = time.Now
+	})
+	defer httpServer.Close()
+
+	mockConnectorDataTestStorage(t, s.storage)
+
+	authorize := func(p string, extra url.Values) *httptest.ResponseRecorder {
+		u, err := url.Parse(s.issuerURL.String())
+		require.NoError(t, err)
+
+		q := url.Values{
+			"client_id":     {"test"},
+			"redirect_uri":  {"https://auth.example.com"},
+			"response_type": {"code"},
+			"scope":         {"openid"},
+		}
+		for k, v := range extra {
+			q[k] = v
+		}
+		u.Path = path.Join(u.Path, p)
+		u.RawQuery = q.Encode()
+
+		rr := httptest.NewRecorder()
+		s.ServeHTTP(rr, httptest.NewRequest("GET", u.String(), nil))
+		return rr
+	}
+	token := func() *httptest.ResponseRecorder {
+		u, err := url.Parse(s.issuerURL.String())
+		require.NoError(t, err)
+
+		u.Path = path.Join(u.Path, "/token")
+		v := url.Values{
+			"scope":      {"openid"},
+			"grant_type": {"password"},
+			"username":   {"test"},
+			"password":   {"test"},
+		}
+
+		req, _ := http.NewRequest("POST", u.String(), bytes.NewBufferString(v.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth("test", "barfoo")
+
+		rr := httptest.NewRecorder()
+		s.ServeHTTP(rr, req)
+		return rr
+	}
+	setAllowed := func(allowed ...string) {
+		err := s.storage.UpdateClient(ctx, "test", func(old storage.Client) (storage.Client, error) {
+			old.AllowedConnectors = allowed
+			return old, nil
+		})
+		require.NoError(t, err)
+	}
+
+	// Without a restriction all connectors are offered.
+	rr := authorize("/auth", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), "/auth/test?")
+
+	rr = authorize("/auth/test", nil)
+	require.Less(t, rr.Code, http.StatusBadRequest)
+
+	setAllowed("http://any.valid.url/")
+
+	// The only allowed connector is chosen automatically.
+	rr = authorize("/auth", nil)
+	require.Equal(t, http.StatusFound, rr.Code)
+	require.Contains(t, rr.Header().Get("Location"), "any.valid.url")
+
+	rr = authorize("/auth", url.Values{"connector_id": {"test"}})
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = authorize("/auth/test", nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = token()
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	var tokenErr struct {
+		Error string `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &tokenErr))
+	require.Equal(t, errInvalidRequest, tokenErr.Error)
+
+	setAllowed("test", "http://any.valid.url/")
+
+	rr = authorize("/auth/test", nil)
+	require.Less(t, rr.Code, http.StatusBadRequest)
+
+	rr = token()
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
 func TestHandlePasswordLoginWithSkipApproval(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
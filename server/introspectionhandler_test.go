@@ -244,7 +244,7 @@ func TestHandleIntrospect(t *testing.T) {
 	// Setup a dex server.
 	now := func() time.Time { return t0 }
 
-	refreshTokenPolicy, err := NewRefreshTokenPolicy(logger, false, "", "24h", "")
+	refreshTokenPolicy, err := NewRefreshTokenPolicy(logger, false, "", "24h", "", false)
 	if err != nil {
 		t.Fatalf("failed to prepare rotation policy: %v", err)
 	}
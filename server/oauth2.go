@@ -303,8 +303,52 @@ type federatedIDClaims struct {
 	UserID      string `json:"user_id,omitempty"`
 }
 
+// mergeExtraClaims adds connector-provided claims to a serialized token payload.
+// Claims already set in the payload take precedence over extra claims.
+func mergeExtraClaims(payload []byte, extra map[string]interface{}) ([]byte, error) {
+	var merged map[string]interface{}
+	if err := json.Unmarshal(payload, &merged); err != nil {
+		return nil, err
+	}
+	for k, v := range extra {
+		if _, ok := merged[k]; !ok && !reservedClaims[k] {
+			merged[k] = v
+		}
+	}
+	return json.Marshal(merged)
+}
+
+// reservedClaims are the claims dex sets itself, whether or not they are
+// present in a given token. Connectors can't override them through extra claims.
+var reservedClaims = map[string]bool{
+	"iss": true, "sub": true, "aud": true, "exp": true, "iat": true, "azp": true, "nonce": true,
+	"at_hash": true, "c_hash": true, "email": true, "email_verified": true, "groups": true,
+	"name": true, "preferred_username": true, "federated_claims": true,
+}
+
 func (s *Server) newAccessToken(ctx context.Context, clientID string, claims storage.Claims, scopes []string, nonce, connID string) (accessToken string, expiry time.Time, err error) {
-	return s.newIDToken(ctx, clientID, claims, scopes, nonce, storage.NewID(), "", connID)
+	validFor, err := s.accessTokenValidFor(ctx, clientID)
+	if err != nil {
+		return "", expiry, err
+	}
+	return s.newToken(ctx, validFor, clientID, claims, scopes, nonce, storage.NewID(), "", connID)
+}
+
+// accessTokenValidFor returns how long access tokens issued to the client are
+// valid for, honoring the client's override if one is set.
+func (s *Server) accessTokenValidFor(ctx context.Context, clientID string) (time.Duration, error) {
+	client, err := s.storage.GetClient(ctx, clientID)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			return s.idTokensValidFor, nil
+		}
+		s.logger.ErrorContext(ctx, "failed to get client", "client_id", clientID, "err", err)
+		return 0, fmt.Errorf("failed to get client: %v", err)
+	}
+	if client.AccessTokenLifetime > 0 {
+		return time.Duration(client.AccessTokenLifetime) * time.Second, nil
+	}
+	return s.idTokensValidFor, nil
 }
 
 func getClientID(aud audience, azp string) (string, error) {
@@ -351,6 +395,10 @@ func genSubject(userID string, connID string) (string, error) {
 }
 
 func (s *Server) newIDToken(ctx context.Context, clientID string, claims storage.Claims, scopes []string, nonce, accessToken, code, connID string) (idToken string, expiry time.Time, err error) {
+	return s.newToken(ctx, s.idTokensValidFor, clientID, claims, scopes, nonce, accessToken, code, connID)
+}
+
+func (s *Server) newToken(ctx context.Context, validFor time.Duration, clientID string, claims storage.Claims, scopes []string, nonce, accessToken, code, connID string) (idToken string, expiry time.Time, err error) {
 	keys, err := s.storage.GetKeys(ctx)
 	if err != nil {
 		s.logger.ErrorContext(ctx, "failed to get keys", "err", err)
@@ -367,7 +415,7 @@ func (s *Server) newIDToken(ctx context.Context, clientID string, claims storage
 	}
 
 	issuedAt := s.now()
-	expiry = issuedAt.Add(s.idTokensValidFor)
+	expiry = issuedAt.Add(validFor)
 
 	subjectString, err := genSubject(claims.UserID, connID)
 	if err != nil {
@@ -445,6 +493,12 @@ func (s *Server) newIDToken(ctx context.Context, clientID string, claims storage
 		return "", expiry, fmt.Errorf("could not serialize claims: %v", err)
 	}
 
+	if len(claims.ExtraClaims) > 0 {
+		if payload, err = mergeExtraClaims(payload, claims.ExtraClaims); err != nil {
+			return "", expiry, fmt.Errorf("could not serialize claims: %v", err)
+		}
+	}
+
 	if idToken, err = signPayload(signingKey, signingAlg, payload); err != nil {
 		return "", expiry, fmt.Errorf("failed to sign payload: %v", err)
 	}
@@ -486,6 +540,10 @@ func (s *Server) parseAuthorizationRequest(r *http.Request) (*storage.AuthReques
 		s.logger.ErrorContext(r.Context(), "failed to get client", "err", err)
 		return nil, newDisplayedErr(http.StatusInternalServerError, "Database error.")
 	}
+	if client.Disabled {
+		s.logger.InfoContext(r.Context(), "authorization request for disabled client", "client_id", clientID)
+		return nil, newDisplayedErr(http.StatusForbidden, "Client %q is disabled.", clientID)
+	}
 
 	if !validateRedirectURI(client, redirectURI) {
 		return nil, newDisplayedErr(http.StatusBadRequest, "Unregistered redirect_uri (%q).", redirectURI)
@@ -508,6 +566,9 @@ func (s *Server) parseAuthorizationRequest(r *http.Request) (*storage.AuthReques
 		if !validateConnectorID(connectors, connectorID) {
 			return nil, newRedirectedErr(errInvalidRequest, "Invalid ConnectorID")
 		}
+		if !connectorAllowed(client, connectorID) {
+			return nil, newRedirectedErr(errInvalidRequest, "Connector %q is not allowed for this client.", connectorID)
+		}
 	}
 
 	// dex doesn't support request parameter and must return request_not_supported error
@@ -703,6 +764,20 @@ func validateConnectorID(connectors []storage.Connector, connectorID string) boo
 	return false
 }
 
+// connectorAllowed reports whether users of the client may log in with the
+// connector. Clients without allowed connectors may use any connector.
+func connectorAllowed(client storage.Client, connectorID string) bool {
+	if len(client.AllowedConnectors) == 0 {
+		return true
+	}
+	for _, id := range client.AllowedConnectors {
+		if id == connectorID {
+			return true
+		}
+	}
+	return false
+}
+
 // storageKeySet implements the oidc.KeySet interface backed by Dex storage
 type storageKeySet struct {
 	storage.Storage
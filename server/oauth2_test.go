@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-jose/go-jose/v4"
 	"github.com/stretchr/testify/require"
@@ -396,6 +397,53 @@ func TestAccessTokenHash(t *testing.T) {
 	}
 }
 
+func TestMergeExtraClaims(t *testing.T) {
+	payload := []byte(`{"iss":"https://dex","sub":"1234"}`)
+	extra := map[string]interface{}{
+		"github_org_count": 3,
+		"sub":              "5678",
+		"email":            "jane@example.com",
+	}
+
+	merged, err := mergeExtraClaims(payload, extra)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"iss":"https://dex","sub":"1234","github_org_count":3}`, string(merged))
+}
+
+func TestAccessTokenClientLifetime(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	t0 := time.Now().Round(time.Second)
+	httpServer, s := newTestServer(ctx, t, func(c *Config) {
+		c.IDTokensValidFor = time.Hour
+		c.Now = func() time.Time { return t0 }
+	})
+	defer httpServer.Close()
+
+	for _, c := range []storage.Client{
+		{ID: "default", Secret: "secret"},
+		{ID: "short", Secret: "secret", AccessTokenLifetime: 300},
+	} {
+		require.NoError(t, s.storage.CreateClient(ctx, c))
+	}
+
+	claims := storage.Claims{UserID: "1", Username: "jane"}
+	scopes := []string{"openid"}
+
+	_, expiry, err := s.newAccessToken(ctx, "default", claims, scopes, "", "mock")
+	require.NoError(t, err)
+	require.Equal(t, t0.Add(time.Hour), expiry)
+
+	_, expiry, err = s.newAccessToken(ctx, "short", claims, scopes, "", "mock")
+	require.NoError(t, err)
+	require.Equal(t, t0.Add(5*time.Minute), expiry)
+
+	_, expiry, err = s.newIDToken(ctx, "short", claims, scopes, "", "", "", "mock")
+	require.NoError(t, err)
+	require.Equal(t, t0.Add(time.Hour), expiry, "ID tokens keep the server default")
+}
+
 func TestValidRedirectURI(t *testing.T) {
 	tests := []struct {
 		client      storage.Client
@@ -79,6 +79,20 @@ type refreshContext struct {
 	scopes []string
 }
 
+// refreshTokenLifetime returns the client's refresh token lifetime override, or
+// zero if the server default applies.
+func (s *Server) refreshTokenLifetime(ctx context.Context, clientID string) (time.Duration, error) {
+	client, err := s.storage.GetClient(ctx, clientID)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			return 0, nil
+		}
+		s.logger.ErrorContext(ctx, "failed to get client", "client_id", clientID, "err", err)
+		return 0, fmt.Errorf("failed to get client: %v", err)
+	}
+	return time.Duration(client.RefreshTokenLifetime) * time.Second, nil
+}
+
 // getRefreshTokenFromStorage checks that refresh token is valid and exists in the storage and gets its info
 func (s *Server) getRefreshTokenFromStorage(ctx context.Context, clientID *string, token *internal.RefreshToken) (*refreshContext, *refreshError) {
 	refreshCtx := refreshContext{requestToken: token}
@@ -101,19 +115,33 @@ func (s *Server) getRefreshTokenFromStorage(ctx context.Context, clientID *strin
 		return nil, &refreshError{msg: errInvalidGrant, desc: invalidErr.desc, code: http.StatusBadRequest}
 	}
 
+	if refresh.Consumed {
+		s.logger.ErrorContext(ctx, "refresh token was revoked after a replay", "token_id", refresh.ID)
+		return nil, invalidErr
+	}
+
 	if refresh.Token != token.Token {
 		switch {
+		case refresh.ObsoleteToken == "", refresh.ObsoleteToken != token.Token:
+			// Neither the current nor the rotated token, so the secret is
+			// merely wrong and the session is left alone.
+			s.logger.ErrorContext(ctx, "refresh token secret doesn't match", "token_id", refresh.ID)
+			return nil, invalidErr
 		case !s.refreshTokenPolicy.AllowedToReuse(refresh.LastUsed):
-			fallthrough
-		case refresh.ObsoleteToken != token.Token:
-			fallthrough
-		case refresh.ObsoleteToken == "":
 			s.logger.ErrorContext(ctx, "refresh token claimed twice", "token_id", refresh.ID)
+			// Introspection doesn't claim the token, only treat refresh requests as replays.
+			if clientID != nil {
+				s.handleRefreshReplay(ctx, refresh)
+			}
 			return nil, invalidErr
 		}
 	}
 
-	if s.refreshTokenPolicy.CompletelyExpired(refresh.CreatedAt) {
+	lifetime, err := s.refreshTokenLifetime(ctx, refresh.ClientID)
+	if err != nil {
+		return nil, newInternalServerError()
+	}
+	if s.refreshTokenPolicy.CompletelyExpiredAfter(refresh.CreatedAt, lifetime) {
 		s.logger.ErrorContext(ctx, "refresh token expired", "token_id", refresh.ID)
 		return nil, expiredErr
 	}
@@ -150,6 +178,34 @@ func (s *Server) getRefreshTokenFromStorage(ctx context.Context, clientID *strin
 	return &refreshCtx, nil
 }
 
+// handleRefreshReplay reports a replay of an already rotated refresh token and,
+// if the policy asks for it, revokes the refresh token so that neither the
+// replayed token nor its current successor can be used again.
+func (s *Server) handleRefreshReplay(ctx context.Context, refresh storage.RefreshToken) {
+	revoke := s.refreshTokenPolicy.RevokeOnReplay()
+	if revoke {
+		err := s.storage.UpdateRefreshToken(ctx, refresh.ID, func(old storage.RefreshToken) (storage.RefreshToken, error) {
+			old.Consumed = true
+			return old, nil
+		})
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to revoke replayed refresh token", "token_id", refresh.ID, "err", err)
+			revoke = false
+		}
+	}
+
+	s.logger.WarnContext(ctx, "refresh token replay detected",
+		"token_id", refresh.ID, "client_id", refresh.ClientID, "user_id", refresh.Claims.UserID, "revoked", revoke)
+	s.audit(ctx, AuditEvent{
+		Type:        AuditRefreshTokenReplay,
+		ClientID:    refresh.ClientID,
+		UserID:      refresh.Claims.UserID,
+		ConnectorID: refresh.ConnectorID,
+		RefreshID:   refresh.ID,
+		Revoked:     revoke,
+	})
+}
+
 func (s *Server) getRefreshScopes(r *http.Request, refresh *storage.RefreshToken) ([]string, *refreshError) {
 	// Per the OAuth2 spec, if the client has omitted the scopes, default to the original
 	// authorized scopes.
@@ -196,6 +252,18 @@ func (s *Server) refreshWithConnector(ctx context.Context, rCtx *refreshContext,
 		newIdent, err := refreshConn.Refresh(ctx, parseScopes(rCtx.scopes), ident)
 		if err != nil {
 			s.logger.ErrorContext(ctx, "failed to refresh identity", "err", err)
+			if errors.Is(err, connector.ErrUserNotFound) {
+				// The upstream account is gone, retrying the refresh won't help.
+				return ident, &refreshError{msg: errInvalidGrant, desc: "Upstream user no longer exists.", code: http.StatusBadRequest}
+			}
+			if errors.Is(err, connector.ErrTokenRevoked) {
+				// The user has to log in again to grant dex a new upstream token.
+				return ident, &refreshError{msg: errInvalidGrant, desc: "Upstream authorization was revoked.", code: http.StatusBadRequest}
+			}
+			if errors.Is(err, connector.ErrUnavailable) {
+				// The upstream is down, e.g. for maintenance, ask the client to come back later.
+				return ident, &refreshError{msg: errTemporarilyUnavailable, desc: "Upstream identity provider is temporarily unavailable.", code: http.StatusServiceUnavailable}
+			}
 			return ident, newInternalServerError()
 		}
 
@@ -250,6 +318,7 @@ func (s *Server) updateRefreshToken(ctx context.Context, rCtx *refreshContext) (
 		Email:             rCtx.storageToken.Claims.Email,
 		EmailVerified:     rCtx.storageToken.Claims.EmailVerified,
 		Groups:            rCtx.storageToken.Claims.Groups,
+		ExtraClaims:       rCtx.storageToken.Claims.ExtraClaims,
 	}
 
 	refreshTokenUpdater := func(old storage.RefreshToken) (storage.RefreshToken, error) {
@@ -309,6 +378,7 @@ func (s *Server) updateRefreshToken(ctx context.Context, rCtx *refreshContext) (
 		old.Claims.Email = ident.Email
 		old.Claims.EmailVerified = ident.EmailVerified
 		old.Claims.Groups = ident.Groups
+		old.Claims.ExtraClaims = ident.ExtraClaims
 
 		return old, nil
 	}
@@ -362,16 +432,17 @@ func (s *Server) handleRefreshToken(w http.ResponseWriter, r *http.Request, clie
 		Email:             ident.Email,
 		EmailVerified:     ident.EmailVerified,
 		Groups:            ident.Groups,
+		ExtraClaims:       ident.ExtraClaims,
 	}
 
-	accessToken, _, err := s.newAccessToken(r.Context(), client.ID, claims, rCtx.scopes, rCtx.storageToken.Nonce, rCtx.storageToken.ConnectorID)
+	accessToken, expiry, err := s.newAccessToken(r.Context(), client.ID, claims, rCtx.scopes, rCtx.storageToken.Nonce, rCtx.storageToken.ConnectorID)
 	if err != nil {
 		s.logger.ErrorContext(r.Context(), "failed to create new access token", "err", err)
 		s.refreshTokenErrHelper(w, newInternalServerError())
 		return
 	}
 
-	idToken, expiry, err := s.newIDToken(r.Context(), client.ID, claims, rCtx.scopes, rCtx.storageToken.Nonce, accessToken, "", rCtx.storageToken.ConnectorID)
+	idToken, _, err := s.newIDToken(r.Context(), client.ID, claims, rCtx.scopes, rCtx.storageToken.Nonce, accessToken, "", rCtx.storageToken.ConnectorID)
 	if err != nil {
 		s.logger.ErrorContext(r.Context(), "failed to create ID token", "err", err)
 		s.refreshTokenErrHelper(w, newInternalServerError())
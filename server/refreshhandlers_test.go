@@ -4,6 +4,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -13,6 +17,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/dexidp/dex/connector"
 	"github.com/dexidp/dex/server/internal"
 	"github.com/dexidp/dex/storage"
 )
@@ -85,7 +90,9 @@ func TestRefreshTokenExpirationScenarios(t *testing.T) {
 		name        string
 		policy      *RefreshTokenPolicy
 		useObsolete bool
-		error       string
+		// clientLifetime is the client's refresh token lifetime, in seconds.
+		clientLifetime int64
+		error          string
 	}{
 		{
 			name:   "Normal",
@@ -119,6 +126,26 @@ func TestRefreshTokenExpirationScenarios(t *testing.T) {
 			},
 			error: `{"error":"invalid_request","error_description":"Refresh token expired."}`,
 		},
+		{
+			name: "Expired by the client's lifetime",
+			policy: &RefreshTokenPolicy{
+				rotateRefreshTokens: true,
+				absoluteLifetime:    time.Hour * 24,
+				now:                 func() time.Time { return t0.Add(time.Hour) },
+			},
+			clientLifetime: 60,
+			error:          `{"error":"invalid_request","error_description":"Refresh token expired."}`,
+		},
+		{
+			name: "Not expired because of the client's lifetime",
+			policy: &RefreshTokenPolicy{
+				rotateRefreshTokens: true,
+				absoluteLifetime:    time.Second * 60,
+				now:                 func() time.Time { return t0.Add(time.Hour) },
+			},
+			clientLifetime: 60 * 60 * 24,
+			error:          ``,
+		},
 		{
 			name:        "Obsolete tokens are allowed",
 			useObsolete: true,
@@ -164,6 +191,11 @@ func TestRefreshTokenExpirationScenarios(t *testing.T) {
 			defer httpServer.Close()
 
 			mockRefreshTokenTestStorage(t, s.storage, tc.useObsolete)
+			err := s.storage.UpdateClient(ctx, "test", func(c storage.Client) (storage.Client, error) {
+				c.RefreshTokenLifetime = tc.clientLifetime
+				return c, nil
+			})
+			require.NoError(t, err)
 
 			u, err := url.Parse(s.issuerURL.String())
 			require.NoError(t, err)
@@ -211,3 +243,133 @@ func TestRefreshTokenExpirationScenarios(t *testing.T) {
 		})
 	}
 }
+
+func TestRefreshTokenReplay(t *testing.T) {
+	t0 := time.Now()
+
+	for _, revoke := range []bool{false, true} {
+		t.Run(fmt.Sprintf("revoke=%t", revoke), func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var events []AuditEvent
+			httpServer, s := newTestServer(ctx, t, func(c *Config) {
+				c.RefreshTokenPolicy = &RefreshTokenPolicy{
+					rotateRefreshTokens: true,
+					revokeOnReplay:      revoke,
+					now:                 func() time.Time { return t0 },
+				}
+				c.Now = func() time.Time { return t0 }
+				c.AuditInterceptor = func(_ context.Context, event AuditEvent) {
+					events = append(events, event)
+				}
+			})
+			defer httpServer.Close()
+
+			// The stored token was already rotated from "bar" to "testtest".
+			mockRefreshTokenTestStorage(t, s.storage, true)
+
+			refresh := func(token string) *httptest.ResponseRecorder {
+				tokenData, err := internal.Marshal(&internal.RefreshToken{RefreshId: "test", Token: token})
+				require.NoError(t, err)
+
+				v := url.Values{}
+				v.Add("grant_type", "refresh_token")
+				v.Add("refresh_token", tokenData)
+
+				req, _ := http.NewRequest("POST", s.issuerURL.String()+"/token", bytes.NewBufferString(v.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				req.SetBasicAuth("test", "barfoo")
+
+				rr := httptest.NewRecorder()
+				s.ServeHTTP(rr, req)
+				return rr
+			}
+
+			// A wrong secret is rejected without being treated as a replay.
+			rr := refresh("wrong")
+			require.Equal(t, http.StatusBadRequest, rr.Code)
+			require.Empty(t, events)
+			stored, err := s.storage.GetRefresh(ctx, "test")
+			require.NoError(t, err)
+			require.False(t, stored.Consumed)
+
+			rr = refresh("bar")
+			require.Equal(t, http.StatusBadRequest, rr.Code)
+			require.Equal(t, `{"error":"invalid_request","error_description":"Refresh token is invalid or has already been claimed by another client."}`, rr.Body.String())
+
+			require.Len(t, events, 1)
+			require.Equal(t, AuditRefreshTokenReplay, events[0].Type)
+			require.Equal(t, "test", events[0].RefreshID)
+			require.Equal(t, "test", events[0].ClientID)
+			require.Equal(t, "1", events[0].UserID)
+			require.Equal(t, revoke, events[0].Revoked)
+
+			stored, err = s.storage.GetRefresh(ctx, "test")
+			require.NoError(t, err)
+			require.Equal(t, revoke, stored.Consumed)
+
+			// The current token only keeps working if the replay didn't revoke it.
+			rr = refresh("testtest")
+			if revoke {
+				require.Equal(t, http.StatusBadRequest, rr.Code)
+			} else {
+				require.Equal(t, http.StatusOK, rr.Code)
+			}
+			require.Len(t, events, 1)
+		})
+	}
+}
+
+// failingRefreshConnector is a connector whose refreshes fail with err.
+type failingRefreshConnector struct {
+	err error
+}
+
+func (c failingRefreshConnector) Refresh(context.Context, connector.Scopes, connector.Identity) (connector.Identity, error) {
+	return connector.Identity{}, c.err
+}
+
+func TestRefreshWithConnectorErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code int
+		desc string
+	}{
+		{
+			name: "user not found",
+			err:  fmt.Errorf("upstream: %w", connector.ErrUserNotFound),
+			code: http.StatusBadRequest,
+			desc: "Upstream user no longer exists.",
+		},
+		{
+			name: "token revoked",
+			err:  fmt.Errorf("upstream: %w", connector.ErrTokenRevoked),
+			code: http.StatusBadRequest,
+			desc: "Upstream authorization was revoked.",
+		},
+		{
+			name: "unavailable",
+			err:  fmt.Errorf("upstream: %w", connector.ErrUnavailable),
+			code: http.StatusServiceUnavailable,
+			desc: "Upstream identity provider is temporarily unavailable.",
+		},
+		{
+			name: "other error",
+			err:  errors.New("upstream: boom"),
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	s := &Server{logger: slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rCtx := &refreshContext{connector: Connector{Connector: failingRefreshConnector{err: tc.err}}}
+			_, rerr := s.refreshWithConnector(context.Background(), rCtx, connector.Identity{})
+			require.NotNil(t, rerr)
+			require.Equal(t, tc.code, rerr.code)
+			require.Equal(t, tc.desc, rerr.desc)
+		})
+	}
+}
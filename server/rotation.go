@@ -185,13 +185,15 @@ type RefreshTokenPolicy struct {
 	validIfNotUsedFor time.Duration // interval from last token update to the end of its life
 	reuseInterval     time.Duration // interval within which old refresh token is allowed to be reused
 
+	revokeOnReplay bool // revoke the whole refresh token when an already rotated token is replayed
+
 	now func() time.Time
 
 	logger *slog.Logger
 }
 
-func NewRefreshTokenPolicy(logger *slog.Logger, rotation bool, validIfNotUsedFor, absoluteLifetime, reuseInterval string) (*RefreshTokenPolicy, error) {
-	r := RefreshTokenPolicy{now: time.Now, logger: logger}
+func NewRefreshTokenPolicy(logger *slog.Logger, rotation bool, validIfNotUsedFor, absoluteLifetime, reuseInterval string, revokeOnReplay bool) (*RefreshTokenPolicy, error) {
+	r := RefreshTokenPolicy{now: time.Now, logger: logger, revokeOnReplay: revokeOnReplay}
 	var err error
 
 	if validIfNotUsedFor != "" {
@@ -220,6 +222,7 @@ func NewRefreshTokenPolicy(logger *slog.Logger, rotation bool, validIfNotUsedFor
 
 	r.rotateRefreshTokens = !rotation
 	logger.Info("config refresh tokens rotation", "enabled", r.rotateRefreshTokens)
+	logger.Info("config refresh tokens replay revocation", "enabled", r.revokeOnReplay)
 	return &r, nil
 }
 
@@ -234,6 +237,15 @@ func (r *RefreshTokenPolicy) CompletelyExpired(lastUsed time.Time) bool {
 	return r.now().After(lastUsed.Add(r.absoluteLifetime))
 }
 
+// CompletelyExpiredAfter is like CompletelyExpired, but a positive lifetime
+// overrides the policy's absolute lifetime.
+func (r *RefreshTokenPolicy) CompletelyExpiredAfter(createdAt time.Time, lifetime time.Duration) bool {
+	if lifetime <= 0 {
+		return r.CompletelyExpired(createdAt)
+	}
+	return r.now().After(createdAt.Add(lifetime))
+}
+
 func (r *RefreshTokenPolicy) ExpiredBecauseUnused(lastUsed time.Time) bool {
 	if r.validIfNotUsedFor == 0 {
 		return false // expiration disabled
@@ -241,6 +253,10 @@ func (r *RefreshTokenPolicy) ExpiredBecauseUnused(lastUsed time.Time) bool {
 	return r.now().After(lastUsed.Add(r.validIfNotUsedFor))
 }
 
+func (r *RefreshTokenPolicy) RevokeOnReplay() bool {
+	return r.revokeOnReplay
+}
+
 func (r *RefreshTokenPolicy) AllowedToReuse(lastUsed time.Time) bool {
 	if r.reuseInterval == 0 {
 		return false // expiration disabled
@@ -103,7 +103,7 @@ func TestRefreshTokenPolicy(t *testing.T) {
 	lastTime := time.Now()
 	l := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
 
-	r, err := NewRefreshTokenPolicy(l, true, "1m", "1m", "1m")
+	r, err := NewRefreshTokenPolicy(l, true, "1m", "1m", "1m", false)
 	require.NoError(t, err)
 
 	t.Run("Allowed", func(t *testing.T) {
@@ -119,4 +119,11 @@ func TestRefreshTokenPolicy(t *testing.T) {
 		require.Equal(t, true, r.ExpiredBecauseUnused(lastTime))
 		require.Equal(t, true, r.CompletelyExpired(lastTime))
 	})
+
+	t.Run("Client lifetime", func(t *testing.T) {
+		r.now = func() time.Time { return lastTime.Add(2 * time.Minute) }
+		require.Equal(t, true, r.CompletelyExpiredAfter(lastTime, 0))
+		require.Equal(t, false, r.CompletelyExpiredAfter(lastTime, time.Hour))
+		require.Equal(t, true, r.CompletelyExpiredAfter(lastTime, time.Minute))
+	})
 }
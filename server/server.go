@@ -21,6 +21,7 @@ import (
 	"time"
 
 	gosundheit "github.com/AppsFlyer/go-sundheit"
+	"github.com/AppsFlyer/go-sundheit/checks"
 	"github.com/google/uuid"
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
@@ -101,9 +102,40 @@ type Config struct {
 	AuthRequestsValidFor   time.Duration // Defaults to 24 hours
 	DeviceRequestsValidFor time.Duration // Defaults to 5 minutes
 
+	// Format of the user and device codes issued by the device flow.
+	DeviceCodeFormat storage.DeviceCodeFormat
+
+	// MaxAccessTokenLifetime is the upper bound for per-client access token
+	// lifetimes accepted by the API. Zero means no limit.
+	MaxAccessTokenLifetime time.Duration
+
+	// MinClientSecretLength and MinClientSecretEntropy (in bits) are enforced on
+	// client secrets supplied through the API. Zero disables the respective check.
+	MinClientSecretLength  int
+	MinClientSecretEntropy float64
+
+	// PasswordHashCost is the bcrypt cost of passwords supplied in plain text
+	// through the API. Defaults to 12, and must be between 10 and 16.
+	PasswordHashCost int
+
+	// ClientWatchInterval is how often the API's WatchClients polls storage
+	// for changes. Defaults to 5 seconds.
+	ClientWatchInterval time.Duration
+
+	// IdempotencyKeysValidFor is how long the API replays its response to
+	// retries of a call with the same idempotency key. Defaults to 24 hours.
+	IdempotencyKeysValidFor time.Duration
+
+	// If set, changes made through the API are logged to it along with the
+	// caller, taken from the APICallerMetadataKey gRPC metadata.
+	APIAuditLogger *slog.Logger
+
 	// Refresh token expiration settings
 	RefreshTokenPolicy *RefreshTokenPolicy
 
+	// If set, receives security relevant events such as refresh token replays.
+	AuditInterceptor AuditInterceptor
+
 	// If set, the server will use this connector to handle password grants
 	PasswordConnector string
 
@@ -119,6 +151,11 @@ type Config struct {
 	PrometheusRegistry *prometheus.Registry
 
 	HealthChecker gosundheit.Health
+
+	// If enabled, connectors which can ping their upstream identity provider
+	// get a health check each, so the server reports itself unhealthy while
+	// the provider can't be reached.
+	ConnectorHealthChecks bool
 }
 
 // WebConfig holds the server's frontend templates and asset configuration.
@@ -192,9 +229,27 @@ type Server struct {
 	idTokensValidFor       time.Duration
 	authRequestsValidFor   time.Duration
 	deviceRequestsValidFor time.Duration
+	deviceCodeFormat       storage.DeviceCodeFormat
+	maxAccessTokenLifetime time.Duration
+	minClientSecretLength  int
+	minClientSecretEntropy float64
+	passwordHashCost       int
+	apiAuditLogger         *slog.Logger
+	clientWatchInterval    time.Duration
+	idempotencyValidFor    time.Duration
 
 	refreshTokenPolicy *RefreshTokenPolicy
 
+	auditInterceptor AuditInterceptor
+
+	// Registry connectors register their metrics with, if any.
+	prometheusRegistry *prometheus.Registry
+
+	// Health checker connector checks are registered with, if enabled.
+	connectorHealthChecker gosundheit.Health
+	// IDs of connectors with a registered health check, guarded by mu.
+	connectorHealthChecks map[string]bool
+
 	logger *slog.Logger
 }
 
@@ -223,6 +278,14 @@ func newServer(ctx context.Context, c Config, rotationStrategy rotationStrategy)
 		return nil, errors.New("server: storage cannot be nil")
 	}
 
+	if err := c.DeviceCodeFormat.Validate(); err != nil {
+		return nil, fmt.Errorf("server: invalid device code format: %v", err)
+	}
+
+	if c.PasswordHashCost != 0 && (c.PasswordHashCost < bcrypt.DefaultCost || c.PasswordHashCost > upBoundCost) {
+		return nil, fmt.Errorf("server: password hash cost must be between %d and %d", bcrypt.DefaultCost, upBoundCost)
+	}
+
 	if len(c.SupportedResponseTypes) == 0 {
 		c.SupportedResponseTypes = []string{responseTypeCode}
 	}
@@ -305,14 +368,28 @@ func newServer(ctx context.Context, c Config, rotationStrategy rotationStrategy)
 		idTokensValidFor:       value(c.IDTokensValidFor, 24*time.Hour),
 		authRequestsValidFor:   value(c.AuthRequestsValidFor, 24*time.Hour),
 		deviceRequestsValidFor: value(c.DeviceRequestsValidFor, 5*time.Minute),
+		deviceCodeFormat:       c.DeviceCodeFormat,
+		maxAccessTokenLifetime: c.MaxAccessTokenLifetime,
+		minClientSecretLength:  c.MinClientSecretLength,
+		minClientSecretEntropy: c.MinClientSecretEntropy,
+		passwordHashCost:       c.PasswordHashCost,
+		apiAuditLogger:         c.APIAuditLogger,
+		clientWatchInterval:    value(c.ClientWatchInterval, defaultClientWatchInterval),
+		idempotencyValidFor:    value(c.IdempotencyKeysValidFor, defaultIdempotencyKeysValidFor),
 		refreshTokenPolicy:     c.RefreshTokenPolicy,
+		auditInterceptor:       c.AuditInterceptor,
 		skipApproval:           c.SkipApprovalScreen,
 		alwaysShowLogin:        c.AlwaysShowLoginScreen,
 		now:                    now,
 		templates:              tmpls,
 		passwordConnector:      c.PasswordConnector,
+		prometheusRegistry:     c.PrometheusRegistry,
+		connectorHealthChecks:  make(map[string]bool),
 		logger:                 c.Logger,
 	}
+	if c.ConnectorHealthChecks {
+		s.connectorHealthChecker = c.HealthChecker
+	}
 
 	// Retrieves connector objects in backend storage. This list includes the static connectors
 	// defined in the ConfigMap and dynamic connectors retrieved from the storage.
@@ -355,6 +432,10 @@ func newServer(ctx context.Context, c Config, rotationStrategy rotationStrategy)
 
 		c.PrometheusRegistry.MustRegister(requestCounter, durationHist, sizeHist)
 
+		deviceMetrics := newDeviceRequestMetrics()
+		c.PrometheusRegistry.MustRegister(deviceMetrics.collectors()...)
+		s.storage = storage.WithDeviceRequestMetrics(s.storage, deviceMetrics)
+
 		instrumentHandler = func(handlerName string, handler http.Handler) http.HandlerFunc {
 			return promhttp.InstrumentHandlerDuration(durationHist.MustCurryWith(prometheus.Labels{"handler": handlerName}),
 				promhttp.InstrumentHandlerCounter(requestCounter.MustCurryWith(prometheus.Labels{"handler": handlerName}),
@@ -535,7 +616,7 @@ type passwordDB struct {
 }
 
 func (db passwordDB) Login(ctx context.Context, s connector.Scopes, email, password string) (connector.Identity, bool, error) {
-	p, err := db.s.GetPassword(ctx, email)
+	p, err := db.s.GetPassword(ctx, normalizeEmail(email))
 	if err != nil {
 		if err != storage.ErrNotFound {
 			return connector.Identity{}, false, fmt.Errorf("get password: %v", err)
@@ -631,7 +712,8 @@ func (s *Server) startGarbageCollection(ctx context.Context, frequency time.Dura
 				} else if !r.IsEmpty() {
 					s.logger.InfoContext(ctx, "garbage collection run, delete auth",
 						"requests", r.AuthRequests, "auth_codes", r.AuthCodes,
-						"device_requests", r.DeviceRequests, "device_tokens", r.DeviceTokens)
+						"device_requests", r.DeviceRequests, "device_tokens", r.DeviceTokens,
+						"idempotency_keys", r.IdempotencyKeys)
 				}
 			}
 		}
@@ -706,6 +788,18 @@ func (s *Server) OpenConnector(conn storage.Connector) (Connector, error) {
 		}
 	}
 
+	if mc, ok := c.(connector.MetricsConnector); ok && s.prometheusRegistry != nil {
+		if err := mc.RegisterMetrics(s.prometheusRegistry); err != nil {
+			return Connector{}, fmt.Errorf("failed to register connector metrics: %v", err)
+		}
+	}
+
+	if _, ok := c.(connector.PingConnector); ok && s.connectorHealthChecker != nil {
+		if err := s.registerConnectorHealthCheck(conn.ID); err != nil {
+			return Connector{}, fmt.Errorf("failed to register connector health check: %v", err)
+		}
+	}
+
 	connector := Connector{
 		ResourceVersion: conn.ResourceVersion,
 		Connector:       c,
@@ -717,6 +811,42 @@ func (s *Server) OpenConnector(conn storage.Connector) (Connector, error) {
 	return connector, nil
 }
 
+// registerConnectorHealthCheck registers a health check pinging the connector
+// with the given ID, unless one already is. The check always pings the
+// connector currently open under the ID, so it survives the connector being
+// reopened.
+func (s *Server) registerConnectorHealthCheck(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.connectorHealthChecks[id] {
+		return nil
+	}
+
+	err := s.connectorHealthChecker.RegisterCheck(
+		&checks.CustomCheck{
+			CheckName: "connector:" + id,
+			CheckFunc: func(ctx context.Context) (details interface{}, err error) {
+				s.mu.Lock()
+				conn := s.connectors[id]
+				s.mu.Unlock()
+				// Pass if the connector is gone or can no longer be pinged.
+				pc, ok := conn.Connector.(connector.PingConnector)
+				if !ok {
+					return nil, nil
+				}
+				return nil, pc.Ping(ctx)
+			},
+		},
+		gosundheit.ExecutionPeriod(15*time.Second),
+		gosundheit.InitiallyPassing(true),
+	)
+	if err != nil {
+		return err
+	}
+	s.connectorHealthChecks[id] = true
+	return nil
+}
+
 // getConnector retrieves the connector object with the given id from the storage
 // and updates the connector list for server if necessary.
 func (s *Server) getConnector(ctx context.Context, id string) (Connector, error) {
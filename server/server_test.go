@@ -125,7 +125,7 @@ func newTestServer(ctx context.Context, t *testing.T, updateConfig func(c *Confi
 
 	// Default rotation policy
 	if server.refreshTokenPolicy == nil {
-		server.refreshTokenPolicy, err = NewRefreshTokenPolicy(logger, false, "", "", "")
+		server.refreshTokenPolicy, err = NewRefreshTokenPolicy(logger, false, "", "", "", false)
 		if err != nil {
 			t.Fatalf("failed to prepare rotation policy: %v", err)
 		}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -43,6 +44,7 @@ func RunTests(t *testing.T, newStorage func() storage.Storage) {
 		{"AuthRequestCRUD", testAuthRequestCRUD},
 		{"ClientCRUD", testClientCRUD},
 		{"RefreshTokenCRUD", testRefreshTokenCRUD},
+		{"DeleteRefreshTokensForUser", testDeleteRefreshTokensForUser},
 		{"PasswordCRUD", testPasswordCRUD},
 		{"KeysCRUD", testKeysCRUD},
 		{"OfflineSessionCRUD", testOfflineSessionCRUD},
@@ -51,6 +53,7 @@ func RunTests(t *testing.T, newStorage func() storage.Storage) {
 		{"TimezoneSupport", testTimezones},
 		{"DeviceRequestCRUD", testDeviceRequestCRUD},
 		{"DeviceTokenCRUD", testDeviceTokenCRUD},
+		{"IdempotencyKeyCRUD", testIdempotencyKeyCRUD},
 	})
 }
 
@@ -111,7 +114,7 @@ func testAuthRequestCRUD(t *testing.T, s storage.Storage) {
 		HMACKey: []byte("hmac_key"),
 	}
 
-	identity := storage.Claims{Email: "foobar"}
+	identity := storage.Claims{Email: "foobar", ExtraClaims: map[string]interface{}{"team": "x"}}
 
 	if err := s.CreateAuthRequest(ctx, a1); err != nil {
 		t.Fatalf("failed creating auth request: %v", err)
@@ -201,6 +204,7 @@ func testAuthCodeCRUD(t *testing.T, s storage.Storage) {
 			Email:         "jane.doe@example.com",
 			EmailVerified: true,
 			Groups:        []string{"a", "b"},
+			ExtraClaims:   map[string]interface{}{"team": "x"},
 		},
 	}
 
@@ -261,12 +265,17 @@ func testAuthCodeCRUD(t *testing.T, s storage.Storage) {
 func testClientCRUD(t *testing.T, s storage.Storage) {
 	ctx := context.Background()
 	id1 := storage.NewID()
+	created := time.Now().UTC().Round(time.Millisecond)
 	c1 := storage.Client{
-		ID:           id1,
-		Secret:       "foobar",
-		RedirectURIs: []string{"foo://bar.com/", "https://auth.example.com"},
-		Name:         "dex client",
-		LogoURL:      "https://goo.gl/JIyzIC",
+		ID:                   id1,
+		Secret:               "foobar",
+		RedirectURIs:         []string{"foo://bar.com/", "https://auth.example.com"},
+		Name:                 "dex client",
+		LogoURL:              "https://goo.gl/JIyzIC",
+		AccessTokenLifetime:  3600,
+		RefreshTokenLifetime: 86400,
+		CreatedAt:            created,
+		UpdatedAt:            created,
 	}
 	err := s.DeleteClient(ctx, id1)
 	mustBeErrNotFound(t, "client", err)
@@ -292,12 +301,48 @@ func testClientCRUD(t *testing.T, s storage.Storage) {
 		t.Fatalf("create client: %v", err)
 	}
 
+	byName, err := s.GetClientsByName(ctx, "dex client", false)
+	if err != nil {
+		t.Fatalf("get clients by name: %v", err)
+	}
+	if len(byName) != 2 {
+		t.Errorf("expected 2 clients named %q, got %d", "dex client", len(byName))
+	}
+
+	byName, err = s.GetClientsByName(ctx, "Dex Client", false)
+	if err != nil {
+		t.Fatalf("get clients by name: %v", err)
+	}
+	if len(byName) != 0 {
+		t.Errorf("expected no clients named %q, got %d", "Dex Client", len(byName))
+	}
+
+	byName, err = s.GetClientsByName(ctx, "Dex Client", true)
+	if err != nil {
+		t.Fatalf("get clients by name: %v", err)
+	}
+	if len(byName) != 2 {
+		t.Errorf("expected 2 clients named %q ignoring case, got %d", "Dex Client", len(byName))
+	}
+
 	getAndCompare := func(_ string, want storage.Client) {
 		gc, err := s.GetClient(ctx, id1)
 		if err != nil {
 			t.Errorf("get client: %v", err)
 			return
 		}
+		if !gc.CreatedAt.Equal(want.CreatedAt) {
+			t.Errorf("client created timestamp retrieved from storage did not match: want %v, got %v", want.CreatedAt, gc.CreatedAt)
+		}
+		if !gc.UpdatedAt.Equal(want.UpdatedAt) {
+			t.Errorf("client updated timestamp retrieved from storage did not match: want %v, got %v", want.UpdatedAt, gc.UpdatedAt)
+		}
+
+		gc.CreatedAt = time.Time{}
+		gc.UpdatedAt = time.Time{}
+		want.CreatedAt = time.Time{}
+		want.UpdatedAt = time.Time{}
+
 		if diff := pretty.Compare(want, gc); diff != "" {
 			t.Errorf("client retrieved from storage did not match: %s", diff)
 		}
@@ -306,14 +351,25 @@ func testClientCRUD(t *testing.T, s storage.Storage) {
 	getAndCompare(id1, c1)
 
 	newSecret := "barfoo"
+	updated := created.Add(time.Minute)
 	err = s.UpdateClient(ctx, id1, func(old storage.Client) (storage.Client, error) {
 		old.Secret = newSecret
+		old.AccessTokenLifetime = 600
+		old.RefreshTokenLifetime = 3600
+		old.Disabled = true
+		old.AllowedConnectors = []string{"github", "ldap"}
+		old.UpdatedAt = updated
 		return old, nil
 	})
 	if err != nil {
 		t.Errorf("update client: %v", err)
 	}
 	c1.Secret = newSecret
+	c1.AccessTokenLifetime = 600
+	c1.RefreshTokenLifetime = 3600
+	c1.Disabled = true
+	c1.AllowedConnectors = []string{"github", "ldap"}
+	c1.UpdatedAt = updated
 	getAndCompare(id1, c1)
 
 	if err := s.DeleteClient(ctx, id1); err != nil {
@@ -347,6 +403,7 @@ func testRefreshTokenCRUD(t *testing.T, s storage.Storage) {
 			Email:         "jane.doe@example.com",
 			EmailVerified: true,
 			Groups:        []string{"a", "b"},
+			ExtraClaims:   map[string]interface{}{"team": "x"},
 		},
 		ConnectorData: []byte(`{"some":"data"}`),
 	}
@@ -417,6 +474,7 @@ func testRefreshTokenCRUD(t *testing.T, s storage.Storage) {
 	updater := func(r storage.RefreshToken) (storage.RefreshToken, error) {
 		r.Token = "spam"
 		r.LastUsed = updatedAt
+		r.Consumed = true
 		return r, nil
 	}
 	if err := s.UpdateRefreshToken(ctx, id, updater); err != nil {
@@ -424,6 +482,7 @@ func testRefreshTokenCRUD(t *testing.T, s storage.Storage) {
 	}
 	refresh.Token = "spam"
 	refresh.LastUsed = updatedAt
+	refresh.Consumed = true
 	getAndCompare(id, refresh)
 
 	// Ensure that updating the first token doesn't impact the second. Issue #847.
@@ -441,6 +500,56 @@ func testRefreshTokenCRUD(t *testing.T, s storage.Storage) {
 	mustBeErrNotFound(t, "refresh token", err)
 }
 
+func testDeleteRefreshTokensForUser(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	newRefresh := func(userID, connID, clientID string) storage.RefreshToken {
+		r := storage.RefreshToken{
+			ID:          storage.NewID(),
+			Token:       storage.NewID(),
+			ClientID:    clientID,
+			ConnectorID: connID,
+			Nonce:       "foo",
+			CreatedAt:   time.Now().UTC().Round(time.Millisecond),
+			LastUsed:    time.Now().UTC().Round(time.Millisecond),
+			Claims:      storage.Claims{UserID: userID, Username: "jane", Email: "jane@example.com"},
+		}
+		if err := s.CreateRefresh(ctx, r); err != nil {
+			t.Fatalf("create refresh token: %v", err)
+		}
+		return r
+	}
+
+	jane1 := newRefresh("jane", "conn-1", "client-1")
+	jane2 := newRefresh("jane", "conn-1", "client-2")
+	janeOtherConn := newRefresh("jane", "conn-2", "client-1")
+	john := newRefresh("john", "conn-1", "client-1")
+
+	n, err := s.DeleteRefreshTokensForUser(ctx, "jane", "conn-1")
+	if err != nil {
+		t.Fatalf("delete refresh tokens for user: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 refresh tokens to be deleted, got %d", n)
+	}
+	for _, r := range []storage.RefreshToken{jane1, jane2} {
+		_, err := s.GetRefresh(ctx, r.ID)
+		mustBeErrNotFound(t, "refresh token", err)
+	}
+	for _, r := range []storage.RefreshToken{janeOtherConn, john} {
+		if _, err := s.GetRefresh(ctx, r.ID); err != nil {
+			t.Errorf("get refresh token of another user or connector: %v", err)
+		}
+	}
+
+	n, err = s.DeleteRefreshTokensForUser(ctx, "jane", "conn-1")
+	if err != nil {
+		t.Fatalf("delete refresh tokens for user without any: %v", err)
+	}
+	if n != 0 {
+		t.Errorf("expected no refresh tokens to be deleted, got %d", n)
+	}
+}
+
 type byEmail []storage.Password
 
 func (n byEmail) Len() int           { return len(n) }
@@ -875,6 +984,19 @@ func testGC(t *testing.T, s storage.Storage) {
 		t.Fatalf("failed creating device request: %v", err)
 	}
 
+	for _, tz := range []*time.Location{time.UTC, est, pst} {
+		if n, err := s.CountDeviceRequestsExpiringBefore(ctx, expiry.Add(-time.Hour).In(tz)); err != nil {
+			t.Errorf("counting device requests failed: %v", err)
+		} else if n != 0 {
+			t.Errorf("expected no device requests expiring before %s, got %d", expiry.Add(-time.Hour).In(tz), n)
+		}
+		if n, err := s.CountDeviceRequestsExpiringBefore(ctx, expiry.Add(time.Hour).In(tz)); err != nil {
+			t.Errorf("counting device requests failed: %v", err)
+		} else if n != 1 {
+			t.Errorf("expected 1 device request expiring before %s, got %d", expiry.Add(time.Hour).In(tz), n)
+		}
+	}
+
 	for _, tz := range []*time.Location{time.UTC, est, pst} {
 		result, err := s.GarbageCollect(ctx, expiry.Add(-time.Hour).In(tz))
 		if err != nil {
@@ -937,6 +1059,39 @@ func testGC(t *testing.T, s storage.Storage) {
 	} else if err != storage.ErrNotFound {
 		t.Errorf("expected storage.ErrNotFound, got %v", err)
 	}
+
+	ik := storage.IdempotencyKey{
+		Key:      "CreateClient/" + storage.NewID(),
+		Response: []byte("response"),
+		Expiry:   expiry,
+	}
+
+	if err := s.CreateIdempotencyKey(ctx, ik); err != nil {
+		t.Fatalf("failed creating idempotency key: %v", err)
+	}
+
+	for _, tz := range []*time.Location{time.UTC, est, pst} {
+		result, err := s.GarbageCollect(ctx, expiry.Add(-time.Hour).In(tz))
+		if err != nil {
+			t.Errorf("garbage collection failed: %v", err)
+		} else if result.IdempotencyKeys != 0 {
+			t.Errorf("expected no idempotency key garbage collection results, got %#v", result)
+		}
+		if _, err := s.GetIdempotencyKey(ctx, ik.Key); err != nil {
+			t.Errorf("expected to be able to get idempotency key after GC: %v", err)
+		}
+	}
+	if r, err := s.GarbageCollect(ctx, expiry.Add(time.Hour)); err != nil {
+		t.Errorf("garbage collection failed: %v", err)
+	} else if r.IdempotencyKeys != 1 {
+		t.Errorf("expected to garbage collect 1 idempotency key, got %d", r.IdempotencyKeys)
+	}
+
+	if _, err := s.GetIdempotencyKey(ctx, ik.Key); err == nil {
+		t.Errorf("expected idempotency key to be GC'd")
+	} else if err != storage.ErrNotFound {
+		t.Errorf("expected storage.ErrNotFound, got %v", err)
+	}
 }
 
 // testTimezones tests that backends either fully support timezones or
@@ -989,12 +1144,14 @@ func testTimezones(t *testing.T, s storage.Storage) {
 func testDeviceRequestCRUD(t *testing.T, s storage.Storage) {
 	ctx := context.Background()
 	d1 := storage.DeviceRequest{
-		UserCode:     storage.NewUserCode(),
-		DeviceCode:   storage.NewID(),
-		ClientID:     "client1",
-		ClientSecret: "secret1",
-		Scopes:       []string{"openid", "email"},
-		Expiry:       neverExpire.Round(time.Second),
+		UserCode:            storage.NewUserCode(),
+		DeviceCode:          storage.NewID(),
+		ClientID:            "client1",
+		ClientSecret:        "secret1",
+		Scopes:              []string{"openid", "email"},
+		Expiry:              neverExpire.Round(time.Second),
+		PollIntervalSeconds: 5,
+		CreatedAt:           time.Now().UTC().Round(time.Second),
 	}
 
 	if err := s.CreateDeviceRequest(ctx, d1); err != nil {
@@ -1012,6 +1169,87 @@ func testDeviceRequestCRUD(t *testing.T, s storage.Storage) {
 
 	require.Equal(t, d1, got)
 
+	got, err = storage.LookupDeviceRequest(ctx, s, d1.UserCode, time.Now())
+	if err != nil {
+		t.Fatalf("failed to look up device request: %v", err)
+	}
+	require.Equal(t, d1, got)
+
+	// User codes are typed in by hand, so case and separators don't matter.
+	for _, userCode := range []string{
+		strings.ToLower(d1.UserCode),
+		strings.ReplaceAll(d1.UserCode, "-", ""),
+		" " + strings.ToLower(d1.UserCode[:2]) + "-" + d1.UserCode[2:] + " ",
+	} {
+		got, err = storage.LookupDeviceRequest(ctx, s, userCode, time.Now())
+		if err != nil {
+			t.Fatalf("failed to look up device request by %q: %v", userCode, err)
+		}
+		require.Equal(t, d1, got)
+	}
+
+	if _, err := storage.LookupDeviceRequest(ctx, s, d1.UserCode, d1.Expiry.Add(time.Second)); err != storage.ErrExpired {
+		t.Errorf("looking up an expired device request expected storage.ErrExpired, got %v", err)
+	}
+	_, err = storage.LookupDeviceRequest(ctx, s, storage.NewUserCode(), time.Now())
+	mustBeErrNotFound(t, "device request", err)
+
+	// User codes are unique even if the device code differs.
+	d2 := d1
+	d2.DeviceCode = storage.NewID()
+	err = s.CreateDeviceRequest(ctx, d2)
+	mustBeErrAlreadyExists(t, "device request", err)
+
+	if err := s.ConsumeDeviceRequest(ctx, d1.DeviceCode); err != nil {
+		t.Fatalf("failed to consume device request: %v", err)
+	}
+	if err := s.ConsumeDeviceRequest(ctx, d1.DeviceCode); err != storage.ErrAlreadyConsumed {
+		t.Errorf("consuming a device request twice expected storage.ErrAlreadyConsumed, got %v", err)
+	}
+	err = s.ConsumeDeviceRequest(ctx, storage.NewID())
+	mustBeErrNotFound(t, "device request", err)
+
+	got, err = s.GetDeviceRequest(ctx, d1.UserCode)
+	if err != nil {
+		t.Fatalf("failed to get device request: %v", err)
+	}
+	if !got.Consumed {
+		t.Errorf("expected device request to be consumed")
+	}
+
+	d3 := storage.DeviceRequest{
+		UserCode:            storage.NewUserCode(),
+		DeviceCode:          storage.NewID(),
+		ClientID:            "client1",
+		ClientSecret:        "secret1",
+		Scopes:              []string{"openid"},
+		Expiry:              neverExpire.Round(time.Second),
+		PollIntervalSeconds: 5,
+		CreatedAt:           d1.CreatedAt.Add(time.Minute),
+	}
+	if err := s.CreateDeviceRequest(ctx, d3); err != nil {
+		t.Fatalf("failed creating device request: %v", err)
+	}
+
+	created, err := s.ListDeviceRequestsCreatedBetween(ctx, d1.CreatedAt, d3.CreatedAt.Add(time.Second))
+	if err != nil {
+		t.Fatalf("failed to list device requests: %v", err)
+	}
+	if len(created) != 2 || created[0].UserCode != d1.UserCode || created[1].UserCode != d3.UserCode {
+		t.Errorf("expected both device requests, oldest first, got %+v", created)
+	} else if !created[0].CreatedAt.Equal(d1.CreatedAt) || !created[1].CreatedAt.Equal(d3.CreatedAt) {
+		t.Errorf("expected the device requests' creation times, got %v and %v", created[0].CreatedAt, created[1].CreatedAt)
+	}
+
+	// The window is inclusive of from and exclusive of to.
+	created, err = s.ListDeviceRequestsCreatedBetween(ctx, d1.CreatedAt.Add(time.Second), d3.CreatedAt)
+	if err != nil {
+		t.Fatalf("failed to list device requests: %v", err)
+	}
+	if len(created) != 0 {
+		t.Errorf("expected no device requests created in the window, got %+v", created)
+	}
+
 	// No manual deletes for device requests, will be handled by garbage collection routines
 	// see testGC
 }
@@ -1068,3 +1306,45 @@ func testDeviceTokenCRUD(t *testing.T, s storage.Storage) {
 		t.Fatalf("storage does not support PKCE, wanted challenge=%#v got %#v", codeChallenge, got.PKCE)
 	}
 }
+
+func testIdempotencyKeyCRUD(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	k1 := storage.IdempotencyKey{
+		Key:         "CreateClient/" + storage.NewID(),
+		Response:    []byte(`{"client":{"id":"foo"}}`),
+		RequestHash: []byte("request-hash"),
+		Expiry:      neverExpire,
+	}
+
+	if err := s.CreateIdempotencyKey(ctx, k1); err != nil {
+		t.Fatalf("failed creating idempotency key: %v", err)
+	}
+
+	// Attempt to create same idempotency key twice.
+	err := s.CreateIdempotencyKey(ctx, k1)
+	mustBeErrAlreadyExists(t, "idempotency key", err)
+
+	got, err := s.GetIdempotencyKey(ctx, k1.Key)
+	if err != nil {
+		t.Fatalf("failed to get idempotency key: %v", err)
+	}
+	if got.Key != k1.Key || string(got.Response) != string(k1.Response) {
+		t.Errorf("idempotency key retrieved from storage did not match: want=%q got=%q", k1.Response, got.Response)
+	}
+	if string(got.RequestHash) != string(k1.RequestHash) {
+		t.Errorf("idempotency key request hash did not match: want=%q got=%q", k1.RequestHash, got.RequestHash)
+	}
+
+	_, err = s.GetIdempotencyKey(ctx, "CreateClient/"+storage.NewID())
+	mustBeErrNotFound(t, "idempotency key", err)
+
+	if err := s.DeleteIdempotencyKey(ctx, k1.Key); err != nil {
+		t.Fatalf("failed to delete idempotency key: %v", err)
+	}
+	_, err = s.GetIdempotencyKey(ctx, k1.Key)
+	mustBeErrNotFound(t, "idempotency key", err)
+
+	err = s.DeleteIdempotencyKey(ctx, k1.Key)
+	mustBeErrNotFound(t, "idempotency key", err)
+}
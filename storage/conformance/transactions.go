@@ -2,6 +2,7 @@ package conformance
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
@@ -23,6 +24,7 @@ func RunTransactionTests(t *testing.T, newStorage func() storage.Storage) {
 		{"ClientConcurrentUpdate", testClientConcurrentUpdate},
 		{"PasswordConcurrentUpdate", testPasswordConcurrentUpdate},
 		{"KeysConcurrentUpdate", testKeysConcurrentUpdate},
+		{"DeviceRequestConcurrentConsume", testDeviceRequestConcurrentConsume},
 	})
 }
 
@@ -176,3 +178,34 @@ func testKeysConcurrentUpdate(t *testing.T, s storage.Storage) {
 		}
 	}
 }
+
+func testDeviceRequestConcurrentConsume(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	d := storage.DeviceRequest{
+		UserCode:     storage.NewUserCode(),
+		DeviceCode:   storage.NewID(),
+		ClientID:     "client1",
+		ClientSecret: "secret1",
+		Scopes:       []string{"openid", "email"},
+		Expiry:       neverExpire,
+	}
+
+	if err := s.CreateDeviceRequest(ctx, d); err != nil {
+		t.Fatalf("create device request: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = s.ConsumeDeviceRequest(ctx, d.DeviceCode)
+		}(i)
+	}
+	wg.Wait()
+
+	if (errs[0] == nil) == (errs[1] == nil) {
+		t.Errorf("consume device request:\nconsume1: %v\nconsume2: %v\n", errs[0], errs[1])
+	}
+}
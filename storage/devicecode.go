@@ -0,0 +1,223 @@
+package storage
+
+import (
+	"context"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Defaults and lower bounds of DeviceCodeFormat.
+const (
+	defaultUserCodeLength    = 8
+	defaultUserCodeGroupSize = 4
+	defaultDeviceCodeLength  = 32
+
+	minUserCodeLength         = 6
+	minUserCodeCharacterCount = 10
+	minDeviceCodeLength       = 16
+
+	// ambiguousUserCodeCharacters are easily confused with each other when
+	// read off a screen and typed in by hand.
+	ambiguousUserCodeCharacters = "0O1I"
+)
+
+// DeviceCodeFormat describes the codes issued by the device flow. Zero values
+// select the defaults, which follow RFC 8628: 8 character user codes such as
+// "BDWP-HQPK" and device codes derived from 32 random bytes.
+type DeviceCodeFormat struct {
+	// UserCodeLength is the number of characters in a user code, not counting
+	// separators.
+	UserCodeLength int
+	// UserCodeGroupSize splits user codes into groups of this many characters
+	// joined by "-". A size of at least UserCodeLength disables grouping.
+	UserCodeGroupSize int
+	// UserCodeCharacters is the set of upper case characters user codes are
+	// drawn from. Ambiguous characters such as "0" and "O" aren't allowed.
+	UserCodeCharacters string
+	// DeviceCodeLength is the number of random bytes a device code is
+	// generated from.
+	DeviceCodeLength int
+}
+
+func (f DeviceCodeFormat) withDefaults() DeviceCodeFormat {
+	if f.UserCodeLength == 0 {
+		f.UserCodeLength = defaultUserCodeLength
+	}
+	if f.UserCodeGroupSize == 0 {
+		f.UserCodeGroupSize = defaultUserCodeGroupSize
+	}
+	if f.UserCodeCharacters == "" {
+		f.UserCodeCharacters = validUserCharacters
+	}
+	if f.DeviceCodeLength == 0 {
+		f.DeviceCodeLength = defaultDeviceCodeLength
+	}
+	return f
+}
+
+// Validate returns an error if the format produces codes that are too easy
+// to guess or to mistype.
+func (f DeviceCodeFormat) Validate() error {
+	f = f.withDefaults()
+	switch {
+	case f.UserCodeLength < minUserCodeLength:
+		return fmt.Errorf("user code length must be at least %d", minUserCodeLength)
+	case f.UserCodeGroupSize < 0:
+		return errors.New("user code group size must not be negative")
+	case len(f.UserCodeCharacters) < minUserCodeCharacterCount:
+		return fmt.Errorf("user code characters must contain at least %d characters", minUserCodeCharacterCount)
+	case f.DeviceCodeLength < minDeviceCodeLength:
+		return fmt.Errorf("device code length must be at least %d", minDeviceCodeLength)
+	}
+	for i, c := range f.UserCodeCharacters {
+		switch {
+		case (c < 'A' || c > 'Z') && (c < '2' || c > '9'):
+			return fmt.Errorf("user code characters must be upper case letters or digits, got %q", c)
+		case strings.ContainsRune(ambiguousUserCodeCharacters, c):
+			return fmt.Errorf("user code characters must not contain ambiguous character %q", c)
+		case strings.ContainsRune(f.UserCodeCharacters[:i], c):
+			return fmt.Errorf("user code characters contain %q more than once", c)
+		}
+	}
+	return nil
+}
+
+// NewUserCode returns a random user code in this format.
+func (f DeviceCodeFormat) NewUserCode() string {
+	f = f.withDefaults()
+	return f.group(randomString(f.UserCodeCharacters, f.UserCodeLength))
+}
+
+// group splits code into groups of UserCodeGroupSize characters joined by "-".
+func (f DeviceCodeFormat) group(code string) string {
+	groups := make([]string, 0, len(code)/f.UserCodeGroupSize+1)
+	for len(code) > f.UserCodeGroupSize {
+		groups = append(groups, code[:f.UserCodeGroupSize])
+		code = code[f.UserCodeGroupSize:]
+	}
+	return strings.Join(append(groups, code), "-")
+}
+
+// NewDeviceCode returns a cryptographically secure device code in this format.
+func (f DeviceCodeFormat) NewDeviceCode() string {
+	return newSecureID(f.withDefaults().DeviceCodeLength)
+}
+
+// ValidateUserCode returns an error if code wasn't generated in this format.
+func (f DeviceCodeFormat) ValidateUserCode(code string) error {
+	f = f.withDefaults()
+	chars := strings.ReplaceAll(code, "-", "")
+	if len(chars) != f.UserCodeLength {
+		return fmt.Errorf("invalid user code: expected %d characters, got %d", f.UserCodeLength, len(chars))
+	}
+	for _, c := range chars {
+		if !strings.ContainsRune(f.UserCodeCharacters, c) {
+			return fmt.Errorf("invalid user code: unexpected character %q", c)
+		}
+	}
+	if f.group(chars) != code {
+		return errors.New("invalid user code: misplaced separator")
+	}
+	return nil
+}
+
+// NormalizeUserCode converts a user code as typed in by a user into this
+// format: letters are upper cased, whitespace is dropped and separators are
+// put back in place. The result still has to be validated.
+func (f DeviceCodeFormat) NormalizeUserCode(code string) string {
+	f = f.withDefaults()
+	chars := strings.Map(func(r rune) rune {
+		if r == '-' || unicode.IsSpace(r) {
+			return -1
+		}
+		return unicode.ToUpper(r)
+	}, code)
+	if chars == "" {
+		return ""
+	}
+	return f.group(chars)
+}
+
+// ValidateDeviceCode returns an error if code wasn't generated in this format.
+func (f DeviceCodeFormat) ValidateDeviceCode(code string) error {
+	f = f.withDefaults()
+	// newSecureID emits one letter followed by the unpadded base32 encoding
+	// of the remaining bytes.
+	want := 1 + encoding.WithPadding(base32.NoPadding).EncodedLen(f.DeviceCodeLength-1)
+	if len(code) != want {
+		return fmt.Errorf("invalid device code: expected %d characters, got %d", want, len(code))
+	}
+	if code[0] < 'a' || code[0] > 'z' {
+		return errors.New("invalid device code: must start with a letter")
+	}
+	if _, err := encoding.WithPadding(base32.NoPadding).DecodeString(code[1:]); err != nil {
+		return fmt.Errorf("invalid device code: %v", err)
+	}
+	return nil
+}
+
+// deviceCodeStorage is a storage that rejects device requests and tokens
+// whose codes don't conform to a DeviceCodeFormat.
+type deviceCodeStorage struct {
+	Storage
+
+	format DeviceCodeFormat
+}
+
+// WithDeviceCodeValidation returns a storage that refuses to create device
+// requests and tokens with codes not generated in the given format.
+func WithDeviceCodeValidation(s Storage, format DeviceCodeFormat) Storage {
+	return deviceCodeStorage{s, format}
+}
+
+func (s deviceCodeStorage) CreateDeviceRequest(ctx context.Context, d DeviceRequest) error {
+	d.UserCode = s.format.NormalizeUserCode(d.UserCode)
+	if err := s.format.ValidateUserCode(d.UserCode); err != nil {
+		return err
+	}
+	if err := s.format.ValidateDeviceCode(d.DeviceCode); err != nil {
+		return err
+	}
+	return s.Storage.CreateDeviceRequest(ctx, d)
+}
+
+func (s deviceCodeStorage) GetDeviceRequest(ctx context.Context, userCode string) (DeviceRequest, error) {
+	return s.Storage.GetDeviceRequest(ctx, s.format.NormalizeUserCode(userCode))
+}
+
+func (s deviceCodeStorage) CreateDeviceToken(ctx context.Context, t DeviceToken) error {
+	if err := s.format.ValidateDeviceCode(t.DeviceCode); err != nil {
+		return err
+	}
+	return s.Storage.CreateDeviceToken(ctx, t)
+}
+
+// LookupDeviceRequest returns the device request with the given user code if
+// it's still valid at now. The user code is normalized first, so "bdwphqpk"
+// finds the request issued as "BDWP-HQPK". Unlike GetDeviceRequest, it tells
+// apart a user code that was never issued, ErrNotFound, from one that has
+// expired, ErrExpired.
+func (f DeviceCodeFormat) LookupDeviceRequest(ctx context.Context, s Storage, userCode string, now time.Time) (DeviceRequest, error) {
+	userCode = f.NormalizeUserCode(userCode)
+	if userCode == "" {
+		return DeviceRequest{}, ErrNotFound
+	}
+	req, err := s.GetDeviceRequest(ctx, userCode)
+	if err != nil {
+		return DeviceRequest{}, err
+	}
+	if now.After(req.Expiry) {
+		return DeviceRequest{}, ErrExpired
+	}
+	return req, nil
+}
+
+// LookupDeviceRequest looks up a device request issued in the default
+// DeviceCodeFormat. See DeviceCodeFormat.LookupDeviceRequest.
+func LookupDeviceRequest(ctx context.Context, s Storage, userCode string, now time.Time) (DeviceRequest, error) {
+	return DeviceCodeFormat{}.LookupDeviceRequest(ctx, s, userCode, now)
+}
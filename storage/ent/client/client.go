@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/dexidp/dex/storage"
+	"github.com/dexidp/dex/storage/ent/db/oauth2client"
 )
 
 // CreateClient saves provided oauth2 client settings into the database.
@@ -14,8 +15,14 @@ func (d *Database) CreateClient(ctx context.Context, client storage.Client) erro
 		SetSecret(client.Secret).
 		SetPublic(client.Public).
 		SetLogoURL(client.LogoURL).
+		SetAccessTokenLifetime(client.AccessTokenLifetime).
+		SetRefreshTokenLifetime(client.RefreshTokenLifetime).
+		SetCreatedAt(client.CreatedAt).
+		SetUpdatedAt(client.UpdatedAt).
+		SetDisabled(client.Disabled).
 		SetRedirectUris(client.RedirectURIs).
 		SetTrustedPeers(client.TrustedPeers).
+		SetAllowedConnectors(client.AllowedConnectors).
 		Save(ctx)
 	if err != nil {
 		return convertDBError("create oauth2 client: %w", err)
@@ -37,6 +44,25 @@ func (d *Database) ListClients(ctx context.Context) ([]storage.Client, error) {
 	return storageClients, nil
 }
 
+// GetClientsByName extracts all oauth2 clients with the given name from the database.
+func (d *Database) GetClientsByName(ctx context.Context, name string, caseInsensitive bool) ([]storage.Client, error) {
+	nameEQ := oauth2client.NameEQ(name)
+	if caseInsensitive {
+		nameEQ = oauth2client.NameEqualFold(name)
+	}
+
+	clients, err := d.client.OAuth2Client.Query().Where(nameEQ).All(ctx)
+	if err != nil {
+		return nil, convertDBError("get clients by name: %w", err)
+	}
+
+	storageClients := make([]storage.Client, 0, len(clients))
+	for _, c := range clients {
+		storageClients = append(storageClients, toStorageClient(c))
+	}
+	return storageClients, nil
+}
+
 // GetClient extracts an oauth2 client from the database by id.
 func (d *Database) GetClient(ctx context.Context, id string) (storage.Client, error) {
 	client, err := d.client.OAuth2Client.Get(ctx, id)
@@ -77,8 +103,14 @@ func (d *Database) UpdateClient(ctx context.Context, id string, updater func(old
 		SetSecret(newClient.Secret).
 		SetPublic(newClient.Public).
 		SetLogoURL(newClient.LogoURL).
+		SetAccessTokenLifetime(newClient.AccessTokenLifetime).
+		SetRefreshTokenLifetime(newClient.RefreshTokenLifetime).
+		SetCreatedAt(newClient.CreatedAt).
+		SetUpdatedAt(newClient.UpdatedAt).
+		SetDisabled(newClient.Disabled).
 		SetRedirectUris(newClient.RedirectURIs).
 		SetTrustedPeers(newClient.TrustedPeers).
+		SetAllowedConnectors(newClient.AllowedConnectors).
 		Save(ctx)
 	if err != nil {
 		return rollback(tx, "update client uploading: %w", err)
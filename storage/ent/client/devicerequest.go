@@ -2,13 +2,28 @@ package client
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"entgo.io/ent/dialect/sql"
+	"entgo.io/ent/dialect/sql/sqljson"
 
 	"github.com/dexidp/dex/storage"
 	"github.com/dexidp/dex/storage/ent/db/devicerequest"
+	"github.com/dexidp/dex/storage/ent/db/predicate"
 )
 
+// defaultDeviceRequestTTL is the server's default device request lifetime,
+// used when the storage wasn't given the configured one.
+const defaultDeviceRequestTTL = 5 * time.Minute
+
 // CreateDeviceRequest saves provided device request into the database.
 func (d *Database) CreateDeviceRequest(ctx context.Context, request storage.DeviceRequest) error {
+	if err := validateScopes(request.Scopes); err != nil {
+		return fmt.Errorf("create device request: %w", err)
+	}
+
 	_, err := d.client.DeviceRequest.Create().
 		SetClientID(request.ClientID).
 		SetClientSecret(request.ClientSecret).
@@ -17,6 +32,9 @@ func (d *Database) CreateDeviceRequest(ctx context.Context, request storage.Devi
 		SetDeviceCode(request.DeviceCode).
 		// Save utc time into database because ent doesn't support comparing dates with different timezones
 		SetExpiry(request.Expiry.UTC()).
+		SetCreatedAt(request.CreatedAt.UTC()).
+		SetPollInterval(request.PollIntervalSeconds).
+		SetConsumed(request.Consumed).
 		Save(ctx)
 	if err != nil {
 		return convertDBError("create device request: %w", err)
@@ -34,3 +52,193 @@ func (d *Database) GetDeviceRequest(ctx context.Context, userCode string) (stora
 	}
 	return toStorageDeviceRequest(deviceRequest), nil
 }
+
+// ConsumeDeviceRequest marks a device request as used, failing if it already was.
+func (d *Database) ConsumeDeviceRequest(ctx context.Context, deviceCode string) error {
+	tx, err := d.BeginTx(ctx)
+	if err != nil {
+		return convertDBError("consume device request tx: %w", err)
+	}
+
+	// Only one of concurrent updates can flip the flag.
+	n, err := tx.DeviceRequest.Update().
+		Where(
+			devicerequest.DeviceCode(deviceCode),
+			devicerequest.Consumed(false),
+		).
+		SetConsumed(true).
+		Save(ctx)
+	if err != nil {
+		return rollback(tx, "consume device request: %w", err)
+	}
+
+	if n == 0 {
+		exists, err := tx.DeviceRequest.Query().
+			Where(devicerequest.DeviceCode(deviceCode)).
+			Exist(ctx)
+		if err != nil {
+			return rollback(tx, "consume device request database: %w", err)
+		}
+		if err := tx.Rollback(); err != nil {
+			return convertDBError("consume device request rollback: %w", err)
+		}
+		if !exists {
+			return storage.ErrNotFound
+		}
+		return storage.ErrAlreadyConsumed
+	}
+
+	if err = tx.Commit(); err != nil {
+		return rollback(tx, "consume device request commit: %w", err)
+	}
+	return nil
+}
+
+// ListDeviceRequestsCreatedBetween returns device requests created at or after
+// from and before to, oldest first.
+func (d *Database) ListDeviceRequestsCreatedBetween(ctx context.Context, from, to time.Time) ([]storage.DeviceRequest, error) {
+	deviceRequests, err := d.client.DeviceRequest.Query().
+		Where(
+			devicerequest.CreatedAtGTE(from.UTC()),
+			devicerequest.CreatedAtLT(to.UTC()),
+		).
+		Order(devicerequest.ByCreatedAt()).
+		All(ctx)
+	if err != nil {
+		return nil, convertDBError("list device requests: %w", err)
+	}
+
+	requests := make([]storage.DeviceRequest, 0, len(deviceRequests))
+	for _, r := range deviceRequests {
+		requests = append(requests, toStorageDeviceRequest(r))
+	}
+	return requests, nil
+}
+
+// DeviceRequestsWithScope returns device requests that asked for scope.
+func (d *Database) DeviceRequestsWithScope(ctx context.Context, scope string) ([]storage.DeviceRequest, error) {
+	deviceRequests, err := d.client.DeviceRequest.Query().
+		Where(scopesContain(scope)).
+		All(ctx)
+	if err != nil {
+		return nil, convertDBError("list device requests: %w", err)
+	}
+
+	requests := make([]storage.DeviceRequest, 0, len(deviceRequests))
+	for _, r := range deviceRequests {
+		requests = append(requests, toStorageDeviceRequest(r))
+	}
+	return requests, nil
+}
+
+// scopesContain matches device requests whose JSON encoded scopes include
+// scope.
+func scopesContain(scope string) predicate.DeviceRequest {
+	return func(s *sql.Selector) {
+		s.Where(sqljson.ValueContains(s.C(devicerequest.FieldScopes), scope))
+	}
+}
+
+// validateScopes rejects scope lists with empty or repeated entries, which the
+// JSON column would otherwise store as they are.
+func validateScopes(scopes []string) error {
+	seen := make(map[string]bool, len(scopes))
+	for _, scope := range scopes {
+		if scope == "" {
+			return errors.New("empty scope")
+		}
+		if seen[scope] {
+			return fmt.Errorf("duplicate scope %q", scope)
+		}
+		seen[scope] = true
+	}
+	return nil
+}
+
+// backfillDeviceRequestCreatedAt dates device requests stored before their
+// creation time was recorded by their expiry minus the device request lifetime.
+func (d *Database) backfillDeviceRequestCreatedAt(ctx context.Context) error {
+	ttl := d.deviceRequestTTL
+	if ttl == 0 {
+		ttl = defaultDeviceRequestTTL
+	}
+
+	tx, err := d.BeginTx(ctx)
+	if err != nil {
+		return convertDBError("backfill device requests tx: %w", err)
+	}
+
+	deviceRequests, err := tx.DeviceRequest.Query().
+		Where(devicerequest.CreatedAtIsNil()).
+		All(ctx)
+	if err != nil {
+		return rollback(tx, "backfill device requests: %w", err)
+	}
+
+	for _, r := range deviceRequests {
+		err := tx.DeviceRequest.UpdateOne(r).
+			SetCreatedAt(r.Expiry.Add(-ttl).UTC()).
+			Exec(ctx)
+		if err != nil {
+			return rollback(tx, "backfill device request: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return rollback(tx, "backfill device requests commit: %w", err)
+	}
+	return nil
+}
+
+// deleteExpiredDeviceRequests removes device requests that expired before now.
+// With a GC batch size set, they are deleted a batch at a time so a large
+// backlog doesn't lock the table for long.
+func (d *Database) deleteExpiredDeviceRequests(ctx context.Context, now time.Time) (int64, error) {
+	if d.gcBatchSize <= 0 {
+		n, err := d.client.DeviceRequest.Delete().
+			Where(devicerequest.ExpiryLT(now)).
+			Exec(ctx)
+		return int64(n), err
+	}
+
+	var deleted int64
+	for {
+		ids, err := d.expiredDeviceRequestIDs(ctx, now, d.gcBatchSize)
+		if err != nil {
+			return deleted, err
+		}
+		if len(ids) == 0 {
+			return deleted, nil
+		}
+
+		n, err := d.client.DeviceRequest.Delete().
+			Where(devicerequest.IDIn(ids...)).
+			Exec(ctx)
+		deleted += int64(n)
+		if err != nil || len(ids) < d.gcBatchSize {
+			return deleted, err
+		}
+	}
+}
+
+// expiredDeviceRequestIDs returns the IDs of up to limit device requests that
+// expired before now, the longest expired first. The lookup uses the index on
+// the expiry column.
+func (d *Database) expiredDeviceRequestIDs(ctx context.Context, now time.Time, limit int) ([]int, error) {
+	return d.client.DeviceRequest.Query().
+		Where(devicerequest.ExpiryLT(now)).
+		Order(devicerequest.ByExpiry()).
+		Limit(limit).
+		IDs(ctx)
+}
+
+// CountDeviceRequestsExpiringBefore counts device requests expiring before t.
+func (d *Database) CountDeviceRequestsExpiringBefore(ctx context.Context, t time.Time) (int, error) {
+	n, err := d.client.DeviceRequest.Query().
+		Where(devicerequest.ExpiryLT(t.UTC())).
+		Count(ctx)
+	if err != nil {
+		return 0, convertDBError("count device requests: %w", err)
+	}
+	return n, nil
+}
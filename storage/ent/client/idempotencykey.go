@@ -0,0 +1,40 @@
+package client
+
+import (
+	"context"
+
+	"github.com/dexidp/dex/storage"
+)
+
+// CreateIdempotencyKey saves provided idempotency key into the database.
+func (d *Database) CreateIdempotencyKey(ctx context.Context, key storage.IdempotencyKey) error {
+	_, err := d.client.IdempotencyKey.Create().
+		SetID(key.Key).
+		SetResponse(key.Response).
+		SetRequestHash(key.RequestHash).
+		// Save utc time into database because ent doesn't support comparing dates with different timezones
+		SetExpiry(key.Expiry.UTC()).
+		Save(ctx)
+	if err != nil {
+		return convertDBError("create idempotency key: %w", err)
+	}
+	return nil
+}
+
+// GetIdempotencyKey extracts an idempotency key from the database by key.
+func (d *Database) GetIdempotencyKey(ctx context.Context, key string) (storage.IdempotencyKey, error) {
+	idempotencyKey, err := d.client.IdempotencyKey.Get(ctx, key)
+	if err != nil {
+		return storage.IdempotencyKey{}, convertDBError("get idempotency key: %w", err)
+	}
+	return toStorageIdempotencyKey(idempotencyKey), nil
+}
+
+// DeleteIdempotencyKey deletes an idempotency key from the database by key.
+func (d *Database) DeleteIdempotencyKey(ctx context.Context, key string) error {
+	err := d.client.IdempotencyKey.DeleteOneID(key).Exec(ctx)
+	if err != nil {
+		return convertDBError("delete idempotency key: %w", err)
+	}
+	return nil
+}
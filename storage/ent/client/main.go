@@ -6,12 +6,16 @@ import (
 	"hash"
 	"time"
 
+	atlas "ariga.io/atlas/sql/schema"
+	"entgo.io/ent/dialect/sql/schema"
+
 	"github.com/dexidp/dex/storage"
 	"github.com/dexidp/dex/storage/ent/db"
 	"github.com/dexidp/dex/storage/ent/db/authcode"
 	"github.com/dexidp/dex/storage/ent/db/authrequest"
 	"github.com/dexidp/dex/storage/ent/db/devicerequest"
 	"github.com/dexidp/dex/storage/ent/db/devicetoken"
+	"github.com/dexidp/dex/storage/ent/db/idempotencykey"
 	"github.com/dexidp/dex/storage/ent/db/migrate"
 )
 
@@ -22,6 +26,14 @@ type Database struct {
 	txOptions *sql.TxOptions
 
 	hasher func() hash.Hash
+
+	// gcBatchSize limits how many expired device requests are deleted at
+	// once, zero deletes them all in one statement.
+	gcBatchSize int
+
+	// deviceRequestTTL is the device request lifetime, see
+	// backfillDeviceRequestCreatedAt.
+	deviceRequestTTL time.Duration
 }
 
 // NewDatabase returns new database client with set options.
@@ -54,11 +66,65 @@ func WithTxIsolationLevel(level sql.IsolationLevel) func(*Database) {
 	}
 }
 
+// WithGCBatchSize sets how many expired device requests garbage collection deletes at once.
+func WithGCBatchSize(n int) func(*Database) {
+	return func(s *Database) {
+		s.gcBatchSize = n
+	}
+}
+
+// WithDeviceRequestTTL sets how long device requests are valid for.
+func WithDeviceRequestTTL(ttl time.Duration) func(*Database) {
+	return func(s *Database) {
+		s.deviceRequestTTL = ttl
+	}
+}
+
 // Schema exposes migration schema to perform migrations.
 func (d *Database) Schema() *migrate.Schema {
 	return d.client.Schema
 }
 
+// Migrate creates or updates the database schema. Columns that need their
+// existing rows backfilled are backfilled once, by the migration adding them.
+func (d *Database) Migrate(ctx context.Context) error {
+	var addsCreatedAt bool
+	err := d.client.Schema.Create(ctx, schema.WithDiffHook(func(next schema.Differ) schema.Differ {
+		return schema.DiffFunc(func(current, desired *atlas.Schema) ([]atlas.Change, error) {
+			changes, err := next.Diff(current, desired)
+			if err != nil {
+				return nil, err
+			}
+			addsCreatedAt = addsColumn(changes, devicerequest.Table, devicerequest.FieldCreatedAt)
+			return changes, nil
+		})
+	}))
+	if err != nil {
+		return err
+	}
+
+	if addsCreatedAt {
+		return d.backfillDeviceRequestCreatedAt(ctx)
+	}
+	return nil
+}
+
+// addsColumn reports whether changes add the column to an existing table.
+func addsColumn(changes []atlas.Change, table, column string) bool {
+	for _, change := range changes {
+		modify, ok := change.(*atlas.ModifyTable)
+		if !ok || modify.T.Name != table {
+			continue
+		}
+		for _, c := range modify.Changes {
+			if add, ok := c.(*atlas.AddColumn); ok && add.C.Name == column {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // Close calls the corresponding method of the ent database client.
 func (d *Database) Close() error {
 	return d.client.Close()
@@ -90,13 +156,10 @@ func (d *Database) GarbageCollect(ctx context.Context, now time.Time) (storage.G
 	}
 	result.AuthCodes = int64(q)
 
-	q, err = d.client.DeviceRequest.Delete().
-		Where(devicerequest.ExpiryLT(utcNow)).
-		Exec(ctx)
+	result.DeviceRequests, err = d.deleteExpiredDeviceRequests(ctx, utcNow)
 	if err != nil {
 		return result, convertDBError("gc device request: %w", err)
 	}
-	result.DeviceRequests = int64(q)
 
 	q, err = d.client.DeviceToken.Delete().
 		Where(devicetoken.ExpiryLT(utcNow)).
@@ -106,5 +169,13 @@ func (d *Database) GarbageCollect(ctx context.Context, now time.Time) (storage.G
 	}
 	result.DeviceTokens = int64(q)
 
+	q, err = d.client.IdempotencyKey.Delete().
+		Where(idempotencykey.ExpiryLT(utcNow)).
+		Exec(ctx)
+	if err != nil {
+		return result, convertDBError("gc idempotency key: %w", err)
+	}
+	result.IdempotencyKeys = int64(q)
+
 	return result, err
 }
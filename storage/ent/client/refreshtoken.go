@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/dexidp/dex/storage"
+	"github.com/dexidp/dex/storage/ent/db/refreshtoken"
 )
 
 // CreateRefresh saves provided refresh token into the database.
@@ -19,10 +20,12 @@ func (d *Database) CreateRefresh(ctx context.Context, refresh storage.RefreshTok
 		SetClaimsUsername(refresh.Claims.Username).
 		SetClaimsPreferredUsername(refresh.Claims.PreferredUsername).
 		SetClaimsGroups(refresh.Claims.Groups).
+		SetClaimsExtra(refresh.Claims.ExtraClaims).
 		SetConnectorID(refresh.ConnectorID).
 		SetConnectorData(refresh.ConnectorData).
 		SetToken(refresh.Token).
 		SetObsoleteToken(refresh.ObsoleteToken).
+		SetConsumed(refresh.Consumed).
 		// Save utc time into database because ent doesn't support comparing dates with different timezones
 		SetLastUsed(refresh.LastUsed.UTC()).
 		SetCreatedAt(refresh.CreatedAt.UTC()).
@@ -65,6 +68,20 @@ func (d *Database) DeleteRefresh(ctx context.Context, id string) error {
 	return nil
 }
 
+// DeleteRefreshTokensForUser deletes the refresh tokens of a user issued through a connector.
+func (d *Database) DeleteRefreshTokensForUser(ctx context.Context, userID, connID string) (int, error) {
+	n, err := d.client.RefreshToken.Delete().
+		Where(
+			refreshtoken.ClaimsUserID(userID),
+			refreshtoken.ConnectorID(connID),
+		).
+		Exec(ctx)
+	if err != nil {
+		return 0, convertDBError("delete refresh tokens: %w", err)
+	}
+	return n, nil
+}
+
 // UpdateRefreshToken changes a refresh token by id using an updater function and saves it to the database.
 func (d *Database) UpdateRefreshToken(ctx context.Context, id string, updater func(old storage.RefreshToken) (storage.RefreshToken, error)) error {
 	tx, err := d.BeginTx(ctx)
@@ -92,10 +109,12 @@ func (d *Database) UpdateRefreshToken(ctx context.Context, id string, updater fu
 		SetClaimsUsername(newtToken.Claims.Username).
 		SetClaimsPreferredUsername(newtToken.Claims.PreferredUsername).
 		SetClaimsGroups(newtToken.Claims.Groups).
+		SetClaimsExtra(newtToken.Claims.ExtraClaims).
 		SetConnectorID(newtToken.ConnectorID).
 		SetConnectorData(newtToken.ConnectorData).
 		SetToken(newtToken.Token).
 		SetObsoleteToken(newtToken.ObsoleteToken).
+		SetConsumed(newtToken.Consumed).
 		// Save utc time into database because ent doesn't support comparing dates with different timezones
 		SetLastUsed(newtToken.LastUsed.UTC()).
 		SetCreatedAt(newtToken.CreatedAt.UTC()).
@@ -40,6 +40,7 @@ func toStorageAuthRequest(a *db.AuthRequest) storage.AuthRequest {
 			Email:             a.ClaimsEmail,
 			EmailVerified:     a.ClaimsEmailVerified,
 			Groups:            a.ClaimsGroups,
+			ExtraClaims:       a.ClaimsExtra,
 		},
 		PKCE: storage.PKCE{
 			CodeChallenge:       a.CodeChallenge,
@@ -66,6 +67,7 @@ func toStorageAuthCode(a *db.AuthCode) storage.AuthCode {
 			Email:             a.ClaimsEmail,
 			EmailVerified:     a.ClaimsEmailVerified,
 			Groups:            a.ClaimsGroups,
+			ExtraClaims:       a.ClaimsExtra,
 		},
 		PKCE: storage.PKCE{
 			CodeChallenge:       a.CodeChallenge,
@@ -76,13 +78,19 @@ func toStorageAuthCode(a *db.AuthCode) storage.AuthCode {
 
 func toStorageClient(c *db.OAuth2Client) storage.Client {
 	return storage.Client{
-		ID:           c.ID,
-		Secret:       c.Secret,
-		RedirectURIs: c.RedirectUris,
-		TrustedPeers: c.TrustedPeers,
-		Public:       c.Public,
-		Name:         c.Name,
-		LogoURL:      c.LogoURL,
+		ID:                   c.ID,
+		Secret:               c.Secret,
+		RedirectURIs:         c.RedirectUris,
+		TrustedPeers:         c.TrustedPeers,
+		Public:               c.Public,
+		Name:                 c.Name,
+		LogoURL:              c.LogoURL,
+		AccessTokenLifetime:  c.AccessTokenLifetime,
+		RefreshTokenLifetime: c.RefreshTokenLifetime,
+		CreatedAt:            c.CreatedAt,
+		UpdatedAt:            c.UpdatedAt,
+		Disabled:             c.Disabled,
+		AllowedConnectors:    c.AllowedConnectors,
 	}
 }
 
@@ -119,6 +127,7 @@ func toStorageRefreshToken(r *db.RefreshToken) storage.RefreshToken {
 		ID:            r.ID,
 		Token:         r.Token,
 		ObsoleteToken: r.ObsoleteToken,
+		Consumed:      r.Consumed,
 		CreatedAt:     r.CreatedAt,
 		LastUsed:      r.LastUsed,
 		ClientID:      r.ClientID,
@@ -133,6 +142,7 @@ func toStorageRefreshToken(r *db.RefreshToken) storage.RefreshToken {
 			Email:             r.ClaimsEmail,
 			EmailVerified:     r.ClaimsEmailVerified,
 			Groups:            r.ClaimsGroups,
+			ExtraClaims:       r.ClaimsExtra,
 		},
 	}
 }
@@ -148,12 +158,15 @@ func toStoragePassword(p *db.Password) storage.Password {
 
 func toStorageDeviceRequest(r *db.DeviceRequest) storage.DeviceRequest {
 	return storage.DeviceRequest{
-		UserCode:     strings.ToUpper(r.UserCode),
-		DeviceCode:   r.DeviceCode,
-		ClientID:     r.ClientID,
-		ClientSecret: r.ClientSecret,
-		Scopes:       r.Scopes,
-		Expiry:       r.Expiry,
+		UserCode:            strings.ToUpper(r.UserCode),
+		DeviceCode:          r.DeviceCode,
+		ClientID:            r.ClientID,
+		ClientSecret:        r.ClientSecret,
+		Scopes:              r.Scopes,
+		Expiry:              r.Expiry,
+		PollIntervalSeconds: r.PollInterval,
+		Consumed:            r.Consumed,
+		CreatedAt:           r.CreatedAt,
 	}
 }
 
@@ -171,3 +184,12 @@ func toStorageDeviceToken(t *db.DeviceToken) storage.DeviceToken {
 		},
 	}
 }
+
+func toStorageIdempotencyKey(k *db.IdempotencyKey) storage.IdempotencyKey {
+	return storage.IdempotencyKey{
+		Key:         k.ID,
+		Response:    k.Response,
+		RequestHash: k.RequestHash,
+		Expiry:      k.Expiry,
+	}
+}
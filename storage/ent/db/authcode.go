@@ -36,6 +36,8 @@ type AuthCode struct {
 	ClaimsEmailVerified bool `json:"claims_email_verified,omitempty"`
 	// ClaimsGroups holds the value of the "claims_groups" field.
 	ClaimsGroups []string `json:"claims_groups,omitempty"`
+	// ClaimsExtra holds the value of the "claims_extra" field.
+	ClaimsExtra map[string]interface{} `json:"claims_extra,omitempty"`
 	// ClaimsPreferredUsername holds the value of the "claims_preferred_username" field.
 	ClaimsPreferredUsername string `json:"claims_preferred_username,omitempty"`
 	// ConnectorID holds the value of the "connector_id" field.
@@ -56,7 +58,7 @@ func (*AuthCode) scanValues(columns []string) ([]any, error) {
 	values := make([]any, len(columns))
 	for i := range columns {
 		switch columns[i] {
-		case authcode.FieldScopes, authcode.FieldClaimsGroups, authcode.FieldConnectorData:
+		case authcode.FieldScopes, authcode.FieldClaimsGroups, authcode.FieldClaimsExtra, authcode.FieldConnectorData:
 			values[i] = new([]byte)
 		case authcode.FieldClaimsEmailVerified:
 			values[i] = new(sql.NullBool)
@@ -143,6 +145,14 @@ func (ac *AuthCode) assignValues(columns []string, values []any) error {
 					return fmt.Errorf("unmarshal field claims_groups: %w", err)
 				}
 			}
+		case authcode.FieldClaimsExtra:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field claims_extra", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &ac.ClaimsExtra); err != nil {
+					return fmt.Errorf("unmarshal field claims_extra: %w", err)
+				}
+			}
 		case authcode.FieldClaimsPreferredUsername:
 			if value, ok := values[i].(*sql.NullString); !ok {
 				return fmt.Errorf("unexpected type %T for field claims_preferred_username", values[i])
@@ -242,6 +252,9 @@ func (ac *AuthCode) String() string {
 	builder.WriteString("claims_groups=")
 	builder.WriteString(fmt.Sprintf("%v", ac.ClaimsGroups))
 	builder.WriteString(", ")
+	builder.WriteString("claims_extra=")
+	builder.WriteString(fmt.Sprintf("%v", ac.ClaimsExtra))
+	builder.WriteString(", ")
 	builder.WriteString("claims_preferred_username=")
 	builder.WriteString(ac.ClaimsPreferredUsername)
 	builder.WriteString(", ")
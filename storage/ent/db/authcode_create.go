@@ -74,6 +74,12 @@ func (acc *AuthCodeCreate) SetClaimsGroups(s []string) *AuthCodeCreate {
 	return acc
 }
 
+// SetClaimsExtra sets the "claims_extra" field.
+func (acc *AuthCodeCreate) SetClaimsExtra(m map[string]interface{}) *AuthCodeCreate {
+	acc.mutation.SetClaimsExtra(m)
+	return acc
+}
+
 // SetClaimsPreferredUsername sets the "claims_preferred_username" field.
 func (acc *AuthCodeCreate) SetClaimsPreferredUsername(s string) *AuthCodeCreate {
 	acc.mutation.SetClaimsPreferredUsername(s)
@@ -338,6 +344,10 @@ func (acc *AuthCodeCreate) createSpec() (*AuthCode, *sqlgraph.CreateSpec) {
 		_spec.SetField(authcode.FieldClaimsGroups, field.TypeJSON, value)
 		_node.ClaimsGroups = value
 	}
+	if value, ok := acc.mutation.ClaimsExtra(); ok {
+		_spec.SetField(authcode.FieldClaimsExtra, field.TypeJSON, value)
+		_node.ClaimsExtra = value
+	}
 	if value, ok := acc.mutation.ClaimsPreferredUsername(); ok {
 		_spec.SetField(authcode.FieldClaimsPreferredUsername, field.TypeString, value)
 		_node.ClaimsPreferredUsername = value
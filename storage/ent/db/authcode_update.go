@@ -163,6 +163,18 @@ func (acu *AuthCodeUpdate) ClearClaimsGroups() *AuthCodeUpdate {
 	return acu
 }
 
+// SetClaimsExtra sets the "claims_extra" field.
+func (acu *AuthCodeUpdate) SetClaimsExtra(m map[string]interface{}) *AuthCodeUpdate {
+	acu.mutation.SetClaimsExtra(m)
+	return acu
+}
+
+// ClearClaimsExtra clears the value of the "claims_extra" field.
+func (acu *AuthCodeUpdate) ClearClaimsExtra() *AuthCodeUpdate {
+	acu.mutation.ClearClaimsExtra()
+	return acu
+}
+
 // SetClaimsPreferredUsername sets the "claims_preferred_username" field.
 func (acu *AuthCodeUpdate) SetClaimsPreferredUsername(s string) *AuthCodeUpdate {
 	acu.mutation.SetClaimsPreferredUsername(s)
@@ -372,6 +384,12 @@ func (acu *AuthCodeUpdate) sqlSave(ctx context.Context) (n int, err error) {
 	if acu.mutation.ClaimsGroupsCleared() {
 		_spec.ClearField(authcode.FieldClaimsGroups, field.TypeJSON)
 	}
+	if value, ok := acu.mutation.ClaimsExtra(); ok {
+		_spec.SetField(authcode.FieldClaimsExtra, field.TypeJSON, value)
+	}
+	if acu.mutation.ClaimsExtraCleared() {
+		_spec.ClearField(authcode.FieldClaimsExtra, field.TypeJSON)
+	}
 	if value, ok := acu.mutation.ClaimsPreferredUsername(); ok {
 		_spec.SetField(authcode.FieldClaimsPreferredUsername, field.TypeString, value)
 	}
@@ -547,6 +565,18 @@ func (acuo *AuthCodeUpdateOne) ClearClaimsGroups() *AuthCodeUpdateOne {
 	return acuo
 }
 
+// SetClaimsExtra sets the "claims_extra" field.
+func (acuo *AuthCodeUpdateOne) SetClaimsExtra(m map[string]interface{}) *AuthCodeUpdateOne {
+	acuo.mutation.SetClaimsExtra(m)
+	return acuo
+}
+
+// ClearClaimsExtra clears the value of the "claims_extra" field.
+func (acuo *AuthCodeUpdateOne) ClearClaimsExtra() *AuthCodeUpdateOne {
+	acuo.mutation.ClearClaimsExtra()
+	return acuo
+}
+
 // SetClaimsPreferredUsername sets the "claims_preferred_username" field.
 func (acuo *AuthCodeUpdateOne) SetClaimsPreferredUsername(s string) *AuthCodeUpdateOne {
 	acuo.mutation.SetClaimsPreferredUsername(s)
@@ -786,6 +816,12 @@ func (acuo *AuthCodeUpdateOne) sqlSave(ctx context.Context) (_node *AuthCode, er
 	if acuo.mutation.ClaimsGroupsCleared() {
 		_spec.ClearField(authcode.FieldClaimsGroups, field.TypeJSON)
 	}
+	if value, ok := acuo.mutation.ClaimsExtra(); ok {
+		_spec.SetField(authcode.FieldClaimsExtra, field.TypeJSON, value)
+	}
+	if acuo.mutation.ClaimsExtraCleared() {
+		_spec.ClearField(authcode.FieldClaimsExtra, field.TypeJSON)
+	}
 	if value, ok := acuo.mutation.ClaimsPreferredUsername(); ok {
 		_spec.SetField(authcode.FieldClaimsPreferredUsername, field.TypeString, value)
 	}
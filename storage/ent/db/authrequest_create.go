@@ -98,6 +98,12 @@ func (arc *AuthRequestCreate) SetClaimsGroups(s []string) *AuthRequestCreate {
 	return arc
 }
 
+// SetClaimsExtra sets the "claims_extra" field.
+func (arc *AuthRequestCreate) SetClaimsExtra(m map[string]interface{}) *AuthRequestCreate {
+	arc.mutation.SetClaimsExtra(m)
+	return arc
+}
+
 // SetClaimsPreferredUsername sets the "claims_preferred_username" field.
 func (arc *AuthRequestCreate) SetClaimsPreferredUsername(s string) *AuthRequestCreate {
 	arc.mutation.SetClaimsPreferredUsername(s)
@@ -361,6 +367,10 @@ func (arc *AuthRequestCreate) createSpec() (*AuthRequest, *sqlgraph.CreateSpec)
 		_spec.SetField(authrequest.FieldClaimsGroups, field.TypeJSON, value)
 		_node.ClaimsGroups = value
 	}
+	if value, ok := arc.mutation.ClaimsExtra(); ok {
+		_spec.SetField(authrequest.FieldClaimsExtra, field.TypeJSON, value)
+		_node.ClaimsExtra = value
+	}
 	if value, ok := arc.mutation.ClaimsPreferredUsername(); ok {
 		_spec.SetField(authrequest.FieldClaimsPreferredUsername, field.TypeString, value)
 		_node.ClaimsPreferredUsername = value
@@ -223,6 +223,18 @@ func (aru *AuthRequestUpdate) ClearClaimsGroups() *AuthRequestUpdate {
 	return aru
 }
 
+// SetClaimsExtra sets the "claims_extra" field.
+func (aru *AuthRequestUpdate) SetClaimsExtra(m map[string]interface{}) *AuthRequestUpdate {
+	aru.mutation.SetClaimsExtra(m)
+	return aru
+}
+
+// ClearClaimsExtra clears the value of the "claims_extra" field.
+func (aru *AuthRequestUpdate) ClearClaimsExtra() *AuthRequestUpdate {
+	aru.mutation.ClearClaimsExtra()
+	return aru
+}
+
 // SetClaimsPreferredUsername sets the "claims_preferred_username" field.
 func (aru *AuthRequestUpdate) SetClaimsPreferredUsername(s string) *AuthRequestUpdate {
 	aru.mutation.SetClaimsPreferredUsername(s)
@@ -415,6 +427,12 @@ func (aru *AuthRequestUpdate) sqlSave(ctx context.Context) (n int, err error) {
 	if aru.mutation.ClaimsGroupsCleared() {
 		_spec.ClearField(authrequest.FieldClaimsGroups, field.TypeJSON)
 	}
+	if value, ok := aru.mutation.ClaimsExtra(); ok {
+		_spec.SetField(authrequest.FieldClaimsExtra, field.TypeJSON, value)
+	}
+	if aru.mutation.ClaimsExtraCleared() {
+		_spec.ClearField(authrequest.FieldClaimsExtra, field.TypeJSON)
+	}
 	if value, ok := aru.mutation.ClaimsPreferredUsername(); ok {
 		_spec.SetField(authrequest.FieldClaimsPreferredUsername, field.TypeString, value)
 	}
@@ -653,6 +671,18 @@ func (aruo *AuthRequestUpdateOne) ClearClaimsGroups() *AuthRequestUpdateOne {
 	return aruo
 }
 
+// SetClaimsExtra sets the "claims_extra" field.
+func (aruo *AuthRequestUpdateOne) SetClaimsExtra(m map[string]interface{}) *AuthRequestUpdateOne {
+	aruo.mutation.SetClaimsExtra(m)
+	return aruo
+}
+
+// ClearClaimsExtra clears the value of the "claims_extra" field.
+func (aruo *AuthRequestUpdateOne) ClearClaimsExtra() *AuthRequestUpdateOne {
+	aruo.mutation.ClearClaimsExtra()
+	return aruo
+}
+
 // SetClaimsPreferredUsername sets the "claims_preferred_username" field.
 func (aruo *AuthRequestUpdateOne) SetClaimsPreferredUsername(s string) *AuthRequestUpdateOne {
 	aruo.mutation.SetClaimsPreferredUsername(s)
@@ -875,6 +905,12 @@ func (aruo *AuthRequestUpdateOne) sqlSave(ctx context.Context) (_node *AuthReque
 	if aruo.mutation.ClaimsGroupsCleared() {
 		_spec.ClearField(authrequest.FieldClaimsGroups, field.TypeJSON)
 	}
+	if value, ok := aruo.mutation.ClaimsExtra(); ok {
+		_spec.SetField(authrequest.FieldClaimsExtra, field.TypeJSON, value)
+	}
+	if aruo.mutation.ClaimsExtraCleared() {
+		_spec.ClearField(authrequest.FieldClaimsExtra, field.TypeJSON)
+	}
 	if value, ok := aruo.mutation.ClaimsPreferredUsername(); ok {
 		_spec.SetField(authrequest.FieldClaimsPreferredUsername, field.TypeString, value)
 	}
@@ -29,7 +29,13 @@ type DeviceRequest struct {
 	// Scopes holds the value of the "scopes" field.
 	Scopes []string `json:"scopes,omitempty"`
 	// Expiry holds the value of the "expiry" field.
-	Expiry       time.Time `json:"expiry,omitempty"`
+	Expiry time.Time `json:"expiry,omitempty"`
+	// CreatedAt holds the value of the "created_at" field.
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	// PollInterval holds the value of the "poll_interval" field.
+	PollInterval int `json:"poll_interval,omitempty"`
+	// Consumed holds the value of the "consumed" field.
+	Consumed     bool `json:"consumed,omitempty"`
 	selectValues sql.SelectValues
 }
 
@@ -40,11 +46,13 @@ func (*DeviceRequest) scanValues(columns []string) ([]any, error) {
 		switch columns[i] {
 		case devicerequest.FieldScopes:
 			values[i] = new([]byte)
-		case devicerequest.FieldID:
+		case devicerequest.FieldConsumed:
+			values[i] = new(sql.NullBool)
+		case devicerequest.FieldID, devicerequest.FieldPollInterval:
 			values[i] = new(sql.NullInt64)
 		case devicerequest.FieldUserCode, devicerequest.FieldDeviceCode, devicerequest.FieldClientID, devicerequest.FieldClientSecret:
 			values[i] = new(sql.NullString)
-		case devicerequest.FieldExpiry:
+		case devicerequest.FieldExpiry, devicerequest.FieldCreatedAt:
 			values[i] = new(sql.NullTime)
 		default:
 			values[i] = new(sql.UnknownType)
@@ -105,6 +113,24 @@ func (dr *DeviceRequest) assignValues(columns []string, values []any) error {
 			} else if value.Valid {
 				dr.Expiry = value.Time
 			}
+		case devicerequest.FieldCreatedAt:
+			if value, ok := values[i].(*sql.NullTime); !ok {
+				return fmt.Errorf("unexpected type %T for field created_at", values[i])
+			} else if value.Valid {
+				dr.CreatedAt = value.Time
+			}
+		case devicerequest.FieldPollInterval:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field poll_interval", values[i])
+			} else if value.Valid {
+				dr.PollInterval = int(value.Int64)
+			}
+		case devicerequest.FieldConsumed:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field consumed", values[i])
+			} else if value.Valid {
+				dr.Consumed = value.Bool
+			}
 		default:
 			dr.selectValues.Set(columns[i], values[i])
 		}
@@ -158,6 +184,15 @@ func (dr *DeviceRequest) String() string {
 	builder.WriteString(", ")
 	builder.WriteString("expiry=")
 	builder.WriteString(dr.Expiry.Format(time.ANSIC))
+	builder.WriteString(", ")
+	builder.WriteString("created_at=")
+	builder.WriteString(dr.CreatedAt.Format(time.ANSIC))
+	builder.WriteString(", ")
+	builder.WriteString("poll_interval=")
+	builder.WriteString(fmt.Sprintf("%v", dr.PollInterval))
+	builder.WriteString(", ")
+	builder.WriteString("consumed=")
+	builder.WriteString(fmt.Sprintf("%v", dr.Consumed))
 	builder.WriteByte(')')
 	return builder.String()
 }
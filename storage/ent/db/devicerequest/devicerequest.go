@@ -23,6 +23,12 @@ const (
 	FieldScopes = "scopes"
 	// FieldExpiry holds the string denoting the expiry field in the database.
 	FieldExpiry = "expiry"
+	// FieldCreatedAt holds the string denoting the created_at field in the database.
+	FieldCreatedAt = "created_at"
+	// FieldPollInterval holds the string denoting the poll_interval field in the database.
+	FieldPollInterval = "poll_interval"
+	// FieldConsumed holds the string denoting the consumed field in the database.
+	FieldConsumed = "consumed"
 	// Table holds the table name of the devicerequest in the database.
 	Table = "device_requests"
 )
@@ -36,6 +42,9 @@ var Columns = []string{
 	FieldClientSecret,
 	FieldScopes,
 	FieldExpiry,
+	FieldCreatedAt,
+	FieldPollInterval,
+	FieldConsumed,
 }
 
 // ValidColumn reports if the column name is valid (part of the table columns).
@@ -57,6 +66,10 @@ var (
 	ClientIDValidator func(string) error
 	// ClientSecretValidator is a validator for the "client_secret" field. It is called by the builders before save.
 	ClientSecretValidator func(string) error
+	// DefaultPollInterval holds the default value on creation for the "poll_interval" field.
+	DefaultPollInterval int
+	// DefaultConsumed holds the default value on creation for the "consumed" field.
+	DefaultConsumed bool
 )
 
 // OrderOption defines the ordering options for the DeviceRequest queries.
@@ -91,3 +104,18 @@ func ByClientSecret(opts ...sql.OrderTermOption) OrderOption {
 func ByExpiry(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldExpiry, opts...).ToFunc()
 }
+
+// ByCreatedAt orders the results by the created_at field.
+func ByCreatedAt(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldCreatedAt, opts...).ToFunc()
+}
+
+// ByPollInterval orders the results by the poll_interval field.
+func ByPollInterval(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldPollInterval, opts...).ToFunc()
+}
+
+// ByConsumed orders the results by the consumed field.
+func ByConsumed(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldConsumed, opts...).ToFunc()
+}
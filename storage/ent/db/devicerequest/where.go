@@ -79,6 +79,21 @@ func Expiry(v time.Time) predicate.DeviceRequest {
 	return predicate.DeviceRequest(sql.FieldEQ(FieldExpiry, v))
 }
 
+// CreatedAt applies equality check predicate on the "created_at" field. It's identical to CreatedAtEQ.
+func CreatedAt(v time.Time) predicate.DeviceRequest {
+	return predicate.DeviceRequest(sql.FieldEQ(FieldCreatedAt, v))
+}
+
+// PollInterval applies equality check predicate on the "poll_interval" field. It's identical to PollIntervalEQ.
+func PollInterval(v int) predicate.DeviceRequest {
+	return predicate.DeviceRequest(sql.FieldEQ(FieldPollInterval, v))
+}
+
+// Consumed applies equality check predicate on the "consumed" field. It's identical to ConsumedEQ.
+func Consumed(v bool) predicate.DeviceRequest {
+	return predicate.DeviceRequest(sql.FieldEQ(FieldConsumed, v))
+}
+
 // UserCodeEQ applies the EQ predicate on the "user_code" field.
 func UserCodeEQ(v string) predicate.DeviceRequest {
 	return predicate.DeviceRequest(sql.FieldEQ(FieldUserCode, v))
@@ -389,6 +404,106 @@ func ExpiryLTE(v time.Time) predicate.DeviceRequest {
 	return predicate.DeviceRequest(sql.FieldLTE(FieldExpiry, v))
 }
 
+// CreatedAtEQ applies the EQ predicate on the "created_at" field.
+func CreatedAtEQ(v time.Time) predicate.DeviceRequest {
+	return predicate.DeviceRequest(sql.FieldEQ(FieldCreatedAt, v))
+}
+
+// CreatedAtNEQ applies the NEQ predicate on the "created_at" field.
+func CreatedAtNEQ(v time.Time) predicate.DeviceRequest {
+	return predicate.DeviceRequest(sql.FieldNEQ(FieldCreatedAt, v))
+}
+
+// CreatedAtIn applies the In predicate on the "created_at" field.
+func CreatedAtIn(vs ...time.Time) predicate.DeviceRequest {
+	return predicate.DeviceRequest(sql.FieldIn(FieldCreatedAt, vs...))
+}
+
+// CreatedAtNotIn applies the NotIn predicate on the "created_at" field.
+func CreatedAtNotIn(vs ...time.Time) predicate.DeviceRequest {
+	return predicate.DeviceRequest(sql.FieldNotIn(FieldCreatedAt, vs...))
+}
+
+// CreatedAtGT applies the GT predicate on the "created_at" field.
+func CreatedAtGT(v time.Time) predicate.DeviceRequest {
+	return predicate.DeviceRequest(sql.FieldGT(FieldCreatedAt, v))
+}
+
+// CreatedAtGTE applies the GTE predicate on the "created_at" field.
+func CreatedAtGTE(v time.Time) predicate.DeviceRequest {
+	return predicate.DeviceRequest(sql.FieldGTE(FieldCreatedAt, v))
+}
+
+// CreatedAtLT applies the LT predicate on the "created_at" field.
+func CreatedAtLT(v time.Time) predicate.DeviceRequest {
+	return predicate.DeviceRequest(sql.FieldLT(FieldCreatedAt, v))
+}
+
+// CreatedAtLTE applies the LTE predicate on the "created_at" field.
+func CreatedAtLTE(v time.Time) predicate.DeviceRequest {
+	return predicate.DeviceRequest(sql.FieldLTE(FieldCreatedAt, v))
+}
+
+// CreatedAtIsNil applies the IsNil predicate on the "created_at" field.
+func CreatedAtIsNil() predicate.DeviceRequest {
+	return predicate.DeviceRequest(sql.FieldIsNull(FieldCreatedAt))
+}
+
+// CreatedAtNotNil applies the NotNil predicate on the "created_at" field.
+func CreatedAtNotNil() predicate.DeviceRequest {
+	return predicate.DeviceRequest(sql.FieldNotNull(FieldCreatedAt))
+}
+
+// PollIntervalEQ applies the EQ predicate on the "poll_interval" field.
+func PollIntervalEQ(v int) predicate.DeviceRequest {
+	return predicate.DeviceRequest(sql.FieldEQ(FieldPollInterval, v))
+}
+
+// PollIntervalNEQ applies the NEQ predicate on the "poll_interval" field.
+func PollIntervalNEQ(v int) predicate.DeviceRequest {
+	return predicate.DeviceRequest(sql.FieldNEQ(FieldPollInterval, v))
+}
+
+// PollIntervalIn applies the In predicate on the "poll_interval" field.
+func PollIntervalIn(vs ...int) predicate.DeviceRequest {
+	return predicate.DeviceRequest(sql.FieldIn(FieldPollInterval, vs...))
+}
+
+// PollIntervalNotIn applies the NotIn predicate on the "poll_interval" field.
+func PollIntervalNotIn(vs ...int) predicate.DeviceRequest {
+	return predicate.DeviceRequest(sql.FieldNotIn(FieldPollInterval, vs...))
+}
+
+// PollIntervalGT applies the GT predicate on the "poll_interval" field.
+func PollIntervalGT(v int) predicate.DeviceRequest {
+	return predicate.DeviceRequest(sql.FieldGT(FieldPollInterval, v))
+}
+
+// PollIntervalGTE applies the GTE predicate on the "poll_interval" field.
+func PollIntervalGTE(v int) predicate.DeviceRequest {
+	return predicate.DeviceRequest(sql.FieldGTE(FieldPollInterval, v))
+}
+
+// PollIntervalLT applies the LT predicate on the "poll_interval" field.
+func PollIntervalLT(v int) predicate.DeviceRequest {
+	return predicate.DeviceRequest(sql.FieldLT(FieldPollInterval, v))
+}
+
+// PollIntervalLTE applies the LTE predicate on the "poll_interval" field.
+func PollIntervalLTE(v int) predicate.DeviceRequest {
+	return predicate.DeviceRequest(sql.FieldLTE(FieldPollInterval, v))
+}
+
+// ConsumedEQ applies the EQ predicate on the "consumed" field.
+func ConsumedEQ(v bool) predicate.DeviceRequest {
+	return predicate.DeviceRequest(sql.FieldEQ(FieldConsumed, v))
+}
+
+// ConsumedNEQ applies the NEQ predicate on the "consumed" field.
+func ConsumedNEQ(v bool) predicate.DeviceRequest {
+	return predicate.DeviceRequest(sql.FieldNEQ(FieldConsumed, v))
+}
+
 // And groups predicates with the AND operator between them.
 func And(predicates ...predicate.DeviceRequest) predicate.DeviceRequest {
 	return predicate.DeviceRequest(sql.AndPredicates(predicates...))
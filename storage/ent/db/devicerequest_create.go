@@ -56,6 +56,48 @@ func (drc *DeviceRequestCreate) SetExpiry(t time.Time) *DeviceRequestCreate {
 	return drc
 }
 
+// SetCreatedAt sets the "created_at" field.
+func (drc *DeviceRequestCreate) SetCreatedAt(t time.Time) *DeviceRequestCreate {
+	drc.mutation.SetCreatedAt(t)
+	return drc
+}
+
+// SetNillableCreatedAt sets the "created_at" field if the given value is not nil.
+func (drc *DeviceRequestCreate) SetNillableCreatedAt(t *time.Time) *DeviceRequestCreate {
+	if t != nil {
+		drc.SetCreatedAt(*t)
+	}
+	return drc
+}
+
+// SetPollInterval sets the "poll_interval" field.
+func (drc *DeviceRequestCreate) SetPollInterval(i int) *DeviceRequestCreate {
+	drc.mutation.SetPollInterval(i)
+	return drc
+}
+
+// SetNillablePollInterval sets the "poll_interval" field if the given value is not nil.
+func (drc *DeviceRequestCreate) SetNillablePollInterval(i *int) *DeviceRequestCreate {
+	if i != nil {
+		drc.SetPollInterval(*i)
+	}
+	return drc
+}
+
+// SetConsumed sets the "consumed" field.
+func (drc *DeviceRequestCreate) SetConsumed(b bool) *DeviceRequestCreate {
+	drc.mutation.SetConsumed(b)
+	return drc
+}
+
+// SetNillableConsumed sets the "consumed" field if the given value is not nil.
+func (drc *DeviceRequestCreate) SetNillableConsumed(b *bool) *DeviceRequestCreate {
+	if b != nil {
+		drc.SetConsumed(*b)
+	}
+	return drc
+}
+
 // Mutation returns the DeviceRequestMutation object of the builder.
 func (drc *DeviceRequestCreate) Mutation() *DeviceRequestMutation {
 	return drc.mutation
@@ -63,6 +105,7 @@ func (drc *DeviceRequestCreate) Mutation() *DeviceRequestMutation {
 
 // Save creates the DeviceRequest in the database.
 func (drc *DeviceRequestCreate) Save(ctx context.Context) (*DeviceRequest, error) {
+	drc.defaults()
 	return withHooks(ctx, drc.sqlSave, drc.mutation, drc.hooks)
 }
 
@@ -88,6 +131,18 @@ func (drc *DeviceRequestCreate) ExecX(ctx context.Context) {
 	}
 }
 
+// defaults sets the default values of the builder before save.
+func (drc *DeviceRequestCreate) defaults() {
+	if _, ok := drc.mutation.PollInterval(); !ok {
+		v := devicerequest.DefaultPollInterval
+		drc.mutation.SetPollInterval(v)
+	}
+	if _, ok := drc.mutation.Consumed(); !ok {
+		v := devicerequest.DefaultConsumed
+		drc.mutation.SetConsumed(v)
+	}
+}
+
 // check runs all checks and user-defined validators on the builder.
 func (drc *DeviceRequestCreate) check() error {
 	if _, ok := drc.mutation.UserCode(); !ok {
@@ -125,6 +180,12 @@ func (drc *DeviceRequestCreate) check() error {
 	if _, ok := drc.mutation.Expiry(); !ok {
 		return &ValidationError{Name: "expiry", err: errors.New(`db: missing required field "DeviceRequest.expiry"`)}
 	}
+	if _, ok := drc.mutation.PollInterval(); !ok {
+		return &ValidationError{Name: "poll_interval", err: errors.New(`db: missing required field "DeviceRequest.poll_interval"`)}
+	}
+	if _, ok := drc.mutation.Consumed(); !ok {
+		return &ValidationError{Name: "consumed", err: errors.New(`db: missing required field "DeviceRequest.consumed"`)}
+	}
 	return nil
 }
 
@@ -175,6 +236,18 @@ func (drc *DeviceRequestCreate) createSpec() (*DeviceRequest, *sqlgraph.CreateSp
 		_spec.SetField(devicerequest.FieldExpiry, field.TypeTime, value)
 		_node.Expiry = value
 	}
+	if value, ok := drc.mutation.CreatedAt(); ok {
+		_spec.SetField(devicerequest.FieldCreatedAt, field.TypeTime, value)
+		_node.CreatedAt = value
+	}
+	if value, ok := drc.mutation.PollInterval(); ok {
+		_spec.SetField(devicerequest.FieldPollInterval, field.TypeInt, value)
+		_node.PollInterval = value
+	}
+	if value, ok := drc.mutation.Consumed(); ok {
+		_spec.SetField(devicerequest.FieldConsumed, field.TypeBool, value)
+		_node.Consumed = value
+	}
 	return _node, _spec
 }
 
@@ -196,6 +269,7 @@ func (drcb *DeviceRequestCreateBulk) Save(ctx context.Context) ([]*DeviceRequest
 	for i := range drcb.builders {
 		func(i int, root context.Context) {
 			builder := drcb.builders[i]
+			builder.defaults()
 			var mut Mutator = MutateFunc(func(ctx context.Context, m Mutation) (Value, error) {
 				mutation, ok := m.(*DeviceRequestMutation)
 				if !ok {
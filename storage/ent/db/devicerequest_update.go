@@ -117,6 +117,61 @@ func (dru *DeviceRequestUpdate) SetNillableExpiry(t *time.Time) *DeviceRequestUp
 	return dru
 }
 
+// SetCreatedAt sets the "created_at" field.
+func (dru *DeviceRequestUpdate) SetCreatedAt(t time.Time) *DeviceRequestUpdate {
+	dru.mutation.SetCreatedAt(t)
+	return dru
+}
+
+// SetNillableCreatedAt sets the "created_at" field if the given value is not nil.
+func (dru *DeviceRequestUpdate) SetNillableCreatedAt(t *time.Time) *DeviceRequestUpdate {
+	if t != nil {
+		dru.SetCreatedAt(*t)
+	}
+	return dru
+}
+
+// ClearCreatedAt clears the value of the "created_at" field.
+func (dru *DeviceRequestUpdate) ClearCreatedAt() *DeviceRequestUpdate {
+	dru.mutation.ClearCreatedAt()
+	return dru
+}
+
+// SetPollInterval sets the "poll_interval" field.
+func (dru *DeviceRequestUpdate) SetPollInterval(i int) *DeviceRequestUpdate {
+	dru.mutation.ResetPollInterval()
+	dru.mutation.SetPollInterval(i)
+	return dru
+}
+
+// SetNillablePollInterval sets the "poll_interval" field if the given value is not nil.
+func (dru *DeviceRequestUpdate) SetNillablePollInterval(i *int) *DeviceRequestUpdate {
+	if i != nil {
+		dru.SetPollInterval(*i)
+	}
+	return dru
+}
+
+// AddPollInterval adds i to the "poll_interval" field.
+func (dru *DeviceRequestUpdate) AddPollInterval(i int) *DeviceRequestUpdate {
+	dru.mutation.AddPollInterval(i)
+	return dru
+}
+
+// SetConsumed sets the "consumed" field.
+func (dru *DeviceRequestUpdate) SetConsumed(b bool) *DeviceRequestUpdate {
+	dru.mutation.SetConsumed(b)
+	return dru
+}
+
+// SetNillableConsumed sets the "consumed" field if the given value is not nil.
+func (dru *DeviceRequestUpdate) SetNillableConsumed(b *bool) *DeviceRequestUpdate {
+	if b != nil {
+		dru.SetConsumed(*b)
+	}
+	return dru
+}
+
 // Mutation returns the DeviceRequestMutation object of the builder.
 func (dru *DeviceRequestUpdate) Mutation() *DeviceRequestMutation {
 	return dru.mutation
@@ -212,6 +267,21 @@ func (dru *DeviceRequestUpdate) sqlSave(ctx context.Context) (n int, err error)
 	if value, ok := dru.mutation.Expiry(); ok {
 		_spec.SetField(devicerequest.FieldExpiry, field.TypeTime, value)
 	}
+	if value, ok := dru.mutation.CreatedAt(); ok {
+		_spec.SetField(devicerequest.FieldCreatedAt, field.TypeTime, value)
+	}
+	if dru.mutation.CreatedAtCleared() {
+		_spec.ClearField(devicerequest.FieldCreatedAt, field.TypeTime)
+	}
+	if value, ok := dru.mutation.PollInterval(); ok {
+		_spec.SetField(devicerequest.FieldPollInterval, field.TypeInt, value)
+	}
+	if value, ok := dru.mutation.AddedPollInterval(); ok {
+		_spec.AddField(devicerequest.FieldPollInterval, field.TypeInt, value)
+	}
+	if value, ok := dru.mutation.Consumed(); ok {
+		_spec.SetField(devicerequest.FieldConsumed, field.TypeBool, value)
+	}
 	if n, err = sqlgraph.UpdateNodes(ctx, dru.driver, _spec); err != nil {
 		if _, ok := err.(*sqlgraph.NotFoundError); ok {
 			err = &NotFoundError{devicerequest.Label}
@@ -320,6 +390,61 @@ func (druo *DeviceRequestUpdateOne) SetNillableExpiry(t *time.Time) *DeviceReque
 	return druo
 }
 
+// SetCreatedAt sets the "created_at" field.
+func (druo *DeviceRequestUpdateOne) SetCreatedAt(t time.Time) *DeviceRequestUpdateOne {
+	druo.mutation.SetCreatedAt(t)
+	return druo
+}
+
+// SetNillableCreatedAt sets the "created_at" field if the given value is not nil.
+func (druo *DeviceRequestUpdateOne) SetNillableCreatedAt(t *time.Time) *DeviceRequestUpdateOne {
+	if t != nil {
+		druo.SetCreatedAt(*t)
+	}
+	return druo
+}
+
+// ClearCreatedAt clears the value of the "created_at" field.
+func (druo *DeviceRequestUpdateOne) ClearCreatedAt() *DeviceRequestUpdateOne {
+	druo.mutation.ClearCreatedAt()
+	return druo
+}
+
+// SetPollInterval sets the "poll_interval" field.
+func (druo *DeviceRequestUpdateOne) SetPollInterval(i int) *DeviceRequestUpdateOne {
+	druo.mutation.ResetPollInterval()
+	druo.mutation.SetPollInterval(i)
+	return druo
+}
+
+// SetNillablePollInterval sets the "poll_interval" field if the given value is not nil.
+func (druo *DeviceRequestUpdateOne) SetNillablePollInterval(i *int) *DeviceRequestUpdateOne {
+	if i != nil {
+		druo.SetPollInterval(*i)
+	}
+	return druo
+}
+
+// AddPollInterval adds i to the "poll_interval" field.
+func (druo *DeviceRequestUpdateOne) AddPollInterval(i int) *DeviceRequestUpdateOne {
+	druo.mutation.AddPollInterval(i)
+	return druo
+}
+
+// SetConsumed sets the "consumed" field.
+func (druo *DeviceRequestUpdateOne) SetConsumed(b bool) *DeviceRequestUpdateOne {
+	druo.mutation.SetConsumed(b)
+	return druo
+}
+
+// SetNillableConsumed sets the "consumed" field if the given value is not nil.
+func (druo *DeviceRequestUpdateOne) SetNillableConsumed(b *bool) *DeviceRequestUpdateOne {
+	if b != nil {
+		druo.SetConsumed(*b)
+	}
+	return druo
+}
+
 // Mutation returns the DeviceRequestMutation object of the builder.
 func (druo *DeviceRequestUpdateOne) Mutation() *DeviceRequestMutation {
 	return druo.mutation
@@ -445,6 +570,21 @@ func (druo *DeviceRequestUpdateOne) sqlSave(ctx context.Context) (_node *DeviceR
 	if value, ok := druo.mutation.Expiry(); ok {
 		_spec.SetField(devicerequest.FieldExpiry, field.TypeTime, value)
 	}
+	if value, ok := druo.mutation.CreatedAt(); ok {
+		_spec.SetField(devicerequest.FieldCreatedAt, field.TypeTime, value)
+	}
+	if druo.mutation.CreatedAtCleared() {
+		_spec.ClearField(devicerequest.FieldCreatedAt, field.TypeTime)
+	}
+	if value, ok := druo.mutation.PollInterval(); ok {
+		_spec.SetField(devicerequest.FieldPollInterval, field.TypeInt, value)
+	}
+	if value, ok := druo.mutation.AddedPollInterval(); ok {
+		_spec.AddField(devicerequest.FieldPollInterval, field.TypeInt, value)
+	}
+	if value, ok := druo.mutation.Consumed(); ok {
+		_spec.SetField(devicerequest.FieldConsumed, field.TypeBool, value)
+	}
 	_node = &DeviceRequest{config: druo.config}
 	_spec.Assign = _node.assignValues
 	_spec.ScanValues = _node.scanValues
@@ -17,6 +17,7 @@ import (
 	"github.com/dexidp/dex/storage/ent/db/connector"
 	"github.com/dexidp/dex/storage/ent/db/devicerequest"
 	"github.com/dexidp/dex/storage/ent/db/devicetoken"
+	"github.com/dexidp/dex/storage/ent/db/idempotencykey"
 	"github.com/dexidp/dex/storage/ent/db/keys"
 	"github.com/dexidp/dex/storage/ent/db/oauth2client"
 	"github.com/dexidp/dex/storage/ent/db/offlinesession"
@@ -87,6 +88,7 @@ func checkColumn(table, column string) error {
 			connector.Table:      connector.ValidColumn,
 			devicerequest.Table:  devicerequest.ValidColumn,
 			devicetoken.Table:    devicetoken.ValidColumn,
+			idempotencykey.Table: idempotencykey.ValidColumn,
 			keys.Table:           keys.ValidColumn,
 			oauth2client.Table:   oauth2client.ValidColumn,
 			offlinesession.Table: offlinesession.ValidColumn,
@@ -17,6 +17,7 @@ import (
 	"github.com/dexidp/dex/storage/ent/db/connector"
 	"github.com/dexidp/dex/storage/ent/db/devicerequest"
 	"github.com/dexidp/dex/storage/ent/db/devicetoken"
+	"github.com/dexidp/dex/storage/ent/db/idempotencykey"
 	"github.com/dexidp/dex/storage/ent/db/keys"
 	"github.com/dexidp/dex/storage/ent/db/oauth2client"
 	"github.com/dexidp/dex/storage/ent/db/offlinesession"
@@ -40,6 +41,7 @@ const (
 	TypeConnector      = "Connector"
 	TypeDeviceRequest  = "DeviceRequest"
 	TypeDeviceToken    = "DeviceToken"
+	TypeIdempotencyKey = "IdempotencyKey"
 	TypeKeys           = "Keys"
 	TypeOAuth2Client   = "OAuth2Client"
 	TypeOfflineSession = "OfflineSession"
@@ -64,6 +66,7 @@ type AuthCodeMutation struct {
 	claims_email_verified     *bool
 	claims_groups             *[]string
 	appendclaims_groups       []string
+	claims_extra              *map[string]interface{}
 	claims_preferred_username *string
 	connector_id              *string
 	connector_data            *[]byte
@@ -562,6 +565,55 @@ func (m *AuthCodeMutation) ResetClaimsGroups() {
 	delete(m.clearedFields, authcode.FieldClaimsGroups)
 }
 
+// SetClaimsExtra sets the "claims_extra" field.
+func (m *AuthCodeMutation) SetClaimsExtra(value map[string]interface{}) {
+	m.claims_extra = &value
+}
+
+// ClaimsExtra returns the value of the "claims_extra" field in the mutation.
+func (m *AuthCodeMutation) ClaimsExtra() (r map[string]interface{}, exists bool) {
+	v := m.claims_extra
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldClaimsExtra returns the old "claims_extra" field's value of the AuthCode entity.
+// If the AuthCode object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *AuthCodeMutation) OldClaimsExtra(ctx context.Context) (v map[string]interface{}, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldClaimsExtra is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldClaimsExtra requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldClaimsExtra: %w", err)
+	}
+	return oldValue.ClaimsExtra, nil
+}
+
+// ClearClaimsExtra clears the value of the "claims_extra" field.
+func (m *AuthCodeMutation) ClearClaimsExtra() {
+	m.claims_extra = nil
+	m.clearedFields[authcode.FieldClaimsExtra] = struct{}{}
+}
+
+// ClaimsExtraCleared returns if the "claims_extra" field was cleared in this mutation.
+func (m *AuthCodeMutation) ClaimsExtraCleared() bool {
+	_, ok := m.clearedFields[authcode.FieldClaimsExtra]
+	return ok
+}
+
+// ResetClaimsExtra resets all changes to the "claims_extra" field.
+func (m *AuthCodeMutation) ResetClaimsExtra() {
+	m.claims_extra = nil
+	delete(m.clearedFields, authcode.FieldClaimsExtra)
+}
+
 // SetClaimsPreferredUsername sets the "claims_preferred_username" field.
 func (m *AuthCodeMutation) SetClaimsPreferredUsername(s string) {
 	m.claims_preferred_username = &s
@@ -825,7 +877,7 @@ func (m *AuthCodeMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *AuthCodeMutation) Fields() []string {
-	fields := make([]string, 0, 15)
+	fields := make([]string, 0, 16)
 	if m.client_id != nil {
 		fields = append(fields, authcode.FieldClientID)
 	}
@@ -853,6 +905,9 @@ func (m *AuthCodeMutation) Fields() []string {
 	if m.claims_groups != nil {
 		fields = append(fields, authcode.FieldClaimsGroups)
 	}
+	if m.claims_extra != nil {
+		fields = append(fields, authcode.FieldClaimsExtra)
+	}
 	if m.claims_preferred_username != nil {
 		fields = append(fields, authcode.FieldClaimsPreferredUsername)
 	}
@@ -897,6 +952,8 @@ func (m *AuthCodeMutation) Field(name string) (ent.Value, bool) {
 		return m.ClaimsEmailVerified()
 	case authcode.FieldClaimsGroups:
 		return m.ClaimsGroups()
+	case authcode.FieldClaimsExtra:
+		return m.ClaimsExtra()
 	case authcode.FieldClaimsPreferredUsername:
 		return m.ClaimsPreferredUsername()
 	case authcode.FieldConnectorID:
@@ -936,6 +993,8 @@ func (m *AuthCodeMutation) OldField(ctx context.Context, name string) (ent.Value
 		return m.OldClaimsEmailVerified(ctx)
 	case authcode.FieldClaimsGroups:
 		return m.OldClaimsGroups(ctx)
+	case authcode.FieldClaimsExtra:
+		return m.OldClaimsExtra(ctx)
 	case authcode.FieldClaimsPreferredUsername:
 		return m.OldClaimsPreferredUsername(ctx)
 	case authcode.FieldConnectorID:
@@ -1020,6 +1079,13 @@ func (m *AuthCodeMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetClaimsGroups(v)
 		return nil
+	case authcode.FieldClaimsExtra:
+		v, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetClaimsExtra(v)
+		return nil
 	case authcode.FieldClaimsPreferredUsername:
 		v, ok := value.(string)
 		if !ok {
@@ -1098,6 +1164,9 @@ func (m *AuthCodeMutation) ClearedFields() []string {
 	if m.FieldCleared(authcode.FieldClaimsGroups) {
 		fields = append(fields, authcode.FieldClaimsGroups)
 	}
+	if m.FieldCleared(authcode.FieldClaimsExtra) {
+		fields = append(fields, authcode.FieldClaimsExtra)
+	}
 	if m.FieldCleared(authcode.FieldConnectorData) {
 		fields = append(fields, authcode.FieldConnectorData)
 	}
@@ -1121,6 +1190,9 @@ func (m *AuthCodeMutation) ClearField(name string) error {
 	case authcode.FieldClaimsGroups:
 		m.ClearClaimsGroups()
 		return nil
+	case authcode.FieldClaimsExtra:
+		m.ClearClaimsExtra()
+		return nil
 	case authcode.FieldConnectorData:
 		m.ClearConnectorData()
 		return nil
@@ -1159,6 +1231,9 @@ func (m *AuthCodeMutation) ResetField(name string) error {
 	case authcode.FieldClaimsGroups:
 		m.ResetClaimsGroups()
 		return nil
+	case authcode.FieldClaimsExtra:
+		m.ResetClaimsExtra()
+		return nil
 	case authcode.FieldClaimsPreferredUsername:
 		m.ResetClaimsPreferredUsername()
 		return nil
@@ -1251,6 +1326,7 @@ type AuthRequestMutation struct {
 	claims_email_verified     *bool
 	claims_groups             *[]string
 	appendclaims_groups       []string
+	claims_extra              *map[string]interface{}
 	claims_preferred_username *string
 	connector_id              *string
 	connector_data            *[]byte
@@ -1923,6 +1999,55 @@ func (m *AuthRequestMutation) ResetClaimsGroups() {
 	delete(m.clearedFields, authrequest.FieldClaimsGroups)
 }
 
+// SetClaimsExtra sets the "claims_extra" field.
+func (m *AuthRequestMutation) SetClaimsExtra(value map[string]interface{}) {
+	m.claims_extra = &value
+}
+
+// ClaimsExtra returns the value of the "claims_extra" field in the mutation.
+func (m *AuthRequestMutation) ClaimsExtra() (r map[string]interface{}, exists bool) {
+	v := m.claims_extra
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldClaimsExtra returns the old "claims_extra" field's value of the AuthRequest entity.
+// If the AuthRequest object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *AuthRequestMutation) OldClaimsExtra(ctx context.Context) (v map[string]interface{}, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldClaimsExtra is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldClaimsExtra requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldClaimsExtra: %w", err)
+	}
+	return oldValue.ClaimsExtra, nil
+}
+
+// ClearClaimsExtra clears the value of the "claims_extra" field.
+func (m *AuthRequestMutation) ClearClaimsExtra() {
+	m.claims_extra = nil
+	m.clearedFields[authrequest.FieldClaimsExtra] = struct{}{}
+}
+
+// ClaimsExtraCleared returns if the "claims_extra" field was cleared in this mutation.
+func (m *AuthRequestMutation) ClaimsExtraCleared() bool {
+	_, ok := m.clearedFields[authrequest.FieldClaimsExtra]
+	return ok
+}
+
+// ResetClaimsExtra resets all changes to the "claims_extra" field.
+func (m *AuthRequestMutation) ResetClaimsExtra() {
+	m.claims_extra = nil
+	delete(m.clearedFields, authrequest.FieldClaimsExtra)
+}
+
 // SetClaimsPreferredUsername sets the "claims_preferred_username" field.
 func (m *AuthRequestMutation) SetClaimsPreferredUsername(s string) {
 	m.claims_preferred_username = &s
@@ -2222,7 +2347,7 @@ func (m *AuthRequestMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *AuthRequestMutation) Fields() []string {
-	fields := make([]string, 0, 20)
+	fields := make([]string, 0, 21)
 	if m.client_id != nil {
 		fields = append(fields, authrequest.FieldClientID)
 	}
@@ -2262,6 +2387,9 @@ func (m *AuthRequestMutation) Fields() []string {
 	if m.claims_groups != nil {
 		fields = append(fields, authrequest.FieldClaimsGroups)
 	}
+	if m.claims_extra != nil {
+		fields = append(fields, authrequest.FieldClaimsExtra)
+	}
 	if m.claims_preferred_username != nil {
 		fields = append(fields, authrequest.FieldClaimsPreferredUsername)
 	}
@@ -2317,6 +2445,8 @@ func (m *AuthRequestMutation) Field(name string) (ent.Value, bool) {
 		return m.ClaimsEmailVerified()
 	case authrequest.FieldClaimsGroups:
 		return m.ClaimsGroups()
+	case authrequest.FieldClaimsExtra:
+		return m.ClaimsExtra()
 	case authrequest.FieldClaimsPreferredUsername:
 		return m.ClaimsPreferredUsername()
 	case authrequest.FieldConnectorID:
@@ -2366,6 +2496,8 @@ func (m *AuthRequestMutation) OldField(ctx context.Context, name string) (ent.Va
 		return m.OldClaimsEmailVerified(ctx)
 	case authrequest.FieldClaimsGroups:
 		return m.OldClaimsGroups(ctx)
+	case authrequest.FieldClaimsExtra:
+		return m.OldClaimsExtra(ctx)
 	case authrequest.FieldClaimsPreferredUsername:
 		return m.OldClaimsPreferredUsername(ctx)
 	case authrequest.FieldConnectorID:
@@ -2480,6 +2612,13 @@ func (m *AuthRequestMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetClaimsGroups(v)
 		return nil
+	case authrequest.FieldClaimsExtra:
+		v, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetClaimsExtra(v)
+		return nil
 	case authrequest.FieldClaimsPreferredUsername:
 		v, ok := value.(string)
 		if !ok {
@@ -2568,6 +2707,9 @@ func (m *AuthRequestMutation) ClearedFields() []string {
 	if m.FieldCleared(authrequest.FieldClaimsGroups) {
 		fields = append(fields, authrequest.FieldClaimsGroups)
 	}
+	if m.FieldCleared(authrequest.FieldClaimsExtra) {
+		fields = append(fields, authrequest.FieldClaimsExtra)
+	}
 	if m.FieldCleared(authrequest.FieldConnectorData) {
 		fields = append(fields, authrequest.FieldConnectorData)
 	}
@@ -2594,6 +2736,9 @@ func (m *AuthRequestMutation) ClearField(name string) error {
 	case authrequest.FieldClaimsGroups:
 		m.ClearClaimsGroups()
 		return nil
+	case authrequest.FieldClaimsExtra:
+		m.ClearClaimsExtra()
+		return nil
 	case authrequest.FieldConnectorData:
 		m.ClearConnectorData()
 		return nil
@@ -2644,6 +2789,9 @@ func (m *AuthRequestMutation) ResetField(name string) error {
 	case authrequest.FieldClaimsGroups:
 		m.ResetClaimsGroups()
 		return nil
+	case authrequest.FieldClaimsExtra:
+		m.ResetClaimsExtra()
+		return nil
 	case authrequest.FieldClaimsPreferredUsername:
 		m.ResetClaimsPreferredUsername()
 		return nil
@@ -3214,20 +3362,24 @@ func (m *ConnectorMutation) ResetEdge(name string) error {
 // DeviceRequestMutation represents an operation that mutates the DeviceRequest nodes in the graph.
 type DeviceRequestMutation struct {
 	config
-	op            Op
-	typ           string
-	id            *int
-	user_code     *string
-	device_code   *string
-	client_id     *string
-	client_secret *string
-	scopes        *[]string
-	appendscopes  []string
-	expiry        *time.Time
-	clearedFields map[string]struct{}
-	done          bool
-	oldValue      func(context.Context) (*DeviceRequest, error)
-	predicates    []predicate.DeviceRequest
+	op               Op
+	typ              string
+	id               *int
+	user_code        *string
+	device_code      *string
+	client_id        *string
+	client_secret    *string
+	scopes           *[]string
+	appendscopes     []string
+	expiry           *time.Time
+	created_at       *time.Time
+	poll_interval    *int
+	addpoll_interval *int
+	consumed         *bool
+	clearedFields    map[string]struct{}
+	done             bool
+	oldValue         func(context.Context) (*DeviceRequest, error)
+	predicates       []predicate.DeviceRequest
 }
 
 var _ ent.Mutation = (*DeviceRequestMutation)(nil)
@@ -3573,6 +3725,147 @@ func (m *DeviceRequestMutation) ResetExpiry() {
 	m.expiry = nil
 }
 
+// SetCreatedAt sets the "created_at" field.
+func (m *DeviceRequestMutation) SetCreatedAt(t time.Time) {
+	m.created_at = &t
+}
+
+// CreatedAt returns the value of the "created_at" field in the mutation.
+func (m *DeviceRequestMutation) CreatedAt() (r time.Time, exists bool) {
+	v := m.created_at
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldCreatedAt returns the old "created_at" field's value of the DeviceRequest entity.
+// If the DeviceRequest object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *DeviceRequestMutation) OldCreatedAt(ctx context.Context) (v time.Time, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldCreatedAt is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldCreatedAt requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldCreatedAt: %w", err)
+	}
+	return oldValue.CreatedAt, nil
+}
+
+// ClearCreatedAt clears the value of the "created_at" field.
+func (m *DeviceRequestMutation) ClearCreatedAt() {
+	m.created_at = nil
+	m.clearedFields[devicerequest.FieldCreatedAt] = struct{}{}
+}
+
+// CreatedAtCleared returns if the "created_at" field was cleared in this mutation.
+func (m *DeviceRequestMutation) CreatedAtCleared() bool {
+	_, ok := m.clearedFields[devicerequest.FieldCreatedAt]
+	return ok
+}
+
+// ResetCreatedAt resets all changes to the "created_at" field.
+func (m *DeviceRequestMutation) ResetCreatedAt() {
+	m.created_at = nil
+	delete(m.clearedFields, devicerequest.FieldCreatedAt)
+}
+
+// SetPollInterval sets the "poll_interval" field.
+func (m *DeviceRequestMutation) SetPollInterval(i int) {
+	m.poll_interval = &i
+	m.addpoll_interval = nil
+}
+
+// PollInterval returns the value of the "poll_interval" field in the mutation.
+func (m *DeviceRequestMutation) PollInterval() (r int, exists bool) {
+	v := m.poll_interval
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldPollInterval returns the old "poll_interval" field's value of the DeviceRequest entity.
+// If the DeviceRequest object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *DeviceRequestMutation) OldPollInterval(ctx context.Context) (v int, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldPollInterval is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldPollInterval requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldPollInterval: %w", err)
+	}
+	return oldValue.PollInterval, nil
+}
+
+// AddPollInterval adds i to the "poll_interval" field.
+func (m *DeviceRequestMutation) AddPollInterval(i int) {
+	if m.addpoll_interval != nil {
+		*m.addpoll_interval += i
+	} else {
+		m.addpoll_interval = &i
+	}
+}
+
+// AddedPollInterval returns the value that was added to the "poll_interval" field in this mutation.
+func (m *DeviceRequestMutation) AddedPollInterval() (r int, exists bool) {
+	v := m.addpoll_interval
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetPollInterval resets all changes to the "poll_interval" field.
+func (m *DeviceRequestMutation) ResetPollInterval() {
+	m.poll_interval = nil
+	m.addpoll_interval = nil
+}
+
+// SetConsumed sets the "consumed" field.
+func (m *DeviceRequestMutation) SetConsumed(b bool) {
+	m.consumed = &b
+}
+
+// Consumed returns the value of the "consumed" field in the mutation.
+func (m *DeviceRequestMutation) Consumed() (r bool, exists bool) {
+	v := m.consumed
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldConsumed returns the old "consumed" field's value of the DeviceRequest entity.
+// If the DeviceRequest object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *DeviceRequestMutation) OldConsumed(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldConsumed is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldConsumed requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldConsumed: %w", err)
+	}
+	return oldValue.Consumed, nil
+}
+
+// ResetConsumed resets all changes to the "consumed" field.
+func (m *DeviceRequestMutation) ResetConsumed() {
+	m.consumed = nil
+}
+
 // Where appends a list predicates to the DeviceRequestMutation builder.
 func (m *DeviceRequestMutation) Where(ps ...predicate.DeviceRequest) {
 	m.predicates = append(m.predicates, ps...)
@@ -3607,7 +3900,7 @@ func (m *DeviceRequestMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *DeviceRequestMutation) Fields() []string {
-	fields := make([]string, 0, 6)
+	fields := make([]string, 0, 9)
 	if m.user_code != nil {
 		fields = append(fields, devicerequest.FieldUserCode)
 	}
@@ -3626,6 +3919,15 @@ func (m *DeviceRequestMutation) Fields() []string {
 	if m.expiry != nil {
 		fields = append(fields, devicerequest.FieldExpiry)
 	}
+	if m.created_at != nil {
+		fields = append(fields, devicerequest.FieldCreatedAt)
+	}
+	if m.poll_interval != nil {
+		fields = append(fields, devicerequest.FieldPollInterval)
+	}
+	if m.consumed != nil {
+		fields = append(fields, devicerequest.FieldConsumed)
+	}
 	return fields
 }
 
@@ -3646,6 +3948,12 @@ func (m *DeviceRequestMutation) Field(name string) (ent.Value, bool) {
 		return m.Scopes()
 	case devicerequest.FieldExpiry:
 		return m.Expiry()
+	case devicerequest.FieldCreatedAt:
+		return m.CreatedAt()
+	case devicerequest.FieldPollInterval:
+		return m.PollInterval()
+	case devicerequest.FieldConsumed:
+		return m.Consumed()
 	}
 	return nil, false
 }
@@ -3667,6 +3975,12 @@ func (m *DeviceRequestMutation) OldField(ctx context.Context, name string) (ent.
 		return m.OldScopes(ctx)
 	case devicerequest.FieldExpiry:
 		return m.OldExpiry(ctx)
+	case devicerequest.FieldCreatedAt:
+		return m.OldCreatedAt(ctx)
+	case devicerequest.FieldPollInterval:
+		return m.OldPollInterval(ctx)
+	case devicerequest.FieldConsumed:
+		return m.OldConsumed(ctx)
 	}
 	return nil, fmt.Errorf("unknown DeviceRequest field %s", name)
 }
@@ -3718,6 +4032,27 @@ func (m *DeviceRequestMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetExpiry(v)
 		return nil
+	case devicerequest.FieldCreatedAt:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetCreatedAt(v)
+		return nil
+	case devicerequest.FieldPollInterval:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetPollInterval(v)
+		return nil
+	case devicerequest.FieldConsumed:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetConsumed(v)
+		return nil
 	}
 	return fmt.Errorf("unknown DeviceRequest field %s", name)
 }
@@ -3725,13 +4060,21 @@ func (m *DeviceRequestMutation) SetField(name string, value ent.Value) error {
 // AddedFields returns all numeric fields that were incremented/decremented during
 // this mutation.
 func (m *DeviceRequestMutation) AddedFields() []string {
-	return nil
+	var fields []string
+	if m.addpoll_interval != nil {
+		fields = append(fields, devicerequest.FieldPollInterval)
+	}
+	return fields
 }
 
 // AddedField returns the numeric value that was incremented/decremented on a field
 // with the given name. The second boolean return value indicates that this field
 // was not set, or was not defined in the schema.
 func (m *DeviceRequestMutation) AddedField(name string) (ent.Value, bool) {
+	switch name {
+	case devicerequest.FieldPollInterval:
+		return m.AddedPollInterval()
+	}
 	return nil, false
 }
 
@@ -3740,6 +4083,13 @@ func (m *DeviceRequestMutation) AddedField(name string) (ent.Value, bool) {
 // type.
 func (m *DeviceRequestMutation) AddField(name string, value ent.Value) error {
 	switch name {
+	case devicerequest.FieldPollInterval:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddPollInterval(v)
+		return nil
 	}
 	return fmt.Errorf("unknown DeviceRequest numeric field %s", name)
 }
@@ -3751,6 +4101,9 @@ func (m *DeviceRequestMutation) ClearedFields() []string {
 	if m.FieldCleared(devicerequest.FieldScopes) {
 		fields = append(fields, devicerequest.FieldScopes)
 	}
+	if m.FieldCleared(devicerequest.FieldCreatedAt) {
+		fields = append(fields, devicerequest.FieldCreatedAt)
+	}
 	return fields
 }
 
@@ -3768,6 +4121,9 @@ func (m *DeviceRequestMutation) ClearField(name string) error {
 	case devicerequest.FieldScopes:
 		m.ClearScopes()
 		return nil
+	case devicerequest.FieldCreatedAt:
+		m.ClearCreatedAt()
+		return nil
 	}
 	return fmt.Errorf("unknown DeviceRequest nullable field %s", name)
 }
@@ -3794,6 +4150,15 @@ func (m *DeviceRequestMutation) ResetField(name string) error {
 	case devicerequest.FieldExpiry:
 		m.ResetExpiry()
 		return nil
+	case devicerequest.FieldCreatedAt:
+		m.ResetCreatedAt()
+		return nil
+	case devicerequest.FieldPollInterval:
+		m.ResetPollInterval()
+		return nil
+	case devicerequest.FieldConsumed:
+		m.ResetConsumed()
+		return nil
 	}
 	return fmt.Errorf("unknown DeviceRequest field %s", name)
 }
@@ -4608,30 +4973,492 @@ func (m *DeviceTokenMutation) ResetEdge(name string) error {
 	return fmt.Errorf("unknown DeviceToken edge %s", name)
 }
 
-// KeysMutation represents an operation that mutates the Keys nodes in the graph.
-type KeysMutation struct {
+// IdempotencyKeyMutation represents an operation that mutates the IdempotencyKey nodes in the graph.
+type IdempotencyKeyMutation struct {
 	config
-	op                      Op
-	typ                     string
-	id                      *string
-	verification_keys       *[]storage.VerificationKey
-	appendverification_keys []storage.VerificationKey
-	signing_key             *jose.JSONWebKey
-	signing_key_pub         *jose.JSONWebKey
-	next_rotation           *time.Time
-	clearedFields           map[string]struct{}
-	done                    bool
-	oldValue                func(context.Context) (*Keys, error)
-	predicates              []predicate.Keys
+	op            Op
+	typ           string
+	id            *string
+	response      *[]byte
+	expiry        *time.Time
+	request_hash  *[]byte
+	clearedFields map[string]struct{}
+	done          bool
+	oldValue      func(context.Context) (*IdempotencyKey, error)
+	predicates    []predicate.IdempotencyKey
 }
 
-var _ ent.Mutation = (*KeysMutation)(nil)
+var _ ent.Mutation = (*IdempotencyKeyMutation)(nil)
 
-// keysOption allows management of the mutation configuration using functional options.
-type keysOption func(*KeysMutation)
+// idempotencykeyOption allows management of the mutation configuration using functional options.
+type idempotencykeyOption func(*IdempotencyKeyMutation)
 
-// newKeysMutation creates new mutation for the Keys entity.
-func newKeysMutation(c config, op Op, opts ...keysOption) *KeysMutation {
+// newIdempotencyKeyMutation creates new mutation for the IdempotencyKey entity.
+func newIdempotencyKeyMutation(c config, op Op, opts ...idempotencykeyOption) *IdempotencyKeyMutation {
+	m := &IdempotencyKeyMutation{
+		config:        c,
+		op:            op,
+		typ:           TypeIdempotencyKey,
+		clearedFields: make(map[string]struct{}),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// withIdempotencyKeyID sets the ID field of the mutation.
+func withIdempotencyKeyID(id string) idempotencykeyOption {
+	return func(m *IdempotencyKeyMutation) {
+		var (
+			err   error
+			once  sync.Once
+			value *IdempotencyKey
+		)
+		m.oldValue = func(ctx context.Context) (*IdempotencyKey, error) {
+			once.Do(func() {
+				if m.done {
+					err = errors.New("querying old values post mutation is not allowed")
+				} else {
+					value, err = m.Client().IdempotencyKey.Get(ctx, id)
+				}
+			})
+			return value, err
+		}
+		m.id = &id
+	}
+}
+
+// withIdempotencyKey sets the old IdempotencyKey of the mutation.
+func withIdempotencyKey(node *IdempotencyKey) idempotencykeyOption {
+	return func(m *IdempotencyKeyMutation) {
+		m.oldValue = func(context.Context) (*IdempotencyKey, error) {
+			return node, nil
+		}
+		m.id = &node.ID
+	}
+}
+
+// Client returns a new `ent.Client` from the mutation. If the mutation was
+// executed in a transaction (ent.Tx), a transactional client is returned.
+func (m IdempotencyKeyMutation) Client() *Client {
+	client := &Client{config: m.config}
+	client.init()
+	return client
+}
+
+// Tx returns an `ent.Tx` for mutations that were executed in transactions;
+// it returns an error otherwise.
+func (m IdempotencyKeyMutation) Tx() (*Tx, error) {
+	if _, ok := m.driver.(*txDriver); !ok {
+		return nil, errors.New("db: mutation is not running in a transaction")
+	}
+	tx := &Tx{config: m.config}
+	tx.init()
+	return tx, nil
+}
+
+// SetID sets the value of the id field. Note that this
+// operation is only accepted on creation of IdempotencyKey entities.
+func (m *IdempotencyKeyMutation) SetID(id string) {
+	m.id = &id
+}
+
+// ID returns the ID value in the mutation. Note that the ID is only available
+// if it was provided to the builder or after it was returned from the database.
+func (m *IdempotencyKeyMutation) ID() (id string, exists bool) {
+	if m.id == nil {
+		return
+	}
+	return *m.id, true
+}
+
+// IDs queries the database and returns the entity ids that match the mutation's predicate.
+// That means, if the mutation is applied within a transaction with an isolation level such
+// as sql.LevelSerializable, the returned ids match the ids of the rows that will be updated
+// or updated by the mutation.
+func (m *IdempotencyKeyMutation) IDs(ctx context.Context) ([]string, error) {
+	switch {
+	case m.op.Is(OpUpdateOne | OpDeleteOne):
+		id, exists := m.ID()
+		if exists {
+			return []string{id}, nil
+		}
+		fallthrough
+	case m.op.Is(OpUpdate | OpDelete):
+		return m.Client().IdempotencyKey.Query().Where(m.predicates...).IDs(ctx)
+	default:
+		return nil, fmt.Errorf("IDs is not allowed on %s operations", m.op)
+	}
+}
+
+// SetResponse sets the "response" field.
+func (m *IdempotencyKeyMutation) SetResponse(b []byte) {
+	m.response = &b
+}
+
+// Response returns the value of the "response" field in the mutation.
+func (m *IdempotencyKeyMutation) Response() (r []byte, exists bool) {
+	v := m.response
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldResponse returns the old "response" field's value of the IdempotencyKey entity.
+// If the IdempotencyKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *IdempotencyKeyMutation) OldResponse(ctx context.Context) (v []byte, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldResponse is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldResponse requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldResponse: %w", err)
+	}
+	return oldValue.Response, nil
+}
+
+// ResetResponse resets all changes to the "response" field.
+func (m *IdempotencyKeyMutation) ResetResponse() {
+	m.response = nil
+}
+
+// SetExpiry sets the "expiry" field.
+func (m *IdempotencyKeyMutation) SetExpiry(t time.Time) {
+	m.expiry = &t
+}
+
+// Expiry returns the value of the "expiry" field in the mutation.
+func (m *IdempotencyKeyMutation) Expiry() (r time.Time, exists bool) {
+	v := m.expiry
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldExpiry returns the old "expiry" field's value of the IdempotencyKey entity.
+// If the IdempotencyKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *IdempotencyKeyMutation) OldExpiry(ctx context.Context) (v time.Time, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldExpiry is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldExpiry requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldExpiry: %w", err)
+	}
+	return oldValue.Expiry, nil
+}
+
+// ResetExpiry resets all changes to the "expiry" field.
+func (m *IdempotencyKeyMutation) ResetExpiry() {
+	m.expiry = nil
+}
+
+// SetRequestHash sets the "request_hash" field.
+func (m *IdempotencyKeyMutation) SetRequestHash(b []byte) {
+	m.request_hash = &b
+}
+
+// RequestHash returns the value of the "request_hash" field in the mutation.
+func (m *IdempotencyKeyMutation) RequestHash() (r []byte, exists bool) {
+	v := m.request_hash
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldRequestHash returns the old "request_hash" field's value of the IdempotencyKey entity.
+// If the IdempotencyKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *IdempotencyKeyMutation) OldRequestHash(ctx context.Context) (v []byte, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldRequestHash is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldRequestHash requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldRequestHash: %w", err)
+	}
+	return oldValue.RequestHash, nil
+}
+
+// ClearRequestHash clears the value of the "request_hash" field.
+func (m *IdempotencyKeyMutation) ClearRequestHash() {
+	m.request_hash = nil
+	m.clearedFields[idempotencykey.FieldRequestHash] = struct{}{}
+}
+
+// RequestHashCleared returns if the "request_hash" field was cleared in this mutation.
+func (m *IdempotencyKeyMutation) RequestHashCleared() bool {
+	_, ok := m.clearedFields[idempotencykey.FieldRequestHash]
+	return ok
+}
+
+// ResetRequestHash resets all changes to the "request_hash" field.
+func (m *IdempotencyKeyMutation) ResetRequestHash() {
+	m.request_hash = nil
+	delete(m.clearedFields, idempotencykey.FieldRequestHash)
+}
+
+// Where appends a list predicates to the IdempotencyKeyMutation builder.
+func (m *IdempotencyKeyMutation) Where(ps ...predicate.IdempotencyKey) {
+	m.predicates = append(m.predicates, ps...)
+}
+
+// WhereP appends storage-level predicates to the IdempotencyKeyMutation builder. Using this method,
+// users can use type-assertion to append predicates that do not depend on any generated package.
+func (m *IdempotencyKeyMutation) WhereP(ps ...func(*sql.Selector)) {
+	p := make([]predicate.IdempotencyKey, len(ps))
+	for i := range ps {
+		p[i] = ps[i]
+	}
+	m.Where(p...)
+}
+
+// Op returns the operation name.
+func (m *IdempotencyKeyMutation) Op() Op {
+	return m.op
+}
+
+// SetOp allows setting the mutation operation.
+func (m *IdempotencyKeyMutation) SetOp(op Op) {
+	m.op = op
+}
+
+// Type returns the node type of this mutation (IdempotencyKey).
+func (m *IdempotencyKeyMutation) Type() string {
+	return m.typ
+}
+
+// Fields returns all fields that were changed during this mutation. Note that in
+// order to get all numeric fields that were incremented/decremented, call
+// AddedFields().
+func (m *IdempotencyKeyMutation) Fields() []string {
+	fields := make([]string, 0, 3)
+	if m.response != nil {
+		fields = append(fields, idempotencykey.FieldResponse)
+	}
+	if m.expiry != nil {
+		fields = append(fields, idempotencykey.FieldExpiry)
+	}
+	if m.request_hash != nil {
+		fields = append(fields, idempotencykey.FieldRequestHash)
+	}
+	return fields
+}
+
+// Field returns the value of a field with the given name. The second boolean
+// return value indicates that this field was not set, or was not defined in the
+// schema.
+func (m *IdempotencyKeyMutation) Field(name string) (ent.Value, bool) {
+	switch name {
+	case idempotencykey.FieldResponse:
+		return m.Response()
+	case idempotencykey.FieldExpiry:
+		return m.Expiry()
+	case idempotencykey.FieldRequestHash:
+		return m.RequestHash()
+	}
+	return nil, false
+}
+
+// OldField returns the old value of the field from the database. An error is
+// returned if the mutation operation is not UpdateOne, or the query to the
+// database failed.
+func (m *IdempotencyKeyMutation) OldField(ctx context.Context, name string) (ent.Value, error) {
+	switch name {
+	case idempotencykey.FieldResponse:
+		return m.OldResponse(ctx)
+	case idempotencykey.FieldExpiry:
+		return m.OldExpiry(ctx)
+	case idempotencykey.FieldRequestHash:
+		return m.OldRequestHash(ctx)
+	}
+	return nil, fmt.Errorf("unknown IdempotencyKey field %s", name)
+}
+
+// SetField sets the value of a field with the given name. It returns an error if
+// the field is not defined in the schema, or if the type mismatched the field
+// type.
+func (m *IdempotencyKeyMutation) SetField(name string, value ent.Value) error {
+	switch name {
+	case idempotencykey.FieldResponse:
+		v, ok := value.([]byte)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetResponse(v)
+		return nil
+	case idempotencykey.FieldExpiry:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetExpiry(v)
+		return nil
+	case idempotencykey.FieldRequestHash:
+		v, ok := value.([]byte)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetRequestHash(v)
+		return nil
+	}
+	return fmt.Errorf("unknown IdempotencyKey field %s", name)
+}
+
+// AddedFields returns all numeric fields that were incremented/decremented during
+// this mutation.
+func (m *IdempotencyKeyMutation) AddedFields() []string {
+	return nil
+}
+
+// AddedField returns the numeric value that was incremented/decremented on a field
+// with the given name. The second boolean return value indicates that this field
+// was not set, or was not defined in the schema.
+func (m *IdempotencyKeyMutation) AddedField(name string) (ent.Value, bool) {
+	return nil, false
+}
+
+// AddField adds the value to the field with the given name. It returns an error if
+// the field is not defined in the schema, or if the type mismatched the field
+// type.
+func (m *IdempotencyKeyMutation) AddField(name string, value ent.Value) error {
+	switch name {
+	}
+	return fmt.Errorf("unknown IdempotencyKey numeric field %s", name)
+}
+
+// ClearedFields returns all nullable fields that were cleared during this
+// mutation.
+func (m *IdempotencyKeyMutation) ClearedFields() []string {
+	var fields []string
+	if m.FieldCleared(idempotencykey.FieldRequestHash) {
+		fields = append(fields, idempotencykey.FieldRequestHash)
+	}
+	return fields
+}
+
+// FieldCleared returns a boolean indicating if a field with the given name was
+// cleared in this mutation.
+func (m *IdempotencyKeyMutation) FieldCleared(name string) bool {
+	_, ok := m.clearedFields[name]
+	return ok
+}
+
+// ClearField clears the value of the field with the given name. It returns an
+// error if the field is not defined in the schema.
+func (m *IdempotencyKeyMutation) ClearField(name string) error {
+	switch name {
+	case idempotencykey.FieldRequestHash:
+		m.ClearRequestHash()
+		return nil
+	}
+	return fmt.Errorf("unknown IdempotencyKey nullable field %s", name)
+}
+
+// ResetField resets all changes in the mutation for the field with the given name.
+// It returns an error if the field is not defined in the schema.
+func (m *IdempotencyKeyMutation) ResetField(name string) error {
+	switch name {
+	case idempotencykey.FieldResponse:
+		m.ResetResponse()
+		return nil
+	case idempotencykey.FieldExpiry:
+		m.ResetExpiry()
+		return nil
+	case idempotencykey.FieldRequestHash:
+		m.ResetRequestHash()
+		return nil
+	}
+	return fmt.Errorf("unknown IdempotencyKey field %s", name)
+}
+
+// AddedEdges returns all edge names that were set/added in this mutation.
+func (m *IdempotencyKeyMutation) AddedEdges() []string {
+	edges := make([]string, 0, 0)
+	return edges
+}
+
+// AddedIDs returns all IDs (to other nodes) that were added for the given edge
+// name in this mutation.
+func (m *IdempotencyKeyMutation) AddedIDs(name string) []ent.Value {
+	return nil
+}
+
+// RemovedEdges returns all edge names that were removed in this mutation.
+func (m *IdempotencyKeyMutation) RemovedEdges() []string {
+	edges := make([]string, 0, 0)
+	return edges
+}
+
+// RemovedIDs returns all IDs (to other nodes) that were removed for the edge with
+// the given name in this mutation.
+func (m *IdempotencyKeyMutation) RemovedIDs(name string) []ent.Value {
+	return nil
+}
+
+// ClearedEdges returns all edge names that were cleared in this mutation.
+func (m *IdempotencyKeyMutation) ClearedEdges() []string {
+	edges := make([]string, 0, 0)
+	return edges
+}
+
+// EdgeCleared returns a boolean which indicates if the edge with the given name
+// was cleared in this mutation.
+func (m *IdempotencyKeyMutation) EdgeCleared(name string) bool {
+	return false
+}
+
+// ClearEdge clears the value of the edge with the given name. It returns an error
+// if that edge is not defined in the schema.
+func (m *IdempotencyKeyMutation) ClearEdge(name string) error {
+	return fmt.Errorf("unknown IdempotencyKey unique edge %s", name)
+}
+
+// ResetEdge resets all changes to the edge with the given name in this mutation.
+// It returns an error if the edge is not defined in the schema.
+func (m *IdempotencyKeyMutation) ResetEdge(name string) error {
+	return fmt.Errorf("unknown IdempotencyKey edge %s", name)
+}
+
+// KeysMutation represents an operation that mutates the Keys nodes in the graph.
+type KeysMutation struct {
+	config
+	op                      Op
+	typ                     string
+	id                      *string
+	verification_keys       *[]storage.VerificationKey
+	appendverification_keys []storage.VerificationKey
+	signing_key             *jose.JSONWebKey
+	signing_key_pub         *jose.JSONWebKey
+	next_rotation           *time.Time
+	clearedFields           map[string]struct{}
+	done                    bool
+	oldValue                func(context.Context) (*Keys, error)
+	predicates              []predicate.Keys
+}
+
+var _ ent.Mutation = (*KeysMutation)(nil)
+
+// keysOption allows management of the mutation configuration using functional options.
+type keysOption func(*KeysMutation)
+
+// newKeysMutation creates new mutation for the Keys entity.
+func newKeysMutation(c config, op Op, opts ...keysOption) *KeysMutation {
 	m := &KeysMutation{
 		config:        c,
 		op:            op,
@@ -5121,21 +5948,30 @@ func (m *KeysMutation) ResetEdge(name string) error {
 // OAuth2ClientMutation represents an operation that mutates the OAuth2Client nodes in the graph.
 type OAuth2ClientMutation struct {
 	config
-	op                  Op
-	typ                 string
-	id                  *string
-	secret              *string
-	redirect_uris       *[]string
-	appendredirect_uris []string
-	trusted_peers       *[]string
-	appendtrusted_peers []string
-	public              *bool
-	name                *string
-	logo_url            *string
-	clearedFields       map[string]struct{}
-	done                bool
-	oldValue            func(context.Context) (*OAuth2Client, error)
-	predicates          []predicate.OAuth2Client
+	op                        Op
+	typ                       string
+	id                        *string
+	secret                    *string
+	redirect_uris             *[]string
+	appendredirect_uris       []string
+	trusted_peers             *[]string
+	appendtrusted_peers       []string
+	public                    *bool
+	name                      *string
+	logo_url                  *string
+	access_token_lifetime     *int64
+	addaccess_token_lifetime  *int64
+	refresh_token_lifetime    *int64
+	addrefresh_token_lifetime *int64
+	created_at                *time.Time
+	updated_at                *time.Time
+	disabled                  *bool
+	allowed_connectors        *[]string
+	appendallowed_connectors  []string
+	clearedFields             map[string]struct{}
+	done                      bool
+	oldValue                  func(context.Context) (*OAuth2Client, error)
+	predicates                []predicate.OAuth2Client
 }
 
 var _ ent.Mutation = (*OAuth2ClientMutation)(nil)
@@ -5330,190 +6166,501 @@ func (m *OAuth2ClientMutation) ClearRedirectUris() {
 	m.clearedFields[oauth2client.FieldRedirectUris] = struct{}{}
 }
 
-// RedirectUrisCleared returns if the "redirect_uris" field was cleared in this mutation.
-func (m *OAuth2ClientMutation) RedirectUrisCleared() bool {
-	_, ok := m.clearedFields[oauth2client.FieldRedirectUris]
-	return ok
+// RedirectUrisCleared returns if the "redirect_uris" field was cleared in this mutation.
+func (m *OAuth2ClientMutation) RedirectUrisCleared() bool {
+	_, ok := m.clearedFields[oauth2client.FieldRedirectUris]
+	return ok
+}
+
+// ResetRedirectUris resets all changes to the "redirect_uris" field.
+func (m *OAuth2ClientMutation) ResetRedirectUris() {
+	m.redirect_uris = nil
+	m.appendredirect_uris = nil
+	delete(m.clearedFields, oauth2client.FieldRedirectUris)
+}
+
+// SetTrustedPeers sets the "trusted_peers" field.
+func (m *OAuth2ClientMutation) SetTrustedPeers(s []string) {
+	m.trusted_peers = &s
+	m.appendtrusted_peers = nil
+}
+
+// TrustedPeers returns the value of the "trusted_peers" field in the mutation.
+func (m *OAuth2ClientMutation) TrustedPeers() (r []string, exists bool) {
+	v := m.trusted_peers
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldTrustedPeers returns the old "trusted_peers" field's value of the OAuth2Client entity.
+// If the OAuth2Client object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *OAuth2ClientMutation) OldTrustedPeers(ctx context.Context) (v []string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldTrustedPeers is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldTrustedPeers requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldTrustedPeers: %w", err)
+	}
+	return oldValue.TrustedPeers, nil
+}
+
+// AppendTrustedPeers adds s to the "trusted_peers" field.
+func (m *OAuth2ClientMutation) AppendTrustedPeers(s []string) {
+	m.appendtrusted_peers = append(m.appendtrusted_peers, s...)
+}
+
+// AppendedTrustedPeers returns the list of values that were appended to the "trusted_peers" field in this mutation.
+func (m *OAuth2ClientMutation) AppendedTrustedPeers() ([]string, bool) {
+	if len(m.appendtrusted_peers) == 0 {
+		return nil, false
+	}
+	return m.appendtrusted_peers, true
+}
+
+// ClearTrustedPeers clears the value of the "trusted_peers" field.
+func (m *OAuth2ClientMutation) ClearTrustedPeers() {
+	m.trusted_peers = nil
+	m.appendtrusted_peers = nil
+	m.clearedFields[oauth2client.FieldTrustedPeers] = struct{}{}
+}
+
+// TrustedPeersCleared returns if the "trusted_peers" field was cleared in this mutation.
+func (m *OAuth2ClientMutation) TrustedPeersCleared() bool {
+	_, ok := m.clearedFields[oauth2client.FieldTrustedPeers]
+	return ok
+}
+
+// ResetTrustedPeers resets all changes to the "trusted_peers" field.
+func (m *OAuth2ClientMutation) ResetTrustedPeers() {
+	m.trusted_peers = nil
+	m.appendtrusted_peers = nil
+	delete(m.clearedFields, oauth2client.FieldTrustedPeers)
+}
+
+// SetPublic sets the "public" field.
+func (m *OAuth2ClientMutation) SetPublic(b bool) {
+	m.public = &b
+}
+
+// Public returns the value of the "public" field in the mutation.
+func (m *OAuth2ClientMutation) Public() (r bool, exists bool) {
+	v := m.public
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldPublic returns the old "public" field's value of the OAuth2Client entity.
+// If the OAuth2Client object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *OAuth2ClientMutation) OldPublic(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldPublic is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldPublic requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldPublic: %w", err)
+	}
+	return oldValue.Public, nil
+}
+
+// ResetPublic resets all changes to the "public" field.
+func (m *OAuth2ClientMutation) ResetPublic() {
+	m.public = nil
+}
+
+// SetName sets the "name" field.
+func (m *OAuth2ClientMutation) SetName(s string) {
+	m.name = &s
+}
+
+// Name returns the value of the "name" field in the mutation.
+func (m *OAuth2ClientMutation) Name() (r string, exists bool) {
+	v := m.name
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldName returns the old "name" field's value of the OAuth2Client entity.
+// If the OAuth2Client object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *OAuth2ClientMutation) OldName(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldName is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldName requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldName: %w", err)
+	}
+	return oldValue.Name, nil
+}
+
+// ResetName resets all changes to the "name" field.
+func (m *OAuth2ClientMutation) ResetName() {
+	m.name = nil
+}
+
+// SetLogoURL sets the "logo_url" field.
+func (m *OAuth2ClientMutation) SetLogoURL(s string) {
+	m.logo_url = &s
+}
+
+// LogoURL returns the value of the "logo_url" field in the mutation.
+func (m *OAuth2ClientMutation) LogoURL() (r string, exists bool) {
+	v := m.logo_url
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldLogoURL returns the old "logo_url" field's value of the OAuth2Client entity.
+// If the OAuth2Client object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *OAuth2ClientMutation) OldLogoURL(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldLogoURL is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldLogoURL requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldLogoURL: %w", err)
+	}
+	return oldValue.LogoURL, nil
+}
+
+// ResetLogoURL resets all changes to the "logo_url" field.
+func (m *OAuth2ClientMutation) ResetLogoURL() {
+	m.logo_url = nil
+}
+
+// SetAccessTokenLifetime sets the "access_token_lifetime" field.
+func (m *OAuth2ClientMutation) SetAccessTokenLifetime(i int64) {
+	m.access_token_lifetime = &i
+	m.addaccess_token_lifetime = nil
+}
+
+// AccessTokenLifetime returns the value of the "access_token_lifetime" field in the mutation.
+func (m *OAuth2ClientMutation) AccessTokenLifetime() (r int64, exists bool) {
+	v := m.access_token_lifetime
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldAccessTokenLifetime returns the old "access_token_lifetime" field's value of the OAuth2Client entity.
+// If the OAuth2Client object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *OAuth2ClientMutation) OldAccessTokenLifetime(ctx context.Context) (v int64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldAccessTokenLifetime is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldAccessTokenLifetime requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldAccessTokenLifetime: %w", err)
+	}
+	return oldValue.AccessTokenLifetime, nil
+}
+
+// AddAccessTokenLifetime adds i to the "access_token_lifetime" field.
+func (m *OAuth2ClientMutation) AddAccessTokenLifetime(i int64) {
+	if m.addaccess_token_lifetime != nil {
+		*m.addaccess_token_lifetime += i
+	} else {
+		m.addaccess_token_lifetime = &i
+	}
+}
+
+// AddedAccessTokenLifetime returns the value that was added to the "access_token_lifetime" field in this mutation.
+func (m *OAuth2ClientMutation) AddedAccessTokenLifetime() (r int64, exists bool) {
+	v := m.addaccess_token_lifetime
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetAccessTokenLifetime resets all changes to the "access_token_lifetime" field.
+func (m *OAuth2ClientMutation) ResetAccessTokenLifetime() {
+	m.access_token_lifetime = nil
+	m.addaccess_token_lifetime = nil
+}
+
+// SetRefreshTokenLifetime sets the "refresh_token_lifetime" field.
+func (m *OAuth2ClientMutation) SetRefreshTokenLifetime(i int64) {
+	m.refresh_token_lifetime = &i
+	m.addrefresh_token_lifetime = nil
+}
+
+// RefreshTokenLifetime returns the value of the "refresh_token_lifetime" field in the mutation.
+func (m *OAuth2ClientMutation) RefreshTokenLifetime() (r int64, exists bool) {
+	v := m.refresh_token_lifetime
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldRefreshTokenLifetime returns the old "refresh_token_lifetime" field's value of the OAuth2Client entity.
+// If the OAuth2Client object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *OAuth2ClientMutation) OldRefreshTokenLifetime(ctx context.Context) (v int64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldRefreshTokenLifetime is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldRefreshTokenLifetime requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldRefreshTokenLifetime: %w", err)
+	}
+	return oldValue.RefreshTokenLifetime, nil
+}
+
+// AddRefreshTokenLifetime adds i to the "refresh_token_lifetime" field.
+func (m *OAuth2ClientMutation) AddRefreshTokenLifetime(i int64) {
+	if m.addrefresh_token_lifetime != nil {
+		*m.addrefresh_token_lifetime += i
+	} else {
+		m.addrefresh_token_lifetime = &i
+	}
 }
 
-// ResetRedirectUris resets all changes to the "redirect_uris" field.
-func (m *OAuth2ClientMutation) ResetRedirectUris() {
-	m.redirect_uris = nil
-	m.appendredirect_uris = nil
-	delete(m.clearedFields, oauth2client.FieldRedirectUris)
+// AddedRefreshTokenLifetime returns the value that was added to the "refresh_token_lifetime" field in this mutation.
+func (m *OAuth2ClientMutation) AddedRefreshTokenLifetime() (r int64, exists bool) {
+	v := m.addrefresh_token_lifetime
+	if v == nil {
+		return
+	}
+	return *v, true
 }
 
-// SetTrustedPeers sets the "trusted_peers" field.
-func (m *OAuth2ClientMutation) SetTrustedPeers(s []string) {
-	m.trusted_peers = &s
-	m.appendtrusted_peers = nil
+// ResetRefreshTokenLifetime resets all changes to the "refresh_token_lifetime" field.
+func (m *OAuth2ClientMutation) ResetRefreshTokenLifetime() {
+	m.refresh_token_lifetime = nil
+	m.addrefresh_token_lifetime = nil
 }
 
-// TrustedPeers returns the value of the "trusted_peers" field in the mutation.
-func (m *OAuth2ClientMutation) TrustedPeers() (r []string, exists bool) {
-	v := m.trusted_peers
+// SetCreatedAt sets the "created_at" field.
+func (m *OAuth2ClientMutation) SetCreatedAt(t time.Time) {
+	m.created_at = &t
+}
+
+// CreatedAt returns the value of the "created_at" field in the mutation.
+func (m *OAuth2ClientMutation) CreatedAt() (r time.Time, exists bool) {
+	v := m.created_at
 	if v == nil {
 		return
 	}
 	return *v, true
 }
 
-// OldTrustedPeers returns the old "trusted_peers" field's value of the OAuth2Client entity.
+// OldCreatedAt returns the old "created_at" field's value of the OAuth2Client entity.
 // If the OAuth2Client object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *OAuth2ClientMutation) OldTrustedPeers(ctx context.Context) (v []string, err error) {
+func (m *OAuth2ClientMutation) OldCreatedAt(ctx context.Context) (v time.Time, err error) {
 	if !m.op.Is(OpUpdateOne) {
-		return v, errors.New("OldTrustedPeers is only allowed on UpdateOne operations")
+		return v, errors.New("OldCreatedAt is only allowed on UpdateOne operations")
 	}
 	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldTrustedPeers requires an ID field in the mutation")
+		return v, errors.New("OldCreatedAt requires an ID field in the mutation")
 	}
 	oldValue, err := m.oldValue(ctx)
 	if err != nil {
-		return v, fmt.Errorf("querying old value for OldTrustedPeers: %w", err)
-	}
-	return oldValue.TrustedPeers, nil
-}
-
-// AppendTrustedPeers adds s to the "trusted_peers" field.
-func (m *OAuth2ClientMutation) AppendTrustedPeers(s []string) {
-	m.appendtrusted_peers = append(m.appendtrusted_peers, s...)
-}
-
-// AppendedTrustedPeers returns the list of values that were appended to the "trusted_peers" field in this mutation.
-func (m *OAuth2ClientMutation) AppendedTrustedPeers() ([]string, bool) {
-	if len(m.appendtrusted_peers) == 0 {
-		return nil, false
+		return v, fmt.Errorf("querying old value for OldCreatedAt: %w", err)
 	}
-	return m.appendtrusted_peers, true
+	return oldValue.CreatedAt, nil
 }
 
-// ClearTrustedPeers clears the value of the "trusted_peers" field.
-func (m *OAuth2ClientMutation) ClearTrustedPeers() {
-	m.trusted_peers = nil
-	m.appendtrusted_peers = nil
-	m.clearedFields[oauth2client.FieldTrustedPeers] = struct{}{}
+// ClearCreatedAt clears the value of the "created_at" field.
+func (m *OAuth2ClientMutation) ClearCreatedAt() {
+	m.created_at = nil
+	m.clearedFields[oauth2client.FieldCreatedAt] = struct{}{}
 }
 
-// TrustedPeersCleared returns if the "trusted_peers" field was cleared in this mutation.
-func (m *OAuth2ClientMutation) TrustedPeersCleared() bool {
-	_, ok := m.clearedFields[oauth2client.FieldTrustedPeers]
+// CreatedAtCleared returns if the "created_at" field was cleared in this mutation.
+func (m *OAuth2ClientMutation) CreatedAtCleared() bool {
+	_, ok := m.clearedFields[oauth2client.FieldCreatedAt]
 	return ok
 }
 
-// ResetTrustedPeers resets all changes to the "trusted_peers" field.
-func (m *OAuth2ClientMutation) ResetTrustedPeers() {
-	m.trusted_peers = nil
-	m.appendtrusted_peers = nil
-	delete(m.clearedFields, oauth2client.FieldTrustedPeers)
+// ResetCreatedAt resets all changes to the "created_at" field.
+func (m *OAuth2ClientMutation) ResetCreatedAt() {
+	m.created_at = nil
+	delete(m.clearedFields, oauth2client.FieldCreatedAt)
 }
 
-// SetPublic sets the "public" field.
-func (m *OAuth2ClientMutation) SetPublic(b bool) {
-	m.public = &b
+// SetUpdatedAt sets the "updated_at" field.
+func (m *OAuth2ClientMutation) SetUpdatedAt(t time.Time) {
+	m.updated_at = &t
 }
 
-// Public returns the value of the "public" field in the mutation.
-func (m *OAuth2ClientMutation) Public() (r bool, exists bool) {
-	v := m.public
+// UpdatedAt returns the value of the "updated_at" field in the mutation.
+func (m *OAuth2ClientMutation) UpdatedAt() (r time.Time, exists bool) {
+	v := m.updated_at
 	if v == nil {
 		return
 	}
 	return *v, true
 }
 
-// OldPublic returns the old "public" field's value of the OAuth2Client entity.
+// OldUpdatedAt returns the old "updated_at" field's value of the OAuth2Client entity.
 // If the OAuth2Client object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *OAuth2ClientMutation) OldPublic(ctx context.Context) (v bool, err error) {
+func (m *OAuth2ClientMutation) OldUpdatedAt(ctx context.Context) (v time.Time, err error) {
 	if !m.op.Is(OpUpdateOne) {
-		return v, errors.New("OldPublic is only allowed on UpdateOne operations")
+		return v, errors.New("OldUpdatedAt is only allowed on UpdateOne operations")
 	}
 	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldPublic requires an ID field in the mutation")
+		return v, errors.New("OldUpdatedAt requires an ID field in the mutation")
 	}
 	oldValue, err := m.oldValue(ctx)
 	if err != nil {
-		return v, fmt.Errorf("querying old value for OldPublic: %w", err)
+		return v, fmt.Errorf("querying old value for OldUpdatedAt: %w", err)
 	}
-	return oldValue.Public, nil
+	return oldValue.UpdatedAt, nil
 }
 
-// ResetPublic resets all changes to the "public" field.
-func (m *OAuth2ClientMutation) ResetPublic() {
-	m.public = nil
+// ClearUpdatedAt clears the value of the "updated_at" field.
+func (m *OAuth2ClientMutation) ClearUpdatedAt() {
+	m.updated_at = nil
+	m.clearedFields[oauth2client.FieldUpdatedAt] = struct{}{}
 }
 
-// SetName sets the "name" field.
-func (m *OAuth2ClientMutation) SetName(s string) {
-	m.name = &s
+// UpdatedAtCleared returns if the "updated_at" field was cleared in this mutation.
+func (m *OAuth2ClientMutation) UpdatedAtCleared() bool {
+	_, ok := m.clearedFields[oauth2client.FieldUpdatedAt]
+	return ok
 }
 
-// Name returns the value of the "name" field in the mutation.
-func (m *OAuth2ClientMutation) Name() (r string, exists bool) {
-	v := m.name
+// ResetUpdatedAt resets all changes to the "updated_at" field.
+func (m *OAuth2ClientMutation) ResetUpdatedAt() {
+	m.updated_at = nil
+	delete(m.clearedFields, oauth2client.FieldUpdatedAt)
+}
+
+// SetDisabled sets the "disabled" field.
+func (m *OAuth2ClientMutation) SetDisabled(b bool) {
+	m.disabled = &b
+}
+
+// Disabled returns the value of the "disabled" field in the mutation.
+func (m *OAuth2ClientMutation) Disabled() (r bool, exists bool) {
+	v := m.disabled
 	if v == nil {
 		return
 	}
 	return *v, true
 }
 
-// OldName returns the old "name" field's value of the OAuth2Client entity.
+// OldDisabled returns the old "disabled" field's value of the OAuth2Client entity.
 // If the OAuth2Client object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *OAuth2ClientMutation) OldName(ctx context.Context) (v string, err error) {
+func (m *OAuth2ClientMutation) OldDisabled(ctx context.Context) (v bool, err error) {
 	if !m.op.Is(OpUpdateOne) {
-		return v, errors.New("OldName is only allowed on UpdateOne operations")
+		return v, errors.New("OldDisabled is only allowed on UpdateOne operations")
 	}
 	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldName requires an ID field in the mutation")
+		return v, errors.New("OldDisabled requires an ID field in the mutation")
 	}
 	oldValue, err := m.oldValue(ctx)
 	if err != nil {
-		return v, fmt.Errorf("querying old value for OldName: %w", err)
+		return v, fmt.Errorf("querying old value for OldDisabled: %w", err)
 	}
-	return oldValue.Name, nil
+	return oldValue.Disabled, nil
 }
 
-// ResetName resets all changes to the "name" field.
-func (m *OAuth2ClientMutation) ResetName() {
-	m.name = nil
+// ResetDisabled resets all changes to the "disabled" field.
+func (m *OAuth2ClientMutation) ResetDisabled() {
+	m.disabled = nil
 }
 
-// SetLogoURL sets the "logo_url" field.
-func (m *OAuth2ClientMutation) SetLogoURL(s string) {
-	m.logo_url = &s
+// SetAllowedConnectors sets the "allowed_connectors" field.
+func (m *OAuth2ClientMutation) SetAllowedConnectors(s []string) {
+	m.allowed_connectors = &s
+	m.appendallowed_connectors = nil
 }
 
-// LogoURL returns the value of the "logo_url" field in the mutation.
-func (m *OAuth2ClientMutation) LogoURL() (r string, exists bool) {
-	v := m.logo_url
+// AllowedConnectors returns the value of the "allowed_connectors" field in the mutation.
+func (m *OAuth2ClientMutation) AllowedConnectors() (r []string, exists bool) {
+	v := m.allowed_connectors
 	if v == nil {
 		return
 	}
 	return *v, true
 }
 
-// OldLogoURL returns the old "logo_url" field's value of the OAuth2Client entity.
+// OldAllowedConnectors returns the old "allowed_connectors" field's value of the OAuth2Client entity.
 // If the OAuth2Client object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *OAuth2ClientMutation) OldLogoURL(ctx context.Context) (v string, err error) {
+func (m *OAuth2ClientMutation) OldAllowedConnectors(ctx context.Context) (v []string, err error) {
 	if !m.op.Is(OpUpdateOne) {
-		return v, errors.New("OldLogoURL is only allowed on UpdateOne operations")
+		return v, errors.New("OldAllowedConnectors is only allowed on UpdateOne operations")
 	}
 	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldLogoURL requires an ID field in the mutation")
+		return v, errors.New("OldAllowedConnectors requires an ID field in the mutation")
 	}
 	oldValue, err := m.oldValue(ctx)
 	if err != nil {
-		return v, fmt.Errorf("querying old value for OldLogoURL: %w", err)
+		return v, fmt.Errorf("querying old value for OldAllowedConnectors: %w", err)
 	}
-	return oldValue.LogoURL, nil
+	return oldValue.AllowedConnectors, nil
 }
 
-// ResetLogoURL resets all changes to the "logo_url" field.
-func (m *OAuth2ClientMutation) ResetLogoURL() {
-	m.logo_url = nil
+// AppendAllowedConnectors adds s to the "allowed_connectors" field.
+func (m *OAuth2ClientMutation) AppendAllowedConnectors(s []string) {
+	m.appendallowed_connectors = append(m.appendallowed_connectors, s...)
+}
+
+// AppendedAllowedConnectors returns the list of values that were appended to the "allowed_connectors" field in this mutation.
+func (m *OAuth2ClientMutation) AppendedAllowedConnectors() ([]string, bool) {
+	if len(m.appendallowed_connectors) == 0 {
+		return nil, false
+	}
+	return m.appendallowed_connectors, true
+}
+
+// ClearAllowedConnectors clears the value of the "allowed_connectors" field.
+func (m *OAuth2ClientMutation) ClearAllowedConnectors() {
+	m.allowed_connectors = nil
+	m.appendallowed_connectors = nil
+	m.clearedFields[oauth2client.FieldAllowedConnectors] = struct{}{}
+}
+
+// AllowedConnectorsCleared returns if the "allowed_connectors" field was cleared in this mutation.
+func (m *OAuth2ClientMutation) AllowedConnectorsCleared() bool {
+	_, ok := m.clearedFields[oauth2client.FieldAllowedConnectors]
+	return ok
+}
+
+// ResetAllowedConnectors resets all changes to the "allowed_connectors" field.
+func (m *OAuth2ClientMutation) ResetAllowedConnectors() {
+	m.allowed_connectors = nil
+	m.appendallowed_connectors = nil
+	delete(m.clearedFields, oauth2client.FieldAllowedConnectors)
 }
 
 // Where appends a list predicates to the OAuth2ClientMutation builder.
@@ -5550,7 +6697,7 @@ func (m *OAuth2ClientMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *OAuth2ClientMutation) Fields() []string {
-	fields := make([]string, 0, 6)
+	fields := make([]string, 0, 12)
 	if m.secret != nil {
 		fields = append(fields, oauth2client.FieldSecret)
 	}
@@ -5569,6 +6716,24 @@ func (m *OAuth2ClientMutation) Fields() []string {
 	if m.logo_url != nil {
 		fields = append(fields, oauth2client.FieldLogoURL)
 	}
+	if m.access_token_lifetime != nil {
+		fields = append(fields, oauth2client.FieldAccessTokenLifetime)
+	}
+	if m.refresh_token_lifetime != nil {
+		fields = append(fields, oauth2client.FieldRefreshTokenLifetime)
+	}
+	if m.created_at != nil {
+		fields = append(fields, oauth2client.FieldCreatedAt)
+	}
+	if m.updated_at != nil {
+		fields = append(fields, oauth2client.FieldUpdatedAt)
+	}
+	if m.disabled != nil {
+		fields = append(fields, oauth2client.FieldDisabled)
+	}
+	if m.allowed_connectors != nil {
+		fields = append(fields, oauth2client.FieldAllowedConnectors)
+	}
 	return fields
 }
 
@@ -5589,6 +6754,18 @@ func (m *OAuth2ClientMutation) Field(name string) (ent.Value, bool) {
 		return m.Name()
 	case oauth2client.FieldLogoURL:
 		return m.LogoURL()
+	case oauth2client.FieldAccessTokenLifetime:
+		return m.AccessTokenLifetime()
+	case oauth2client.FieldRefreshTokenLifetime:
+		return m.RefreshTokenLifetime()
+	case oauth2client.FieldCreatedAt:
+		return m.CreatedAt()
+	case oauth2client.FieldUpdatedAt:
+		return m.UpdatedAt()
+	case oauth2client.FieldDisabled:
+		return m.Disabled()
+	case oauth2client.FieldAllowedConnectors:
+		return m.AllowedConnectors()
 	}
 	return nil, false
 }
@@ -5610,6 +6787,18 @@ func (m *OAuth2ClientMutation) OldField(ctx context.Context, name string) (ent.V
 		return m.OldName(ctx)
 	case oauth2client.FieldLogoURL:
 		return m.OldLogoURL(ctx)
+	case oauth2client.FieldAccessTokenLifetime:
+		return m.OldAccessTokenLifetime(ctx)
+	case oauth2client.FieldRefreshTokenLifetime:
+		return m.OldRefreshTokenLifetime(ctx)
+	case oauth2client.FieldCreatedAt:
+		return m.OldCreatedAt(ctx)
+	case oauth2client.FieldUpdatedAt:
+		return m.OldUpdatedAt(ctx)
+	case oauth2client.FieldDisabled:
+		return m.OldDisabled(ctx)
+	case oauth2client.FieldAllowedConnectors:
+		return m.OldAllowedConnectors(ctx)
 	}
 	return nil, fmt.Errorf("unknown OAuth2Client field %s", name)
 }
@@ -5661,6 +6850,48 @@ func (m *OAuth2ClientMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetLogoURL(v)
 		return nil
+	case oauth2client.FieldAccessTokenLifetime:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetAccessTokenLifetime(v)
+		return nil
+	case oauth2client.FieldRefreshTokenLifetime:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetRefreshTokenLifetime(v)
+		return nil
+	case oauth2client.FieldCreatedAt:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetCreatedAt(v)
+		return nil
+	case oauth2client.FieldUpdatedAt:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetUpdatedAt(v)
+		return nil
+	case oauth2client.FieldDisabled:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetDisabled(v)
+		return nil
+	case oauth2client.FieldAllowedConnectors:
+		v, ok := value.([]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetAllowedConnectors(v)
+		return nil
 	}
 	return fmt.Errorf("unknown OAuth2Client field %s", name)
 }
@@ -5668,13 +6899,26 @@ func (m *OAuth2ClientMutation) SetField(name string, value ent.Value) error {
 // AddedFields returns all numeric fields that were incremented/decremented during
 // this mutation.
 func (m *OAuth2ClientMutation) AddedFields() []string {
-	return nil
+	var fields []string
+	if m.addaccess_token_lifetime != nil {
+		fields = append(fields, oauth2client.FieldAccessTokenLifetime)
+	}
+	if m.addrefresh_token_lifetime != nil {
+		fields = append(fields, oauth2client.FieldRefreshTokenLifetime)
+	}
+	return fields
 }
 
 // AddedField returns the numeric value that was incremented/decremented on a field
 // with the given name. The second boolean return value indicates that this field
 // was not set, or was not defined in the schema.
 func (m *OAuth2ClientMutation) AddedField(name string) (ent.Value, bool) {
+	switch name {
+	case oauth2client.FieldAccessTokenLifetime:
+		return m.AddedAccessTokenLifetime()
+	case oauth2client.FieldRefreshTokenLifetime:
+		return m.AddedRefreshTokenLifetime()
+	}
 	return nil, false
 }
 
@@ -5683,6 +6927,20 @@ func (m *OAuth2ClientMutation) AddedField(name string) (ent.Value, bool) {
 // type.
 func (m *OAuth2ClientMutation) AddField(name string, value ent.Value) error {
 	switch name {
+	case oauth2client.FieldAccessTokenLifetime:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddAccessTokenLifetime(v)
+		return nil
+	case oauth2client.FieldRefreshTokenLifetime:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddRefreshTokenLifetime(v)
+		return nil
 	}
 	return fmt.Errorf("unknown OAuth2Client numeric field %s", name)
 }
@@ -5697,6 +6955,15 @@ func (m *OAuth2ClientMutation) ClearedFields() []string {
 	if m.FieldCleared(oauth2client.FieldTrustedPeers) {
 		fields = append(fields, oauth2client.FieldTrustedPeers)
 	}
+	if m.FieldCleared(oauth2client.FieldCreatedAt) {
+		fields = append(fields, oauth2client.FieldCreatedAt)
+	}
+	if m.FieldCleared(oauth2client.FieldUpdatedAt) {
+		fields = append(fields, oauth2client.FieldUpdatedAt)
+	}
+	if m.FieldCleared(oauth2client.FieldAllowedConnectors) {
+		fields = append(fields, oauth2client.FieldAllowedConnectors)
+	}
 	return fields
 }
 
@@ -5717,6 +6984,15 @@ func (m *OAuth2ClientMutation) ClearField(name string) error {
 	case oauth2client.FieldTrustedPeers:
 		m.ClearTrustedPeers()
 		return nil
+	case oauth2client.FieldCreatedAt:
+		m.ClearCreatedAt()
+		return nil
+	case oauth2client.FieldUpdatedAt:
+		m.ClearUpdatedAt()
+		return nil
+	case oauth2client.FieldAllowedConnectors:
+		m.ClearAllowedConnectors()
+		return nil
 	}
 	return fmt.Errorf("unknown OAuth2Client nullable field %s", name)
 }
@@ -5743,6 +7019,24 @@ func (m *OAuth2ClientMutation) ResetField(name string) error {
 	case oauth2client.FieldLogoURL:
 		m.ResetLogoURL()
 		return nil
+	case oauth2client.FieldAccessTokenLifetime:
+		m.ResetAccessTokenLifetime()
+		return nil
+	case oauth2client.FieldRefreshTokenLifetime:
+		m.ResetRefreshTokenLifetime()
+		return nil
+	case oauth2client.FieldCreatedAt:
+		m.ResetCreatedAt()
+		return nil
+	case oauth2client.FieldUpdatedAt:
+		m.ResetUpdatedAt()
+		return nil
+	case oauth2client.FieldDisabled:
+		m.ResetDisabled()
+		return nil
+	case oauth2client.FieldAllowedConnectors:
+		m.ResetAllowedConnectors()
+		return nil
 	}
 	return fmt.Errorf("unknown OAuth2Client field %s", name)
 }
@@ -6815,6 +8109,7 @@ type RefreshTokenMutation struct {
 	claims_email_verified     *bool
 	claims_groups             *[]string
 	appendclaims_groups       []string
+	claims_extra              *map[string]interface{}
 	claims_preferred_username *string
 	connector_id              *string
 	connector_data            *[]byte
@@ -6822,6 +8117,7 @@ type RefreshTokenMutation struct {
 	obsolete_token            *string
 	created_at                *time.Time
 	last_used                 *time.Time
+	consumed                  *bool
 	clearedFields             map[string]struct{}
 	done                      bool
 	oldValue                  func(context.Context) (*RefreshToken, error)
@@ -7278,6 +8574,55 @@ func (m *RefreshTokenMutation) ResetClaimsGroups() {
 	delete(m.clearedFields, refreshtoken.FieldClaimsGroups)
 }
 
+// SetClaimsExtra sets the "claims_extra" field.
+func (m *RefreshTokenMutation) SetClaimsExtra(value map[string]interface{}) {
+	m.claims_extra = &value
+}
+
+// ClaimsExtra returns the value of the "claims_extra" field in the mutation.
+func (m *RefreshTokenMutation) ClaimsExtra() (r map[string]interface{}, exists bool) {
+	v := m.claims_extra
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldClaimsExtra returns the old "claims_extra" field's value of the RefreshToken entity.
+// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *RefreshTokenMutation) OldClaimsExtra(ctx context.Context) (v map[string]interface{}, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldClaimsExtra is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldClaimsExtra requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldClaimsExtra: %w", err)
+	}
+	return oldValue.ClaimsExtra, nil
+}
+
+// ClearClaimsExtra clears the value of the "claims_extra" field.
+func (m *RefreshTokenMutation) ClearClaimsExtra() {
+	m.claims_extra = nil
+	m.clearedFields[refreshtoken.FieldClaimsExtra] = struct{}{}
+}
+
+// ClaimsExtraCleared returns if the "claims_extra" field was cleared in this mutation.
+func (m *RefreshTokenMutation) ClaimsExtraCleared() bool {
+	_, ok := m.clearedFields[refreshtoken.FieldClaimsExtra]
+	return ok
+}
+
+// ResetClaimsExtra resets all changes to the "claims_extra" field.
+func (m *RefreshTokenMutation) ResetClaimsExtra() {
+	m.claims_extra = nil
+	delete(m.clearedFields, refreshtoken.FieldClaimsExtra)
+}
+
 // SetClaimsPreferredUsername sets the "claims_preferred_username" field.
 func (m *RefreshTokenMutation) SetClaimsPreferredUsername(s string) {
 	m.claims_preferred_username = &s
@@ -7543,6 +8888,42 @@ func (m *RefreshTokenMutation) ResetLastUsed() {
 	m.last_used = nil
 }
 
+// SetConsumed sets the "consumed" field.
+func (m *RefreshTokenMutation) SetConsumed(b bool) {
+	m.consumed = &b
+}
+
+// Consumed returns the value of the "consumed" field in the mutation.
+func (m *RefreshTokenMutation) Consumed() (r bool, exists bool) {
+	v := m.consumed
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldConsumed returns the old "consumed" field's value of the RefreshToken entity.
+// If the RefreshToken object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *RefreshTokenMutation) OldConsumed(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldConsumed is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldConsumed requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldConsumed: %w", err)
+	}
+	return oldValue.Consumed, nil
+}
+
+// ResetConsumed resets all changes to the "consumed" field.
+func (m *RefreshTokenMutation) ResetConsumed() {
+	m.consumed = nil
+}
+
 // Where appends a list predicates to the RefreshTokenMutation builder.
 func (m *RefreshTokenMutation) Where(ps ...predicate.RefreshToken) {
 	m.predicates = append(m.predicates, ps...)
@@ -7577,7 +8958,7 @@ func (m *RefreshTokenMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *RefreshTokenMutation) Fields() []string {
-	fields := make([]string, 0, 15)
+	fields := make([]string, 0, 17)
 	if m.client_id != nil {
 		fields = append(fields, refreshtoken.FieldClientID)
 	}
@@ -7602,6 +8983,9 @@ func (m *RefreshTokenMutation) Fields() []string {
 	if m.claims_groups != nil {
 		fields = append(fields, refreshtoken.FieldClaimsGroups)
 	}
+	if m.claims_extra != nil {
+		fields = append(fields, refreshtoken.FieldClaimsExtra)
+	}
 	if m.claims_preferred_username != nil {
 		fields = append(fields, refreshtoken.FieldClaimsPreferredUsername)
 	}
@@ -7623,6 +9007,9 @@ func (m *RefreshTokenMutation) Fields() []string {
 	if m.last_used != nil {
 		fields = append(fields, refreshtoken.FieldLastUsed)
 	}
+	if m.consumed != nil {
+		fields = append(fields, refreshtoken.FieldConsumed)
+	}
 	return fields
 }
 
@@ -7647,6 +9034,8 @@ func (m *RefreshTokenMutation) Field(name string) (ent.Value, bool) {
 		return m.ClaimsEmailVerified()
 	case refreshtoken.FieldClaimsGroups:
 		return m.ClaimsGroups()
+	case refreshtoken.FieldClaimsExtra:
+		return m.ClaimsExtra()
 	case refreshtoken.FieldClaimsPreferredUsername:
 		return m.ClaimsPreferredUsername()
 	case refreshtoken.FieldConnectorID:
@@ -7661,6 +9050,8 @@ func (m *RefreshTokenMutation) Field(name string) (ent.Value, bool) {
 		return m.CreatedAt()
 	case refreshtoken.FieldLastUsed:
 		return m.LastUsed()
+	case refreshtoken.FieldConsumed:
+		return m.Consumed()
 	}
 	return nil, false
 }
@@ -7686,6 +9077,8 @@ func (m *RefreshTokenMutation) OldField(ctx context.Context, name string) (ent.V
 		return m.OldClaimsEmailVerified(ctx)
 	case refreshtoken.FieldClaimsGroups:
 		return m.OldClaimsGroups(ctx)
+	case refreshtoken.FieldClaimsExtra:
+		return m.OldClaimsExtra(ctx)
 	case refreshtoken.FieldClaimsPreferredUsername:
 		return m.OldClaimsPreferredUsername(ctx)
 	case refreshtoken.FieldConnectorID:
@@ -7700,6 +9093,8 @@ func (m *RefreshTokenMutation) OldField(ctx context.Context, name string) (ent.V
 		return m.OldCreatedAt(ctx)
 	case refreshtoken.FieldLastUsed:
 		return m.OldLastUsed(ctx)
+	case refreshtoken.FieldConsumed:
+		return m.OldConsumed(ctx)
 	}
 	return nil, fmt.Errorf("unknown RefreshToken field %s", name)
 }
@@ -7765,6 +9160,13 @@ func (m *RefreshTokenMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetClaimsGroups(v)
 		return nil
+	case refreshtoken.FieldClaimsExtra:
+		v, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetClaimsExtra(v)
+		return nil
 	case refreshtoken.FieldClaimsPreferredUsername:
 		v, ok := value.(string)
 		if !ok {
@@ -7814,6 +9216,13 @@ func (m *RefreshTokenMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetLastUsed(v)
 		return nil
+	case refreshtoken.FieldConsumed:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetConsumed(v)
+		return nil
 	}
 	return fmt.Errorf("unknown RefreshToken field %s", name)
 }
@@ -7850,6 +9259,9 @@ func (m *RefreshTokenMutation) ClearedFields() []string {
 	if m.FieldCleared(refreshtoken.FieldClaimsGroups) {
 		fields = append(fields, refreshtoken.FieldClaimsGroups)
 	}
+	if m.FieldCleared(refreshtoken.FieldClaimsExtra) {
+		fields = append(fields, refreshtoken.FieldClaimsExtra)
+	}
 	if m.FieldCleared(refreshtoken.FieldConnectorData) {
 		fields = append(fields, refreshtoken.FieldConnectorData)
 	}
@@ -7873,6 +9285,9 @@ func (m *RefreshTokenMutation) ClearField(name string) error {
 	case refreshtoken.FieldClaimsGroups:
 		m.ClearClaimsGroups()
 		return nil
+	case refreshtoken.FieldClaimsExtra:
+		m.ClearClaimsExtra()
+		return nil
 	case refreshtoken.FieldConnectorData:
 		m.ClearConnectorData()
 		return nil
@@ -7908,6 +9323,9 @@ func (m *RefreshTokenMutation) ResetField(name string) error {
 	case refreshtoken.FieldClaimsGroups:
 		m.ResetClaimsGroups()
 		return nil
+	case refreshtoken.FieldClaimsExtra:
+		m.ResetClaimsExtra()
+		return nil
 	case refreshtoken.FieldClaimsPreferredUsername:
 		m.ResetClaimsPreferredUsername()
 		return nil
@@ -7929,6 +9347,9 @@ func (m *RefreshTokenMutation) ResetField(name string) error {
 	case refreshtoken.FieldLastUsed:
 		m.ResetLastUsed()
 		return nil
+	case refreshtoken.FieldConsumed:
+		m.ResetConsumed()
+		return nil
 	}
 	return fmt.Errorf("unknown RefreshToken field %s", name)
 }
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"entgo.io/ent"
 	"entgo.io/ent/dialect/sql"
@@ -28,8 +29,20 @@ type OAuth2Client struct {
 	// Name holds the value of the "name" field.
 	Name string `json:"name,omitempty"`
 	// LogoURL holds the value of the "logo_url" field.
-	LogoURL      string `json:"logo_url,omitempty"`
-	selectValues sql.SelectValues
+	LogoURL string `json:"logo_url,omitempty"`
+	// AccessTokenLifetime holds the value of the "access_token_lifetime" field.
+	AccessTokenLifetime int64 `json:"access_token_lifetime,omitempty"`
+	// RefreshTokenLifetime holds the value of the "refresh_token_lifetime" field.
+	RefreshTokenLifetime int64 `json:"refresh_token_lifetime,omitempty"`
+	// CreatedAt holds the value of the "created_at" field.
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	// UpdatedAt holds the value of the "updated_at" field.
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+	// Disabled holds the value of the "disabled" field.
+	Disabled bool `json:"disabled,omitempty"`
+	// AllowedConnectors holds the value of the "allowed_connectors" field.
+	AllowedConnectors []string `json:"allowed_connectors,omitempty"`
+	selectValues      sql.SelectValues
 }
 
 // scanValues returns the types for scanning values from sql.Rows.
@@ -37,12 +50,16 @@ func (*OAuth2Client) scanValues(columns []string) ([]any, error) {
 	values := make([]any, len(columns))
 	for i := range columns {
 		switch columns[i] {
-		case oauth2client.FieldRedirectUris, oauth2client.FieldTrustedPeers:
+		case oauth2client.FieldRedirectUris, oauth2client.FieldTrustedPeers, oauth2client.FieldAllowedConnectors:
 			values[i] = new([]byte)
-		case oauth2client.FieldPublic:
+		case oauth2client.FieldPublic, oauth2client.FieldDisabled:
 			values[i] = new(sql.NullBool)
+		case oauth2client.FieldAccessTokenLifetime, oauth2client.FieldRefreshTokenLifetime:
+			values[i] = new(sql.NullInt64)
 		case oauth2client.FieldID, oauth2client.FieldSecret, oauth2client.FieldName, oauth2client.FieldLogoURL:
 			values[i] = new(sql.NullString)
+		case oauth2client.FieldCreatedAt, oauth2client.FieldUpdatedAt:
+			values[i] = new(sql.NullTime)
 		default:
 			values[i] = new(sql.UnknownType)
 		}
@@ -104,6 +121,44 @@ func (o *OAuth2Client) assignValues(columns []string, values []any) error {
 			} else if value.Valid {
 				o.LogoURL = value.String
 			}
+		case oauth2client.FieldAccessTokenLifetime:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field access_token_lifetime", values[i])
+			} else if value.Valid {
+				o.AccessTokenLifetime = value.Int64
+			}
+		case oauth2client.FieldRefreshTokenLifetime:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field refresh_token_lifetime", values[i])
+			} else if value.Valid {
+				o.RefreshTokenLifetime = value.Int64
+			}
+		case oauth2client.FieldCreatedAt:
+			if value, ok := values[i].(*sql.NullTime); !ok {
+				return fmt.Errorf("unexpected type %T for field created_at", values[i])
+			} else if value.Valid {
+				o.CreatedAt = value.Time
+			}
+		case oauth2client.FieldUpdatedAt:
+			if value, ok := values[i].(*sql.NullTime); !ok {
+				return fmt.Errorf("unexpected type %T for field updated_at", values[i])
+			} else if value.Valid {
+				o.UpdatedAt = value.Time
+			}
+		case oauth2client.FieldDisabled:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field disabled", values[i])
+			} else if value.Valid {
+				o.Disabled = value.Bool
+			}
+		case oauth2client.FieldAllowedConnectors:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field allowed_connectors", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &o.AllowedConnectors); err != nil {
+					return fmt.Errorf("unmarshal field allowed_connectors: %w", err)
+				}
+			}
 		default:
 			o.selectValues.Set(columns[i], values[i])
 		}
@@ -157,6 +212,24 @@ func (o *OAuth2Client) String() string {
 	builder.WriteString(", ")
 	builder.WriteString("logo_url=")
 	builder.WriteString(o.LogoURL)
+	builder.WriteString(", ")
+	builder.WriteString("access_token_lifetime=")
+	builder.WriteString(fmt.Sprintf("%v", o.AccessTokenLifetime))
+	builder.WriteString(", ")
+	builder.WriteString("refresh_token_lifetime=")
+	builder.WriteString(fmt.Sprintf("%v", o.RefreshTokenLifetime))
+	builder.WriteString(", ")
+	builder.WriteString("created_at=")
+	builder.WriteString(o.CreatedAt.Format(time.ANSIC))
+	builder.WriteString(", ")
+	builder.WriteString("updated_at=")
+	builder.WriteString(o.UpdatedAt.Format(time.ANSIC))
+	builder.WriteString(", ")
+	builder.WriteString("disabled=")
+	builder.WriteString(fmt.Sprintf("%v", o.Disabled))
+	builder.WriteString(", ")
+	builder.WriteString("allowed_connectors=")
+	builder.WriteString(fmt.Sprintf("%v", o.AllowedConnectors))
 	builder.WriteByte(')')
 	return builder.String()
 }
@@ -23,6 +23,18 @@ const (
 	FieldName = "name"
 	// FieldLogoURL holds the string denoting the logo_url field in the database.
 	FieldLogoURL = "logo_url"
+	// FieldAccessTokenLifetime holds the string denoting the access_token_lifetime field in the database.
+	FieldAccessTokenLifetime = "access_token_lifetime"
+	// FieldRefreshTokenLifetime holds the string denoting the refresh_token_lifetime field in the database.
+	FieldRefreshTokenLifetime = "refresh_token_lifetime"
+	// FieldCreatedAt holds the string denoting the created_at field in the database.
+	FieldCreatedAt = "created_at"
+	// FieldUpdatedAt holds the string denoting the updated_at field in the database.
+	FieldUpdatedAt = "updated_at"
+	// FieldDisabled holds the string denoting the disabled field in the database.
+	FieldDisabled = "disabled"
+	// FieldAllowedConnectors holds the string denoting the allowed_connectors field in the database.
+	FieldAllowedConnectors = "allowed_connectors"
 	// Table holds the table name of the oauth2client in the database.
 	Table = "oauth2clients"
 )
@@ -36,6 +48,12 @@ var Columns = []string{
 	FieldPublic,
 	FieldName,
 	FieldLogoURL,
+	FieldAccessTokenLifetime,
+	FieldRefreshTokenLifetime,
+	FieldCreatedAt,
+	FieldUpdatedAt,
+	FieldDisabled,
+	FieldAllowedConnectors,
 }
 
 // ValidColumn reports if the column name is valid (part of the table columns).
@@ -55,6 +73,12 @@ var (
 	NameValidator func(string) error
 	// LogoURLValidator is a validator for the "logo_url" field. It is called by the builders before save.
 	LogoURLValidator func(string) error
+	// DefaultAccessTokenLifetime holds the default value on creation for the "access_token_lifetime" field.
+	DefaultAccessTokenLifetime int64
+	// DefaultRefreshTokenLifetime holds the default value on creation for the "refresh_token_lifetime" field.
+	DefaultRefreshTokenLifetime int64
+	// DefaultDisabled holds the default value on creation for the "disabled" field.
+	DefaultDisabled bool
 	// IDValidator is a validator for the "id" field. It is called by the builders before save.
 	IDValidator func(string) error
 )
@@ -86,3 +110,28 @@ func ByName(opts ...sql.OrderTermOption) OrderOption {
 func ByLogoURL(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldLogoURL, opts...).ToFunc()
 }
+
+// ByAccessTokenLifetime orders the results by the access_token_lifetime field.
+func ByAccessTokenLifetime(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldAccessTokenLifetime, opts...).ToFunc()
+}
+
+// ByRefreshTokenLifetime orders the results by the refresh_token_lifetime field.
+func ByRefreshTokenLifetime(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldRefreshTokenLifetime, opts...).ToFunc()
+}
+
+// ByCreatedAt orders the results by the created_at field.
+func ByCreatedAt(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldCreatedAt, opts...).ToFunc()
+}
+
+// ByUpdatedAt orders the results by the updated_at field.
+func ByUpdatedAt(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldUpdatedAt, opts...).ToFunc()
+}
+
+// ByDisabled orders the results by the disabled field.
+func ByDisabled(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldDisabled, opts...).ToFunc()
+}
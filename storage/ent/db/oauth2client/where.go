@@ -3,6 +3,8 @@
 package oauth2client
 
 import (
+	"time"
+
 	"entgo.io/ent/dialect/sql"
 	"github.com/dexidp/dex/storage/ent/db/predicate"
 )
@@ -82,6 +84,31 @@ func LogoURL(v string) predicate.OAuth2Client {
 	return predicate.OAuth2Client(sql.FieldEQ(FieldLogoURL, v))
 }
 
+// AccessTokenLifetime applies equality check predicate on the "access_token_lifetime" field. It's identical to AccessTokenLifetimeEQ.
+func AccessTokenLifetime(v int64) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldEQ(FieldAccessTokenLifetime, v))
+}
+
+// RefreshTokenLifetime applies equality check predicate on the "refresh_token_lifetime" field. It's identical to RefreshTokenLifetimeEQ.
+func RefreshTokenLifetime(v int64) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldEQ(FieldRefreshTokenLifetime, v))
+}
+
+// CreatedAt applies equality check predicate on the "created_at" field. It's identical to CreatedAtEQ.
+func CreatedAt(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldEQ(FieldCreatedAt, v))
+}
+
+// UpdatedAt applies equality check predicate on the "updated_at" field. It's identical to UpdatedAtEQ.
+func UpdatedAt(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldEQ(FieldUpdatedAt, v))
+}
+
+// Disabled applies equality check predicate on the "disabled" field. It's identical to DisabledEQ.
+func Disabled(v bool) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldEQ(FieldDisabled, v))
+}
+
 // SecretEQ applies the EQ predicate on the "secret" field.
 func SecretEQ(v string) predicate.OAuth2Client {
 	return predicate.OAuth2Client(sql.FieldEQ(FieldSecret, v))
@@ -307,6 +334,206 @@ func LogoURLContainsFold(v string) predicate.OAuth2Client {
 	return predicate.OAuth2Client(sql.FieldContainsFold(FieldLogoURL, v))
 }
 
+// AccessTokenLifetimeEQ applies the EQ predicate on the "access_token_lifetime" field.
+func AccessTokenLifetimeEQ(v int64) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldEQ(FieldAccessTokenLifetime, v))
+}
+
+// AccessTokenLifetimeNEQ applies the NEQ predicate on the "access_token_lifetime" field.
+func AccessTokenLifetimeNEQ(v int64) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNEQ(FieldAccessTokenLifetime, v))
+}
+
+// AccessTokenLifetimeIn applies the In predicate on the "access_token_lifetime" field.
+func AccessTokenLifetimeIn(vs ...int64) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldIn(FieldAccessTokenLifetime, vs...))
+}
+
+// AccessTokenLifetimeNotIn applies the NotIn predicate on the "access_token_lifetime" field.
+func AccessTokenLifetimeNotIn(vs ...int64) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNotIn(FieldAccessTokenLifetime, vs...))
+}
+
+// AccessTokenLifetimeGT applies the GT predicate on the "access_token_lifetime" field.
+func AccessTokenLifetimeGT(v int64) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldGT(FieldAccessTokenLifetime, v))
+}
+
+// AccessTokenLifetimeGTE applies the GTE predicate on the "access_token_lifetime" field.
+func AccessTokenLifetimeGTE(v int64) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldGTE(FieldAccessTokenLifetime, v))
+}
+
+// AccessTokenLifetimeLT applies the LT predicate on the "access_token_lifetime" field.
+func AccessTokenLifetimeLT(v int64) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldLT(FieldAccessTokenLifetime, v))
+}
+
+// AccessTokenLifetimeLTE applies the LTE predicate on the "access_token_lifetime" field.
+func AccessTokenLifetimeLTE(v int64) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldLTE(FieldAccessTokenLifetime, v))
+}
+
+// RefreshTokenLifetimeEQ applies the EQ predicate on the "refresh_token_lifetime" field.
+func RefreshTokenLifetimeEQ(v int64) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldEQ(FieldRefreshTokenLifetime, v))
+}
+
+// RefreshTokenLifetimeNEQ applies the NEQ predicate on the "refresh_token_lifetime" field.
+func RefreshTokenLifetimeNEQ(v int64) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNEQ(FieldRefreshTokenLifetime, v))
+}
+
+// RefreshTokenLifetimeIn applies the In predicate on the "refresh_token_lifetime" field.
+func RefreshTokenLifetimeIn(vs ...int64) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldIn(FieldRefreshTokenLifetime, vs...))
+}
+
+// RefreshTokenLifetimeNotIn applies the NotIn predicate on the "refresh_token_lifetime" field.
+func RefreshTokenLifetimeNotIn(vs ...int64) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNotIn(FieldRefreshTokenLifetime, vs...))
+}
+
+// RefreshTokenLifetimeGT applies the GT predicate on the "refresh_token_lifetime" field.
+func RefreshTokenLifetimeGT(v int64) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldGT(FieldRefreshTokenLifetime, v))
+}
+
+// RefreshTokenLifetimeGTE applies the GTE predicate on the "refresh_token_lifetime" field.
+func RefreshTokenLifetimeGTE(v int64) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldGTE(FieldRefreshTokenLifetime, v))
+}
+
+// RefreshTokenLifetimeLT applies the LT predicate on the "refresh_token_lifetime" field.
+func RefreshTokenLifetimeLT(v int64) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldLT(FieldRefreshTokenLifetime, v))
+}
+
+// RefreshTokenLifetimeLTE applies the LTE predicate on the "refresh_token_lifetime" field.
+func RefreshTokenLifetimeLTE(v int64) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldLTE(FieldRefreshTokenLifetime, v))
+}
+
+// CreatedAtEQ applies the EQ predicate on the "created_at" field.
+func CreatedAtEQ(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldEQ(FieldCreatedAt, v))
+}
+
+// CreatedAtNEQ applies the NEQ predicate on the "created_at" field.
+func CreatedAtNEQ(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNEQ(FieldCreatedAt, v))
+}
+
+// CreatedAtIn applies the In predicate on the "created_at" field.
+func CreatedAtIn(vs ...time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldIn(FieldCreatedAt, vs...))
+}
+
+// CreatedAtNotIn applies the NotIn predicate on the "created_at" field.
+func CreatedAtNotIn(vs ...time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNotIn(FieldCreatedAt, vs...))
+}
+
+// CreatedAtGT applies the GT predicate on the "created_at" field.
+func CreatedAtGT(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldGT(FieldCreatedAt, v))
+}
+
+// CreatedAtGTE applies the GTE predicate on the "created_at" field.
+func CreatedAtGTE(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldGTE(FieldCreatedAt, v))
+}
+
+// CreatedAtLT applies the LT predicate on the "created_at" field.
+func CreatedAtLT(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldLT(FieldCreatedAt, v))
+}
+
+// CreatedAtLTE applies the LTE predicate on the "created_at" field.
+func CreatedAtLTE(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldLTE(FieldCreatedAt, v))
+}
+
+// CreatedAtIsNil applies the IsNil predicate on the "created_at" field.
+func CreatedAtIsNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldIsNull(FieldCreatedAt))
+}
+
+// CreatedAtNotNil applies the NotNil predicate on the "created_at" field.
+func CreatedAtNotNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNotNull(FieldCreatedAt))
+}
+
+// UpdatedAtEQ applies the EQ predicate on the "updated_at" field.
+func UpdatedAtEQ(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldEQ(FieldUpdatedAt, v))
+}
+
+// UpdatedAtNEQ applies the NEQ predicate on the "updated_at" field.
+func UpdatedAtNEQ(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNEQ(FieldUpdatedAt, v))
+}
+
+// UpdatedAtIn applies the In predicate on the "updated_at" field.
+func UpdatedAtIn(vs ...time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldIn(FieldUpdatedAt, vs...))
+}
+
+// UpdatedAtNotIn applies the NotIn predicate on the "updated_at" field.
+func UpdatedAtNotIn(vs ...time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNotIn(FieldUpdatedAt, vs...))
+}
+
+// UpdatedAtGT applies the GT predicate on the "updated_at" field.
+func UpdatedAtGT(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldGT(FieldUpdatedAt, v))
+}
+
+// UpdatedAtGTE applies the GTE predicate on the "updated_at" field.
+func UpdatedAtGTE(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldGTE(FieldUpdatedAt, v))
+}
+
+// UpdatedAtLT applies the LT predicate on the "updated_at" field.
+func UpdatedAtLT(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldLT(FieldUpdatedAt, v))
+}
+
+// UpdatedAtLTE applies the LTE predicate on the "updated_at" field.
+func UpdatedAtLTE(v time.Time) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldLTE(FieldUpdatedAt, v))
+}
+
+// UpdatedAtIsNil applies the IsNil predicate on the "updated_at" field.
+func UpdatedAtIsNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldIsNull(FieldUpdatedAt))
+}
+
+// UpdatedAtNotNil applies the NotNil predicate on the "updated_at" field.
+func UpdatedAtNotNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNotNull(FieldUpdatedAt))
+}
+
+// DisabledEQ applies the EQ predicate on the "disabled" field.
+func DisabledEQ(v bool) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldEQ(FieldDisabled, v))
+}
+
+// DisabledNEQ applies the NEQ predicate on the "disabled" field.
+func DisabledNEQ(v bool) predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNEQ(FieldDisabled, v))
+}
+
+// AllowedConnectorsIsNil applies the IsNil predicate on the "allowed_connectors" field.
+func AllowedConnectorsIsNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldIsNull(FieldAllowedConnectors))
+}
+
+// AllowedConnectorsNotNil applies the NotNil predicate on the "allowed_connectors" field.
+func AllowedConnectorsNotNil() predicate.OAuth2Client {
+	return predicate.OAuth2Client(sql.FieldNotNull(FieldAllowedConnectors))
+}
+
 // And groups predicates with the AND operator between them.
 func And(predicates ...predicate.OAuth2Client) predicate.OAuth2Client {
 	return predicate.OAuth2Client(sql.AndPredicates(predicates...))
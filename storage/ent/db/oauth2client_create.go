@@ -6,6 +6,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"entgo.io/ent/dialect/sql/sqlgraph"
 	"entgo.io/ent/schema/field"
@@ -55,6 +56,82 @@ func (oc *OAuth2ClientCreate) SetLogoURL(s string) *OAuth2ClientCreate {
 	return oc
 }
 
+// SetAccessTokenLifetime sets the "access_token_lifetime" field.
+func (oc *OAuth2ClientCreate) SetAccessTokenLifetime(i int64) *OAuth2ClientCreate {
+	oc.mutation.SetAccessTokenLifetime(i)
+	return oc
+}
+
+// SetNillableAccessTokenLifetime sets the "access_token_lifetime" field if the given value is not nil.
+func (oc *OAuth2ClientCreate) SetNillableAccessTokenLifetime(i *int64) *OAuth2ClientCreate {
+	if i != nil {
+		oc.SetAccessTokenLifetime(*i)
+	}
+	return oc
+}
+
+// SetRefreshTokenLifetime sets the "refresh_token_lifetime" field.
+func (oc *OAuth2ClientCreate) SetRefreshTokenLifetime(i int64) *OAuth2ClientCreate {
+	oc.mutation.SetRefreshTokenLifetime(i)
+	return oc
+}
+
+// SetNillableRefreshTokenLifetime sets the "refresh_token_lifetime" field if the given value is not nil.
+func (oc *OAuth2ClientCreate) SetNillableRefreshTokenLifetime(i *int64) *OAuth2ClientCreate {
+	if i != nil {
+		oc.SetRefreshTokenLifetime(*i)
+	}
+	return oc
+}
+
+// SetCreatedAt sets the "created_at" field.
+func (oc *OAuth2ClientCreate) SetCreatedAt(t time.Time) *OAuth2ClientCreate {
+	oc.mutation.SetCreatedAt(t)
+	return oc
+}
+
+// SetNillableCreatedAt sets the "created_at" field if the given value is not nil.
+func (oc *OAuth2ClientCreate) SetNillableCreatedAt(t *time.Time) *OAuth2ClientCreate {
+	if t != nil {
+		oc.SetCreatedAt(*t)
+	}
+	return oc
+}
+
+// SetUpdatedAt sets the "updated_at" field.
+func (oc *OAuth2ClientCreate) SetUpdatedAt(t time.Time) *OAuth2ClientCreate {
+	oc.mutation.SetUpdatedAt(t)
+	return oc
+}
+
+// SetNillableUpdatedAt sets the "updated_at" field if the given value is not nil.
+func (oc *OAuth2ClientCreate) SetNillableUpdatedAt(t *time.Time) *OAuth2ClientCreate {
+	if t != nil {
+		oc.SetUpdatedAt(*t)
+	}
+	return oc
+}
+
+// SetDisabled sets the "disabled" field.
+func (oc *OAuth2ClientCreate) SetDisabled(b bool) *OAuth2ClientCreate {
+	oc.mutation.SetDisabled(b)
+	return oc
+}
+
+// SetNillableDisabled sets the "disabled" field if the given value is not nil.
+func (oc *OAuth2ClientCreate) SetNillableDisabled(b *bool) *OAuth2ClientCreate {
+	if b != nil {
+		oc.SetDisabled(*b)
+	}
+	return oc
+}
+
+// SetAllowedConnectors sets the "allowed_connectors" field.
+func (oc *OAuth2ClientCreate) SetAllowedConnectors(s []string) *OAuth2ClientCreate {
+	oc.mutation.SetAllowedConnectors(s)
+	return oc
+}
+
 // SetID sets the "id" field.
 func (oc *OAuth2ClientCreate) SetID(s string) *OAuth2ClientCreate {
 	oc.mutation.SetID(s)
@@ -68,6 +145,7 @@ func (oc *OAuth2ClientCreate) Mutation() *OAuth2ClientMutation {
 
 // Save creates the OAuth2Client in the database.
 func (oc *OAuth2ClientCreate) Save(ctx context.Context) (*OAuth2Client, error) {
+	oc.defaults()
 	return withHooks(ctx, oc.sqlSave, oc.mutation, oc.hooks)
 }
 
@@ -93,6 +171,22 @@ func (oc *OAuth2ClientCreate) ExecX(ctx context.Context) {
 	}
 }
 
+// defaults sets the default values of the builder before save.
+func (oc *OAuth2ClientCreate) defaults() {
+	if _, ok := oc.mutation.AccessTokenLifetime(); !ok {
+		v := oauth2client.DefaultAccessTokenLifetime
+		oc.mutation.SetAccessTokenLifetime(v)
+	}
+	if _, ok := oc.mutation.RefreshTokenLifetime(); !ok {
+		v := oauth2client.DefaultRefreshTokenLifetime
+		oc.mutation.SetRefreshTokenLifetime(v)
+	}
+	if _, ok := oc.mutation.Disabled(); !ok {
+		v := oauth2client.DefaultDisabled
+		oc.mutation.SetDisabled(v)
+	}
+}
+
 // check runs all checks and user-defined validators on the builder.
 func (oc *OAuth2ClientCreate) check() error {
 	if _, ok := oc.mutation.Secret(); !ok {
@@ -122,6 +216,15 @@ func (oc *OAuth2ClientCreate) check() error {
 			return &ValidationError{Name: "logo_url", err: fmt.Errorf(`db: validator failed for field "OAuth2Client.logo_url": %w`, err)}
 		}
 	}
+	if _, ok := oc.mutation.AccessTokenLifetime(); !ok {
+		return &ValidationError{Name: "access_token_lifetime", err: errors.New(`db: missing required field "OAuth2Client.access_token_lifetime"`)}
+	}
+	if _, ok := oc.mutation.RefreshTokenLifetime(); !ok {
+		return &ValidationError{Name: "refresh_token_lifetime", err: errors.New(`db: missing required field "OAuth2Client.refresh_token_lifetime"`)}
+	}
+	if _, ok := oc.mutation.Disabled(); !ok {
+		return &ValidationError{Name: "disabled", err: errors.New(`db: missing required field "OAuth2Client.disabled"`)}
+	}
 	if v, ok := oc.mutation.ID(); ok {
 		if err := oauth2client.IDValidator(v); err != nil {
 			return &ValidationError{Name: "id", err: fmt.Errorf(`db: validator failed for field "OAuth2Client.id": %w`, err)}
@@ -186,6 +289,30 @@ func (oc *OAuth2ClientCreate) createSpec() (*OAuth2Client, *sqlgraph.CreateSpec)
 		_spec.SetField(oauth2client.FieldLogoURL, field.TypeString, value)
 		_node.LogoURL = value
 	}
+	if value, ok := oc.mutation.AccessTokenLifetime(); ok {
+		_spec.SetField(oauth2client.FieldAccessTokenLifetime, field.TypeInt64, value)
+		_node.AccessTokenLifetime = value
+	}
+	if value, ok := oc.mutation.RefreshTokenLifetime(); ok {
+		_spec.SetField(oauth2client.FieldRefreshTokenLifetime, field.TypeInt64, value)
+		_node.RefreshTokenLifetime = value
+	}
+	if value, ok := oc.mutation.CreatedAt(); ok {
+		_spec.SetField(oauth2client.FieldCreatedAt, field.TypeTime, value)
+		_node.CreatedAt = value
+	}
+	if value, ok := oc.mutation.UpdatedAt(); ok {
+		_spec.SetField(oauth2client.FieldUpdatedAt, field.TypeTime, value)
+		_node.UpdatedAt = value
+	}
+	if value, ok := oc.mutation.Disabled(); ok {
+		_spec.SetField(oauth2client.FieldDisabled, field.TypeBool, value)
+		_node.Disabled = value
+	}
+	if value, ok := oc.mutation.AllowedConnectors(); ok {
+		_spec.SetField(oauth2client.FieldAllowedConnectors, field.TypeJSON, value)
+		_node.AllowedConnectors = value
+	}
 	return _node, _spec
 }
 
@@ -207,6 +334,7 @@ func (ocb *OAuth2ClientCreateBulk) Save(ctx context.Context) ([]*OAuth2Client, e
 	for i := range ocb.builders {
 		func(i int, root context.Context) {
 			builder := ocb.builders[i]
+			builder.defaults()
 			var mut Mutator = MutateFunc(func(ctx context.Context, m Mutation) (Value, error) {
 				mutation, ok := m.(*OAuth2ClientMutation)
 				if !ok {
@@ -6,6 +6,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"entgo.io/ent/dialect/sql"
 	"entgo.io/ent/dialect/sql/sqlgraph"
@@ -120,6 +121,120 @@ func (ou *OAuth2ClientUpdate) SetNillableLogoURL(s *string) *OAuth2ClientUpdate
 	return ou
 }
 
+// SetAccessTokenLifetime sets the "access_token_lifetime" field.
+func (ou *OAuth2ClientUpdate) SetAccessTokenLifetime(i int64) *OAuth2ClientUpdate {
+	ou.mutation.ResetAccessTokenLifetime()
+	ou.mutation.SetAccessTokenLifetime(i)
+	return ou
+}
+
+// SetNillableAccessTokenLifetime sets the "access_token_lifetime" field if the given value is not nil.
+func (ou *OAuth2ClientUpdate) SetNillableAccessTokenLifetime(i *int64) *OAuth2ClientUpdate {
+	if i != nil {
+		ou.SetAccessTokenLifetime(*i)
+	}
+	return ou
+}
+
+// AddAccessTokenLifetime adds i to the "access_token_lifetime" field.
+func (ou *OAuth2ClientUpdate) AddAccessTokenLifetime(i int64) *OAuth2ClientUpdate {
+	ou.mutation.AddAccessTokenLifetime(i)
+	return ou
+}
+
+// SetRefreshTokenLifetime sets the "refresh_token_lifetime" field.
+func (ou *OAuth2ClientUpdate) SetRefreshTokenLifetime(i int64) *OAuth2ClientUpdate {
+	ou.mutation.ResetRefreshTokenLifetime()
+	ou.mutation.SetRefreshTokenLifetime(i)
+	return ou
+}
+
+// SetNillableRefreshTokenLifetime sets the "refresh_token_lifetime" field if the given value is not nil.
+func (ou *OAuth2ClientUpdate) SetNillableRefreshTokenLifetime(i *int64) *OAuth2ClientUpdate {
+	if i != nil {
+		ou.SetRefreshTokenLifetime(*i)
+	}
+	return ou
+}
+
+// AddRefreshTokenLifetime adds i to the "refresh_token_lifetime" field.
+func (ou *OAuth2ClientUpdate) AddRefreshTokenLifetime(i int64) *OAuth2ClientUpdate {
+	ou.mutation.AddRefreshTokenLifetime(i)
+	return ou
+}
+
+// SetCreatedAt sets the "created_at" field.
+func (ou *OAuth2ClientUpdate) SetCreatedAt(t time.Time) *OAuth2ClientUpdate {
+	ou.mutation.SetCreatedAt(t)
+	return ou
+}
+
+// SetNillableCreatedAt sets the "created_at" field if the given value is not nil.
+func (ou *OAuth2ClientUpdate) SetNillableCreatedAt(t *time.Time) *OAuth2ClientUpdate {
+	if t != nil {
+		ou.SetCreatedAt(*t)
+	}
+	return ou
+}
+
+// ClearCreatedAt clears the value of the "created_at" field.
+func (ou *OAuth2ClientUpdate) ClearCreatedAt() *OAuth2ClientUpdate {
+	ou.mutation.ClearCreatedAt()
+	return ou
+}
+
+// SetUpdatedAt sets the "updated_at" field.
+func (ou *OAuth2ClientUpdate) SetUpdatedAt(t time.Time) *OAuth2ClientUpdate {
+	ou.mutation.SetUpdatedAt(t)
+	return ou
+}
+
+// SetNillableUpdatedAt sets the "updated_at" field if the given value is not nil.
+func (ou *OAuth2ClientUpdate) SetNillableUpdatedAt(t *time.Time) *OAuth2ClientUpdate {
+	if t != nil {
+		ou.SetUpdatedAt(*t)
+	}
+	return ou
+}
+
+// ClearUpdatedAt clears the value of the "updated_at" field.
+func (ou *OAuth2ClientUpdate) ClearUpdatedAt() *OAuth2ClientUpdate {
+	ou.mutation.ClearUpdatedAt()
+	return ou
+}
+
+// SetDisabled sets the "disabled" field.
+func (ou *OAuth2ClientUpdate) SetDisabled(b bool) *OAuth2ClientUpdate {
+	ou.mutation.SetDisabled(b)
+	return ou
+}
+
+// SetNillableDisabled sets the "disabled" field if the given value is not nil.
+func (ou *OAuth2ClientUpdate) SetNillableDisabled(b *bool) *OAuth2ClientUpdate {
+	if b != nil {
+		ou.SetDisabled(*b)
+	}
+	return ou
+}
+
+// SetAllowedConnectors sets the "allowed_connectors" field.
+func (ou *OAuth2ClientUpdate) SetAllowedConnectors(s []string) *OAuth2ClientUpdate {
+	ou.mutation.SetAllowedConnectors(s)
+	return ou
+}
+
+// AppendAllowedConnectors appends s to the "allowed_connectors" field.
+func (ou *OAuth2ClientUpdate) AppendAllowedConnectors(s []string) *OAuth2ClientUpdate {
+	ou.mutation.AppendAllowedConnectors(s)
+	return ou
+}
+
+// ClearAllowedConnectors clears the value of the "allowed_connectors" field.
+func (ou *OAuth2ClientUpdate) ClearAllowedConnectors() *OAuth2ClientUpdate {
+	ou.mutation.ClearAllowedConnectors()
+	return ou
+}
+
 // Mutation returns the OAuth2ClientMutation object of the builder.
 func (ou *OAuth2ClientUpdate) Mutation() *OAuth2ClientMutation {
 	return ou.mutation
@@ -218,6 +333,44 @@ func (ou *OAuth2ClientUpdate) sqlSave(ctx context.Context) (n int, err error) {
 	if value, ok := ou.mutation.LogoURL(); ok {
 		_spec.SetField(oauth2client.FieldLogoURL, field.TypeString, value)
 	}
+	if value, ok := ou.mutation.AccessTokenLifetime(); ok {
+		_spec.SetField(oauth2client.FieldAccessTokenLifetime, field.TypeInt64, value)
+	}
+	if value, ok := ou.mutation.AddedAccessTokenLifetime(); ok {
+		_spec.AddField(oauth2client.FieldAccessTokenLifetime, field.TypeInt64, value)
+	}
+	if value, ok := ou.mutation.RefreshTokenLifetime(); ok {
+		_spec.SetField(oauth2client.FieldRefreshTokenLifetime, field.TypeInt64, value)
+	}
+	if value, ok := ou.mutation.AddedRefreshTokenLifetime(); ok {
+		_spec.AddField(oauth2client.FieldRefreshTokenLifetime, field.TypeInt64, value)
+	}
+	if value, ok := ou.mutation.CreatedAt(); ok {
+		_spec.SetField(oauth2client.FieldCreatedAt, field.TypeTime, value)
+	}
+	if ou.mutation.CreatedAtCleared() {
+		_spec.ClearField(oauth2client.FieldCreatedAt, field.TypeTime)
+	}
+	if value, ok := ou.mutation.UpdatedAt(); ok {
+		_spec.SetField(oauth2client.FieldUpdatedAt, field.TypeTime, value)
+	}
+	if ou.mutation.UpdatedAtCleared() {
+		_spec.ClearField(oauth2client.FieldUpdatedAt, field.TypeTime)
+	}
+	if value, ok := ou.mutation.Disabled(); ok {
+		_spec.SetField(oauth2client.FieldDisabled, field.TypeBool, value)
+	}
+	if value, ok := ou.mutation.AllowedConnectors(); ok {
+		_spec.SetField(oauth2client.FieldAllowedConnectors, field.TypeJSON, value)
+	}
+	if value, ok := ou.mutation.AppendedAllowedConnectors(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, oauth2client.FieldAllowedConnectors, value)
+		})
+	}
+	if ou.mutation.AllowedConnectorsCleared() {
+		_spec.ClearField(oauth2client.FieldAllowedConnectors, field.TypeJSON)
+	}
 	if n, err = sqlgraph.UpdateNodes(ctx, ou.driver, _spec); err != nil {
 		if _, ok := err.(*sqlgraph.NotFoundError); ok {
 			err = &NotFoundError{oauth2client.Label}
@@ -330,6 +483,120 @@ func (ouo *OAuth2ClientUpdateOne) SetNillableLogoURL(s *string) *OAuth2ClientUpd
 	return ouo
 }
 
+// SetAccessTokenLifetime sets the "access_token_lifetime" field.
+func (ouo *OAuth2ClientUpdateOne) SetAccessTokenLifetime(i int64) *OAuth2ClientUpdateOne {
+	ouo.mutation.ResetAccessTokenLifetime()
+	ouo.mutation.SetAccessTokenLifetime(i)
+	return ouo
+}
+
+// SetNillableAccessTokenLifetime sets the "access_token_lifetime" field if the given value is not nil.
+func (ouo *OAuth2ClientUpdateOne) SetNillableAccessTokenLifetime(i *int64) *OAuth2ClientUpdateOne {
+	if i != nil {
+		ouo.SetAccessTokenLifetime(*i)
+	}
+	return ouo
+}
+
+// AddAccessTokenLifetime adds i to the "access_token_lifetime" field.
+func (ouo *OAuth2ClientUpdateOne) AddAccessTokenLifetime(i int64) *OAuth2ClientUpdateOne {
+	ouo.mutation.AddAccessTokenLifetime(i)
+	return ouo
+}
+
+// SetRefreshTokenLifetime sets the "refresh_token_lifetime" field.
+func (ouo *OAuth2ClientUpdateOne) SetRefreshTokenLifetime(i int64) *OAuth2ClientUpdateOne {
+	ouo.mutation.ResetRefreshTokenLifetime()
+	ouo.mutation.SetRefreshTokenLifetime(i)
+	return ouo
+}
+
+// SetNillableRefreshTokenLifetime sets the "refresh_token_lifetime" field if the given value is not nil.
+func (ouo *OAuth2ClientUpdateOne) SetNillableRefreshTokenLifetime(i *int64) *OAuth2ClientUpdateOne {
+	if i != nil {
+		ouo.SetRefreshTokenLifetime(*i)
+	}
+	return ouo
+}
+
+// AddRefreshTokenLifetime adds i to the "refresh_token_lifetime" field.
+func (ouo *OAuth2ClientUpdateOne) AddRefreshTokenLifetime(i int64) *OAuth2ClientUpdateOne {
+	ouo.mutation.AddRefreshTokenLifetime(i)
+	return ouo
+}
+
+// SetCreatedAt sets the "created_at" field.
+func (ouo *OAuth2ClientUpdateOne) SetCreatedAt(t time.Time) *OAuth2ClientUpdateOne {
+	ouo.mutation.SetCreatedAt(t)
+	return ouo
+}
+
+// SetNillableCreatedAt sets the "created_at" field if the given value is not nil.
+func (ouo *OAuth2ClientUpdateOne) SetNillableCreatedAt(t *time.Time) *OAuth2ClientUpdateOne {
+	if t != nil {
+		ouo.SetCreatedAt(*t)
+	}
+	return ouo
+}
+
+// ClearCreatedAt clears the value of the "created_at" field.
+func (ouo *OAuth2ClientUpdateOne) ClearCreatedAt() *OAuth2ClientUpdateOne {
+	ouo.mutation.ClearCreatedAt()
+	return ouo
+}
+
+// SetUpdatedAt sets the "updated_at" field.
+func (ouo *OAuth2ClientUpdateOne) SetUpdatedAt(t time.Time) *OAuth2ClientUpdateOne {
+	ouo.mutation.SetUpdatedAt(t)
+	return ouo
+}
+
+// SetNillableUpdatedAt sets the "updated_at" field if the given value is not nil.
+func (ouo *OAuth2ClientUpdateOne) SetNillableUpdatedAt(t *time.Time) *OAuth2ClientUpdateOne {
+	if t != nil {
+		ouo.SetUpdatedAt(*t)
+	}
+	return ouo
+}
+
+// ClearUpdatedAt clears the value of the "updated_at" field.
+func (ouo *OAuth2ClientUpdateOne) ClearUpdatedAt() *OAuth2ClientUpdateOne {
+	ouo.mutation.ClearUpdatedAt()
+	return ouo
+}
+
+// SetDisabled sets the "disabled" field.
+func (ouo *OAuth2ClientUpdateOne) SetDisabled(b bool) *OAuth2ClientUpdateOne {
+	ouo.mutation.SetDisabled(b)
+	return ouo
+}
+
+// SetNillableDisabled sets the "disabled" field if the given value is not nil.
+func (ouo *OAuth2ClientUpdateOne) SetNillableDisabled(b *bool) *OAuth2ClientUpdateOne {
+	if b != nil {
+		ouo.SetDisabled(*b)
+	}
+	return ouo
+}
+
+// SetAllowedConnectors sets the "allowed_connectors" field.
+func (ouo *OAuth2ClientUpdateOne) SetAllowedConnectors(s []string) *OAuth2ClientUpdateOne {
+	ouo.mutation.SetAllowedConnectors(s)
+	return ouo
+}
+
+// AppendAllowedConnectors appends s to the "allowed_connectors" field.
+func (ouo *OAuth2ClientUpdateOne) AppendAllowedConnectors(s []string) *OAuth2ClientUpdateOne {
+	ouo.mutation.AppendAllowedConnectors(s)
+	return ouo
+}
+
+// ClearAllowedConnectors clears the value of the "allowed_connectors" field.
+func (ouo *OAuth2ClientUpdateOne) ClearAllowedConnectors() *OAuth2ClientUpdateOne {
+	ouo.mutation.ClearAllowedConnectors()
+	return ouo
+}
+
 // Mutation returns the OAuth2ClientMutation object of the builder.
 func (ouo *OAuth2ClientUpdateOne) Mutation() *OAuth2ClientMutation {
 	return ouo.mutation
@@ -458,6 +725,44 @@ func (ouo *OAuth2ClientUpdateOne) sqlSave(ctx context.Context) (_node *OAuth2Cli
 	if value, ok := ouo.mutation.LogoURL(); ok {
 		_spec.SetField(oauth2client.FieldLogoURL, field.TypeString, value)
 	}
+	if value, ok := ouo.mutation.AccessTokenLifetime(); ok {
+		_spec.SetField(oauth2client.FieldAccessTokenLifetime, field.TypeInt64, value)
+	}
+	if value, ok := ouo.mutation.AddedAccessTokenLifetime(); ok {
+		_spec.AddField(oauth2client.FieldAccessTokenLifetime, field.TypeInt64, value)
+	}
+	if value, ok := ouo.mutation.RefreshTokenLifetime(); ok {
+		_spec.SetField(oauth2client.FieldRefreshTokenLifetime, field.TypeInt64, value)
+	}
+	if value, ok := ouo.mutation.AddedRefreshTokenLifetime(); ok {
+		_spec.AddField(oauth2client.FieldRefreshTokenLifetime, field.TypeInt64, value)
+	}
+	if value, ok := ouo.mutation.CreatedAt(); ok {
+		_spec.SetField(oauth2client.FieldCreatedAt, field.TypeTime, value)
+	}
+	if ouo.mutation.CreatedAtCleared() {
+		_spec.ClearField(oauth2client.FieldCreatedAt, field.TypeTime)
+	}
+	if value, ok := ouo.mutation.UpdatedAt(); ok {
+		_spec.SetField(oauth2client.FieldUpdatedAt, field.TypeTime, value)
+	}
+	if ouo.mutation.UpdatedAtCleared() {
+		_spec.ClearField(oauth2client.FieldUpdatedAt, field.TypeTime)
+	}
+	if value, ok := ouo.mutation.Disabled(); ok {
+		_spec.SetField(oauth2client.FieldDisabled, field.TypeBool, value)
+	}
+	if value, ok := ouo.mutation.AllowedConnectors(); ok {
+		_spec.SetField(oauth2client.FieldAllowedConnectors, field.TypeJSON, value)
+	}
+	if value, ok := ouo.mutation.AppendedAllowedConnectors(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, oauth2client.FieldAllowedConnectors, value)
+		})
+	}
+	if ouo.mutation.AllowedConnectorsCleared() {
+		_spec.ClearField(oauth2client.FieldAllowedConnectors, field.TypeJSON)
+	}
 	_node = &OAuth2Client{config: ouo.config}
 	_spec.Assign = _node.assignValues
 	_spec.ScanValues = _node.scanValues
@@ -21,6 +21,9 @@ type DeviceRequest func(*sql.Selector)
 // DeviceToken is the predicate function for devicetoken builders.
 type DeviceToken func(*sql.Selector)
 
+// IdempotencyKey is the predicate function for idempotencykey builders.
+type IdempotencyKey func(*sql.Selector)
+
 // Keys is the predicate function for keys builders.
 type Keys func(*sql.Selector)
 
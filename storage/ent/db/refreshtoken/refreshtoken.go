@@ -29,6 +29,8 @@ const (
 	FieldClaimsEmailVerified = "claims_email_verified"
 	// FieldClaimsGroups holds the string denoting the claims_groups field in the database.
 	FieldClaimsGroups = "claims_groups"
+	// FieldClaimsExtra holds the string denoting the claims_extra field in the database.
+	FieldClaimsExtra = "claims_extra"
 	// FieldClaimsPreferredUsername holds the string denoting the claims_preferred_username field in the database.
 	FieldClaimsPreferredUsername = "claims_preferred_username"
 	// FieldConnectorID holds the string denoting the connector_id field in the database.
@@ -43,6 +45,8 @@ const (
 	FieldCreatedAt = "created_at"
 	// FieldLastUsed holds the string denoting the last_used field in the database.
 	FieldLastUsed = "last_used"
+	// FieldConsumed holds the string denoting the consumed field in the database.
+	FieldConsumed = "consumed"
 	// Table holds the table name of the refreshtoken in the database.
 	Table = "refresh_tokens"
 )
@@ -58,6 +62,7 @@ var Columns = []string{
 	FieldClaimsEmail,
 	FieldClaimsEmailVerified,
 	FieldClaimsGroups,
+	FieldClaimsExtra,
 	FieldClaimsPreferredUsername,
 	FieldConnectorID,
 	FieldConnectorData,
@@ -65,6 +70,7 @@ var Columns = []string{
 	FieldObsoleteToken,
 	FieldCreatedAt,
 	FieldLastUsed,
+	FieldConsumed,
 }
 
 // ValidColumn reports if the column name is valid (part of the table columns).
@@ -100,6 +106,8 @@ var (
 	DefaultCreatedAt func() time.Time
 	// DefaultLastUsed holds the default value on creation for the "last_used" field.
 	DefaultLastUsed func() time.Time
+	// DefaultConsumed holds the default value on creation for the "consumed" field.
+	DefaultConsumed bool
 	// IDValidator is a validator for the "id" field. It is called by the builders before save.
 	IDValidator func(string) error
 )
@@ -171,3 +179,8 @@ func ByCreatedAt(opts ...sql.OrderTermOption) OrderOption {
 func ByLastUsed(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldLastUsed, opts...).ToFunc()
 }
+
+// ByConsumed orders the results by the consumed field.
+func ByConsumed(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldConsumed, opts...).ToFunc()
+}
@@ -68,6 +68,12 @@ func (rtc *RefreshTokenCreate) SetClaimsGroups(s []string) *RefreshTokenCreate {
 	return rtc
 }
 
+// SetClaimsExtra sets the "claims_extra" field.
+func (rtc *RefreshTokenCreate) SetClaimsExtra(m map[string]interface{}) *RefreshTokenCreate {
+	rtc.mutation.SetClaimsExtra(m)
+	return rtc
+}
+
 // SetClaimsPreferredUsername sets the "claims_preferred_username" field.
 func (rtc *RefreshTokenCreate) SetClaimsPreferredUsername(s string) *RefreshTokenCreate {
 	rtc.mutation.SetClaimsPreferredUsername(s)
@@ -150,6 +156,20 @@ func (rtc *RefreshTokenCreate) SetNillableLastUsed(t *time.Time) *RefreshTokenCr
 	return rtc
 }
 
+// SetConsumed sets the "consumed" field.
+func (rtc *RefreshTokenCreate) SetConsumed(b bool) *RefreshTokenCreate {
+	rtc.mutation.SetConsumed(b)
+	return rtc
+}
+
+// SetNillableConsumed sets the "consumed" field if the given value is not nil.
+func (rtc *RefreshTokenCreate) SetNillableConsumed(b *bool) *RefreshTokenCreate {
+	if b != nil {
+		rtc.SetConsumed(*b)
+	}
+	return rtc
+}
+
 // SetID sets the "id" field.
 func (rtc *RefreshTokenCreate) SetID(s string) *RefreshTokenCreate {
 	rtc.mutation.SetID(s)
@@ -211,6 +231,10 @@ func (rtc *RefreshTokenCreate) defaults() {
 		v := refreshtoken.DefaultLastUsed()
 		rtc.mutation.SetLastUsed(v)
 	}
+	if _, ok := rtc.mutation.Consumed(); !ok {
+		v := refreshtoken.DefaultConsumed
+		rtc.mutation.SetConsumed(v)
+	}
 }
 
 // check runs all checks and user-defined validators on the builder.
@@ -281,6 +305,9 @@ func (rtc *RefreshTokenCreate) check() error {
 	if _, ok := rtc.mutation.LastUsed(); !ok {
 		return &ValidationError{Name: "last_used", err: errors.New(`db: missing required field "RefreshToken.last_used"`)}
 	}
+	if _, ok := rtc.mutation.Consumed(); !ok {
+		return &ValidationError{Name: "consumed", err: errors.New(`db: missing required field "RefreshToken.consumed"`)}
+	}
 	if v, ok := rtc.mutation.ID(); ok {
 		if err := refreshtoken.IDValidator(v); err != nil {
 			return &ValidationError{Name: "id", err: fmt.Errorf(`db: validator failed for field "RefreshToken.id": %w`, err)}
@@ -353,6 +380,10 @@ func (rtc *RefreshTokenCreate) createSpec() (*RefreshToken, *sqlgraph.CreateSpec
 		_spec.SetField(refreshtoken.FieldClaimsGroups, field.TypeJSON, value)
 		_node.ClaimsGroups = value
 	}
+	if value, ok := rtc.mutation.ClaimsExtra(); ok {
+		_spec.SetField(refreshtoken.FieldClaimsExtra, field.TypeJSON, value)
+		_node.ClaimsExtra = value
+	}
 	if value, ok := rtc.mutation.ClaimsPreferredUsername(); ok {
 		_spec.SetField(refreshtoken.FieldClaimsPreferredUsername, field.TypeString, value)
 		_node.ClaimsPreferredUsername = value
@@ -381,6 +412,10 @@ func (rtc *RefreshTokenCreate) createSpec() (*RefreshToken, *sqlgraph.CreateSpec
 		_spec.SetField(refreshtoken.FieldLastUsed, field.TypeTime, value)
 		_node.LastUsed = value
 	}
+	if value, ok := rtc.mutation.Consumed(); ok {
+		_spec.SetField(refreshtoken.FieldConsumed, field.TypeBool, value)
+		_node.Consumed = value
+	}
 	return _node, _spec
 }
 
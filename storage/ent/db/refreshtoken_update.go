@@ -149,6 +149,18 @@ func (rtu *RefreshTokenUpdate) ClearClaimsGroups() *RefreshTokenUpdate {
 	return rtu
 }
 
+// SetClaimsExtra sets the "claims_extra" field.
+func (rtu *RefreshTokenUpdate) SetClaimsExtra(m map[string]interface{}) *RefreshTokenUpdate {
+	rtu.mutation.SetClaimsExtra(m)
+	return rtu
+}
+
+// ClearClaimsExtra clears the value of the "claims_extra" field.
+func (rtu *RefreshTokenUpdate) ClearClaimsExtra() *RefreshTokenUpdate {
+	rtu.mutation.ClearClaimsExtra()
+	return rtu
+}
+
 // SetClaimsPreferredUsername sets the "claims_preferred_username" field.
 func (rtu *RefreshTokenUpdate) SetClaimsPreferredUsername(s string) *RefreshTokenUpdate {
 	rtu.mutation.SetClaimsPreferredUsername(s)
@@ -245,6 +257,20 @@ func (rtu *RefreshTokenUpdate) SetNillableLastUsed(t *time.Time) *RefreshTokenUp
 	return rtu
 }
 
+// SetConsumed sets the "consumed" field.
+func (rtu *RefreshTokenUpdate) SetConsumed(b bool) *RefreshTokenUpdate {
+	rtu.mutation.SetConsumed(b)
+	return rtu
+}
+
+// SetNillableConsumed sets the "consumed" field if the given value is not nil.
+func (rtu *RefreshTokenUpdate) SetNillableConsumed(b *bool) *RefreshTokenUpdate {
+	if b != nil {
+		rtu.SetConsumed(*b)
+	}
+	return rtu
+}
+
 // Mutation returns the RefreshTokenMutation object of the builder.
 func (rtu *RefreshTokenUpdate) Mutation() *RefreshTokenMutation {
 	return rtu.mutation
@@ -364,6 +390,12 @@ func (rtu *RefreshTokenUpdate) sqlSave(ctx context.Context) (n int, err error) {
 	if rtu.mutation.ClaimsGroupsCleared() {
 		_spec.ClearField(refreshtoken.FieldClaimsGroups, field.TypeJSON)
 	}
+	if value, ok := rtu.mutation.ClaimsExtra(); ok {
+		_spec.SetField(refreshtoken.FieldClaimsExtra, field.TypeJSON, value)
+	}
+	if rtu.mutation.ClaimsExtraCleared() {
+		_spec.ClearField(refreshtoken.FieldClaimsExtra, field.TypeJSON)
+	}
 	if value, ok := rtu.mutation.ClaimsPreferredUsername(); ok {
 		_spec.SetField(refreshtoken.FieldClaimsPreferredUsername, field.TypeString, value)
 	}
@@ -388,6 +420,9 @@ func (rtu *RefreshTokenUpdate) sqlSave(ctx context.Context) (n int, err error) {
 	if value, ok := rtu.mutation.LastUsed(); ok {
 		_spec.SetField(refreshtoken.FieldLastUsed, field.TypeTime, value)
 	}
+	if value, ok := rtu.mutation.Consumed(); ok {
+		_spec.SetField(refreshtoken.FieldConsumed, field.TypeBool, value)
+	}
 	if n, err = sqlgraph.UpdateNodes(ctx, rtu.driver, _spec); err != nil {
 		if _, ok := err.(*sqlgraph.NotFoundError); ok {
 			err = &NotFoundError{refreshtoken.Label}
@@ -528,6 +563,18 @@ func (rtuo *RefreshTokenUpdateOne) ClearClaimsGroups() *RefreshTokenUpdateOne {
 	return rtuo
 }
 
+// SetClaimsExtra sets the "claims_extra" field.
+func (rtuo *RefreshTokenUpdateOne) SetClaimsExtra(m map[string]interface{}) *RefreshTokenUpdateOne {
+	rtuo.mutation.SetClaimsExtra(m)
+	return rtuo
+}
+
+// ClearClaimsExtra clears the value of the "claims_extra" field.
+func (rtuo *RefreshTokenUpdateOne) ClearClaimsExtra() *RefreshTokenUpdateOne {
+	rtuo.mutation.ClearClaimsExtra()
+	return rtuo
+}
+
 // SetClaimsPreferredUsername sets the "claims_preferred_username" field.
 func (rtuo *RefreshTokenUpdateOne) SetClaimsPreferredUsername(s string) *RefreshTokenUpdateOne {
 	rtuo.mutation.SetClaimsPreferredUsername(s)
@@ -624,6 +671,20 @@ func (rtuo *RefreshTokenUpdateOne) SetNillableLastUsed(t *time.Time) *RefreshTok
 	return rtuo
 }
 
+// SetConsumed sets the "consumed" field.
+func (rtuo *RefreshTokenUpdateOne) SetConsumed(b bool) *RefreshTokenUpdateOne {
+	rtuo.mutation.SetConsumed(b)
+	return rtuo
+}
+
+// SetNillableConsumed sets the "consumed" field if the given value is not nil.
+func (rtuo *RefreshTokenUpdateOne) SetNillableConsumed(b *bool) *RefreshTokenUpdateOne {
+	if b != nil {
+		rtuo.SetConsumed(*b)
+	}
+	return rtuo
+}
+
 // Mutation returns the RefreshTokenMutation object of the builder.
 func (rtuo *RefreshTokenUpdateOne) Mutation() *RefreshTokenMutation {
 	return rtuo.mutation
@@ -773,6 +834,12 @@ func (rtuo *RefreshTokenUpdateOne) sqlSave(ctx context.Context) (_node *RefreshT
 	if rtuo.mutation.ClaimsGroupsCleared() {
 		_spec.ClearField(refreshtoken.FieldClaimsGroups, field.TypeJSON)
 	}
+	if value, ok := rtuo.mutation.ClaimsExtra(); ok {
+		_spec.SetField(refreshtoken.FieldClaimsExtra, field.TypeJSON, value)
+	}
+	if rtuo.mutation.ClaimsExtraCleared() {
+		_spec.ClearField(refreshtoken.FieldClaimsExtra, field.TypeJSON)
+	}
 	if value, ok := rtuo.mutation.ClaimsPreferredUsername(); ok {
 		_spec.SetField(refreshtoken.FieldClaimsPreferredUsername, field.TypeString, value)
 	}
@@ -797,6 +864,9 @@ func (rtuo *RefreshTokenUpdateOne) sqlSave(ctx context.Context) (_node *RefreshT
 	if value, ok := rtuo.mutation.LastUsed(); ok {
 		_spec.SetField(refreshtoken.FieldLastUsed, field.TypeTime, value)
 	}
+	if value, ok := rtuo.mutation.Consumed(); ok {
+		_spec.SetField(refreshtoken.FieldConsumed, field.TypeBool, value)
+	}
 	_node = &RefreshToken{config: rtuo.config}
 	_spec.Assign = _node.assignValues
 	_spec.ScanValues = _node.scanValues
@@ -10,6 +10,7 @@ import (
 	"github.com/dexidp/dex/storage/ent/db/connector"
 	"github.com/dexidp/dex/storage/ent/db/devicerequest"
 	"github.com/dexidp/dex/storage/ent/db/devicetoken"
+	"github.com/dexidp/dex/storage/ent/db/idempotencykey"
 	"github.com/dexidp/dex/storage/ent/db/keys"
 	"github.com/dexidp/dex/storage/ent/db/oauth2client"
 	"github.com/dexidp/dex/storage/ent/db/offlinesession"
@@ -49,19 +50,19 @@ func init() {
 	// authcode.ClaimsEmailValidator is a validator for the "claims_email" field. It is called by the builders before save.
 	authcode.ClaimsEmailValidator = authcodeDescClaimsEmail.Validators[0].(func(string) error)
 	// authcodeDescClaimsPreferredUsername is the schema descriptor for claims_preferred_username field.
-	authcodeDescClaimsPreferredUsername := authcodeFields[10].Descriptor()
+	authcodeDescClaimsPreferredUsername := authcodeFields[11].Descriptor()
 	// authcode.DefaultClaimsPreferredUsername holds the default value on creation for the claims_preferred_username field.
 	authcode.DefaultClaimsPreferredUsername = authcodeDescClaimsPreferredUsername.Default.(string)
 	// authcodeDescConnectorID is the schema descriptor for connector_id field.
-	authcodeDescConnectorID := authcodeFields[11].Descriptor()
+	authcodeDescConnectorID := authcodeFields[12].Descriptor()
 	// authcode.ConnectorIDValidator is a validator for the "connector_id" field. It is called by the builders before save.
 	authcode.ConnectorIDValidator = authcodeDescConnectorID.Validators[0].(func(string) error)
 	// authcodeDescCodeChallenge is the schema descriptor for code_challenge field.
-	authcodeDescCodeChallenge := authcodeFields[14].Descriptor()
+	authcodeDescCodeChallenge := authcodeFields[15].Descriptor()
 	// authcode.DefaultCodeChallenge holds the default value on creation for the code_challenge field.
 	authcode.DefaultCodeChallenge = authcodeDescCodeChallenge.Default.(string)
 	// authcodeDescCodeChallengeMethod is the schema descriptor for code_challenge_method field.
-	authcodeDescCodeChallengeMethod := authcodeFields[15].Descriptor()
+	authcodeDescCodeChallengeMethod := authcodeFields[16].Descriptor()
 	// authcode.DefaultCodeChallengeMethod holds the default value on creation for the code_challenge_method field.
 	authcode.DefaultCodeChallengeMethod = authcodeDescCodeChallengeMethod.Default.(string)
 	// authcodeDescID is the schema descriptor for id field.
@@ -71,15 +72,15 @@ func init() {
 	authrequestFields := schema.AuthRequest{}.Fields()
 	_ = authrequestFields
 	// authrequestDescClaimsPreferredUsername is the schema descriptor for claims_preferred_username field.
-	authrequestDescClaimsPreferredUsername := authrequestFields[14].Descriptor()
+	authrequestDescClaimsPreferredUsername := authrequestFields[15].Descriptor()
 	// authrequest.DefaultClaimsPreferredUsername holds the default value on creation for the claims_preferred_username field.
 	authrequest.DefaultClaimsPreferredUsername = authrequestDescClaimsPreferredUsername.Default.(string)
 	// authrequestDescCodeChallenge is the schema descriptor for code_challenge field.
-	authrequestDescCodeChallenge := authrequestFields[18].Descriptor()
+	authrequestDescCodeChallenge := authrequestFields[19].Descriptor()
 	// authrequest.DefaultCodeChallenge holds the default value on creation for the code_challenge field.
 	authrequest.DefaultCodeChallenge = authrequestDescCodeChallenge.Default.(string)
 	// authrequestDescCodeChallengeMethod is the schema descriptor for code_challenge_method field.
-	authrequestDescCodeChallengeMethod := authrequestFields[19].Descriptor()
+	authrequestDescCodeChallengeMethod := authrequestFields[20].Descriptor()
 	// authrequest.DefaultCodeChallengeMethod holds the default value on creation for the code_challenge_method field.
 	authrequest.DefaultCodeChallengeMethod = authrequestDescCodeChallengeMethod.Default.(string)
 	// authrequestDescID is the schema descriptor for id field.
@@ -132,6 +133,14 @@ func init() {
 	devicerequestDescClientSecret := devicerequestFields[3].Descriptor()
 	// devicerequest.ClientSecretValidator is a validator for the "client_secret" field. It is called by the builders before save.
 	devicerequest.ClientSecretValidator = devicerequestDescClientSecret.Validators[0].(func(string) error)
+	// devicerequestDescPollInterval is the schema descriptor for poll_interval field.
+	devicerequestDescPollInterval := devicerequestFields[7].Descriptor()
+	// devicerequest.DefaultPollInterval holds the default value on creation for the poll_interval field.
+	devicerequest.DefaultPollInterval = devicerequestDescPollInterval.Default.(int)
+	// devicerequestDescConsumed is the schema descriptor for consumed field.
+	devicerequestDescConsumed := devicerequestFields[8].Descriptor()
+	// devicerequest.DefaultConsumed holds the default value on creation for the consumed field.
+	devicerequest.DefaultConsumed = devicerequestDescConsumed.Default.(bool)
 	devicetokenFields := schema.DeviceToken{}.Fields()
 	_ = devicetokenFields
 	// devicetokenDescDeviceCode is the schema descriptor for device_code field.
@@ -150,6 +159,12 @@ func init() {
 	devicetokenDescCodeChallengeMethod := devicetokenFields[7].Descriptor()
 	// devicetoken.DefaultCodeChallengeMethod holds the default value on creation for the code_challenge_method field.
 	devicetoken.DefaultCodeChallengeMethod = devicetokenDescCodeChallengeMethod.Default.(string)
+	idempotencykeyFields := schema.IdempotencyKey{}.Fields()
+	_ = idempotencykeyFields
+	// idempotencykeyDescID is the schema descriptor for id field.
+	idempotencykeyDescID := idempotencykeyFields[0].Descriptor()
+	// idempotencykey.IDValidator is a validator for the "id" field. It is called by the builders before save.
+	idempotencykey.IDValidator = idempotencykeyDescID.Validators[0].(func(string) error)
 	keysFields := schema.Keys{}.Fields()
 	_ = keysFields
 	// keysDescID is the schema descriptor for id field.
@@ -170,6 +185,18 @@ func init() {
 	oauth2clientDescLogoURL := oauth2clientFields[6].Descriptor()
 	// oauth2client.LogoURLValidator is a validator for the "logo_url" field. It is called by the builders before save.
 	oauth2client.LogoURLValidator = oauth2clientDescLogoURL.Validators[0].(func(string) error)
+	// oauth2clientDescAccessTokenLifetime is the schema descriptor for access_token_lifetime field.
+	oauth2clientDescAccessTokenLifetime := oauth2clientFields[7].Descriptor()
+	// oauth2client.DefaultAccessTokenLifetime holds the default value on creation for the access_token_lifetime field.
+	oauth2client.DefaultAccessTokenLifetime = oauth2clientDescAccessTokenLifetime.Default.(int64)
+	// oauth2clientDescRefreshTokenLifetime is the schema descriptor for refresh_token_lifetime field.
+	oauth2clientDescRefreshTokenLifetime := oauth2clientFields[8].Descriptor()
+	// oauth2client.DefaultRefreshTokenLifetime holds the default value on creation for the refresh_token_lifetime field.
+	oauth2client.DefaultRefreshTokenLifetime = oauth2clientDescRefreshTokenLifetime.Default.(int64)
+	// oauth2clientDescDisabled is the schema descriptor for disabled field.
+	oauth2clientDescDisabled := oauth2clientFields[11].Descriptor()
+	// oauth2client.DefaultDisabled holds the default value on creation for the disabled field.
+	oauth2client.DefaultDisabled = oauth2clientDescDisabled.Default.(bool)
 	// oauth2clientDescID is the schema descriptor for id field.
 	oauth2clientDescID := oauth2clientFields[0].Descriptor()
 	// oauth2client.IDValidator is a validator for the "id" field. It is called by the builders before save.
@@ -239,29 +266,33 @@ func init() {
 	// refreshtoken.ClaimsEmailValidator is a validator for the "claims_email" field. It is called by the builders before save.
 	refreshtoken.ClaimsEmailValidator = refreshtokenDescClaimsEmail.Validators[0].(func(string) error)
 	// refreshtokenDescClaimsPreferredUsername is the schema descriptor for claims_preferred_username field.
-	refreshtokenDescClaimsPreferredUsername := refreshtokenFields[9].Descriptor()
+	refreshtokenDescClaimsPreferredUsername := refreshtokenFields[10].Descriptor()
 	// refreshtoken.DefaultClaimsPreferredUsername holds the default value on creation for the claims_preferred_username field.
 	refreshtoken.DefaultClaimsPreferredUsername = refreshtokenDescClaimsPreferredUsername.Default.(string)
 	// refreshtokenDescConnectorID is the schema descriptor for connector_id field.
-	refreshtokenDescConnectorID := refreshtokenFields[10].Descriptor()
+	refreshtokenDescConnectorID := refreshtokenFields[11].Descriptor()
 	// refreshtoken.ConnectorIDValidator is a validator for the "connector_id" field. It is called by the builders before save.
 	refreshtoken.ConnectorIDValidator = refreshtokenDescConnectorID.Validators[0].(func(string) error)
 	// refreshtokenDescToken is the schema descriptor for token field.
-	refreshtokenDescToken := refreshtokenFields[12].Descriptor()
+	refreshtokenDescToken := refreshtokenFields[13].Descriptor()
 	// refreshtoken.DefaultToken holds the default value on creation for the token field.
 	refreshtoken.DefaultToken = refreshtokenDescToken.Default.(string)
 	// refreshtokenDescObsoleteToken is the schema descriptor for obsolete_token field.
-	refreshtokenDescObsoleteToken := refreshtokenFields[13].Descriptor()
+	refreshtokenDescObsoleteToken := refreshtokenFields[14].Descriptor()
 	// refreshtoken.DefaultObsoleteToken holds the default value on creation for the obsolete_token field.
 	refreshtoken.DefaultObsoleteToken = refreshtokenDescObsoleteToken.Default.(string)
 	// refreshtokenDescCreatedAt is the schema descriptor for created_at field.
-	refreshtokenDescCreatedAt := refreshtokenFields[14].Descriptor()
+	refreshtokenDescCreatedAt := refreshtokenFields[15].Descriptor()
 	// refreshtoken.DefaultCreatedAt holds the default value on creation for the created_at field.
 	refreshtoken.DefaultCreatedAt = refreshtokenDescCreatedAt.Default.(func() time.Time)
 	// refreshtokenDescLastUsed is the schema descriptor for last_used field.
-	refreshtokenDescLastUsed := refreshtokenFields[15].Descriptor()
+	refreshtokenDescLastUsed := refreshtokenFields[16].Descriptor()
 	// refreshtoken.DefaultLastUsed holds the default value on creation for the last_used field.
 	refreshtoken.DefaultLastUsed = refreshtokenDescLastUsed.Default.(func() time.Time)
+	// refreshtokenDescConsumed is the schema descriptor for consumed field.
+	refreshtokenDescConsumed := refreshtokenFields[17].Descriptor()
+	// refreshtoken.DefaultConsumed holds the default value on creation for the consumed field.
+	refreshtoken.DefaultConsumed = refreshtokenDescConsumed.Default.(bool)
 	// refreshtokenDescID is the schema descriptor for id field.
 	refreshtokenDescID := refreshtokenFields[0].Descriptor()
 	// refreshtoken.IDValidator is a validator for the "id" field. It is called by the builders before save.
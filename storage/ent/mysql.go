@@ -51,9 +51,11 @@ func (m *MySQL) Open(logger *slog.Logger) (storage.Storage, error) {
 		client.WithHasher(sha256.New),
 		// Set tx isolation leve for each transaction as dex does for postgres
 		client.WithTxIsolationLevel(sql.LevelSerializable),
+		client.WithGCBatchSize(m.GCBatchSize),
+		client.WithDeviceRequestTTL(m.DeviceRequestTTL),
 	)
 
-	if err := databaseClient.Schema().Create(context.TODO()); err != nil {
+	if err := databaseClient.Migrate(context.TODO()); err != nil {
 		return nil, err
 	}
 
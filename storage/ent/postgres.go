@@ -51,9 +51,11 @@ func (p *Postgres) Open(logger *slog.Logger) (storage.Storage, error) {
 		//
 		// See: https://www.postgresql.org/docs/9.3/static/sql-set-transaction.html
 		client.WithTxIsolationLevel(sql.LevelSerializable),
+		client.WithGCBatchSize(p.GCBatchSize),
+		client.WithDeviceRequestTTL(p.DeviceRequestTTL),
 	)
 
-	if err := databaseClient.Schema().Create(context.TODO()); err != nil {
+	if err := databaseClient.Migrate(context.TODO()); err != nil {
 		return nil, err
 	}
 
@@ -28,7 +28,8 @@ create table auth_request
     claims_preferred_username text default '' not null,
     code_challenge            text default '' not null,
     code_challenge_method     text default '' not null,
-    hmac_key                  blob
+    hmac_key                  blob,
+    claims_extra              blob
 );
 */
 
@@ -69,6 +70,8 @@ func (AuthRequest) Fields() []ent.Field {
 		field.Bool("claims_email_verified"),
 		field.JSON("claims_groups", []string{}).
 			Optional(),
+		field.JSON("claims_extra", map[string]interface{}{}).
+			Optional(),
 		field.Text("claims_preferred_username").
 			SchemaType(textSchema).
 			Default(""),
@@ -3,6 +3,7 @@ package schema
 import (
 	"entgo.io/ent"
 	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
 )
 
 /* Original SQL table:
@@ -14,8 +15,15 @@ create table client
     trusted_peers blob    not null,
     public        integer not null,
     name          text    not null,
-    logo_url      text    not null
+    logo_url      text    not null,
+    access_token_lifetime integer not null default 0,
+    created_at    timestamp,
+    updated_at    timestamp,
+    disabled      integer not null default 0,
+    allowed_connectors blob,
+    refresh_token_lifetime integer not null default 0
 );
+create index client_name on client (name);
 */
 
 // OAuth2Client holds the schema definition for the Client entity.
@@ -45,6 +53,30 @@ func (OAuth2Client) Fields() []ent.Field {
 		field.Text("logo_url").
 			SchemaType(textSchema).
 			NotEmpty(),
+		field.Int64("access_token_lifetime").
+			Default(0),
+		field.Int64("refresh_token_lifetime").
+			Default(0),
+		// Clients created before these fields existed have no timestamps.
+		field.Time("created_at").
+			SchemaType(timeSchema).
+			Optional(),
+		field.Time("updated_at").
+			SchemaType(timeSchema).
+			Optional(),
+		// Disabled clients keep their configuration but can't log users in.
+		field.Bool("disabled").
+			Default(false),
+		field.JSON("allowed_connectors", []string{}).
+			Optional(),
+	}
+}
+
+// Indexes of the OAuth2Client.
+func (OAuth2Client) Indexes() []ent.Index {
+	return []ent.Index{
+		// Clients are looked up by name through the API.
+		index.Fields("name"),
 	}
 }
 
@@ -3,6 +3,7 @@ package schema
 import (
 	"entgo.io/ent"
 	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
 )
 
 /* Original SQL table:
@@ -13,8 +14,11 @@ create table device_request
     client_id     text      not null,
     client_secret text,
     scopes        blob      not null,
-    expiry        timestamp not null
+    expiry        timestamp not null,
+    poll_interval integer   not null default 5,
+    consumed      boolean   not null default false
 );
+create index device_request_expiry on device_request (expiry);
 */
 
 // DeviceRequest holds the schema definition for the DeviceRequest entity.
@@ -42,6 +46,24 @@ func (DeviceRequest) Fields() []ent.Field {
 			Optional(),
 		field.Time("expiry").
 			SchemaType(timeSchema),
+		// Rows created before this field existed are backfilled on migration.
+		field.Time("created_at").
+			SchemaType(timeSchema).
+			Optional(),
+		// Seconds the device has to wait between polls for a token.
+		field.Int("poll_interval").
+			Default(5),
+		// Set once the device code has been exchanged for a token.
+		field.Bool("consumed").
+			Default(false),
+	}
+}
+
+// Indexes of the DeviceRequest.
+func (DeviceRequest) Indexes() []ent.Index {
+	return []ent.Index{
+		// Garbage collection looks device requests up by expiry.
+		index.Fields("expiry"),
 	}
 }
 
@@ -0,0 +1,51 @@
+package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+/* Original SQL table:
+create table idempotency_key
+(
+    id       text      not null primary key,
+    response blob      not null,
+    expiry   timestamp not null,
+    request_hash blob
+);
+create index idempotency_key_expiry on idempotency_key (expiry);
+*/
+
+// IdempotencyKey holds the schema definition for the IdempotencyKey entity.
+type IdempotencyKey struct {
+	ent.Schema
+}
+
+// Fields of the IdempotencyKey.
+func (IdempotencyKey) Fields() []ent.Field {
+	return []ent.Field{
+		field.Text("id").
+			SchemaType(textSchema).
+			NotEmpty().
+			Unique(),
+		field.Bytes("response"),
+		field.Time("expiry").
+			SchemaType(timeSchema),
+		field.Bytes("request_hash").
+			Optional(),
+	}
+}
+
+// Indexes of the IdempotencyKey.
+func (IdempotencyKey) Indexes() []ent.Index {
+	return []ent.Index{
+		// Garbage collection looks idempotency keys up by expiry.
+		index.Fields("expiry"),
+	}
+}
+
+// Edges of the IdempotencyKey.
+func (IdempotencyKey) Edges() []ent.Edge {
+	return []ent.Edge{}
+}
@@ -25,7 +25,9 @@ create table refresh_token
     created_at                timestamp default '0001-01-01 00:00:00 UTC' not null,
     last_used                 timestamp default '0001-01-01 00:00:00 UTC' not null,
     claims_preferred_username text      default '' not null,
-    obsolete_token            text      default ''
+    obsolete_token            text      default '',
+    claims_extra              blob,
+    consumed                  integer   default 0 not null
 );
 */
 
@@ -62,6 +64,8 @@ func (RefreshToken) Fields() []ent.Field {
 		field.Bool("claims_email_verified"),
 		field.JSON("claims_groups", []string{}).
 			Optional(),
+		field.JSON("claims_extra", map[string]interface{}{}).
+			Optional(),
 		field.Text("claims_preferred_username").
 			SchemaType(textSchema).
 			Default(""),
@@ -86,6 +90,9 @@ func (RefreshToken) Fields() []ent.Field {
 		field.Time("last_used").
 			SchemaType(timeSchema).
 			Default(time.Now),
+
+		field.Bool("consumed").
+			Default(false),
 	}
 }
 
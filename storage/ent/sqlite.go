@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"log/slog"
 	"strings"
+	"time"
 
 	"entgo.io/ent/dialect/sql"
 	_ "github.com/mattn/go-sqlite3" // Register sqlite driver.
@@ -17,6 +18,12 @@ import (
 // SQLite3 options for creating an SQL db.
 type SQLite3 struct {
 	File string `json:"file"`
+
+	// GCBatchSize limits how many expired device requests are deleted at once.
+	GCBatchSize int `json:"gcBatchSize"`
+
+	// DeviceRequestTTL is set from expiry.deviceRequests.
+	DeviceRequestTTL time.Duration `json:"-"`
 }
 
 // Open always returns a new in sqlite3 storage.
@@ -39,9 +46,11 @@ func (s *SQLite3) Open(logger *slog.Logger) (storage.Storage, error) {
 	databaseClient := client.NewDatabase(
 		client.WithClient(db.NewClient(db.Driver(drv))),
 		client.WithHasher(sha256.New),
+		client.WithGCBatchSize(s.GCBatchSize),
+		client.WithDeviceRequestTTL(s.DeviceRequestTTL),
 	)
 
-	if err := databaseClient.Schema().Create(context.TODO()); err != nil {
+	if err := databaseClient.Migrate(context.TODO()); err != nil {
 		return nil, err
 	}
 
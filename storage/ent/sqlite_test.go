@@ -1,12 +1,19 @@
 package ent
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
 	"io"
 	"log/slog"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/dexidp/dex/storage"
 	"github.com/dexidp/dex/storage/conformance"
+	"github.com/dexidp/dex/storage/ent/client"
 )
 
 func newSQLiteStorage() storage.Storage {
@@ -23,3 +30,284 @@ func newSQLiteStorage() storage.Storage {
 func TestSQLite3(t *testing.T) {
 	conformance.RunTests(t, newSQLiteStorage)
 }
+
+func TestSQLite3GCBatchSize(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	cfg := SQLite3{File: ":memory:", GCBatchSize: 2}
+	s, err := cfg.Open(logger)
+	if err != nil {
+		t.Fatalf("open storage: %v", err)
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+
+	createDeviceRequest := func(userCode string, expiry time.Time) {
+		if err := s.CreateDeviceRequest(ctx, storage.DeviceRequest{
+			UserCode:     userCode,
+			DeviceCode:   storage.NewID(),
+			ClientID:     "client",
+			ClientSecret: "secret",
+			Scopes:       []string{"openid"},
+			Expiry:       expiry,
+		}); err != nil {
+			t.Fatalf("create device request: %v", err)
+		}
+	}
+	// More expired requests than fit in one batch, and one in a partial batch.
+	for i := 0; i < 5; i++ {
+		createDeviceRequest(fmt.Sprintf("EXPIRED%d", i), now.Add(-time.Minute))
+	}
+	for i := 0; i < 2; i++ {
+		createDeviceRequest(fmt.Sprintf("VALID%d", i), now.Add(time.Minute))
+	}
+
+	result, err := s.GarbageCollect(ctx, now)
+	if err != nil {
+		t.Fatalf("garbage collect: %v", err)
+	}
+	if result.DeviceRequests != 5 {
+		t.Errorf("expected 5 device requests to be garbage collected, got %d", result.DeviceRequests)
+	}
+
+	for i := 0; i < 5; i++ {
+		if _, err := s.GetDeviceRequest(ctx, fmt.Sprintf("EXPIRED%d", i)); err != storage.ErrNotFound {
+			t.Errorf("expected expired device request %d to be removed, got %v", i, err)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := s.GetDeviceRequest(ctx, fmt.Sprintf("VALID%d", i)); err != nil {
+			t.Errorf("expected valid device request %d to be kept, got %v", i, err)
+		}
+	}
+}
+
+func TestSQLite3DeviceRequestExpiryIndex(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	file := filepath.Join(t.TempDir(), "dex.db")
+	cfg := SQLite3{File: file}
+	s, err := cfg.Open(logger)
+	if err != nil {
+		t.Fatalf("open storage: %v", err)
+	}
+	defer s.Close()
+
+	conn, err := sql.Open("sqlite3", file)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	defer conn.Close()
+
+	// The query garbage collection uses to find expired device requests.
+	rows, err := conn.Query(`EXPLAIN QUERY PLAN SELECT id FROM device_requests WHERE expiry < ? ORDER BY expiry LIMIT 100`, time.Now())
+	if err != nil {
+		t.Fatalf("explain query: %v", err)
+	}
+	defer rows.Close()
+
+	var plan []string
+	for rows.Next() {
+		var id, parent, notUsed int
+		var detail string
+		if err := rows.Scan(&id, &parent, &notUsed, &detail); err != nil {
+			t.Fatalf("scan query plan: %v", err)
+		}
+		plan = append(plan, detail)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("read query plan: %v", err)
+	}
+
+	if !strings.Contains(strings.Join(plan, "\n"), "INDEX devicerequest_expiry") {
+		t.Errorf("expected the query to use the expiry index, got plan %q", plan)
+	}
+}
+
+func TestSQLite3DeviceRequestCreatedAt(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	file := filepath.Join(t.TempDir(), "dex.db")
+	cfg := SQLite3{File: file, DeviceRequestTTL: 10 * time.Minute}
+	s, err := cfg.Open(logger)
+	if err != nil {
+		t.Fatalf("open storage: %v", err)
+	}
+
+	s.Close()
+
+	// Drop the created_at column to get back to the schema from before it was
+	// added, and store a device request in it.
+	conn, err := sql.Open("sqlite3", file)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Exec(`ALTER TABLE device_requests DROP COLUMN created_at`); err != nil {
+		t.Fatalf("drop created_at column: %v", err)
+	}
+	now := time.Now().UTC()
+	oldExpiry := now.Add(-time.Hour).Truncate(time.Second)
+	if _, err := conn.Exec(`INSERT INTO device_requests (user_code, device_code, client_id, client_secret, scopes, expiry) VALUES (?, ?, ?, ?, ?, ?)`,
+		"OLD", storage.NewID(), "client", "secret", `["openid"]`, oldExpiry); err != nil {
+		t.Fatalf("insert device request: %v", err)
+	}
+
+	// Adding the column back dates the old request by the configured lifetime.
+	ctx := context.Background()
+	s, err = cfg.Open(logger)
+	if err != nil {
+		t.Fatalf("reopen storage: %v", err)
+	}
+	got, err := s.ListDeviceRequestsCreatedBetween(ctx, oldExpiry.Add(-time.Hour), now.Add(time.Second))
+	if err != nil {
+		t.Fatalf("list device requests: %v", err)
+	}
+	if len(got) != 1 || got[0].UserCode != "OLD" || !got[0].CreatedAt.Equal(oldExpiry.Add(-10*time.Minute)) {
+		t.Fatalf("expected the backfilled device request, got %+v", got)
+	}
+	s.Close()
+
+	// The backfill only runs once, when the column is added.
+	if _, err := conn.Exec(`INSERT INTO device_requests (user_code, device_code, client_id, client_secret, scopes, expiry) VALUES (?, ?, ?, ?, ?, ?)`,
+		"LATER", storage.NewID(), "client", "secret", `["openid"]`, oldExpiry); err != nil {
+		t.Fatalf("insert device request: %v", err)
+	}
+	s, err = cfg.Open(logger)
+	if err != nil {
+		t.Fatalf("reopen storage: %v", err)
+	}
+	defer s.Close()
+	later, err := s.GetDeviceRequest(ctx, "LATER")
+	if err != nil {
+		t.Fatalf("get device request: %v", err)
+	}
+	if !later.CreatedAt.IsZero() {
+		t.Errorf("expected the device request not to be backfilled again, got created at %v", later.CreatedAt)
+	}
+}
+
+func TestSQLite3DeviceRequestPollIntervalDefault(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	file := filepath.Join(t.TempDir(), "dex.db")
+	cfg := SQLite3{File: file}
+	s, err := cfg.Open(logger)
+	if err != nil {
+		t.Fatalf("open storage: %v", err)
+	}
+	defer s.Close()
+
+	// A device request stored before the poll_interval column existed.
+	conn, err := sql.Open("sqlite3", file)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Exec(`INSERT INTO device_requests (user_code, device_code, client_id, client_secret, scopes, expiry) VALUES (?, ?, ?, ?, ?, ?)`,
+		"OLD", storage.NewID(), "client", "secret", `["openid"]`, time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("insert device request: %v", err)
+	}
+
+	got, err := s.GetDeviceRequest(context.Background(), "OLD")
+	if err != nil {
+		t.Fatalf("get device request: %v", err)
+	}
+	if got.PollIntervalSeconds != 5 {
+		t.Errorf("expected the default poll interval of 5 seconds, got %d", got.PollIntervalSeconds)
+	}
+}
+
+func TestSQLite3DeviceRequestScopes(t *testing.T) {
+	s := newSQLiteStorage()
+	defer s.Close()
+	db := s.(*client.Database)
+
+	ctx := context.Background()
+	newRequest := func(userCode string, scopes []string) storage.DeviceRequest {
+		return storage.DeviceRequest{
+			UserCode:     userCode,
+			DeviceCode:   storage.NewID(),
+			ClientID:     "client",
+			ClientSecret: "secret",
+			Scopes:       scopes,
+			Expiry:       time.Now().Add(time.Minute),
+		}
+	}
+
+	scopes := []string{"openid", "email", "groups", "audience:server:client_id:other"}
+	if err := s.CreateDeviceRequest(ctx, newRequest("FIRST", scopes)); err != nil {
+		t.Fatalf("create device request: %v", err)
+	}
+	if err := s.CreateDeviceRequest(ctx, newRequest("SECOND", []string{"openid"})); err != nil {
+		t.Fatalf("create device request: %v", err)
+	}
+
+	got, err := s.GetDeviceRequest(ctx, "FIRST")
+	if err != nil {
+		t.Fatalf("get device request: %v", err)
+	}
+	if strings.Join(got.Scopes, " ") != strings.Join(scopes, " ") {
+		t.Errorf("expected scopes %q to round-trip, got %q", scopes, got.Scopes)
+	}
+
+	for _, invalid := range [][]string{
+		{"openid", ""},
+		{"openid", "email", "openid"},
+	} {
+		if err := s.CreateDeviceRequest(ctx, newRequest("INVALID", invalid)); err == nil {
+			t.Errorf("expected scopes %q to be rejected", invalid)
+		}
+	}
+	if _, err := s.GetDeviceRequest(ctx, "INVALID"); err != storage.ErrNotFound {
+		t.Errorf("expected no device request with invalid scopes to be stored, got %v", err)
+	}
+
+	withEmail, err := db.DeviceRequestsWithScope(ctx, "email")
+	if err != nil {
+		t.Fatalf("list device requests: %v", err)
+	}
+	if len(withEmail) != 1 || withEmail[0].UserCode != "FIRST" {
+		t.Errorf("expected only the first device request to ask for email, got %+v", withEmail)
+	}
+	withOpenID, err := db.DeviceRequestsWithScope(ctx, "openid")
+	if err != nil {
+		t.Fatalf("list device requests: %v", err)
+	}
+	if len(withOpenID) != 2 {
+		t.Errorf("expected both device requests to ask for openid, got %+v", withOpenID)
+	}
+}
+
+func TestSQLite3ClientNameIndex(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+
+	file := filepath.Join(t.TempDir(), "dex.db")
+	cfg := SQLite3{File: file}
+	s, err := cfg.Open(logger)
+	if err != nil {
+		t.Fatalf("open storage: %v", err)
+	}
+	defer s.Close()
+
+	conn, err := sql.Open("sqlite3", file)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	defer conn.Close()
+
+	// The query GetClientsByName uses to find clients by their exact name.
+	var id, parent, notUsed int
+	var detail string
+	err = conn.QueryRow(`EXPLAIN QUERY PLAN SELECT id FROM oauth2clients WHERE name = ?`, "Admin UI").
+		Scan(&id, &parent, &notUsed, &detail)
+	if err != nil {
+		t.Fatalf("explain query: %v", err)
+	}
+
+	if !strings.Contains(detail, "USING INDEX oauth2client_name") {
+		t.Errorf("expected the query to use the name index, got plan %q", detail)
+	}
+}
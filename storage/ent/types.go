@@ -1,5 +1,7 @@
 package ent
 
+import "time"
+
 // NetworkDB contains options common to SQL databases accessed over network.
 type NetworkDB struct {
 	Database string
@@ -13,6 +15,10 @@ type NetworkDB struct {
 	MaxOpenConns    int // default: 5
 	MaxIdleConns    int // default: 5
 	ConnMaxLifetime int // Seconds, default: not set
+
+	GCBatchSize int // default: not set, expired device requests are deleted at once
+
+	DeviceRequestTTL time.Duration `json:"-"` // set from expiry.deviceRequests, default: 5m
 }
 
 // SSL represents SSL options for network databases.
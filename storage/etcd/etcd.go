@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
 	"time"
 
@@ -24,6 +25,7 @@ const (
 	keysName             = "openid-connect-keys"
 	deviceRequestPrefix  = "device_req/"
 	deviceTokenPrefix    = "device_token/"
+	idempotencyKeyPrefix = "idempotency_key/"
 
 	// defaultStorageTimeout will be applied to all storage's operations.
 	defaultStorageTimeout = 5 * time.Second
@@ -106,6 +108,21 @@ func (c *conn) GarbageCollect(ctx context.Context, now time.Time) (result storag
 			result.DeviceTokens++
 		}
 	}
+
+	idempotencyKeys, err := c.listIdempotencyKeys(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	for _, idempotencyKey := range idempotencyKeys {
+		if now.After(idempotencyKey.Expiry) {
+			if err := c.deleteKey(ctx, keyID(idempotencyKeyPrefix, idempotencyKey.Key)); err != nil {
+				c.logger.Error("failed to delete idempotency key", "err", err)
+				delErr = fmt.Errorf("failed to delete idempotency key: %v", err)
+			}
+			result.IdempotencyKeys++
+		}
+	}
 	return result, delErr
 }
 
@@ -207,6 +224,35 @@ func (c *conn) DeleteRefresh(ctx context.Context, id string) error {
 	return c.deleteKey(ctx, keyID(refreshTokenPrefix, id))
 }
 
+func (c *conn) DeleteRefreshTokensForUser(ctx context.Context, userID, connID string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultStorageTimeout)
+	defer cancel()
+	res, err := c.db.Get(ctx, refreshTokenPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return 0, err
+	}
+	var ops []clientv3.Op
+	for _, v := range res.Kvs {
+		var token RefreshToken
+		if err = json.Unmarshal(v.Value, &token); err != nil {
+			return 0, err
+		}
+		if token.Claims.UserID == userID && token.ConnectorID == connID {
+			ops = append(ops, clientv3.OpDelete(string(v.Key)))
+		}
+	}
+
+	// Transactions are limited to 128 operations by default.
+	n := 0
+	for batch := range slices.Chunk(ops, 128) {
+		if _, err := c.db.Txn(ctx).Then(batch...).Commit(); err != nil {
+			return n, err
+		}
+		n += len(batch)
+	}
+	return n, nil
+}
+
 func (c *conn) ListRefreshTokens(ctx context.Context) (tokens []storage.RefreshToken, err error) {
 	ctx, cancel := context.WithTimeout(ctx, defaultStorageTimeout)
 	defer cancel()
@@ -259,6 +305,20 @@ func (c *conn) DeleteClient(ctx context.Context, id string) error {
 	return c.deleteKey(ctx, keyID(clientPrefix, id))
 }
 
+func (c *conn) GetClientsByName(ctx context.Context, name string, caseInsensitive bool) ([]storage.Client, error) {
+	all, err := c.ListClients(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var clients []storage.Client
+	for _, cli := range all {
+		if cli.Name == name || (caseInsensitive && strings.EqualFold(cli.Name, name)) {
+			clients = append(clients, cli)
+		}
+	}
+	return clients, nil
+}
+
 func (c *conn) ListClients(ctx context.Context) (clients []storage.Client, err error) {
 	ctx, cancel := context.WithTimeout(ctx, defaultStorageTimeout)
 	defer cancel()
@@ -571,6 +631,39 @@ func (c *conn) GetDeviceRequest(ctx context.Context, userCode string) (r storage
 	return
 }
 
+func (c *conn) CountDeviceRequestsExpiringBefore(ctx context.Context, t time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultStorageTimeout)
+	defer cancel()
+	requests, err := c.listDeviceRequests(ctx)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, r := range requests {
+		if r.Expiry.Before(t) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (c *conn) ListDeviceRequestsCreatedBetween(ctx context.Context, from, to time.Time) ([]storage.DeviceRequest, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultStorageTimeout)
+	defer cancel()
+	requests, err := c.listDeviceRequests(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var created []storage.DeviceRequest
+	for _, r := range requests {
+		if !r.CreatedAt.Before(from) && r.CreatedAt.Before(to) {
+			created = append(created, toStorageDeviceRequest(r))
+		}
+	}
+	slices.SortFunc(created, func(a, b storage.DeviceRequest) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return created, nil
+}
+
 func (c *conn) listDeviceRequests(ctx context.Context) (requests []DeviceRequest, err error) {
 	res, err := c.db.Get(ctx, deviceRequestPrefix, clientv3.WithPrefix())
 	if err != nil {
@@ -615,6 +708,69 @@ func (c *conn) listDeviceTokens(ctx context.Context) (deviceTokens []DeviceToken
 	return deviceTokens, nil
 }
 
+func (c *conn) CreateIdempotencyKey(ctx context.Context, k storage.IdempotencyKey) error {
+	return c.txnCreate(ctx, keyID(idempotencyKeyPrefix, k.Key), fromStorageIdempotencyKey(k))
+}
+
+func (c *conn) GetIdempotencyKey(ctx context.Context, key string) (k storage.IdempotencyKey, err error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultStorageTimeout)
+	defer cancel()
+	var ik IdempotencyKey
+	if err = c.getKey(ctx, keyID(idempotencyKeyPrefix, key), &ik); err == nil {
+		k = toStorageIdempotencyKey(ik)
+	}
+	return
+}
+
+func (c *conn) DeleteIdempotencyKey(ctx context.Context, key string) error {
+	ctx, cancel := context.WithTimeout(ctx, defaultStorageTimeout)
+	defer cancel()
+	return c.deleteKey(ctx, keyID(idempotencyKeyPrefix, key))
+}
+
+func (c *conn) listIdempotencyKeys(ctx context.Context) (keys []IdempotencyKey, err error) {
+	res, err := c.db.Get(ctx, idempotencyKeyPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return keys, err
+	}
+	for _, v := range res.Kvs {
+		var ik IdempotencyKey
+		if err = json.Unmarshal(v.Value, &ik); err != nil {
+			return keys, err
+		}
+		keys = append(keys, ik)
+	}
+	return keys, nil
+}
+
+func (c *conn) ConsumeDeviceRequest(ctx context.Context, deviceCode string) error {
+	ctx, cancel := context.WithTimeout(ctx, defaultStorageTimeout)
+	defer cancel()
+	requests, err := c.listDeviceRequests(ctx)
+	if err != nil {
+		return err
+	}
+	for _, r := range requests {
+		if r.DeviceCode != deviceCode {
+			continue
+		}
+		return c.txnUpdate(ctx, keyID(deviceRequestPrefix, r.UserCode), func(currentValue []byte) ([]byte, error) {
+			var current DeviceRequest
+			if len(currentValue) > 0 {
+				if err := json.Unmarshal(currentValue, &current); err != nil {
+					return nil, err
+				}
+			}
+			if current.Consumed {
+				return nil, storage.ErrAlreadyConsumed
+			}
+			current.Consumed = true
+			return json.Marshal(current)
+		})
+	}
+	return storage.ErrNotFound
+}
+
 func (c *conn) UpdateDeviceToken(ctx context.Context, deviceCode string, updater func(old storage.DeviceToken) (storage.DeviceToken, error)) error {
 	ctx, cancel := context.WithTimeout(ctx, defaultStorageTimeout)
 	defer cancel()
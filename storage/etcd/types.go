@@ -138,6 +138,7 @@ type RefreshToken struct {
 
 	Token         string `json:"token"`
 	ObsoleteToken string `json:"obsolete_token"`
+	Consumed      bool   `json:"consumed,omitempty"`
 
 	CreatedAt time.Time `json:"created_at"`
 	LastUsed  time.Time `json:"last_used"`
@@ -158,6 +159,7 @@ func toStorageRefreshToken(r RefreshToken) storage.RefreshToken {
 		ID:            r.ID,
 		Token:         r.Token,
 		ObsoleteToken: r.ObsoleteToken,
+		Consumed:      r.Consumed,
 		CreatedAt:     r.CreatedAt,
 		LastUsed:      r.LastUsed,
 		ClientID:      r.ClientID,
@@ -174,6 +176,7 @@ func fromStorageRefreshToken(r storage.RefreshToken) RefreshToken {
 		ID:            r.ID,
 		Token:         r.Token,
 		ObsoleteToken: r.ObsoleteToken,
+		Consumed:      r.Consumed,
 		CreatedAt:     r.CreatedAt,
 		LastUsed:      r.LastUsed,
 		ClientID:      r.ClientID,
@@ -193,6 +196,8 @@ type Claims struct {
 	Email             string   `json:"email"`
 	EmailVerified     bool     `json:"emailVerified"`
 	Groups            []string `json:"groups,omitempty"`
+
+	ExtraClaims map[string]interface{} `json:"extraClaims,omitempty"`
 }
 
 func fromStorageClaims(i storage.Claims) Claims {
@@ -203,6 +208,7 @@ func fromStorageClaims(i storage.Claims) Claims {
 		Email:             i.Email,
 		EmailVerified:     i.EmailVerified,
 		Groups:            i.Groups,
+		ExtraClaims:       i.ExtraClaims,
 	}
 }
 
@@ -214,6 +220,7 @@ func toStorageClaims(i Claims) storage.Claims {
 		Email:             i.Email,
 		EmailVerified:     i.EmailVerified,
 		Groups:            i.Groups,
+		ExtraClaims:       i.ExtraClaims,
 	}
 }
 
@@ -258,33 +265,42 @@ func toStorageOfflineSessions(o OfflineSessions) storage.OfflineSessions {
 
 // DeviceRequest is a mirrored struct from storage with JSON struct tags
 type DeviceRequest struct {
-	UserCode     string    `json:"user_code"`
-	DeviceCode   string    `json:"device_code"`
-	ClientID     string    `json:"client_id"`
-	ClientSecret string    `json:"client_secret"`
-	Scopes       []string  `json:"scopes"`
-	Expiry       time.Time `json:"expiry"`
+	UserCode            string    `json:"user_code"`
+	DeviceCode          string    `json:"device_code"`
+	ClientID            string    `json:"client_id"`
+	ClientSecret        string    `json:"client_secret"`
+	Scopes              []string  `json:"scopes"`
+	Expiry              time.Time `json:"expiry"`
+	PollIntervalSeconds int       `json:"poll_interval"`
+	Consumed            bool      `json:"consumed,omitempty"`
+	CreatedAt           time.Time `json:"created_at"`
 }
 
 func fromStorageDeviceRequest(d storage.DeviceRequest) DeviceRequest {
 	return DeviceRequest{
-		UserCode:     d.UserCode,
-		DeviceCode:   d.DeviceCode,
-		ClientID:     d.ClientID,
-		ClientSecret: d.ClientSecret,
-		Scopes:       d.Scopes,
-		Expiry:       d.Expiry,
+		UserCode:            d.UserCode,
+		DeviceCode:          d.DeviceCode,
+		ClientID:            d.ClientID,
+		ClientSecret:        d.ClientSecret,
+		Scopes:              d.Scopes,
+		Expiry:              d.Expiry,
+		PollIntervalSeconds: d.PollIntervalSeconds,
+		Consumed:            d.Consumed,
+		CreatedAt:           d.CreatedAt,
 	}
 }
 
 func toStorageDeviceRequest(d DeviceRequest) storage.DeviceRequest {
 	return storage.DeviceRequest{
-		UserCode:     d.UserCode,
-		DeviceCode:   d.DeviceCode,
-		ClientID:     d.ClientID,
-		ClientSecret: d.ClientSecret,
-		Scopes:       d.Scopes,
-		Expiry:       d.Expiry,
+		UserCode:            d.UserCode,
+		DeviceCode:          d.DeviceCode,
+		ClientID:            d.ClientID,
+		ClientSecret:        d.ClientSecret,
+		Scopes:              d.Scopes,
+		Expiry:              d.Expiry,
+		PollIntervalSeconds: d.PollIntervalSeconds,
+		Consumed:            d.Consumed,
+		CreatedAt:           d.CreatedAt,
 	}
 }
 
@@ -327,3 +343,29 @@ func toStorageDeviceToken(t DeviceToken) storage.DeviceToken {
 		},
 	}
 }
+
+// IdempotencyKey is a mirrored struct from storage with JSON struct tags
+type IdempotencyKey struct {
+	Key         string    `json:"key"`
+	Response    []byte    `json:"response"`
+	RequestHash []byte    `json:"request_hash,omitempty"`
+	Expiry      time.Time `json:"expiry"`
+}
+
+func fromStorageIdempotencyKey(k storage.IdempotencyKey) IdempotencyKey {
+	return IdempotencyKey{
+		Key:         k.Key,
+		Response:    k.Response,
+		RequestHash: k.RequestHash,
+		Expiry:      k.Expiry,
+	}
+}
+
+func toStorageIdempotencyKey(k IdempotencyKey) storage.IdempotencyKey {
+	return storage.IdempotencyKey{
+		Key:         k.Key,
+		Response:    k.Response,
+		RequestHash: k.RequestHash,
+		Expiry:      k.Expiry,
+	}
+}
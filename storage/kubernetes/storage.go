@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"math/rand"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
@@ -25,6 +26,7 @@ const (
 	kindConnector       = "Connector"
 	kindDeviceRequest   = "DeviceRequest"
 	kindDeviceToken     = "DeviceToken"
+	kindIdempotencyKey  = "IdempotencyKey"
 )
 
 const (
@@ -38,6 +40,7 @@ const (
 	resourceConnector       = "connectors"
 	resourceDeviceRequest   = "devicerequests"
 	resourceDeviceToken     = "devicetokens"
+	resourceIdempotencyKey  = "idempotencykeys"
 )
 
 var _ storage.Storage = (*client)(nil)
@@ -368,6 +371,20 @@ func (cli *client) GetConnector(ctx context.Context, id string) (storage.Connect
 	return toStorageConnector(c), nil
 }
 
+func (cli *client) GetClientsByName(ctx context.Context, name string, caseInsensitive bool) (clients []storage.Client, err error) {
+	var clientList ClientList
+	if err = cli.list(resourceClient, &clientList); err != nil {
+		return clients, fmt.Errorf("failed to list clients: %v", err)
+	}
+
+	for _, c := range clientList.Clients {
+		if c.Name == name || (caseInsensitive && strings.EqualFold(c.Name, name)) {
+			clients = append(clients, toStorageClient(c))
+		}
+	}
+	return clients, nil
+}
+
 func (cli *client) ListClients(ctx context.Context) ([]storage.Client, error) {
 	return nil, errors.New("not implemented")
 }
@@ -430,6 +447,28 @@ func (cli *client) DeleteRefresh(ctx context.Context, id string) error {
 	return cli.delete(resourceRefreshToken, id)
 }
 
+func (cli *client) DeleteRefreshTokensForUser(ctx context.Context, userID, connID string) (int, error) {
+	var refreshTokens RefreshList
+	if err := cli.list(resourceRefreshToken, &refreshTokens); err != nil {
+		return 0, fmt.Errorf("failed to list refresh tokens: %v", err)
+	}
+
+	n := 0
+	for _, r := range refreshTokens.RefreshTokens {
+		if r.Claims.UserID != userID || r.ConnectorID != connID {
+			continue
+		}
+		if err := cli.delete(resourceRefreshToken, r.ObjectMeta.Name); err != nil {
+			if err == storage.ErrNotFound {
+				continue
+			}
+			return n, fmt.Errorf("failed to delete refresh token: %v", err)
+		}
+		n++
+	}
+	return n, nil
+}
+
 func (cli *client) DeletePassword(ctx context.Context, email string) error {
 	// Check for hash collision.
 	p, err := cli.getPassword(email)
@@ -677,6 +716,21 @@ func (cli *client) GarbageCollect(ctx context.Context, now time.Time) (result st
 		}
 	}
 
+	var idempotencyKeys IdempotencyKeyList
+	if err := cli.listN(resourceIdempotencyKey, &idempotencyKeys, gcResultLimit); err != nil {
+		return result, fmt.Errorf("failed to list idempotency keys: %v", err)
+	}
+
+	for _, idempotencyKey := range idempotencyKeys.IdempotencyKeys {
+		if now.After(idempotencyKey.Expiry) {
+			if err := cli.delete(resourceIdempotencyKey, idempotencyKey.ObjectMeta.Name); err != nil {
+				cli.logger.Error("failed to delete idempotency key", "err", err)
+				delErr = fmt.Errorf("failed to delete idempotency key: %v", err)
+			}
+			result.IdempotencyKeys++
+		}
+	}
+
 	if delErr != nil {
 		return result, delErr
 	}
@@ -695,6 +749,35 @@ func (cli *client) GetDeviceRequest(ctx context.Context, userCode string) (stora
 	return toStorageDeviceRequest(req), nil
 }
 
+func (cli *client) CountDeviceRequestsExpiringBefore(ctx context.Context, t time.Time) (int, error) {
+	var requests DeviceRequestList
+	if err := cli.list(resourceDeviceRequest, &requests); err != nil {
+		return 0, fmt.Errorf("failed to list device requests: %v", err)
+	}
+	n := 0
+	for _, r := range requests.DeviceRequests {
+		if r.Expiry.Before(t) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (cli *client) ListDeviceRequestsCreatedBetween(ctx context.Context, from, to time.Time) ([]storage.DeviceRequest, error) {
+	var requests DeviceRequestList
+	if err := cli.list(resourceDeviceRequest, &requests); err != nil {
+		return nil, fmt.Errorf("failed to list device requests: %v", err)
+	}
+	var created []storage.DeviceRequest
+	for _, r := range requests.DeviceRequests {
+		if !r.CreatedAt.Before(from) && r.CreatedAt.Before(to) {
+			created = append(created, toStorageDeviceRequest(r))
+		}
+	}
+	slices.SortFunc(created, func(a, b storage.DeviceRequest) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return created, nil
+}
+
 func (cli *client) CreateDeviceToken(ctx context.Context, t storage.DeviceToken) error {
 	return cli.post(resourceDeviceToken, cli.fromStorageDeviceToken(t))
 }
@@ -730,6 +813,27 @@ func (cli *client) UpdateDeviceToken(ctx context.Context, deviceCode string, upd
 	})
 }
 
+func (cli *client) ConsumeDeviceRequest(ctx context.Context, deviceCode string) error {
+	return retryOnConflict(ctx, func() error {
+		var requests DeviceRequestList
+		if err := cli.list(resourceDeviceRequest, &requests); err != nil {
+			return fmt.Errorf("failed to list device requests: %v", err)
+		}
+		for _, r := range requests.DeviceRequests {
+			if r.DeviceCode != deviceCode {
+				continue
+			}
+			if r.Consumed {
+				return storage.ErrAlreadyConsumed
+			}
+			// The resource version makes a concurrent consume conflict.
+			r.Consumed = true
+			return cli.put(resourceDeviceRequest, r.ObjectMeta.Name, r)
+		}
+		return storage.ErrNotFound
+	})
+}
+
 func isKubernetesAPIConflictError(err error) bool {
 	if httpErr, ok := err.(httpError); ok {
 		if httpErr.StatusCode() == http.StatusConflict {
@@ -769,3 +873,26 @@ func retryOnConflict(ctx context.Context, action func() error) error {
 		}
 	}
 }
+
+func (cli *client) CreateIdempotencyKey(ctx context.Context, k storage.IdempotencyKey) error {
+	return cli.post(resourceIdempotencyKey, cli.fromStorageIdempotencyKey(k))
+}
+
+func (cli *client) GetIdempotencyKey(ctx context.Context, key string) (storage.IdempotencyKey, error) {
+	var k IdempotencyKey
+	if err := cli.get(resourceIdempotencyKey, cli.idToName(key), &k); err != nil {
+		return storage.IdempotencyKey{}, err
+	}
+	if k.Key != key {
+		return storage.IdempotencyKey{}, fmt.Errorf("get idempotency key: key %q mapped to idempotency key %q", key, k.Key)
+	}
+	return toStorageIdempotencyKey(k), nil
+}
+
+func (cli *client) DeleteIdempotencyKey(ctx context.Context, key string) error {
+	// Check for hash collision.
+	if _, err := cli.GetIdempotencyKey(ctx, key); err != nil {
+		return err
+	}
+	return cli.delete(resourceIdempotencyKey, cli.idToName(key))
+}
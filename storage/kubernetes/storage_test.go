@@ -73,6 +73,7 @@ func (s *StorageTestSuite) TestStorage() {
 			resourceAuthRequest,
 			resourceDeviceRequest,
 			resourceDeviceToken,
+			resourceIdempotencyKey,
 			resourceClient,
 			resourceRefreshToken,
 			resourceKeys,
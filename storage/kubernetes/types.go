@@ -226,6 +226,23 @@ func customResourceDefinitions(apiVersion string) []k8sapi.CustomResourceDefinit
 				},
 			},
 		},
+		{
+			ObjectMeta: k8sapi.ObjectMeta{
+				Name: "idempotencykeys.dex.coreos.com",
+			},
+			TypeMeta: crdMeta,
+			Spec: k8sapi.CustomResourceDefinitionSpec{
+				Group:    apiGroup,
+				Version:  version,
+				Versions: versions,
+				Scope:    scope,
+				Names: k8sapi.CustomResourceDefinitionNames{
+					Plural:   "idempotencykeys",
+					Singular: "idempotencykey",
+					Kind:     "IdempotencyKey",
+				},
+			},
+		},
 	}
 }
 
@@ -251,6 +268,15 @@ type Client struct {
 
 	Name    string `json:"name,omitempty"`
 	LogoURL string `json:"logoURL,omitempty"`
+
+	AccessTokenLifetime  int64 `json:"accessTokenLifetime,omitempty"`
+	RefreshTokenLifetime int64 `json:"refreshTokenLifetime,omitempty"`
+	Disabled             bool  `json:"disabled,omitempty"`
+
+	AllowedConnectors []string `json:"allowedConnectors,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt,omitempty"`
 }
 
 // ClientList is a list of Clients.
@@ -270,25 +296,37 @@ func (cli *client) fromStorageClient(c storage.Client) Client {
 			Name:      cli.idToName(c.ID),
 			Namespace: cli.namespace,
 		},
-		ID:           c.ID,
-		Secret:       c.Secret,
-		RedirectURIs: c.RedirectURIs,
-		TrustedPeers: c.TrustedPeers,
-		Public:       c.Public,
-		Name:         c.Name,
-		LogoURL:      c.LogoURL,
+		ID:                   c.ID,
+		Secret:               c.Secret,
+		RedirectURIs:         c.RedirectURIs,
+		TrustedPeers:         c.TrustedPeers,
+		Public:               c.Public,
+		Name:                 c.Name,
+		LogoURL:              c.LogoURL,
+		AccessTokenLifetime:  c.AccessTokenLifetime,
+		RefreshTokenLifetime: c.RefreshTokenLifetime,
+		Disabled:             c.Disabled,
+		AllowedConnectors:    c.AllowedConnectors,
+		CreatedAt:            c.CreatedAt,
+		UpdatedAt:            c.UpdatedAt,
 	}
 }
 
 func toStorageClient(c Client) storage.Client {
 	return storage.Client{
-		ID:           c.ID,
-		Secret:       c.Secret,
-		RedirectURIs: c.RedirectURIs,
-		TrustedPeers: c.TrustedPeers,
-		Public:       c.Public,
-		Name:         c.Name,
-		LogoURL:      c.LogoURL,
+		ID:                   c.ID,
+		Secret:               c.Secret,
+		RedirectURIs:         c.RedirectURIs,
+		TrustedPeers:         c.TrustedPeers,
+		Public:               c.Public,
+		Name:                 c.Name,
+		LogoURL:              c.LogoURL,
+		AccessTokenLifetime:  c.AccessTokenLifetime,
+		RefreshTokenLifetime: c.RefreshTokenLifetime,
+		Disabled:             c.Disabled,
+		AllowedConnectors:    c.AllowedConnectors,
+		CreatedAt:            c.CreatedAt,
+		UpdatedAt:            c.UpdatedAt,
 	}
 }
 
@@ -300,6 +338,8 @@ type Claims struct {
 	Email             string   `json:"email"`
 	EmailVerified     bool     `json:"emailVerified"`
 	Groups            []string `json:"groups,omitempty"`
+
+	ExtraClaims map[string]interface{} `json:"extraClaims,omitempty"`
 }
 
 func fromStorageClaims(i storage.Claims) Claims {
@@ -310,6 +350,7 @@ func fromStorageClaims(i storage.Claims) Claims {
 		Email:             i.Email,
 		EmailVerified:     i.EmailVerified,
 		Groups:            i.Groups,
+		ExtraClaims:       i.ExtraClaims,
 	}
 }
 
@@ -321,6 +362,7 @@ func toStorageClaims(i Claims) storage.Claims {
 		Email:             i.Email,
 		EmailVerified:     i.EmailVerified,
 		Groups:            i.Groups,
+		ExtraClaims:       i.ExtraClaims,
 	}
 }
 
@@ -556,6 +598,7 @@ type RefreshToken struct {
 
 	Token         string `json:"token,omitempty"`
 	ObsoleteToken string `json:"obsoleteToken,omitempty"`
+	Consumed      bool   `json:"consumed,omitempty"`
 
 	Nonce string `json:"nonce,omitempty"`
 
@@ -576,6 +619,7 @@ func toStorageRefreshToken(r RefreshToken) storage.RefreshToken {
 		ID:            r.ObjectMeta.Name,
 		Token:         r.Token,
 		ObsoleteToken: r.ObsoleteToken,
+		Consumed:      r.Consumed,
 		CreatedAt:     r.CreatedAt,
 		LastUsed:      r.LastUsed,
 		ClientID:      r.ClientID,
@@ -599,6 +643,7 @@ func (cli *client) fromStorageRefreshToken(r storage.RefreshToken) RefreshToken
 		},
 		Token:         r.Token,
 		ObsoleteToken: r.ObsoleteToken,
+		Consumed:      r.Consumed,
 		CreatedAt:     r.CreatedAt,
 		LastUsed:      r.LastUsed,
 		ClientID:      r.ClientID,
@@ -751,11 +796,14 @@ type DeviceRequest struct {
 	k8sapi.TypeMeta   `json:",inline"`
 	k8sapi.ObjectMeta `json:"metadata,omitempty"`
 
-	DeviceCode   string    `json:"device_code,omitempty"`
-	ClientID     string    `json:"client_id,omitempty"`
-	ClientSecret string    `json:"client_secret,omitempty"`
-	Scopes       []string  `json:"scopes,omitempty"`
-	Expiry       time.Time `json:"expiry"`
+	DeviceCode          string    `json:"device_code,omitempty"`
+	ClientID            string    `json:"client_id,omitempty"`
+	ClientSecret        string    `json:"client_secret,omitempty"`
+	Scopes              []string  `json:"scopes,omitempty"`
+	Expiry              time.Time `json:"expiry"`
+	PollIntervalSeconds int       `json:"poll_interval,omitempty"`
+	Consumed            bool      `json:"consumed,omitempty"`
+	CreatedAt           time.Time `json:"createdAt,omitempty"`
 }
 
 // DeviceRequestList is a list of DeviceRequests.
@@ -775,23 +823,29 @@ func (cli *client) fromStorageDeviceRequest(a storage.DeviceRequest) DeviceReque
 			Name:      strings.ToLower(a.UserCode),
 			Namespace: cli.namespace,
 		},
-		DeviceCode:   a.DeviceCode,
-		ClientID:     a.ClientID,
-		ClientSecret: a.ClientSecret,
-		Scopes:       a.Scopes,
-		Expiry:       a.Expiry,
+		DeviceCode:          a.DeviceCode,
+		ClientID:            a.ClientID,
+		ClientSecret:        a.ClientSecret,
+		Scopes:              a.Scopes,
+		Expiry:              a.Expiry,
+		PollIntervalSeconds: a.PollIntervalSeconds,
+		Consumed:            a.Consumed,
+		CreatedAt:           a.CreatedAt,
 	}
 	return req
 }
 
 func toStorageDeviceRequest(req DeviceRequest) storage.DeviceRequest {
 	return storage.DeviceRequest{
-		UserCode:     strings.ToUpper(req.ObjectMeta.Name),
-		DeviceCode:   req.DeviceCode,
-		ClientID:     req.ClientID,
-		ClientSecret: req.ClientSecret,
-		Scopes:       req.Scopes,
-		Expiry:       req.Expiry,
+		UserCode:            strings.ToUpper(req.ObjectMeta.Name),
+		DeviceCode:          req.DeviceCode,
+		ClientID:            req.ClientID,
+		ClientSecret:        req.ClientSecret,
+		Scopes:              req.Scopes,
+		Expiry:              req.Expiry,
+		PollIntervalSeconds: req.PollIntervalSeconds,
+		Consumed:            req.Consumed,
+		CreatedAt:           req.CreatedAt,
 	}
 }
 
@@ -852,3 +906,49 @@ func toStorageDeviceToken(t DeviceToken) storage.DeviceToken {
 		},
 	}
 }
+
+// IdempotencyKey is a mirrored struct from storage with JSON struct tags and
+// Kubernetes type metadata.
+type IdempotencyKey struct {
+	// Name is a hash of the key.
+	k8sapi.TypeMeta   `json:",inline"`
+	k8sapi.ObjectMeta `json:"metadata,omitempty"`
+
+	Key         string    `json:"key,omitempty"`
+	Response    []byte    `json:"response,omitempty"`
+	RequestHash []byte    `json:"requestHash,omitempty"`
+	Expiry      time.Time `json:"expiry"`
+}
+
+// IdempotencyKeyList is a list of IdempotencyKeys.
+type IdempotencyKeyList struct {
+	k8sapi.TypeMeta `json:",inline"`
+	k8sapi.ListMeta `json:"metadata,omitempty"`
+	IdempotencyKeys []IdempotencyKey `json:"items"`
+}
+
+func (cli *client) fromStorageIdempotencyKey(k storage.IdempotencyKey) IdempotencyKey {
+	return IdempotencyKey{
+		TypeMeta: k8sapi.TypeMeta{
+			Kind:       kindIdempotencyKey,
+			APIVersion: cli.apiVersion,
+		},
+		ObjectMeta: k8sapi.ObjectMeta{
+			Name:      cli.idToName(k.Key),
+			Namespace: cli.namespace,
+		},
+		Key:         k.Key,
+		Response:    k.Response,
+		RequestHash: k.RequestHash,
+		Expiry:      k.Expiry,
+	}
+}
+
+func toStorageIdempotencyKey(k IdempotencyKey) storage.IdempotencyKey {
+	return storage.IdempotencyKey{
+		Key:         k.Key,
+		Response:    k.Response,
+		RequestHash: k.RequestHash,
+		Expiry:      k.Expiry,
+	}
+}
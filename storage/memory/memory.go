@@ -4,6 +4,7 @@ package memory
 import (
 	"context"
 	"log/slog"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -25,6 +26,7 @@ func New(logger *slog.Logger) storage.Storage {
 		connectors:      make(map[string]storage.Connector),
 		deviceRequests:  make(map[string]storage.DeviceRequest),
 		deviceTokens:    make(map[string]storage.DeviceToken),
+		idempotencyKeys: make(map[string]storage.IdempotencyKey),
 		logger:          logger,
 	}
 }
@@ -52,6 +54,7 @@ type memStorage struct {
 	connectors      map[string]storage.Connector
 	deviceRequests  map[string]storage.DeviceRequest
 	deviceTokens    map[string]storage.DeviceToken
+	idempotencyKeys map[string]storage.IdempotencyKey
 
 	keys storage.Keys
 
@@ -97,6 +100,12 @@ func (s *memStorage) GarbageCollect(ctx context.Context, now time.Time) (result
 				result.DeviceTokens++
 			}
 		}
+		for id, a := range s.idempotencyKeys {
+			if now.After(a.Expiry) {
+				delete(s.idempotencyKeys, id)
+				result.IdempotencyKeys++
+			}
+		}
 	})
 	return result, nil
 }
@@ -267,6 +276,17 @@ func (s *memStorage) GetConnector(ctx context.Context, id string) (connector sto
 	return
 }
 
+func (s *memStorage) GetClientsByName(ctx context.Context, name string, caseInsensitive bool) (clients []storage.Client, err error) {
+	s.tx(func() {
+		for _, client := range s.clients {
+			if client.Name == name || (caseInsensitive && strings.EqualFold(client.Name, name)) {
+				clients = append(clients, client)
+			}
+		}
+	})
+	return
+}
+
 func (s *memStorage) ListClients(ctx context.Context) (clients []storage.Client, err error) {
 	s.tx(func() {
 		for _, client := range s.clients {
@@ -337,6 +357,18 @@ func (s *memStorage) DeleteRefresh(ctx context.Context, id string) (err error) {
 	return
 }
 
+func (s *memStorage) DeleteRefreshTokensForUser(ctx context.Context, userID, connID string) (n int, err error) {
+	s.tx(func() {
+		for id, r := range s.refreshTokens {
+			if r.Claims.UserID == userID && r.ConnectorID == connID {
+				delete(s.refreshTokens, id)
+				n++
+			}
+		}
+	})
+	return n, nil
+}
+
 func (s *memStorage) DeleteAuthCode(ctx context.Context, id string) (err error) {
 	s.tx(func() {
 		if _, ok := s.authCodes[id]; !ok {
@@ -506,6 +538,29 @@ func (s *memStorage) GetDeviceRequest(ctx context.Context, userCode string) (req
 	return
 }
 
+func (s *memStorage) CountDeviceRequestsExpiringBefore(ctx context.Context, t time.Time) (n int, err error) {
+	s.tx(func() {
+		for _, req := range s.deviceRequests {
+			if req.Expiry.Before(t) {
+				n++
+			}
+		}
+	})
+	return
+}
+
+func (s *memStorage) ListDeviceRequestsCreatedBetween(ctx context.Context, from, to time.Time) (requests []storage.DeviceRequest, err error) {
+	s.tx(func() {
+		for _, req := range s.deviceRequests {
+			if !req.CreatedAt.Before(from) && req.CreatedAt.Before(to) {
+				requests = append(requests, req)
+			}
+		}
+	})
+	slices.SortFunc(requests, func(a, b storage.DeviceRequest) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return
+}
+
 func (s *memStorage) CreateDeviceToken(ctx context.Context, t storage.DeviceToken) (err error) {
 	s.tx(func() {
 		if _, ok := s.deviceTokens[t.DeviceCode]; ok {
@@ -541,3 +596,55 @@ func (s *memStorage) UpdateDeviceToken(ctx context.Context, deviceCode string, u
 	})
 	return
 }
+
+func (s *memStorage) CreateIdempotencyKey(ctx context.Context, k storage.IdempotencyKey) (err error) {
+	s.tx(func() {
+		if _, ok := s.idempotencyKeys[k.Key]; ok {
+			err = storage.ErrAlreadyExists
+		} else {
+			s.idempotencyKeys[k.Key] = k
+		}
+	})
+	return
+}
+
+func (s *memStorage) GetIdempotencyKey(ctx context.Context, key string) (k storage.IdempotencyKey, err error) {
+	s.tx(func() {
+		var ok bool
+		if k, ok = s.idempotencyKeys[key]; !ok {
+			err = storage.ErrNotFound
+			return
+		}
+	})
+	return
+}
+
+func (s *memStorage) DeleteIdempotencyKey(ctx context.Context, key string) (err error) {
+	s.tx(func() {
+		if _, ok := s.idempotencyKeys[key]; !ok {
+			err = storage.ErrNotFound
+			return
+		}
+		delete(s.idempotencyKeys, key)
+	})
+	return
+}
+
+func (s *memStorage) ConsumeDeviceRequest(ctx context.Context, deviceCode string) (err error) {
+	s.tx(func() {
+		for userCode, req := range s.deviceRequests {
+			if req.DeviceCode != deviceCode {
+				continue
+			}
+			if req.Consumed {
+				err = storage.ErrAlreadyConsumed
+				return
+			}
+			req.Consumed = true
+			s.deviceRequests[userCode] = req
+			return
+		}
+		err = storage.ErrNotFound
+	})
+	return
+}
@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/dexidp/dex/storage"
 )
@@ -291,3 +292,195 @@ func TestStaticConnectors(t *testing.T) {
 		}
 	}
 }
+
+func TestDeviceCodeValidation(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	format := storage.DeviceCodeFormat{UserCodeLength: 9, UserCodeGroupSize: 3, DeviceCodeLength: 20}
+	s := storage.WithDeviceCodeValidation(New(logger), format)
+
+	expiry := time.Now().Add(time.Minute)
+	request := func(userCode, deviceCode string) storage.DeviceRequest {
+		return storage.DeviceRequest{UserCode: userCode, DeviceCode: deviceCode, ClientID: "foo", Expiry: expiry}
+	}
+
+	tests := []struct {
+		name    string
+		action  func() error
+		wantErr bool
+	}{
+		{
+			name: "create request with generated codes",
+			action: func() error {
+				return s.CreateDeviceRequest(ctx, request(format.NewUserCode(), format.NewDeviceCode()))
+			},
+		},
+		{
+			name: "create request with default format user code",
+			action: func() error {
+				return s.CreateDeviceRequest(ctx, request(storage.NewUserCode(), format.NewDeviceCode()))
+			},
+			wantErr: true,
+		},
+		{
+			name: "create request with misplaced separator",
+			action: func() error {
+				if err := s.CreateDeviceRequest(ctx, request("BCDF-GHJKM", format.NewDeviceCode())); err != nil {
+					return err
+				}
+				_, err := s.GetDeviceRequest(ctx, "BCD-FGH-JKM")
+				return err
+			},
+		},
+		{
+			name: "create request with vowels in user code",
+			action: func() error {
+				return s.CreateDeviceRequest(ctx, request("ABC-DFG-HJK", format.NewDeviceCode()))
+			},
+			wantErr: true,
+		},
+		{
+			name: "create request with lowercase user code",
+			action: func() error {
+				if err := s.CreateDeviceRequest(ctx, request("bcd-fgh-jkl", format.NewDeviceCode())); err != nil {
+					return err
+				}
+				_, err := s.GetDeviceRequest(ctx, "BCD-FGH-JKL")
+				return err
+			},
+		},
+		{
+			name: "get request with ungrouped lowercase user code",
+			action: func() error {
+				_, err := s.GetDeviceRequest(ctx, "bcdfghjkl")
+				return err
+			},
+		},
+		{
+			name: "create request with default format device code",
+			action: func() error {
+				return s.CreateDeviceRequest(ctx, request(format.NewUserCode(), storage.NewDeviceCode()))
+			},
+			wantErr: true,
+		},
+		{
+			name: "create token with generated code",
+			action: func() error {
+				return s.CreateDeviceToken(ctx, storage.DeviceToken{DeviceCode: format.NewDeviceCode(), Expiry: expiry})
+			},
+		},
+		{
+			name: "create token with arbitrary code",
+			action: func() error {
+				return s.CreateDeviceToken(ctx, storage.DeviceToken{DeviceCode: "devicecode", Expiry: expiry})
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		err := tc.action()
+		if err != nil && !tc.wantErr {
+			t.Errorf("%s: %v", tc.name, err)
+		}
+		if err == nil && tc.wantErr {
+			t.Errorf("%s: expected error, didn't get one", tc.name)
+		}
+	}
+}
+
+func TestDeviceCodeFormatValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  storage.DeviceCodeFormat
+		wantErr bool
+	}{
+		{name: "defaults"},
+		{name: "ungrouped user code", format: storage.DeviceCodeFormat{UserCodeLength: 6, UserCodeGroupSize: 6}},
+		{name: "short user code", format: storage.DeviceCodeFormat{UserCodeLength: 4}, wantErr: true},
+		{name: "negative group size", format: storage.DeviceCodeFormat{UserCodeGroupSize: -1}, wantErr: true},
+		{name: "short device code", format: storage.DeviceCodeFormat{DeviceCodeLength: 8}, wantErr: true},
+		{name: "custom characters", format: storage.DeviceCodeFormat{UserCodeCharacters: "23456789BCDFGHJK"}},
+		{name: "too few characters", format: storage.DeviceCodeFormat{UserCodeCharacters: "BCDFG"}, wantErr: true},
+		{name: "ambiguous characters", format: storage.DeviceCodeFormat{UserCodeCharacters: "BCDFGHJKLM0"}, wantErr: true},
+		{name: "lowercase characters", format: storage.DeviceCodeFormat{UserCodeCharacters: "bcdfghjklm"}, wantErr: true},
+		{name: "separator character", format: storage.DeviceCodeFormat{UserCodeCharacters: "BCDFGHJKLM-"}, wantErr: true},
+		{name: "repeated characters", format: storage.DeviceCodeFormat{UserCodeCharacters: "BCDFGHJKLMB"}, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		err := tc.format.Validate()
+		if err != nil && !tc.wantErr {
+			t.Errorf("%s: %v", tc.name, err)
+		}
+		if err == nil && tc.wantErr {
+			t.Errorf("%s: expected error, didn't get one", tc.name)
+		}
+	}
+}
+
+func TestDeviceCodeFormatUserCodeCharacters(t *testing.T) {
+	format := storage.DeviceCodeFormat{UserCodeLength: 8, UserCodeGroupSize: 4, UserCodeCharacters: "2345678BCDFG"}
+	for i := 0; i < 20; i++ {
+		code := format.NewUserCode()
+		if err := format.ValidateUserCode(code); err != nil {
+			t.Fatalf("generated user code %q: %v", code, err)
+		}
+		if strings.Trim(strings.ReplaceAll(code, "-", ""), format.UserCodeCharacters) != "" {
+			t.Fatalf("generated user code %q contains characters outside %q", code, format.UserCodeCharacters)
+		}
+	}
+	if err := format.ValidateUserCode("BCDF-GHJK"); err == nil {
+		t.Errorf("expected user code with characters outside the configured set to be rejected")
+	}
+}
+
+func TestDeviceCodeFormatNormalizeUserCode(t *testing.T) {
+	tests := []struct {
+		format storage.DeviceCodeFormat
+		code   string
+		want   string
+	}{
+		{code: "BDWP-HQPK", want: "BDWP-HQPK"},
+		{code: "bdwp-hqpk", want: "BDWP-HQPK"},
+		{code: "bdwphqpk", want: "BDWP-HQPK"},
+		{code: " bdwp hqpk ", want: "BDWP-HQPK"},
+		{code: "BD-WP-HQ-PK", want: "BDWP-HQPK"},
+		{code: "bdwphqpk", want: "BDWPHQPK", format: storage.DeviceCodeFormat{UserCodeGroupSize: 8}},
+		{code: "bdwphqpkx", want: "BDW-PHQ-PKX", format: storage.DeviceCodeFormat{UserCodeLength: 9, UserCodeGroupSize: 3}},
+		{code: "-", want: ""},
+	}
+
+	for _, tc := range tests {
+		if got := tc.format.NormalizeUserCode(tc.code); got != tc.want {
+			t.Errorf("NormalizeUserCode(%q) = %q, want %q", tc.code, got, tc.want)
+		}
+	}
+}
+
+func TestLookupDeviceRequestUserCodeCase(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	s := New(logger)
+
+	req := storage.DeviceRequest{
+		UserCode:   "BDWP-HQPK",
+		DeviceCode: storage.NewDeviceCode(),
+		ClientID:   "foo",
+		Expiry:     time.Now().Add(time.Minute),
+	}
+	if err := s.CreateDeviceRequest(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, userCode := range []string{"bdwp-hqpk", "BDWPHQPK", "BDWP-HQPK"} {
+		got, err := storage.LookupDeviceRequest(ctx, s, userCode, time.Now())
+		if err != nil {
+			t.Errorf("%s: %v", userCode, err)
+			continue
+		}
+		if got.DeviceCode != req.DeviceCode {
+			t.Errorf("%s: resolved to device code %q, want %q", userCode, got.DeviceCode, req.DeviceCode)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// DeviceRequestMetrics records the lifecycle of device requests.
+// Implementations must be safe for concurrent use.
+type DeviceRequestMetrics interface {
+	// DeviceRequestCreated is called when a device request is stored.
+	DeviceRequestCreated()
+	// DeviceRequestConsumed is called when a device code is redeemed.
+	DeviceRequestConsumed()
+	// DeviceRequestsExpired is called with the number of expired device
+	// requests deleted by garbage collection.
+	DeviceRequestsExpired(n int64)
+	// DeviceRequestRedemptionFailed is called when a device code can't be
+	// redeemed because it's unknown or has already been used.
+	DeviceRequestRedemptionFailed()
+}
+
+// deviceRequestMetricsStorage is a storage that reports device request
+// operations to a DeviceRequestMetrics.
+type deviceRequestMetricsStorage struct {
+	Storage
+
+	metrics DeviceRequestMetrics
+}
+
+// WithDeviceRequestMetrics returns a storage that reports created, consumed
+// and expired device requests, as well as failed redemptions, to metrics.
+func WithDeviceRequestMetrics(s Storage, metrics DeviceRequestMetrics) Storage {
+	return deviceRequestMetricsStorage{s, metrics}
+}
+
+func (s deviceRequestMetricsStorage) CreateDeviceRequest(ctx context.Context, d DeviceRequest) error {
+	if err := s.Storage.CreateDeviceRequest(ctx, d); err != nil {
+		return err
+	}
+	s.metrics.DeviceRequestCreated()
+	return nil
+}
+
+func (s deviceRequestMetricsStorage) ConsumeDeviceRequest(ctx context.Context, deviceCode string) error {
+	err := s.Storage.ConsumeDeviceRequest(ctx, deviceCode)
+	switch {
+	case err == nil:
+		s.metrics.DeviceRequestConsumed()
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrAlreadyConsumed):
+		s.metrics.DeviceRequestRedemptionFailed()
+	}
+	return err
+}
+
+func (s deviceRequestMetricsStorage) GarbageCollect(ctx context.Context, now time.Time) (GCResult, error) {
+	result, err := s.Storage.GarbageCollect(ctx, now)
+	if result.DeviceRequests > 0 {
+		s.metrics.DeviceRequestsExpired(result.DeviceRequests)
+	}
+	return result, err
+}
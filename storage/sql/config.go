@@ -63,6 +63,8 @@ type NetworkDB struct {
 	MaxOpenConns    int // default: 5
 	MaxIdleConns    int // default: 5
 	ConnMaxLifetime int // Seconds, default: not set
+
+	DeviceRequestTTL time.Duration `json:"-"` // set from expiry.deviceRequests, default: 5m
 }
 
 // SSL represents SSL options for network databases.
@@ -195,7 +197,7 @@ func (p *Postgres) open(logger *slog.Logger) (*conn, error) {
 		return sqlErr.Code == pgErrUniqueViolation
 	}
 
-	c := &conn{db, &flavorPostgres, logger, errCheck}
+	c := &conn{db, &flavorPostgres, logger, errCheck, p.DeviceRequestTTL}
 	if _, err := c.migrate(); err != nil {
 		return nil, fmt.Errorf("failed to perform migrations: %v", err)
 	}
@@ -307,7 +309,7 @@ func (s *MySQL) open(logger *slog.Logger) (*conn, error) {
 			sqlErr.Number == mysqlErrDupEntryWithKeyName
 	}
 
-	c := &conn{db, &flavorMySQL, logger, errCheck}
+	c := &conn{db, &flavorMySQL, logger, errCheck, s.DeviceRequestTTL}
 	if _, err := c.migrate(); err != nil {
 		return nil, fmt.Errorf("failed to perform migrations: %v", err)
 	}
@@ -74,6 +74,23 @@ func (j jsonDecoder) Scan(dest interface{}) error {
 	return nil
 }
 
+// nullDecoder is like decoder, but leaves the underlying value untouched if the
+// column is NULL. It's used for JSON columns which were added by later migrations.
+func nullDecoder(i interface{}) sql.Scanner {
+	return jsonNullDecoder{jsonDecoder{i}}
+}
+
+type jsonNullDecoder struct {
+	jsonDecoder
+}
+
+func (j jsonNullDecoder) Scan(dest interface{}) error {
+	if dest == nil {
+		return nil
+	}
+	return j.jsonDecoder.Scan(dest)
+}
+
 // Abstract conn vs trans.
 type querier interface {
 	QueryRow(query string, args ...interface{}) *sql.Row
@@ -121,6 +138,14 @@ func (c *conn) GarbageCollect(ctc context.Context, now time.Time) (storage.GCRes
 		result.DeviceTokens = n
 	}
 
+	r, err = c.Exec(`delete from idempotency_key where expiry < $1`, now)
+	if err != nil {
+		return result, fmt.Errorf("gc idempotency_key: %v", err)
+	}
+	if n, err := r.RowsAffected(); err == nil {
+		result.IdempotencyKeys = n
+	}
+
 	return result, err
 }
 
@@ -134,10 +159,11 @@ func (c *conn) CreateAuthRequest(ctx context.Context, a storage.AuthRequest) err
 			connector_id, connector_data,
 			expiry,
 			code_challenge, code_challenge_method,
-			hmac_key
+			hmac_key,
+			claims_extra
 		)
 		values (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22
 		);
 	`,
 		a.ID, a.ClientID, encoder(a.ResponseTypes), encoder(a.Scopes), a.RedirectURI, a.Nonce, a.State,
@@ -148,6 +174,7 @@ func (c *conn) CreateAuthRequest(ctx context.Context, a storage.AuthRequest) err
 		a.Expiry,
 		a.PKCE.CodeChallenge, a.PKCE.CodeChallengeMethod,
 		a.HMACKey,
+		encoder(a.Claims.ExtraClaims),
 	)
 	if err != nil {
 		if c.alreadyExistsCheck(err) {
@@ -180,8 +207,9 @@ func (c *conn) UpdateAuthRequest(ctx context.Context, id string, updater func(a
 				connector_id = $15, connector_data = $16,
 				expiry = $17,
 				code_challenge = $18, code_challenge_method = $19,
-				hmac_key = $20
-			where id = $21;
+				hmac_key = $20,
+				claims_extra = $21
+			where id = $22;
 		`,
 			a.ClientID, encoder(a.ResponseTypes), encoder(a.Scopes), a.RedirectURI, a.Nonce, a.State,
 			a.ForceApprovalPrompt, a.LoggedIn,
@@ -191,6 +219,7 @@ func (c *conn) UpdateAuthRequest(ctx context.Context, id string, updater func(a
 			a.ConnectorID, a.ConnectorData,
 			a.Expiry,
 			a.PKCE.CodeChallenge, a.PKCE.CodeChallengeMethod, a.HMACKey,
+			encoder(a.Claims.ExtraClaims),
 			r.ID,
 		)
 		if err != nil {
@@ -212,7 +241,8 @@ func getAuthRequest(ctx context.Context, q querier, id string) (a storage.AuthRe
 			claims_user_id, claims_username, claims_preferred_username,
 			claims_email, claims_email_verified, claims_groups,
 			connector_id, connector_data, expiry,
-			code_challenge, code_challenge_method, hmac_key
+			code_challenge, code_challenge_method, hmac_key,
+			claims_extra
 		from auth_request where id = $1;
 	`, id).Scan(
 		&a.ID, &a.ClientID, decoder(&a.ResponseTypes), decoder(&a.Scopes), &a.RedirectURI, &a.Nonce, &a.State,
@@ -222,6 +252,7 @@ func getAuthRequest(ctx context.Context, q querier, id string) (a storage.AuthRe
 		decoder(&a.Claims.Groups),
 		&a.ConnectorID, &a.ConnectorData, &a.Expiry,
 		&a.PKCE.CodeChallenge, &a.PKCE.CodeChallengeMethod, &a.HMACKey,
+		nullDecoder(&a.Claims.ExtraClaims),
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -240,14 +271,16 @@ func (c *conn) CreateAuthCode(ctx context.Context, a storage.AuthCode) error {
 			claims_email, claims_email_verified, claims_groups,
 			connector_id, connector_data,
 			expiry,
-			code_challenge, code_challenge_method
+			code_challenge, code_challenge_method,
+			claims_extra
 		)
-		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16);
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17);
 	`,
 		a.ID, a.ClientID, encoder(a.Scopes), a.Nonce, a.RedirectURI, a.Claims.UserID,
 		a.Claims.Username, a.Claims.PreferredUsername, a.Claims.Email, a.Claims.EmailVerified,
 		encoder(a.Claims.Groups), a.ConnectorID, a.ConnectorData, a.Expiry,
 		a.PKCE.CodeChallenge, a.PKCE.CodeChallengeMethod,
+		encoder(a.Claims.ExtraClaims),
 	)
 	if err != nil {
 		if c.alreadyExistsCheck(err) {
@@ -266,13 +299,15 @@ func (c *conn) GetAuthCode(ctx context.Context, id string) (a storage.AuthCode,
 			claims_email, claims_email_verified, claims_groups,
 			connector_id, connector_data,
 			expiry,
-			code_challenge, code_challenge_method
+			code_challenge, code_challenge_method,
+			claims_extra
 		from auth_code where id = $1;
 	`, id).Scan(
 		&a.ID, &a.ClientID, decoder(&a.Scopes), &a.Nonce, &a.RedirectURI, &a.Claims.UserID,
 		&a.Claims.Username, &a.Claims.PreferredUsername, &a.Claims.Email, &a.Claims.EmailVerified,
 		decoder(&a.Claims.Groups), &a.ConnectorID, &a.ConnectorData, &a.Expiry,
 		&a.PKCE.CodeChallenge, &a.PKCE.CodeChallengeMethod,
+		nullDecoder(&a.Claims.ExtraClaims),
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -290,9 +325,10 @@ func (c *conn) CreateRefresh(ctx context.Context, r storage.RefreshToken) error
 			claims_user_id, claims_username, claims_preferred_username,
 			claims_email, claims_email_verified, claims_groups,
 			connector_id, connector_data,
-			token, obsolete_token, created_at, last_used
+			token, obsolete_token, created_at, last_used,
+			claims_extra, consumed
 		)
-		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16);
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18);
 	`,
 		r.ID, r.ClientID, encoder(r.Scopes), r.Nonce,
 		r.Claims.UserID, r.Claims.Username, r.Claims.PreferredUsername,
@@ -300,6 +336,7 @@ func (c *conn) CreateRefresh(ctx context.Context, r storage.RefreshToken) error
 		encoder(r.Claims.Groups),
 		r.ConnectorID, r.ConnectorData,
 		r.Token, r.ObsoleteToken, r.CreatedAt, r.LastUsed,
+		encoder(r.Claims.ExtraClaims), r.Consumed,
 	)
 	if err != nil {
 		if c.alreadyExistsCheck(err) {
@@ -336,16 +373,20 @@ func (c *conn) UpdateRefreshToken(ctx context.Context, id string, updater func(o
 				token = $12,
                 obsolete_token = $13,
 				created_at = $14,
-				last_used = $15
+				last_used = $15,
+				claims_extra = $16,
+				consumed = $17
 			where
-				id = $16
+				id = $18
 		`,
 			r.ClientID, encoder(r.Scopes), r.Nonce,
 			r.Claims.UserID, r.Claims.Username, r.Claims.PreferredUsername,
 			r.Claims.Email, r.Claims.EmailVerified,
 			encoder(r.Claims.Groups),
 			r.ConnectorID, r.ConnectorData,
-			r.Token, r.ObsoleteToken, r.CreatedAt, r.LastUsed, id,
+			r.Token, r.ObsoleteToken, r.CreatedAt, r.LastUsed,
+			encoder(r.Claims.ExtraClaims), r.Consumed,
+			id,
 		)
 		if err != nil {
 			return fmt.Errorf("update refresh token: %v", err)
@@ -366,7 +407,8 @@ func getRefresh(ctx context.Context, q querier, id string) (storage.RefreshToken
 			claims_email, claims_email_verified,
 			claims_groups,
 			connector_id, connector_data,
-			token, obsolete_token, created_at, last_used
+			token, obsolete_token, created_at, last_used,
+			claims_extra, consumed
 		from refresh_token where id = $1;
 	`, id))
 }
@@ -378,7 +420,8 @@ func (c *conn) ListRefreshTokens(ctx context.Context) ([]storage.RefreshToken, e
 			claims_user_id, claims_username, claims_preferred_username,
 			claims_email, claims_email_verified, claims_groups,
 			connector_id, connector_data,
-			token, obsolete_token, created_at, last_used
+			token, obsolete_token, created_at, last_used,
+			claims_extra, consumed
 		from refresh_token;
 	`)
 	if err != nil {
@@ -408,6 +451,7 @@ func scanRefresh(s scanner) (r storage.RefreshToken, err error) {
 		decoder(&r.Claims.Groups),
 		&r.ConnectorID, &r.ConnectorData,
 		&r.Token, &r.ObsoleteToken, &r.CreatedAt, &r.LastUsed,
+		nullDecoder(&r.Claims.ExtraClaims), &r.Consumed,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -513,9 +557,17 @@ func (c *conn) UpdateClient(ctx context.Context, id string, updater func(old sto
 				trusted_peers = $3,
 				public = $4,
 				name = $5,
-				logo_url = $6
-			where id = $7;
-		`, nc.Secret, encoder(nc.RedirectURIs), encoder(nc.TrustedPeers), nc.Public, nc.Name, nc.LogoURL, id,
+				logo_url = $6,
+				access_token_lifetime = $7,
+				created_at = $8,
+				updated_at = $9,
+				disabled = $10,
+				allowed_connectors = $11,
+				refresh_token_lifetime = $12
+			where id = $13;
+		`, nc.Secret, encoder(nc.RedirectURIs), encoder(nc.TrustedPeers), nc.Public, nc.Name, nc.LogoURL,
+			nc.AccessTokenLifetime, nc.CreatedAt, nc.UpdatedAt, nc.Disabled, encoder(nc.AllowedConnectors),
+			nc.RefreshTokenLifetime, id,
 		)
 		if err != nil {
 			return fmt.Errorf("update client: %v", err)
@@ -527,12 +579,15 @@ func (c *conn) UpdateClient(ctx context.Context, id string, updater func(old sto
 func (c *conn) CreateClient(ctx context.Context, cli storage.Client) error {
 	_, err := c.Exec(`
 		insert into client (
-			id, secret, redirect_uris, trusted_peers, public, name, logo_url
+			id, secret, redirect_uris, trusted_peers, public, name, logo_url,
+			access_token_lifetime, created_at, updated_at, disabled, allowed_connectors,
+			refresh_token_lifetime
 		)
-		values ($1, $2, $3, $4, $5, $6, $7);
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13);
 	`,
 		cli.ID, cli.Secret, encoder(cli.RedirectURIs), encoder(cli.TrustedPeers),
-		cli.Public, cli.Name, cli.LogoURL,
+		cli.Public, cli.Name, cli.LogoURL, cli.AccessTokenLifetime, cli.CreatedAt, cli.UpdatedAt,
+		cli.Disabled, encoder(cli.AllowedConnectors), cli.RefreshTokenLifetime,
 	)
 	if err != nil {
 		if c.alreadyExistsCheck(err) {
@@ -546,7 +601,9 @@ func (c *conn) CreateClient(ctx context.Context, cli storage.Client) error {
 func getClient(ctx context.Context, q querier, id string) (storage.Client, error) {
 	return scanClient(q.QueryRow(`
 		select
-			id, secret, redirect_uris, trusted_peers, public, name, logo_url
+			id, secret, redirect_uris, trusted_peers, public, name, logo_url,
+			access_token_lifetime, created_at, updated_at, disabled, allowed_connectors,
+			refresh_token_lifetime
 	    from client where id = $1;
 	`, id))
 }
@@ -555,15 +612,45 @@ func (c *conn) GetClient(ctx context.Context, id string) (storage.Client, error)
 	return getClient(ctx, c, id)
 }
 
+func (c *conn) GetClientsByName(ctx context.Context, name string, caseInsensitive bool) ([]storage.Client, error) {
+	query := `
+		select
+			id, secret, redirect_uris, trusted_peers, public, name, logo_url,
+			access_token_lifetime, created_at, updated_at, disabled, allowed_connectors,
+			refresh_token_lifetime
+		from client where name = $1;
+	`
+	if caseInsensitive {
+		query = `
+		select
+			id, secret, redirect_uris, trusted_peers, public, name, logo_url,
+			access_token_lifetime, created_at, updated_at, disabled, allowed_connectors,
+			refresh_token_lifetime
+		from client where lower(name) = lower($1);
+	`
+	}
+	rows, err := c.Query(query, name)
+	if err != nil {
+		return nil, err
+	}
+	return scanClients(rows)
+}
+
 func (c *conn) ListClients(ctx context.Context) ([]storage.Client, error) {
 	rows, err := c.Query(`
 		select
-			id, secret, redirect_uris, trusted_peers, public, name, logo_url
+			id, secret, redirect_uris, trusted_peers, public, name, logo_url,
+			access_token_lifetime, created_at, updated_at, disabled, allowed_connectors,
+			refresh_token_lifetime
 		from client;
 	`)
 	if err != nil {
 		return nil, err
 	}
+	return scanClients(rows)
+}
+
+func scanClients(rows *sql.Rows) ([]storage.Client, error) {
 	defer rows.Close()
 
 	var clients []storage.Client
@@ -583,7 +670,8 @@ func (c *conn) ListClients(ctx context.Context) ([]storage.Client, error) {
 func scanClient(s scanner) (cli storage.Client, err error) {
 	err = s.Scan(
 		&cli.ID, &cli.Secret, decoder(&cli.RedirectURIs), decoder(&cli.TrustedPeers),
-		&cli.Public, &cli.Name, &cli.LogoURL,
+		&cli.Public, &cli.Name, &cli.LogoURL, &cli.AccessTokenLifetime, &cli.CreatedAt, &cli.UpdatedAt,
+		&cli.Disabled, nullDecoder(&cli.AllowedConnectors), &cli.RefreshTokenLifetime,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -880,6 +968,20 @@ func (c *conn) DeleteRefresh(ctx context.Context, id string) error {
 	return c.delete("refresh_token", "id", id)
 }
 
+func (c *conn) DeleteRefreshTokensForUser(ctx context.Context, userID, connID string) (int, error) {
+	result, err := c.Exec(`
+		delete from refresh_token where claims_user_id = $1 and connector_id = $2;
+	`, userID, connID)
+	if err != nil {
+		return 0, fmt.Errorf("delete refresh tokens: %v", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("rows affected: %v", err)
+	}
+	return int(n), nil
+}
+
 func (c *conn) DeletePassword(ctx context.Context, email string) error {
 	return c.delete("password", "email", strings.ToLower(email))
 }
@@ -928,12 +1030,12 @@ func (c *conn) delete(table, field, id string) error {
 func (c *conn) CreateDeviceRequest(ctx context.Context, d storage.DeviceRequest) error {
 	_, err := c.Exec(`
 		insert into device_request (
-			user_code, device_code, client_id, client_secret, scopes, expiry
+			user_code, device_code, client_id, client_secret, scopes, expiry, poll_interval, consumed, created_at
 		)
 		values (
-			$1, $2, $3, $4, $5, $6
+			$1, $2, $3, $4, $5, $6, $7, $8, $9
 		);`,
-		d.UserCode, d.DeviceCode, d.ClientID, d.ClientSecret, encoder(d.Scopes), d.Expiry,
+		d.UserCode, d.DeviceCode, d.ClientID, d.ClientSecret, encoder(d.Scopes), d.Expiry, d.PollIntervalSeconds, d.Consumed, d.CreatedAt,
 	)
 	if err != nil {
 		if c.alreadyExistsCheck(err) {
@@ -970,10 +1072,10 @@ func (c *conn) GetDeviceRequest(ctx context.Context, userCode string) (storage.D
 func getDeviceRequest(ctx context.Context, q querier, userCode string) (d storage.DeviceRequest, err error) {
 	err = q.QueryRow(`
 		select
-            device_code, client_id, client_secret, scopes, expiry
+            device_code, client_id, client_secret, scopes, expiry, poll_interval, consumed, created_at
 		from device_request where user_code = $1;
 	`, userCode).Scan(
-		&d.DeviceCode, &d.ClientID, &d.ClientSecret, decoder(&d.Scopes), &d.Expiry,
+		&d.DeviceCode, &d.ClientID, &d.ClientSecret, decoder(&d.Scopes), &d.Expiry, &d.PollIntervalSeconds, &d.Consumed, &d.CreatedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -985,6 +1087,45 @@ func getDeviceRequest(ctx context.Context, q querier, userCode string) (d storag
 	return d, nil
 }
 
+func (c *conn) CountDeviceRequestsExpiringBefore(ctx context.Context, t time.Time) (n int, err error) {
+	err = c.QueryRow(`
+		select count(*) from device_request where expiry < $1;
+	`, t).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("count device requests: %v", err)
+	}
+	return n, nil
+}
+
+func (c *conn) ListDeviceRequestsCreatedBetween(ctx context.Context, from, to time.Time) ([]storage.DeviceRequest, error) {
+	rows, err := c.Query(`
+		select
+			user_code, device_code, client_id, client_secret, scopes, expiry, poll_interval, consumed, created_at
+		from device_request where created_at >= $1 and created_at < $2
+		order by created_at;
+	`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("query device requests: %v", err)
+	}
+	defer rows.Close()
+
+	var requests []storage.DeviceRequest
+	for rows.Next() {
+		var d storage.DeviceRequest
+		err := rows.Scan(
+			&d.UserCode, &d.DeviceCode, &d.ClientID, &d.ClientSecret, decoder(&d.Scopes), &d.Expiry, &d.PollIntervalSeconds, &d.Consumed, &d.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan device request: %v", err)
+		}
+		requests = append(requests, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scan: %v", err)
+	}
+	return requests, nil
+}
+
 func (c *conn) GetDeviceToken(ctx context.Context, deviceCode string) (storage.DeviceToken, error) {
 	return getDeviceToken(ctx, c, deviceCode)
 }
@@ -1036,3 +1177,76 @@ func (c *conn) UpdateDeviceToken(ctx context.Context, deviceCode string, updater
 		return nil
 	})
 }
+
+func (c *conn) ConsumeDeviceRequest(ctx context.Context, deviceCode string) error {
+	return c.ExecTx(func(tx *trans) error {
+		// Only one of concurrent updates can flip the flag.
+		result, err := tx.Exec(`
+			update device_request set consumed = $1
+			where device_code = $2 and consumed = $3;
+		`, true, deviceCode, false)
+		if err != nil {
+			return fmt.Errorf("consume device request: %v", err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("rows affected: %v", err)
+		}
+		if n > 0 {
+			return nil
+		}
+
+		var consumed bool
+		err = tx.QueryRow(`
+			select consumed from device_request where device_code = $1;
+		`, deviceCode).Scan(&consumed)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return storage.ErrNotFound
+			}
+			return fmt.Errorf("select device request: %v", err)
+		}
+		return storage.ErrAlreadyConsumed
+	})
+}
+
+func (c *conn) CreateIdempotencyKey(ctx context.Context, k storage.IdempotencyKey) error {
+	_, err := c.Exec(`
+		insert into idempotency_key (
+			id, response, request_hash, expiry
+		)
+		values (
+			$1, $2, $3, $4
+		);`,
+		k.Key, k.Response, k.RequestHash, k.Expiry,
+	)
+	if err != nil {
+		if c.alreadyExistsCheck(err) {
+			return storage.ErrAlreadyExists
+		}
+		return fmt.Errorf("insert idempotency key: %v", err)
+	}
+	return nil
+}
+
+func (c *conn) GetIdempotencyKey(ctx context.Context, key string) (k storage.IdempotencyKey, err error) {
+	err = c.QueryRow(`
+		select
+			response, request_hash, expiry
+		from idempotency_key where id = $1;
+	`, key).Scan(
+		&k.Response, &k.RequestHash, &k.Expiry,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return k, storage.ErrNotFound
+		}
+		return k, fmt.Errorf("select idempotency key: %v", err)
+	}
+	k.Key = key
+	return k, nil
+}
+
+func (c *conn) DeleteIdempotencyKey(ctx context.Context, key string) error {
+	return c.delete("idempotency_key", "id", key)
+}
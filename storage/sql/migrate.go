@@ -3,8 +3,12 @@ package sql
 import (
 	"database/sql"
 	"fmt"
+	"time"
 )
 
+// defaultDeviceRequestTTL is the server's default device request lifetime.
+const defaultDeviceRequestTTL = 5 * time.Minute
+
 func (c *conn) migrate() (int, error) {
 	_, err := c.Exec(`
 		create table if not exists migrations (
@@ -51,6 +55,11 @@ func (c *conn) migrate() (int, error) {
 					return fmt.Errorf("migration %d statement %d failed: %v", migrationNum, i+1, err)
 				}
 			}
+			if m.backfill != nil {
+				if err := m.backfill(tx); err != nil {
+					return fmt.Errorf("migration %d backfill failed: %v", migrationNum, err)
+				}
+			}
 
 			q := `insert into migrations (num, at) values ($1, now());`
 			if _, err := tx.Exec(q, migrationNum); err != nil {
@@ -73,6 +82,10 @@ func (c *conn) migrate() (int, error) {
 type migration struct {
 	stmts []string
 
+	// backfill, if set, runs after stmts in the same transaction to fill in
+	// columns they added with values that can't be computed in SQL.
+	backfill func(tx *trans) error
+
 	// If flavor is nil the migration will take place for all database backend flavors.
 	// If specified, only for that corresponding flavor, in that case stmts can be written
 	// in the specific SQL dialect.
@@ -298,4 +311,154 @@ var migrations = []migration{
 				add column hmac_key bytea;`,
 		},
 	},
+	{
+		stmts: []string{
+			`
+			alter table auth_request
+				add column claims_extra bytea;`,
+			`
+			alter table auth_code
+				add column claims_extra bytea;`,
+			`
+			alter table refresh_token
+				add column claims_extra bytea;`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			alter table client
+				add column access_token_lifetime bigint not null default 0;`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			alter table refresh_token
+				add column consumed boolean not null default false;`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			alter table client
+				add column created_at timestamptz not null default '0001-01-01 00:00:00 UTC';`,
+			`
+			alter table client
+				add column updated_at timestamptz not null default '0001-01-01 00:00:00 UTC';`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			create index device_request_expiry on device_request (expiry);`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			alter table device_request
+				add column poll_interval integer not null default 5;`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			alter table device_request
+				add column consumed boolean not null default false;`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			create index client_name on client (name);`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			alter table client
+				add column disabled boolean not null default false;`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			alter table client
+				add column allowed_connectors bytea;`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			create table idempotency_key (
+				id text not null primary key,
+				response bytea not null,
+				expiry timestamptz not null
+			);`,
+			`
+			create index idempotency_key_expiry on idempotency_key (expiry);`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			alter table client
+				add column refresh_token_lifetime bigint not null default 0;`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			alter table idempotency_key
+				add column request_hash bytea;`,
+		},
+	},
+	{
+		stmts: []string{
+			`
+			alter table device_request
+				add column created_at timestamptz;`,
+		},
+		backfill: backfillDeviceRequestCreatedAt,
+	},
+}
+
+// backfillDeviceRequestCreatedAt dates device requests stored before their
+// creation time was recorded by their expiry minus the device request lifetime.
+func backfillDeviceRequestCreatedAt(tx *trans) error {
+	ttl := tx.c.deviceRequestTTL
+	if ttl == 0 {
+		ttl = defaultDeviceRequestTTL
+	}
+
+	rows, err := tx.Query(`select user_code, expiry from device_request where created_at is null;`)
+	if err != nil {
+		return fmt.Errorf("select device requests: %v", err)
+	}
+	defer rows.Close()
+
+	expiries := make(map[string]time.Time)
+	for rows.Next() {
+		var (
+			userCode string
+			expiry   time.Time
+		)
+		if err := rows.Scan(&userCode, &expiry); err != nil {
+			return fmt.Errorf("scan device request: %v", err)
+		}
+		expiries[userCode] = expiry
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("select device requests: %v", err)
+	}
+	rows.Close()
+
+	for userCode, expiry := range expiries {
+		_, err := tx.Exec(`update device_request set created_at = $1 where user_code = $2;`, expiry.Add(-ttl), userCode)
+		if err != nil {
+			return fmt.Errorf("update device request: %v", err)
+		}
+	}
+	return nil
 }
@@ -4,10 +4,13 @@
 package sql
 
 import (
+	"context"
 	"database/sql"
 	"io"
 	"log/slog"
+	"strings"
 	"testing"
+	"time"
 
 	sqlite3 "github.com/mattn/go-sqlite3"
 )
@@ -36,7 +39,7 @@ func TestMigrate(t *testing.T) {
 		}
 	}
 
-	c := &conn{db, &flavorSQLite3, logger, errCheck}
+	c := &conn{db, &flavorSQLite3, logger, errCheck, 0}
 	for _, want := range []int{len(sqliteMigrations), 0} {
 		got, err := c.migrate()
 		if err != nil {
@@ -47,3 +50,70 @@ func TestMigrate(t *testing.T) {
 		}
 	}
 }
+
+func TestMigrateClientNameIndex(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	c := &conn{db, &flavorSQLite3, logger, func(error) bool { return false }, 0}
+	if _, err := c.migrate(); err != nil {
+		t.Fatal(err)
+	}
+
+	var id, parent, notUsed int
+	var detail string
+	err = db.QueryRow(`explain query plan select id from client where name = ?`, "Admin UI").
+		Scan(&id, &parent, &notUsed, &detail)
+	if err != nil {
+		t.Fatalf("explain query: %v", err)
+	}
+	if !strings.Contains(detail, "USING INDEX client_name") {
+		t.Errorf("expected the query to use the name index, got plan %q", detail)
+	}
+}
+
+func TestMigrateDeviceRequestCreatedAt(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	c := &conn{db, &flavorSQLite3, logger, func(error) bool { return false }, 10 * time.Minute}
+
+	// Migrate up to before the created_at column was added, and store a
+	// device request in that schema.
+	all := migrations
+	migrations = all[:len(all)-1]
+	_, err = c.migrate()
+	migrations = all
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expiry := time.Now().UTC().Truncate(time.Second)
+	_, err = c.Exec(`
+		insert into device_request (user_code, device_code, client_id, client_secret, scopes, expiry)
+		values ($1, $2, $3, $4, $5, $6);`,
+		"OLD", "device-code", "client", "secret", encoder([]string{"openid"}), expiry)
+	if err != nil {
+		t.Fatalf("insert device request: %v", err)
+	}
+
+	if _, err := c.migrate(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.GetDeviceRequest(context.Background(), "OLD")
+	if err != nil {
+		t.Fatalf("get device request: %v", err)
+	}
+	if want := expiry.Add(-10 * time.Minute); !got.CreatedAt.Equal(want) {
+		t.Errorf("expected the device request to be created at %v, got %v", want, got.CreatedAt)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log/slog"
+	"time"
 
 	sqlite3 "github.com/mattn/go-sqlite3"
 
@@ -17,6 +18,9 @@ import (
 type SQLite3 struct {
 	// File to
 	File string `json:"file"`
+
+	// DeviceRequestTTL is set from expiry.deviceRequests.
+	DeviceRequestTTL time.Duration `json:"-"`
 }
 
 // Open creates a new storage implementation backed by SQLite3
@@ -45,7 +49,7 @@ func (s *SQLite3) open(logger *slog.Logger) (*conn, error) {
 		return sqlErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey
 	}
 
-	c := &conn{db, &flavorSQLite3, logger, errCheck}
+	c := &conn{db, &flavorSQLite3, logger, errCheck, s.DeviceRequestTTL}
 	if _, err := c.migrate(); err != nil {
 		return nil, fmt.Errorf("failed to perform migrations: %v", err)
 	}
@@ -60,6 +60,29 @@ func (s staticClientsStorage) ListClients(ctx context.Context) ([]Client, error)
 	return append(clients[:n], s.clients...), nil
 }
 
+func (s staticClientsStorage) GetClientsByName(ctx context.Context, name string, caseInsensitive bool) ([]Client, error) {
+	clients, err := s.Storage.GetClientsByName(ctx, name, caseInsensitive)
+	if err != nil {
+		return nil, err
+	}
+	n := 0
+	for _, client := range clients {
+		// If a client in the backing storage has the same ID as a static client
+		// prefer the static client.
+		if !s.isStatic(client.ID) {
+			clients[n] = client
+			n++
+		}
+	}
+	clients = clients[:n]
+	for _, client := range s.clients {
+		if client.Name == name || (caseInsensitive && strings.EqualFold(client.Name, name)) {
+			clients = append(clients, client)
+		}
+	}
+	return clients, nil
+}
+
 func (s staticClientsStorage) CreateClient(ctx context.Context, c Client) error {
 	if s.isStatic(c.ID) {
 		return errors.New("static clients: read-only cannot create client")
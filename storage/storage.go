@@ -20,6 +20,14 @@ var (
 
 	// ErrAlreadyExists is the error returned by storages if a resource ID is taken during a create.
 	ErrAlreadyExists = errors.New("ID already exists")
+
+	// ErrAlreadyConsumed is the error returned by storages if a single-use resource
+	// has already been used.
+	ErrAlreadyConsumed = errors.New("already consumed")
+
+	// ErrExpired is the error returned by lookups of a resource that still exists
+	// but has expired.
+	ErrExpired = errors.New("expired")
 )
 
 // Kubernetes only allows lower case letters for names.
@@ -30,9 +38,9 @@ var encoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567")
 // Valid characters for user codes
 const validUserCharacters = "BCDFGHJKLMNPQRSTVWXZ"
 
-// NewDeviceCode returns a 32 char alphanumeric cryptographically secure string
+// NewDeviceCode returns a device code in the default DeviceCodeFormat.
 func NewDeviceCode() string {
-	return newSecureID(32)
+	return DeviceCodeFormat{}.NewDeviceCode()
 }
 
 // NewID returns a random string which can be used as an ID for objects.
@@ -56,10 +64,11 @@ func NewHMACKey(h crypto.Hash) []byte {
 
 // GCResult returns the number of objects deleted by garbage collection.
 type GCResult struct {
-	AuthRequests   int64
-	AuthCodes      int64
-	DeviceRequests int64
-	DeviceTokens   int64
+	AuthRequests    int64
+	AuthCodes       int64
+	DeviceRequests  int64
+	DeviceTokens    int64
+	IdempotencyKeys int64
 }
 
 // IsEmpty returns whether the garbage collection result is empty or not.
@@ -67,7 +76,8 @@ func (g *GCResult) IsEmpty() bool {
 	return g.AuthRequests == 0 &&
 		g.AuthCodes == 0 &&
 		g.DeviceRequests == 0 &&
-		g.DeviceTokens == 0
+		g.DeviceTokens == 0 &&
+		g.IdempotencyKeys == 0
 }
 
 // Storage is the storage interface used by the server. Implementations are
@@ -86,12 +96,16 @@ type Storage interface {
 	CreateConnector(ctx context.Context, c Connector) error
 	CreateDeviceRequest(ctx context.Context, d DeviceRequest) error
 	CreateDeviceToken(ctx context.Context, d DeviceToken) error
+	CreateIdempotencyKey(ctx context.Context, k IdempotencyKey) error
 
 	// TODO(ericchiang): return (T, bool, error) so we can indicate not found
 	// requests that way instead of using ErrNotFound.
 	GetAuthRequest(ctx context.Context, id string) (AuthRequest, error)
 	GetAuthCode(ctx context.Context, id string) (AuthCode, error)
 	GetClient(ctx context.Context, id string) (Client, error)
+	// GetClientsByName returns all clients with the given name. Names aren't
+	// unique, so this may return more than one client, or none.
+	GetClientsByName(ctx context.Context, name string, caseInsensitive bool) ([]Client, error)
 	GetKeys(ctx context.Context) (Keys, error)
 	GetRefresh(ctx context.Context, id string) (RefreshToken, error)
 	GetPassword(ctx context.Context, email string) (Password, error)
@@ -99,6 +113,16 @@ type Storage interface {
 	GetConnector(ctx context.Context, id string) (Connector, error)
 	GetDeviceRequest(ctx context.Context, userCode string) (DeviceRequest, error)
 	GetDeviceToken(ctx context.Context, deviceCode string) (DeviceToken, error)
+	GetIdempotencyKey(ctx context.Context, key string) (IdempotencyKey, error)
+
+	// CountDeviceRequestsExpiringBefore returns the number of device requests whose
+	// expiry is before t. Expired requests that haven't been garbage collected yet
+	// are included.
+	CountDeviceRequestsExpiringBefore(ctx context.Context, t time.Time) (int, error)
+
+	// ListDeviceRequestsCreatedBetween returns the device requests created at or
+	// after from and before to, oldest first.
+	ListDeviceRequestsCreatedBetween(ctx context.Context, from, to time.Time) ([]DeviceRequest, error)
 
 	ListClients(ctx context.Context) ([]Client, error)
 	ListRefreshTokens(ctx context.Context) ([]RefreshToken, error)
@@ -113,6 +137,12 @@ type Storage interface {
 	DeletePassword(ctx context.Context, email string) error
 	DeleteOfflineSessions(ctx context.Context, userID string, connID string) error
 	DeleteConnector(ctx context.Context, id string) error
+	DeleteIdempotencyKey(ctx context.Context, key string) error
+
+	// DeleteRefreshTokensForUser deletes all refresh tokens issued to the user
+	// through the connector, and returns how many were deleted. It doesn't
+	// update the user's offline sessions.
+	DeleteRefreshTokensForUser(ctx context.Context, userID, connID string) (int, error)
 
 	// Update methods take a function for updating an object then performs that update within
 	// a transaction. "updater" functions may be called multiple times by a single update call.
@@ -137,8 +167,13 @@ type Storage interface {
 	UpdateConnector(ctx context.Context, id string, updater func(c Connector) (Connector, error)) error
 	UpdateDeviceToken(ctx context.Context, deviceCode string, updater func(t DeviceToken) (DeviceToken, error)) error
 
+	// ConsumeDeviceRequest atomically marks the device request with the given
+	// device code as used. It returns ErrAlreadyConsumed if it already was, so
+	// a device code can only be exchanged once.
+	ConsumeDeviceRequest(ctx context.Context, deviceCode string) error
+
 	// GarbageCollect deletes all expired AuthCodes,
-	// AuthRequests, DeviceRequests, and DeviceTokens.
+	// AuthRequests, DeviceRequests, DeviceTokens, and IdempotencyKeys.
 	GarbageCollect(ctx context.Context, now time.Time) (GCResult, error)
 }
 
@@ -171,6 +206,28 @@ type Client struct {
 	// Name and LogoURL used when displaying this client to the end user.
 	Name    string `json:"name" yaml:"name"`
 	LogoURL string `json:"logoURL" yaml:"logoURL"`
+
+	// AccessTokenLifetime overrides how long access tokens issued to this client are
+	// valid for, in seconds. Zero means the server default is used.
+	AccessTokenLifetime int64 `json:"accessTokenLifetime" yaml:"accessTokenLifetime"`
+
+	// RefreshTokenLifetime overrides how long refresh tokens issued to this client
+	// are valid for after they were created, in seconds. Zero means the server
+	// default is used.
+	RefreshTokenLifetime int64 `json:"refreshTokenLifetime" yaml:"refreshTokenLifetime"`
+
+	// Disabled clients keep their configuration but can't complete authorization
+	// or obtain tokens.
+	Disabled bool `json:"disabled" yaml:"disabled"`
+
+	// AllowedConnectors restricts which connectors users of this client may log in
+	// with. An empty list allows all connectors.
+	AllowedConnectors []string `json:"allowedConnectors" yaml:"allowedConnectors"`
+
+	// CreatedAt and UpdatedAt record when the client was registered and last changed.
+	// They are zero for clients stored before they were tracked.
+	CreatedAt time.Time `json:"createdAt" yaml:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt" yaml:"updatedAt"`
 }
 
 // Claims represents the ID Token claims supported by the server.
@@ -182,6 +239,8 @@ type Claims struct {
 	EmailVerified     bool
 
 	Groups []string
+
+	ExtraClaims map[string]interface{}
 }
 
 // PKCE is a container for the data needed to perform Proof Key for Code Exchange (RFC 7636) auth flow
@@ -281,9 +340,15 @@ type RefreshToken struct {
 	// A single token that's rotated every time the refresh token is refreshed.
 	//
 	// May be empty.
-	Token         string
+	Token string
+	// ObsoleteToken is the token Token was rotated from. It links the refresh
+	// token to the previous step of its rotation chain.
 	ObsoleteToken string
 
+	// Consumed is set once a replay of an already rotated token has revoked this
+	// refresh token. Consumed refresh tokens are never honored again.
+	Consumed bool
+
 	CreatedAt time.Time
 	LastUsed  time.Time
 
@@ -399,19 +464,18 @@ type Keys struct {
 	NextRotation time.Time
 }
 
-// NewUserCode returns a randomized 8 character user code for the device flow.
-// No vowels are included to prevent accidental generation of words
+// NewUserCode returns a user code in the default DeviceCodeFormat, e.g.
+// "BDWP-HQPK". No vowels are included to prevent accidental generation of words
 func NewUserCode() string {
-	code := randomString(8)
-	return code[:4] + "-" + code[4:]
+	return DeviceCodeFormat{}.NewUserCode()
 }
 
-func randomString(n int) string {
-	v := big.NewInt(int64(len(validUserCharacters)))
+func randomString(chars string, n int) string {
+	v := big.NewInt(int64(len(chars)))
 	bytes := make([]byte, n)
 	for i := 0; i < n; i++ {
 		c, _ := rand.Int(rand.Reader, v)
-		bytes[i] = validUserCharacters[c.Int64()]
+		bytes[i] = chars[c.Int64()]
 	}
 	return string(bytes)
 }
@@ -431,6 +495,12 @@ type DeviceRequest struct {
 	Scopes []string
 	// The expire time
 	Expiry time.Time
+	// How often, in seconds, the device may poll for a token
+	PollIntervalSeconds int
+	// Whether the device code has already been exchanged for a token
+	Consumed bool
+	// When the request was created
+	CreatedAt time.Time
 }
 
 // DeviceToken is a structure which represents the actual token of an authorized device and its rotation parameters
@@ -443,3 +513,19 @@ type DeviceToken struct {
 	PollIntervalSeconds int
 	PKCE                PKCE
 }
+
+// IdempotencyKey records the response to an API call made with an idempotency
+// key, so that retries of the call get the same response instead of being
+// executed again.
+type IdempotencyKey struct {
+	// The key supplied by the caller, prefixed by the API call it was used
+	// with.
+	Key string
+	// The serialized response to the call.
+	Response []byte
+	// A hash of the request, to tell retries from other requests reusing the
+	// key.
+	RequestHash []byte
+	// The time after which the call is no longer deduplicated.
+	Expiry time.Time
+}